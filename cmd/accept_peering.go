@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/sgykfjsm/msk/internal/vpcpeering"
 	"github.com/urfave/cli/v3"
 )
@@ -42,12 +42,17 @@ var AcceptPeeringCmd = &cli.Command{
 			return fmt.Errorf("cannot use both check-only and dry-run flags together")
 		}
 
+		cfg, err := loadAWSConfig(ctx, c)
+		if err != nil {
+			return err
+		}
+
 		if dryRun {
 			fmt.Printf("Dry run: would accept VPC peering connection with ID %q\n", peeringID)
-			util.PrintAWSVariables(ctx, c.Root().Writer)
+			printAWSVariables(ctx, c, cfg)
 			return nil
 		}
 
-		return vpcpeering.AcceptVPCPeeringConnection(ctx, peeringID, c.Root().Writer, checkOnly)
+		return vpcpeering.AcceptVPCPeeringConnection(ctx, ec2.NewFromConfig(cfg), peeringID, c.Root().Writer, checkOnly)
 	},
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/urfave/cli/v3"
+)
+
+// AWSFlags are the global options used to build every AWS client, so that the network commands
+// can work against VPCs in other accounts and regions.
+var AWSFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "aws-profile",
+		Usage: "AWS shared config profile to use. Defaults to the SDK default (AWS_PROFILE or 'default')",
+	},
+	&cli.StringFlag{
+		Name:  "aws-region",
+		Usage: "AWS region to use. Defaults to the region of the profile or AWS_REGION",
+	},
+	&cli.StringFlag{
+		Name:  "assume-role-arn",
+		Usage: "ARN of the IAM role to assume for all AWS calls, e.g. to work with VPCs in another account",
+	},
+	&cli.StringFlag{
+		Name:    "assume-role-external-id",
+		Usage:   "External ID to pass when assuming the role",
+		Sources: cli.EnvVars("MSK_ASSUME_ROLE_EXTERNAL_ID"),
+	},
+	&cli.StringFlag{
+		Name:  "assume-role-session-name",
+		Usage: "Session name of the assumed role",
+		Value: "msk",
+	},
+	&cli.StringFlag{
+		Name:  "aws-endpoint-url",
+		Usage: "Base endpoint URL of all AWS services, e.g. http://localhost:4566 for LocalStack or Moto",
+	},
+}
+
+// awsOptions returns the AWS options given by the global flags.
+func awsOptions(c *cli.Command) util.AWSOptions {
+	return util.AWSOptions{
+		Profile:       c.String("aws-profile"),
+		Region:        c.String("aws-region"),
+		AssumeRoleARN: c.String("assume-role-arn"),
+		ExternalID:    c.String("assume-role-external-id"),
+		SessionName:   c.String("assume-role-session-name"),
+		EndpointURL:   c.String("aws-endpoint-url"),
+	}
+}
+
+// loadAWSConfig loads the AWS configuration according to the global flags.
+func loadAWSConfig(ctx context.Context, c *cli.Command) (aws.Config, error) {
+	return util.LoadAWSConfig(ctx, awsOptions(c))
+}
+
+// printAWSVariables prints the effective AWS context. Errors are printed rather than returned
+// because it is used only for information in dry runs.
+func printAWSVariables(ctx context.Context, c *cli.Command, cfg aws.Config) {
+	if err := util.PrintAWSVariables(ctx, cfg, sts.NewFromConfig(cfg), awsOptions(c), c.Root().Writer); err != nil {
+		fmt.Fprintf(c.Root().Writer, "failed to print AWS context variables: %v\n", err)
+	}
+}
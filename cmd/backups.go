@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/urfave/cli/v3"
+)
+
+var TrackBackupsCmd = &cli.Command{
+	Name:  "track-backups",
+	Usage: "Opt projects in to (or out of) backup tracking. Backups of opted-in projects are fetched by fetch-clusters",
+	UsageText: `msk track-backups --project-id 123 --project-id 456
+msk track-backups --project-id 123 --disable`,
+	Flags: append([]cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "project-id",
+			Usage:    "Target project ID(s) (can be specified multiple times)",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "disable",
+			Usage: "Opt the projects out of backup tracking",
+		},
+	}, newDBFlags("updating projects")...),
+	Action: func(ctx context.Context, c *cli.Command) error {
+		dsn, err := dbConnectionString(c)
+		if err != nil {
+			return err
+		}
+
+		store, err := backups.NewDBBackupStore(dsn, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create backup store: %w", err)
+		}
+		defer store.Close()
+
+		enabled := !c.Bool("disable")
+		for _, projectID := range c.StringSlice("project-id") {
+			if err := store.SetBackupTracking(ctx, projectID, enabled); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.Root().Writer, "[SUCCESS] Backup tracking of project %s is set to %t\n", projectID, enabled)
+		}
+
+		return nil
+	},
+}
+
+var AnalyzeCmd = &cli.Command{
+	Name:  "analyze",
+	Usage: "Analyze the information collected by fetch-clusters",
+	Commands: []*cli.Command{
+		{
+			Name:      "backups",
+			Usage:     "List clusters whose latest successful backup is older than the threshold or missing",
+			UsageText: `msk analyze backups --threshold 48h`,
+			Flags: append([]cli.Flag{
+				&cli.DurationFlag{
+					Name:  "threshold",
+					Usage: "Maximum age of the latest successful backup. (duration, e.g. 24h, 72h)",
+					Value: 24 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			}, newDBFlags("reading clusters and backups")...),
+			Action: runAnalyzeBackupsCmd,
+		},
+	},
+}
+
+func runAnalyzeBackupsCmd(ctx context.Context, c *cli.Command) error {
+	threshold := c.Duration("threshold")
+	if threshold <= 0 {
+		return fmt.Errorf("threshold must be a positive duration")
+	}
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+
+	store, err := backups.NewDBBackupStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create backup store: %w", err)
+	}
+	defer store.Close()
+
+	statuses, err := store.ListLatestSuccessfulBackups(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stale := backups.FindStaleBackups(statuses, now, threshold)
+
+	if outputFormat == "json" {
+		data, err := json.Marshal(stale)
+		if err != nil {
+			return fmt.Errorf("error converting backup report to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+		return nil
+	}
+
+	if len(stale) == 0 {
+		fmt.Fprintf(c.Root().Writer, "All %d tracked clusters have a successful backup within %s\n", len(statuses), threshold)
+		return nil
+	}
+	fmt.Fprintf(c.Root().Writer, "%d of %d tracked clusters have no successful backup within %s\n", len(stale), len(statuses), threshold)
+	return backups.PrintStaleBackups(c.Root().Writer, stale, now)
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusterops"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/clusterspec"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/urfave/cli/v3"
+)
+
+var ClusterCmd = &cli.Command{
+	Name:  "cluster",
+	Usage: "Change TiDB Cloud Dedicated clusters and record the result in the inventory",
+	Commands: []*cli.Command{
+		{
+			Name:  "create",
+			Usage: "Create a cluster from YAML templates, wait until it is available and record it in the inventory",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... MSK_CLUSTER_ROOT_PASSWORD=... msk cluster create -f cluster.yaml
+msk cluster create -f templates/base.yaml -f templates/team-a.yaml --name team-a-analytics --dry-run`,
+			Flags: append(append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringSliceFlag{
+					Name:     "file",
+					Aliases:  []string{"f"},
+					Usage:    "Template file. Can be specified multiple times to override a base template, later files take precedence",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "project-id",
+					Usage: "Override the project_id of the template",
+				},
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "Override the name of the template",
+				},
+				&cli.StringFlag{
+					Name:    "root-password",
+					Usage:   "Root password of the created cluster",
+					Sources: cli.EnvVars("MSK_CLUSTER_ROOT_PASSWORD"),
+					Hidden:  true, // accept only from environment variable
+				},
+				&cli.DurationFlag{
+					Name:  "wait-timeout",
+					Usage: "How long to wait for the cluster to become available. (duration, e.g. 1h)",
+					Value: time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:  "poll-interval",
+					Usage: "Interval to check the status of the cluster while waiting. (duration, e.g. 30s)",
+					Value: 30 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only validate the template and print the cluster that would be created",
+					Value: false,
+				},
+			), newDBFlags("recording the created cluster")...),
+			Action: runClusterCreateCmd,
+		},
+		{
+			Name:  "scale",
+			Usage: "Change the node counts and sizes of a cluster within the configured limits and wait until the nodes settle",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk cluster scale --cluster-id 1234 --tikv-count 6 --tidb-size 16C32G --policy scale-policy.yaml
+msk cluster scale --cluster-id 1234 --tiflash-count 2 --tiflash-size 8C64G --tiflash-storage 500 --dry-run`,
+			Flags: append(append(append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringFlag{
+					Name:     "cluster-id",
+					Usage:    "ID of the cluster to scale",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "project-id",
+					Usage: "Project ID of the cluster. If omitted, it is looked up in the inventory",
+				},
+				&cli.StringFlag{
+					Name:  "policy",
+					Usage: "YAML file with the price data and the per-project limits. Without it, no cost delta is shown and no limits are enforced",
+				},
+				&cli.DurationFlag{
+					Name:  "wait-timeout",
+					Usage: "How long to wait for the nodes to settle. (duration, e.g. 2h)",
+					Value: 2 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:  "poll-interval",
+					Usage: "Interval to check the status of the nodes while waiting. (duration, e.g. 30s)",
+					Value: 30 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print the plan and the cost delta, and check the limits",
+					Value: false,
+				},
+			), newComponentChangeFlags()...), newDBFlags("looking up and recording the scaled cluster")...),
+			Action: runClusterScaleCmd,
+		},
+		{
+			Name:  "delete",
+			Usage: "Take a final backup of a cluster, wait for it to succeed and delete the cluster once its name is confirmed",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk cluster delete --cluster-id 1234
+msk cluster delete --cluster-id 1234 --yes --confirm-name team-a-old`,
+			Flags: append(append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringFlag{
+					Name:     "cluster-id",
+					Usage:    "ID of the cluster to delete",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "project-id",
+					Usage: "Project ID of the cluster. If omitted, it is looked up in the inventory",
+				},
+				&cli.BoolFlag{
+					Name:  "yes",
+					Usage: "Do not prompt for the cluster name. --confirm-name must match the cluster name",
+				},
+				&cli.StringFlag{
+					Name:  "confirm-name",
+					Usage: "Name of the cluster to delete, required with --yes",
+				},
+				&cli.DurationFlag{
+					Name:  "wait-timeout",
+					Usage: "How long to wait for the final backup to succeed. (duration, e.g. 2h)",
+					Value: 2 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:  "poll-interval",
+					Usage: "Interval to check the status of the final backup while waiting. (duration, e.g. 30s)",
+					Value: 30 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print the cluster that would be deleted",
+					Value: false,
+				},
+			), newDBFlags("looking up the cluster and recording the deletion")...),
+			Action: runClusterDeleteCmd,
+		},
+	},
+}
+
+// newComponentChangeFlags returns the flags to change the node count, node size and storage size of each component.
+func newComponentChangeFlags() []cli.Flag {
+	var flags []cli.Flag
+	for _, component := range []string{clusters.ComponentTiDB, clusters.ComponentTiKV, clusters.ComponentTiFlash} {
+		flags = append(flags,
+			&cli.IntFlag{
+				Name:  component + "-count",
+				Usage: fmt.Sprintf("Number of %s nodes", component),
+			},
+			&cli.StringFlag{
+				Name:  component + "-size",
+				Usage: fmt.Sprintf("Node size of %s, e.g. 8C16G", component),
+			},
+		)
+		if component != clusters.ComponentTiDB {
+			flags = append(flags, &cli.IntFlag{
+				Name:  component + "-storage",
+				Usage: fmt.Sprintf("Storage size of each %s node in GiB. It can only be increased", component),
+			})
+		}
+	}
+
+	return flags
+}
+
+func componentChange(c *cli.Command, component string) clusterops.ComponentChange {
+	change := clusterops.ComponentChange{NodeSize: c.String(component + "-size")}
+	if c.IsSet(component + "-count") {
+		count := int(c.Int(component + "-count"))
+		change.NodeQuantity = &count
+	}
+	if component != clusters.ComponentTiDB {
+		change.StorageSizeGib = int(c.Int(component + "-storage"))
+	}
+
+	return change
+}
+
+func runClusterCreateCmd(ctx context.Context, c *cli.Command) error {
+	dryRun := c.Bool("dry-run")
+	if c.String("api-key") == "" || c.String("api-secret") == "" {
+		return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+	if !dryRun && c.String("root-password") == "" {
+		return fmt.Errorf("MSK_CLUSTER_ROOT_PASSWORD is required")
+	}
+
+	tmpl, err := clusterspec.LoadTemplate(c.StringSlice("file"))
+	if err != nil {
+		return err
+	}
+	if projectID := c.String("project-id"); projectID != "" {
+		tmpl.ProjectID = projectID
+	}
+	if name := c.String("name"); name != "" {
+		tmpl.Name = name
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := clusters.NewDBClusterStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster store: %w", err)
+	}
+	defer store.Close()
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+	api := clusterops.NewAPIClusterClient(client, c.String("api-endpoint-base"))
+
+	wait := clusterops.WaitOptions{PollInterval: c.Duration("poll-interval"), WaitTimeout: c.Duration("wait-timeout")}
+	return clusterops.Create(ctx, api, store, tmpl, c.String("root-password"), wait, dryRun, c.Root().Writer)
+}
+
+func runClusterScaleCmd(ctx context.Context, c *cli.Command) error {
+	if c.String("api-key") == "" || c.String("api-secret") == "" {
+		return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+
+	changes := clusterops.Changes{
+		TiDB:    componentChange(c, clusters.ComponentTiDB),
+		TiKV:    componentChange(c, clusters.ComponentTiKV),
+		TiFlash: componentChange(c, clusters.ComponentTiFlash),
+	}
+	if changes == (clusterops.Changes{}) {
+		return fmt.Errorf("no change is specified, use --tidb-*, --tikv-* or --tiflash-* flags")
+	}
+
+	policy, err := clusterops.LoadPolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := clusters.NewDBClusterStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster store: %w", err)
+	}
+	defer store.Close()
+
+	projectID := c.String("project-id")
+	if projectID == "" {
+		projectID, err = store.GetProjectID(ctx, c.String("cluster-id"))
+		if err != nil {
+			return err
+		}
+	}
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+	api := clusterops.NewAPIClusterClient(client, c.String("api-endpoint-base"))
+
+	p := clusterops.ScaleParams{ProjectID: projectID, ClusterID: c.String("cluster-id"), Changes: changes}
+	wait := clusterops.WaitOptions{PollInterval: c.Duration("poll-interval"), WaitTimeout: c.Duration("wait-timeout")}
+	return clusterops.Scale(ctx, api, store, p, policy, wait, c.Bool("dry-run"), c.Root().Writer)
+}
+
+func runClusterDeleteCmd(ctx context.Context, c *cli.Command) error {
+	if c.String("api-key") == "" || c.String("api-secret") == "" {
+		return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+
+	confirm := clusterops.ConfirmByPrompt(c.Root().Reader, c.Root().Writer)
+	if c.Bool("yes") {
+		if c.String("confirm-name") == "" {
+			return fmt.Errorf("--confirm-name is required with --yes")
+		}
+		confirm = clusterops.ConfirmByName(c.String("confirm-name"))
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := clusterops.NewDBActionStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster store: %w", err)
+	}
+	defer store.Close()
+
+	projectID := c.String("project-id")
+	if projectID == "" {
+		projectID, err = store.GetProjectID(ctx, c.String("cluster-id"))
+		if err != nil {
+			return err
+		}
+	}
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+	api := clusterops.NewAPIClusterClient(client, c.String("api-endpoint-base"))
+
+	p := clusterops.DeleteParams{ProjectID: projectID, ClusterID: c.String("cluster-id"), Operator: currentUsername()}
+	wait := clusterops.WaitOptions{PollInterval: c.Duration("poll-interval"), WaitTimeout: c.Duration("wait-timeout")}
+	return clusterops.Delete(ctx, api, store, p, confirm, wait, c.Bool("dry-run"), c.Root().Writer)
+}
+
+// currentUsername returns the name of the OS user running msk, or an empty string if it is unknown.
+func currentUsername() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
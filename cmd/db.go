@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/urfave/cli/v3"
+)
+
+// newDBFlags returns the flags for the database connection, described with the given purpose
+// (e.g. "reading clusters").
+func newDBFlags(purpose string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "db-host",
+			Usage: "Database host for " + purpose,
+			Value: "127.0.0.1",
+		},
+		&cli.StringFlag{
+			Name:  "db-user",
+			Usage: "Database user for " + purpose,
+			Value: "root",
+		},
+		&cli.StringFlag{
+			Name:  "db-name",
+			Usage: "Database name for " + purpose,
+			Value: "test",
+		},
+		&cli.IntFlag{
+			Name:  "db-port",
+			Usage: "Database port for " + purpose,
+			Value: 4000,
+		},
+		&cli.StringFlag{
+			Name:    "db-password",
+			Usage:   "Database password for " + purpose,
+			Sources: cli.EnvVars("MSK_DB_PASSWORD"),
+			Value:   "",
+			Hidden:  true, // accept only from environment variable
+		},
+	}
+}
+
+// dbConnectionString returns the DSN built from the flags of newDBFlags.
+func dbConnectionString(c *cli.Command) (string, error) {
+	port := c.Int("db-port")
+	if port <= 0 || port > 65535 {
+		return "", fmt.Errorf("db-port must be a valid TCP port (1–65535)")
+	}
+
+	return util.GetDBConnectionString(c.String("db-host"), c.String("db-user"), c.String("db-password"), c.String("db-name"), port), nil
+}
+
+// openDB opens the database given by the flags of newDBFlags, for the stores reading with db.Queries.
+func openDB(c *cli.Command) (*sql.DB, error) {
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Open(dsn, nil)
+}
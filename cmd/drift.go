@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sgykfjsm/msk/internal/clusterspec"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/urfave/cli/v3"
+)
+
+var DriftCmd = &cli.Command{
+	Name:  "drift",
+	Usage: "Compare the cluster specs with the clusters stored by fetch-clusters and exit with an error on differences",
+	UsageText: `msk drift --spec specs/
+msk drift --spec specs/prod.yaml --spec specs/dev.yaml --output json --fail-on-unmanaged`,
+	Flags: append([]cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "spec",
+			Usage:    "Spec file or directory of spec files (*.yaml, *.yml). Can be specified multiple times",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "fail-on-unmanaged",
+			Usage: "Also exit with an error if there are clusters not declared in any spec",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "Output format (json, text) case-insensitive, defaults to text",
+			Value: "text",
+		},
+	}, newDBFlags("reading clusters")...),
+	Action: runDriftCmd,
+}
+
+func runDriftCmd(ctx context.Context, c *cli.Command) error {
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	specs, err := clusterspec.Load(c.StringSlice("spec"))
+	if err != nil {
+		return err
+	}
+
+	conn, err := openDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster inventory: %w", err)
+	}
+	defer conn.Close()
+	inventory := clusterspec.NewDBInventory(db.New(conn))
+
+	actual, err := inventory.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	drifts := clusterspec.Compare(specs, actual)
+	if outputFormat == "json" {
+		data, err := json.Marshal(drifts)
+		if err != nil {
+			return fmt.Errorf("error converting drifts to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+	} else if len(drifts) == 0 {
+		fmt.Fprintf(c.Root().Writer, "All %d clusters match the specs\n", len(specs))
+	} else if err := clusterspec.PrintDrifts(c.Root().Writer, drifts); err != nil {
+		return err
+	}
+
+	if n := clusterspec.CountDrifts(drifts, c.Bool("fail-on-unmanaged")); n > 0 {
+		return fmt.Errorf("drift detected: %d differences between %d specs and the stored clusters", n, len(specs))
+	}
+
+	return nil
+}
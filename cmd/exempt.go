@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/exemptions"
+	"github.com/urfave/cli/v3"
+)
+
+var ExemptCmd = &cli.Command{
+	Name:  "exempt",
+	Usage: "Manage the clusters exempted from the long-running analysis of generate-notice, e.g. shared staging clusters",
+	Commands: []*cli.Command{
+		{
+			Name:  "add",
+			Usage: "Exempt a cluster until the expiry",
+			Description: `Until the exemption expires, generate-notice reports no long_running finding for the cluster,
+so notify does not send one either. The cluster is still listed in the notice with its other findings,
+e.g. stale_backup, which are notified as usual. The exemption is shown as "(exempt until ...)" in the
+Markdown and HTML notices, as the exemption field in JSON and as the exempt_until column in CSV.`,
+			UsageText: `msk exempt add --cluster-id 1234567890 --reason "shared staging cluster" --until 30d
+msk exempt add --cluster-id 1234567890 --reason "performance benchmark" --until 2025-07-01 --dry-run`,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:     "cluster-id",
+					Usage:    "ID of the cluster to exempt, collected by fetch-clusters",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "reason",
+					Usage:    "Why the cluster is allowed to keep running",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "until",
+					Usage:    "When the exemption expires. An RFC 3339 time, a date (until the end of the day in UTC), or a duration like 30d or 36h",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "created-by",
+					Usage: "Who adds the exemption, defaults to the current user",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the exemption without adding it",
+				},
+			}, newDBFlags("storing exemptions")...),
+			Action: runExemptAddCmd,
+		},
+		{
+			Name:  "list",
+			Usage: "List the active exemptions",
+			Flags: append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Include the expired exemptions",
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			}, newDBFlags("reading exemptions")...),
+			Action: runExemptListCmd,
+		},
+		{
+			Name:  "remove",
+			Usage: "Remove an exemption by ID, or all exemptions of a cluster",
+			UsageText: `msk exempt remove --id 3
+msk exempt remove --cluster-id 1234567890`,
+			Flags: append([]cli.Flag{
+				&cli.Int64Flag{
+					Name:  "id",
+					Usage: "ID of the exemption shown by exempt list",
+				},
+				&cli.StringFlag{
+					Name:  "cluster-id",
+					Usage: "ID of the cluster whose exemptions are removed",
+				},
+			}, newDBFlags("removing exemptions")...),
+			Action: runExemptRemoveCmd,
+		},
+	},
+}
+
+func runExemptAddCmd(ctx context.Context, c *cli.Command) error {
+	expiresAt, err := exemptions.ParseUntil(c.String("until"), time.Now())
+	if err != nil {
+		return err
+	}
+	createdBy := c.String("created-by")
+	if createdBy == "" {
+		createdBy = currentUsername()
+	}
+
+	store, err := newExemptionStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return exemptions.Add(ctx, store, exemptions.AddParams{
+		ClusterID: c.String("cluster-id"),
+		Reason:    c.String("reason"),
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}, c.Bool("dry-run"), c.Root().Writer)
+}
+
+func runExemptListCmd(ctx context.Context, c *cli.Command) error {
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	store, err := newExemptionStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	now := time.Now()
+	expiresAfter := now
+	if c.Bool("all") {
+		expiresAfter = time.Time{}
+	}
+	list, err := store.ListExemptions(ctx, expiresAfter)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		data, err := json.Marshal(list)
+		if err != nil {
+			return fmt.Errorf("error converting exemptions to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+		return nil
+	}
+
+	if len(list) == 0 {
+		fmt.Fprintln(c.Root().Writer, "No exemptions found")
+		return nil
+	}
+
+	return exemptions.PrintExemptions(c.Root().Writer, list, now)
+}
+
+func runExemptRemoveCmd(ctx context.Context, c *cli.Command) error {
+	id, clusterID := c.Int64("id"), c.String("cluster-id")
+	if (id == 0) == (clusterID == "") {
+		return errors.New("exactly one of --id or --cluster-id is required")
+	}
+
+	store, err := newExemptionStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	w := c.Root().Writer
+	if id != 0 {
+		removed, err := store.RemoveExemption(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("exemption %d not found", id)
+		}
+		fmt.Fprintf(w, "[SUCCESS] Exemption %d is removed\n", id)
+		return nil
+	}
+
+	count, err := store.RemoveClusterExemptions(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		fmt.Fprintf(w, "[SKIP] Cluster %s has no exemptions\n", clusterID)
+		return nil
+	}
+	fmt.Fprintf(w, "[SUCCESS] %d exemptions of cluster %s are removed\n", count, clusterID)
+
+	return nil
+}
+
+// newExemptionStore returns the exemption store of the database of the flags.
+func newExemptionStore(c *cli.Command) (*exemptions.DBExemptionStore, error) {
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return nil, err
+	}
+	store, err := exemptions.NewDBExemptionStore(dsn, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exemption store: %w", err)
+	}
+
+	return store, nil
+}
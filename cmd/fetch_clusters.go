@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/icholy/digest"
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/changes"
 	"github.com/sgykfjsm/msk/internal/clusters"
 	"github.com/sgykfjsm/msk/internal/project"
 	"github.com/sgykfjsm/msk/internal/util"
@@ -24,7 +26,7 @@ msk fetch-clusters --project-id 123 --project-id 456 --page-size 20
 		&cli.StringFlag{
 			Name:  "api-endpoint-base",
 			Usage: "TiDB Cloud API endpoint base",
-			Value: "https://api.tidbcloud.com/api/v1beta",
+			Value: util.TiDBCloudAPIEndpointBase,
 		},
 		&cli.StringFlag{
 			Name:    "api-key",
@@ -88,6 +90,11 @@ msk fetch-clusters --project-id 123 --project-id 456 --page-size 20
 			Usage: "Timeout for the entire job. (duration, e.g. 180s, 5m)",
 			Value: 180 * time.Second, // Default to 3 minutes
 		},
+		&cli.DurationFlag{
+			Name:  "history-retention",
+			Usage: "How long the sync history read by report changes is kept, 0 to keep it forever. (duration, e.g. 720h)",
+			Value: defaultHistoryRetention,
+		},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		return runFetchClustersCmd(ctx, c)
@@ -95,36 +102,38 @@ msk fetch-clusters --project-id 123 --project-id 456 --page-size 20
 }
 
 type fetchClustersArgs struct {
-	APIKey          string
-	APISecret       string
-	APIEndpointBase string
-	ProjectIDs      []string
-	PageSize        int
-	All             bool
-	DBHost          string
-	DBUser          string
-	DBName          string
-	DBPort          int
-	DBPassword      string
-	HTTPTimeout     time.Duration // time.Second
-	JobTimeout      time.Duration // time.Second
+	APIKey           string
+	APISecret        string
+	APIEndpointBase  string
+	ProjectIDs       []string
+	PageSize         int
+	All              bool
+	DBHost           string
+	DBUser           string
+	DBName           string
+	DBPort           int
+	DBPassword       string
+	HTTPTimeout      time.Duration // time.Second
+	JobTimeout       time.Duration // time.Second
+	HistoryRetention time.Duration
 }
 
 func parseFetchClustersArgs(c *cli.Command) *fetchClustersArgs {
 	return &fetchClustersArgs{
-		APIKey:          c.String("api-key"),
-		APISecret:       c.String("api-secret"),
-		APIEndpointBase: c.String("api-endpoint-base"),
-		ProjectIDs:      c.StringSlice("project-id"),
-		PageSize:        c.Int("page-size"),
-		All:             c.Bool("all"),
-		DBHost:          c.String("db-host"),
-		DBUser:          c.String("db-user"),
-		DBName:          c.String("db-name"),
-		DBPort:          c.Int("db-port"),
-		DBPassword:      c.String("db-password"),
-		HTTPTimeout:     c.Duration("http-timeout"),
-		JobTimeout:      c.Duration("job-timeout"),
+		APIKey:           c.String("api-key"),
+		APISecret:        c.String("api-secret"),
+		APIEndpointBase:  c.String("api-endpoint-base"),
+		ProjectIDs:       c.StringSlice("project-id"),
+		PageSize:         c.Int("page-size"),
+		All:              c.Bool("all"),
+		DBHost:           c.String("db-host"),
+		DBUser:           c.String("db-user"),
+		DBName:           c.String("db-name"),
+		DBPort:           c.Int("db-port"),
+		DBPassword:       c.String("db-password"),
+		HTTPTimeout:      c.Duration("http-timeout"),
+		JobTimeout:       c.Duration("job-timeout"),
+		HistoryRetention: c.Duration("history-retention"),
 	}
 }
 
@@ -139,7 +148,7 @@ func validateFetchClustersArgs(v *fetchClustersArgs) error {
 
 	// fool proofing for API endpoint base
 	if v.APIEndpointBase == "" {
-		v.APIEndpointBase = "https://api.tidbcloud.com/api/v1beta"
+		v.APIEndpointBase = util.TiDBCloudAPIEndpointBase
 	}
 
 	if v.PageSize <= 0 || v.PageSize > 100 {
@@ -166,6 +175,10 @@ func validateFetchClustersArgs(v *fetchClustersArgs) error {
 		return fmt.Errorf("job-timeout must be a positive duration")
 	}
 
+	if v.HistoryRetention < 0 {
+		return fmt.Errorf("history-retention must not be negative")
+	}
+
 	return nil
 }
 
@@ -223,6 +236,7 @@ func runFetchClustersCmd(ctx context.Context, c *cli.Command) error {
 		projectIDs = activeProjectIDs
 	}
 
+	startedAt := time.Now()
 	svc := clusters.NewClusterService(fetcher, store)
 	if projectNum, clusterNum, deletedClusterNum, err := svc.FetchAndStoreClusters(ctx, projectIDs, args.PageSize); err != nil {
 		return err
@@ -230,5 +244,26 @@ func runFetchClustersCmd(ctx context.Context, c *cli.Command) error {
 		fmt.Fprintf(c.Root().Writer, "Clusters fetched and stored successfully. Projects: %d, Clusters: %d, Deleted clusters: %d\n", projectNum, clusterNum, deletedClusterNum)
 	}
 
+	// Record the inventory after the sync, including the clusters of the projects not fetched this time
+	if err := recordSyncHistory(ctx, dbDSN, args.HistoryRetention, c.Root().Writer, func(history *changes.DBStore) error {
+		return history.RecordClusters(ctx, startedAt)
+	}); err != nil {
+		return err
+	}
+
+	// Fetch backups of the clusters of the projects that opted in by track-backups
+	backupStore, err := backups.NewDBBackupStore(dbDSN, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create backup store: %w", err)
+	}
+	defer backupStore.Close()
+
+	backupSvc := backups.NewBackupService(backups.NewAPIBackupFetcher(client, args.APIEndpointBase), backupStore)
+	if projectNum, clusterNum, backupNum, err := backupSvc.FetchAndStoreBackups(ctx, projectIDs, args.PageSize); err != nil {
+		return err
+	} else if projectNum > 0 {
+		fmt.Fprintf(c.Root().Writer, "Backups fetched and stored successfully. Projects: %d, Clusters: %d, Backups: %d\n", projectNum, clusterNum, backupNum)
+	}
+
 	return nil
 }
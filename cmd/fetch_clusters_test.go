@@ -200,3 +200,28 @@ func TestValidateFetchClustersArgs_TimeoutsMustBePositive(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateFetchClustersArgs_HistoryRetentionNotNegative(t *testing.T) {
+	for _, tt := range []struct {
+		retention time.Duration
+		wantErr   bool
+	}{
+		{0, false},
+		{time.Hour, false},
+		{-time.Hour, true},
+	} {
+		arg := &fetchClustersArgs{
+			APIKey:           "k",
+			APISecret:        "s",
+			PageSize:         10,
+			ProjectIDs:       []string{"p"},
+			DBPort:           4000,
+			HTTPTimeout:      time.Second,
+			JobTimeout:       time.Second,
+			HistoryRetention: tt.retention,
+		}
+		if err := validateFetchClustersArgs(arg); (err != nil) != tt.wantErr {
+			t.Fatalf("retention=%s err=%v wantErr=%v", tt.retention, err, tt.wantErr)
+		}
+	}
+}
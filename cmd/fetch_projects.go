@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/sgykfjsm/msk/internal/changes"
 	"github.com/sgykfjsm/msk/internal/project"
 	"github.com/sgykfjsm/msk/internal/util"
 	"github.com/urfave/cli/v3"
@@ -16,7 +18,7 @@ var FetchProjectsCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:  "api-endpoint",
 			Usage: "TiDB Cloud API endpoint",
-			Value: "https://api.tidbcloud.com/api/v1beta/projects",
+			Value: util.TiDBCloudAPIEndpointBase + "/projects",
 		},
 		&cli.StringFlag{
 			Name:    "api-key",
@@ -67,6 +69,11 @@ var FetchProjectsCmd = &cli.Command{
 			Value:   "",
 			Hidden:  true, // accept only from environment variable
 		},
+		&cli.DurationFlag{
+			Name:  "history-retention",
+			Usage: "How long the sync history read by report changes is kept, 0 to keep it forever. (duration, e.g. 720h)",
+			Value: defaultHistoryRetention,
+		},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		return runFetchProjects(ctx, c)
@@ -92,47 +99,63 @@ func runFetchProjects(ctx context.Context, c *cli.Command) error {
 		return fmt.Errorf("failed to create project store: %w", err)
 	}
 
-	if err := runFetchAndStoreProjectsService(ctx, fetcher, store, args.Page, args.PageSize); err != nil {
+	startedAt := time.Now()
+	projects, err := runFetchAndStoreProjectsService(ctx, fetcher, store, args.Page, args.PageSize)
+	if err != nil {
 		return err
 	}
 
 	fmt.Fprintln(c.Root().Writer, "Projects fetched and stored successfully.")
-	return nil
+
+	// Only a sync from the first page lists all projects, the others would report the projects before it as removed
+	if args.Page != 1 {
+		return nil
+	}
+	snapshot := make([]changes.Project, 0, len(projects))
+	for _, p := range projects {
+		snapshot = append(snapshot, changes.Project{ID: p.ID, Name: p.Name})
+	}
+	return recordSyncHistory(ctx, dbDSN, args.HistoryRetention, c.Root().Writer, func(history *changes.DBStore) error {
+		return history.RecordProjects(ctx, startedAt, snapshot)
+	})
 }
 
-func runFetchAndStoreProjectsService(ctx context.Context, fetcher project.ProjectFetcher, store project.ProjectStore, page int, pageSize int) error {
-	if err := project.NewProjectService(fetcher, store).FetchAndStoreProjects(ctx, page, pageSize); err != nil {
-		return err
+func runFetchAndStoreProjectsService(ctx context.Context, fetcher project.ProjectFetcher, store project.ProjectStore, page int, pageSize int) (project.Projects, error) {
+	projects, err := project.NewProjectService(fetcher, store).FetchAndStoreProjects(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return projects, nil
 }
 
 type fetchProjectArgs struct {
-	APIKey      string
-	APISecret   string
-	APIEndpoint string
-	Page        int
-	PageSize    int
-	DBHost      string
-	DBUser      string
-	DBName      string
-	DBPort      int
-	DBPassword  string
+	APIKey           string
+	APISecret        string
+	APIEndpoint      string
+	Page             int
+	PageSize         int
+	DBHost           string
+	DBUser           string
+	DBName           string
+	DBPort           int
+	DBPassword       string
+	HistoryRetention time.Duration
 }
 
 func parseFetchProjectArgs(c *cli.Command) *fetchProjectArgs {
 	return &fetchProjectArgs{
-		APIKey:      c.String("api-key"),
-		APISecret:   c.String("api-secret"),
-		APIEndpoint: c.String("api-endpoint"),
-		Page:        c.Int("page"),
-		PageSize:    c.Int("page-size"),
-		DBHost:      c.String("db-host"),
-		DBUser:      c.String("db-user"),
-		DBName:      c.String("db-name"),
-		DBPort:      c.Int("db-port"),
-		DBPassword:  c.String("db-password"),
+		APIKey:           c.String("api-key"),
+		APISecret:        c.String("api-secret"),
+		APIEndpoint:      c.String("api-endpoint"),
+		Page:             c.Int("page"),
+		PageSize:         c.Int("page-size"),
+		DBHost:           c.String("db-host"),
+		DBUser:           c.String("db-user"),
+		DBName:           c.String("db-name"),
+		DBPort:           c.Int("db-port"),
+		DBPassword:       c.String("db-password"),
+		HistoryRetention: c.Duration("history-retention"),
 	}
 }
 
@@ -145,5 +168,9 @@ func validateFetchProjectArgs(v *fetchProjectArgs) error {
 		return fmt.Errorf("API secret is not allowed to be empty")
 	}
 
+	if v.HistoryRetention < 0 {
+		return fmt.Errorf("history-retention must not be negative")
+	}
+
 	return nil
 }
@@ -1,6 +1,9 @@
 package cmd
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestFetchProjects_validateFetchProjectArgs(t *testing.T) {
 	tests := []struct {
@@ -20,6 +23,10 @@ func TestFetchProjects_validateFetchProjectArgs(t *testing.T) {
 			name:  "api-secret is empty",
 			args:  &fetchProjectArgs{APIKey: "test-token", APISecret: ""},
 			isErr: true,
+		}, {
+			name:  "history-retention is negative",
+			args:  &fetchProjectArgs{APIKey: "test-token", APISecret: "test-secret", HistoryRetention: -time.Hour},
+			isErr: true,
 		}, {
 			name:  "all empty",
 			args:  &fetchProjectArgs{APIKey: "", APISecret: ""},
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sgykfjsm/msk/internal/netcheck"
+	"github.com/sgykfjsm/msk/internal/vpcinfo"
+	"github.com/sgykfjsm/msk/internal/vpcsg"
+	"github.com/urfave/cli/v3"
+)
+
+var NetworkCmd = &cli.Command{
+	Name:  "network",
+	Usage: "Check and prepare the network between our VPCs and TiDB Cloud",
+	Commands: []*cli.Command{
+		{
+			Name:  "check-cidr",
+			Usage: "Check a TiDB Cloud CIDR against the CIDR blocks, subnets, peerings and routes of the VPC",
+			UsageText: `msk network check-cidr --vpc-id vpc-0123 --cidr 172.16.0.0/21
+MSK_API_KEY=... MSK_API_SECRET=... msk network check-cidr --vpc-id vpc-0123 --project-id 1234567890`,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:     "vpc-id",
+					Usage:    "ID of the VPC to check. It should start with 'vpc-' prefix",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "cidr",
+					Usage: "TiDB Cloud CIDR to check (e.g., 172.16.0.0/21). Either --cidr or --project-id is required",
+				},
+				&cli.StringFlag{
+					Name:  "project-id",
+					Usage: "TiDB Cloud project ID to read the project CIDRs from. Either --cidr or --project-id is required",
+				},
+				&cli.StringFlag{
+					Name:  "peer-id",
+					Usage: "VPC peering connection the CIDR is routed to, if any. Routes to it are not reported as conflicts",
+				},
+				&cli.StringFlag{
+					Name:  "api-endpoint-base",
+					Usage: "TiDB Cloud Dedicated API endpoint base, used with --project-id",
+					Value: netcheck.DefaultDedicatedAPIEndpointBase,
+				},
+				&cli.DurationFlag{
+					Name:  "http-timeout",
+					Usage: "Timeout for the HTTP request to the TiDB Cloud API. (duration, e.g. 30s, 1m)",
+					Value: 30 * time.Second,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			}, newAPICredentialFlags()...),
+			Action: runCheckCIDRCmd,
+		},
+		{
+			Name:  "allow-tidb",
+			Usage: "Ensure security groups allow the TiDB traffic to and from a TiDB Cloud CIDR or prefix list",
+			UsageText: `msk network allow-tidb --security-group-id sg-0123 --cidr 172.16.0.0/21 --direction egress
+msk network allow-tidb --security-group-id sg-0123 --prefix-list-id pl-0123 --revoke`,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:     "security-group-id",
+					Usage:    "Security group to update. Can be specified multiple times",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "cidr",
+					Usage: "TiDB Cloud CIDR to allow (e.g., 172.16.0.0/21). Either --cidr or --prefix-list-id is required",
+				},
+				&cli.StringFlag{
+					Name:  "prefix-list-id",
+					Usage: "Managed prefix list to allow. It should start with 'pl-' prefix. Either --cidr or --prefix-list-id is required",
+				},
+				&cli.IntFlag{
+					Name:  "port",
+					Usage: "TiDB port to allow",
+					Value: 4000,
+				},
+				&cli.StringFlag{
+					Name:  "direction",
+					Usage: "Direction of the rules (ingress, egress, both)",
+					Value: "both",
+				},
+				&cli.StringFlag{
+					Name:  "description",
+					Usage: "Description of the rules",
+					Value: "TiDB Cloud (managed by msk)",
+				},
+				&cli.BoolFlag{
+					Name:  "revoke",
+					Usage: "Revoke the rules instead of authorizing them",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "If true, only simulate the update without making changes",
+					Value: false,
+				},
+			},
+			Action: runAllowTiDBCmd,
+		},
+	},
+}
+
+func runAllowTiDBCmd(ctx context.Context, c *cli.Command) error {
+	rules, err := tidbRules(c.String("cidr"), c.String("prefix-list-id"), c.Int("port"), c.String("direction"), c.String("description"))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadAWSConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	dryRun := c.Bool("dry-run")
+	if dryRun {
+		printAWSVariables(ctx, c, cfg)
+	}
+
+	return vpcsg.EnsureRules(ctx, ec2.NewFromConfig(cfg), c.StringSlice("security-group-id"), rules, c.Bool("revoke"), dryRun, c.Root().Writer)
+}
+
+// tidbRules returns the security group rules for the TiDB port from the command line arguments.
+func tidbRules(cidr, prefixListID string, port int, direction, description string) ([]vpcsg.Rule, error) {
+	if (cidr == "") == (prefixListID == "") {
+		return nil, fmt.Errorf("exactly one of --cidr or --prefix-list-id is required")
+	}
+	if cidr != "" {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("cidr %q should be in CIDR notation, e.g., 192.168.1.0/24", cidr)
+		}
+	}
+	if prefixListID != "" && !strings.HasPrefix(prefixListID, "pl-") {
+		return nil, fmt.Errorf("prefix-list-id should start with 'pl-' prefix, please provide the actual prefix list ID with the prefix")
+	}
+	if port < 1 || port > 65535 {
+		return nil, fmt.Errorf("port %d is out of range", port)
+	}
+
+	var directions []vpcsg.Direction
+	switch strings.ToLower(direction) {
+	case "ingress":
+		directions = []vpcsg.Direction{vpcsg.Ingress}
+	case "egress":
+		directions = []vpcsg.Direction{vpcsg.Egress}
+	case "both":
+		directions = []vpcsg.Direction{vpcsg.Ingress, vpcsg.Egress}
+	default:
+		return nil, fmt.Errorf("invalid direction: %s, allowed directions are: ingress, egress, both", direction)
+	}
+
+	rules := make([]vpcsg.Rule, 0, len(directions))
+	for _, d := range directions {
+		rules = append(rules, vpcsg.Rule{
+			Direction:    d,
+			FromPort:     int32(port),
+			ToPort:       int32(port),
+			CIDR:         cidr,
+			PrefixListID: prefixListID,
+			Description:  description,
+		})
+	}
+	return rules, nil
+}
+
+func runCheckCIDRCmd(ctx context.Context, c *cli.Command) error {
+	vpcID, cidr, projectID := c.String("vpc-id"), c.String("cidr"), c.String("project-id")
+	if !strings.HasPrefix(vpcID, "vpc-") {
+		return fmt.Errorf("vpc-id should start with 'vpc-' prefix, please provide the actual VPC ID with the prefix")
+	}
+	if (cidr == "") == (projectID == "") {
+		return fmt.Errorf("exactly one of --cidr or --project-id is required")
+	}
+	if cidr != "" {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("cidr %q should be in CIDR notation, e.g., 192.168.1.0/24", cidr)
+		}
+	}
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	cfg, err := loadAWSConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	info, err := vpcinfo.FetchVPCInfo(ctx, ec2.NewFromConfig(cfg), sts.NewFromConfig(cfg), cfg.Region, vpcID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch VPC info: %w", err)
+	}
+
+	cidrs := []string{cidr}
+	if projectID != "" {
+		if c.String("api-key") == "" || c.String("api-secret") == "" {
+			return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required with --project-id")
+		}
+		client := newTiDBCloudHTTPClient(c)
+		defer client.CloseIdleConnections()
+
+		containers, err := netcheck.NewAPIProjectCIDRFetcher(client, c.String("api-endpoint-base")).FetchProjectCIDRs(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		cidrs = projectCIDRsInRegion(containers, info.Region)
+		if len(cidrs) == 0 {
+			return fmt.Errorf("no project CIDR found for project %s in region %s", projectID, info.Region)
+		}
+	}
+
+	var results []*netcheck.Result
+	var errorCount int
+	for _, cidr := range cidrs {
+		result, err := netcheck.CheckCIDR(cidr, info, c.String("peer-id"))
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		if result.HasErrors() {
+			errorCount++
+		}
+	}
+
+	if err := printCheckResults(results, outputFormat, c.Root().Writer); err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d CIDRs overlap with VPC %s or conflict with its routes", errorCount, len(cidrs), vpcID)
+	}
+
+	return nil
+}
+
+// projectCIDRsInRegion returns the CIDRs of the network containers in the given AWS region.
+// The region ID of a network container is prefixed with the cloud provider, e.g. "aws-ap-northeast-1".
+func projectCIDRsInRegion(containers []netcheck.NetworkContainer, region string) []string {
+	var cidrs []string
+	for _, nc := range containers {
+		if nc.CIDRNotation == "" {
+			continue
+		}
+		if region == "" || nc.RegionID == region || strings.HasSuffix(nc.RegionID, "-"+region) {
+			cidrs = append(cidrs, nc.CIDRNotation)
+		}
+	}
+	return cidrs
+}
+
+func printCheckResults(results []*netcheck.Result, format string, w io.Writer) error {
+	if format == "json" {
+		data, err := json.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("error converting CIDR check results to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return fmt.Errorf("error writing JSON output: %w", err)
+		}
+		return nil
+	}
+
+	for _, result := range results {
+		result.Print(w)
+	}
+	return nil
+}
+
+// checkCIDRBeforeRouteChange runs the CIDR check for a route change and returns an error if it must not proceed.
+// In a dry run, the findings are only printed.
+func checkCIDRBeforeRouteChange(ctx context.Context, cfg aws.Config, w io.Writer, vpcID, cidr, peerID string, dryRun bool) error {
+	info, err := vpcinfo.FetchVPCInfo(ctx, ec2.NewFromConfig(cfg), sts.NewFromConfig(cfg), cfg.Region, vpcID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch VPC info for the CIDR check: %w", err)
+	}
+
+	result, err := netcheck.CheckCIDR(cidr, info, peerID)
+	if err != nil {
+		return err
+	}
+	result.Print(w)
+
+	if result.HasErrors() && !dryRun {
+		return fmt.Errorf("CIDR %s overlaps with VPC %s or conflicts with its routes, no changes were made (use --skip-cidr-check to override)", cidr, vpcID)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/netcheck"
+)
+
+func TestProjectCIDRsInRegion(t *testing.T) {
+	containers := []netcheck.NetworkContainer{
+		{RegionID: "aws-us-west-2", CIDRNotation: "172.16.0.0/21"},
+		{RegionID: "aws-ap-northeast-1", CIDRNotation: "172.16.8.0/21"},
+		{RegionID: "aws-ap-northeast-1", CIDRNotation: ""},
+	}
+
+	tests := []struct {
+		name   string
+		region string
+		want   []string
+	}{
+		{"matchingRegion", "ap-northeast-1", []string{"172.16.8.0/21"}},
+		{"noRegion", "", []string{"172.16.0.0/21", "172.16.8.0/21"}},
+		{"unknownRegion", "eu-west-1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := projectCIDRsInRegion(containers, tt.region); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTiDBRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		cidr         string
+		prefixListID string
+		port         int
+		direction    string
+		wantRules    int
+		wantErr      bool
+	}{
+		{"cidrBoth", "172.16.0.0/21", "", 4000, "both", 2, false},
+		{"prefixListEgress", "", "pl-0123", 4000, "EGRESS", 1, false},
+		{"neither", "", "", 4000, "both", 0, true},
+		{"both", "172.16.0.0/21", "pl-0123", 4000, "both", 0, true},
+		{"invalidCIDR", "172.16.0.0", "", 4000, "both", 0, true},
+		{"invalidPrefixList", "", "0123", 4000, "both", 0, true},
+		{"invalidPort", "172.16.0.0/21", "", 70000, "both", 0, true},
+		{"invalidDirection", "172.16.0.0/21", "", 4000, "inbound", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := tidbRules(tt.cidr, tt.prefixListID, tt.port, tt.direction, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(rules) != tt.wantRules {
+				t.Fatalf("got %d rules want %d", len(rules), tt.wantRules)
+			}
+		})
+	}
+}
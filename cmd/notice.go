@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/sgykfjsm/msk/internal/clusterops"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/urfave/cli/v3"
+)
+
+var GenerateNoticeCmd = &cli.Command{
+	Name:  "generate-notice",
+	Usage: "Generate a notice from the information collected by fetch-clusters and save it to a local directory or S3",
+	UsageText: `msk generate-notice --policy scale-policy.yaml
+msk generate-notice --format markdown --format html --format json --format csv --location s3://my-bucket/msk
+msk generate-notice --template notice.html.tmpl --template partials.html.tmpl --format html --output notice.html`,
+	Flags: append([]cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "format",
+			Usage: fmt.Sprintf("Format of the notice (%s). Can be specified multiple times with --location", strings.Join(notice.Formats, ", ")),
+			Value: []string{notice.FormatMarkdown},
+		},
+		&cli.StringSliceFlag{
+			Name:  "template",
+			Usage: "Template file rendering the notice with Go text/template, or html/template for .html/.htm files. It replaces the built-in template of the markdown format, or of the html format for HTML templates. The first one is executed and the others can define templates used by it",
+		},
+		&cli.StringFlag{
+			Name:    "location",
+			Usage:   "Where the notices are saved, under notices/<timestamp>/. A local directory or s3://bucket/prefix. Without it, the notice is written to --output",
+			Sources: cli.EnvVars("MSK_NOTICE_LOCATION"),
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "File to write the notice to, or - for stdout. Only for a single format without --location",
+			Value: "-",
+		},
+		&cli.StringFlag{
+			Name:  "policy",
+			Usage: "YAML file with the price data used to estimate the costs (see cluster scale). Without it, no cost is shown",
+		},
+		&cli.DurationFlag{
+			Name:  "running-threshold",
+			Usage: "Age of an available cluster to report it as long running, 0 to disable. (duration, e.g. 168h)",
+			Value: 7 * 24 * time.Hour,
+		},
+		&cli.DurationFlag{
+			Name:  "backup-threshold",
+			Usage: "Maximum age of the latest successful backup of the clusters with backup tracking, 0 to disable. (duration, e.g. 24h)",
+			Value: 24 * time.Hour,
+		},
+	}, newDBFlags("reading projects, clusters and backups")...),
+	Action: runGenerateNoticeCmd,
+}
+
+func runGenerateNoticeCmd(ctx context.Context, c *cli.Command) error {
+	location := c.String("location")
+	formats := c.StringSlice("format")
+	if location == "" && len(formats) > 1 {
+		return fmt.Errorf("--location is required to write %d formats", len(formats))
+	}
+
+	renderers, err := newNoticeRenderers(formats, c.StringSlice("template"))
+	if err != nil {
+		return err
+	}
+	policy, err := clusterops.LoadPolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+
+	var store blob.Store
+	if location != "" {
+		if store, err = openNoticeLocation(ctx, c, location); err != nil {
+			return err
+		}
+	}
+
+	conn, err := openDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to create notice store: %w", err)
+	}
+	defer conn.Close()
+	inventory := notice.NewDBStore(db.New(conn))
+
+	n, err := notice.Build(ctx, inventory, notice.Options{
+		Now:              time.Now(),
+		RunningThreshold: c.Duration("running-threshold"),
+		BackupThreshold:  c.Duration("backup-threshold"),
+		Pricing:          policy.Pricing,
+	})
+	if err != nil {
+		return err
+	}
+
+	if store != nil {
+		keys, err := notice.Save(ctx, store, n, renderers)
+		for _, key := range keys {
+			fmt.Fprintf(c.Root().Writer, "[SUCCESS] Notice of %d clusters with %d findings is saved to %s\n", n.ClusterCount, len(n.Findings), key)
+		}
+		return err
+	}
+
+	output := c.String("output")
+	if output == "-" {
+		return renderers[0].Render(c.Root().Writer, n)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	if err := renderers[0].Render(f, n); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write notice to %s: %w", output, err)
+	}
+	fmt.Fprintf(c.Root().Writer, "[SUCCESS] Notice of %d clusters with %d findings is written to %s\n", n.ClusterCount, len(n.Findings), output)
+
+	return nil
+}
+
+// openNoticeLocation opens the store of the notices. The AWS configuration is loaded only for S3.
+func openNoticeLocation(ctx context.Context, c *cli.Command, location string) (blob.Store, error) {
+	var cfg aws.Config
+	if strings.HasPrefix(location, "s3://") {
+		var err error
+		if cfg, err = loadAWSConfig(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	store, err := blob.Open(location, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notice location: %w", err)
+	}
+
+	return store, nil
+}
+
+// newNoticeRenderers returns the renderers of the formats. The templates, if any, replace the built-in template
+// of the html format if they are HTML templates, or of the markdown format otherwise.
+func newNoticeRenderers(formats, templatePaths []string) ([]notice.Renderer, error) {
+	var markdown, html *notice.Template
+	if len(templatePaths) > 0 {
+		tmpl, err := notice.LoadTemplate(templatePaths)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl.IsHTML() {
+			html = tmpl
+		} else {
+			markdown = tmpl
+		}
+	}
+
+	seen := make(map[string]bool)
+	renderers := make([]notice.Renderer, 0, len(formats))
+	for _, format := range formats {
+		format = strings.ToLower(format)
+		if seen[format] {
+			continue
+		}
+		seen[format] = true
+
+		tmpl := markdown
+		if format == notice.FormatHTML {
+			tmpl = html
+		}
+		r, err := notice.NewRenderer(format, tmpl)
+		if err != nil {
+			return nil, err
+		}
+		renderers = append(renderers, r)
+	}
+
+	if html != nil && !seen[notice.FormatHTML] {
+		return nil, fmt.Errorf("template %s is an HTML template, add --format html to use it", templatePaths[0])
+	} else if markdown != nil && !seen[notice.FormatMarkdown] {
+		return nil, fmt.Errorf("template %s is a text template, add --format markdown to use it", templatePaths[0])
+	}
+
+	return renderers, nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNoticeRenderers(t *testing.T) {
+	dir := t.TempDir()
+	htmlTemplate := filepath.Join(dir, "notice.html")
+	require.NoError(t, os.WriteFile(htmlTemplate, []byte(`<p>{{.ClusterCount}}</p>`), 0o600))
+
+	renderers, err := newNoticeRenderers([]string{"markdown", "HTML", "json", "html"}, []string{htmlTemplate})
+	require.NoError(t, err)
+	require.Len(t, renderers, 3)
+	require.Equal(t, "html", renderers[1].Extension())
+
+	_, err = newNoticeRenderers([]string{"markdown"}, []string{htmlTemplate})
+	require.ErrorContains(t, err, "add --format html")
+
+	_, err = newNoticeRenderers([]string{"xml"}, nil)
+	require.ErrorContains(t, err, "invalid format: xml")
+}
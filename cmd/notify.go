@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/sgykfjsm/msk/internal/notify"
+	"github.com/sgykfjsm/msk/internal/owners"
+	"github.com/urfave/cli/v3"
+)
+
+var NotifyCmd = &cli.Command{
+	Name:  "notify",
+	Usage: "Notify via messaging service using the latest notice saved by generate-notice in JSON",
+	UsageText: `msk generate-notice --format json --format markdown --location s3://my-bucket/msk
+msk notify --config notify.yaml --location s3://my-bucket/msk --dry-run`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "config",
+			Usage:    "YAML file configuring the channels and the recipients of each project",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "location",
+			Usage:    "Where generate-notice saved the notices. A local directory or s3://bucket/prefix",
+			Sources:  cli.EnvVars("MSK_NOTICE_LOCATION"),
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:    "smtp-password",
+			Usage:   "Password of the SMTP user",
+			Sources: cli.EnvVars("MSK_SMTP_PASSWORD"),
+			Hidden:  true, // accept only from environment variable
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the messages that would be sent without sending them",
+		},
+	}, newDBFlags("reading owners and notification state, if configured")...),
+	Action: runNotifyCmd,
+}
+
+func runNotifyCmd(ctx context.Context, c *cli.Command) error {
+	cfg, err := notify.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+
+	var notifiers []notify.Notifier
+	var email *notify.EmailNotifier
+	if cfg.Email != nil {
+		if cfg.Email.SMTP.Username != "" && c.String("smtp-password") == "" {
+			return errors.New("MSK_SMTP_PASSWORD is required to authenticate as email.smtp.username")
+		}
+		if email, err = notify.NewEmailNotifier(*cfg.Email, c.String("smtp-password")); err != nil {
+			return err
+		}
+		notifiers = append(notifiers, email)
+	}
+	webhooks := make(map[string]*notify.WebhookNotifier)
+	for _, webhook := range cfg.Webhooks {
+		// Like the other secrets, the webhook secrets are accepted only from environment variables
+		h, err := notify.NewWebhookNotifier(webhook, os.Getenv(webhook.SecretEnv))
+		if err != nil {
+			return err
+		}
+		webhooks[webhook.Name] = h
+		notifiers = append(notifiers, h)
+	}
+
+	if cfg.Owners != nil {
+		owner, err := newOwnerNotifier(ctx, c, *cfg.Owners, email, webhooks)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, owner)
+	}
+
+	store, err := openNoticeLocation(ctx, c, c.String("location"))
+	if err != nil {
+		return err
+	}
+	n, key, err := notice.LoadLatest(ctx, store)
+	if err != nil {
+		return err
+	}
+	w := c.Root().Writer
+	fmt.Fprintf(w, "Notifying the notice of %d clusters with %d findings from %s\n", n.ClusterCount, len(n.Findings), key)
+
+	var states notify.StateStore
+	if cfg.State != nil {
+		dsn, err := dbConnectionString(c)
+		if err != nil {
+			return err
+		}
+		stateStore, err := notify.NewDBStateStore(dsn, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create notification state store: %w", err)
+		}
+		defer stateStore.Close()
+		states = stateStore
+	}
+
+	return notify.Run(ctx, n, notifiers, cfg.State, states, time.Now(), c.Bool("dry-run"), w)
+}
+
+// newOwnerNotifier returns the notifier of the owners imported by owners import.
+func newOwnerNotifier(ctx context.Context, c *cli.Command, cfg notify.OwnersConfig, email *notify.EmailNotifier, webhooks map[string]*notify.WebhookNotifier) (*notify.OwnerNotifier, error) {
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return nil, err
+	}
+	store, err := owners.NewDBOwnerStore(dsn, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create owner store: %w", err)
+	}
+	defer store.Close()
+
+	entries, err := store.ListOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(c.Root().Writer, "No owners are imported, all clusters are sent to the admin channel. Run owners import first")
+	}
+
+	notifier := &notify.OwnerNotifier{Owners: entries, Config: cfg, Email: email}
+	for _, name := range cfg.Webhooks {
+		notifier.Webhooks = append(notifier.Webhooks, webhooks[name])
+	}
+	for _, name := range cfg.Admin.Webhooks {
+		notifier.AdminWebhooks = append(notifier.AdminWebhooks, webhooks[name])
+	}
+
+	return notifier, nil
+}
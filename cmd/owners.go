@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sgykfjsm/msk/internal/owners"
+	"github.com/urfave/cli/v3"
+)
+
+var OwnersCmd = &cli.Command{
+	Name:  "owners",
+	Usage: "Manage the owners of the clusters, to whom notify sends the clusters they own",
+	Commands: []*cli.Command{
+		{
+			Name:  "import",
+			Usage: "Replace the owners with the ones of a YAML or CSV mapping of projects and cluster name patterns",
+			UsageText: `msk owners import --file owners.yaml
+msk owners import --file owners.csv --dry-run`,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:     "file",
+					Usage:    "Mapping file with the .yaml, .yml or .csv extension",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the owners of the mapping without importing them",
+				},
+			}, newDBFlags("storing owners")...),
+			Action: runOwnersImportCmd,
+		},
+		{
+			Name:   "list",
+			Usage:  "List the imported owners",
+			Flags:  newDBFlags("reading owners"),
+			Action: runOwnersListCmd,
+		},
+	},
+}
+
+func runOwnersImportCmd(ctx context.Context, c *cli.Command) error {
+	entries, err := owners.Load(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	w := c.Root().Writer
+	if c.Bool("dry-run") {
+		if err := owners.PrintOwners(w, entries); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "[DRY RUN] Would replace the owners with %d entries of %d owners from %s\n", len(entries), len(owners.Contacts(entries)), c.String("file"))
+		return nil
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := owners.NewDBOwnerStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create owner store: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.ReplaceOwners(ctx, entries); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "[SUCCESS] Replaced the owners with %d entries of %d owners from %s\n", len(entries), len(owners.Contacts(entries)), c.String("file"))
+
+	return nil
+}
+
+func runOwnersListCmd(ctx context.Context, c *cli.Command) error {
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := owners.NewDBOwnerStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create owner store: %w", err)
+	}
+	defer store.Close()
+
+	entries, err := store.ListOwners(ctx)
+	if err != nil {
+		return err
+	}
+
+	return owners.PrintOwners(c.Root().Writer, entries)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/sgykfjsm/msk/internal/vpcpeering"
+	"github.com/urfave/cli/v3"
+)
+
+var PeeringCmd = &cli.Command{
+	Name:  "peering",
+	Usage: "Discover VPC peering connections across regions and accounts",
+	Commands: []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List VPC peering connections in all given regions and accounts, and optionally accept pending requests",
+			UsageText: `msk peering list --region ap-northeast-1 --region us-west-2 --state pending-acceptance
+msk peering list --role-arn arn:aws:iam::111111111111:role/msk --role-arn arn:aws:iam::222222222222:role/msk --requester-account 380838443567
+msk peering list --region ap-northeast-1 --accept-pending-from 380838443567 --dry-run`,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "region",
+					Usage: "Region(s) to scan (can be specified multiple times). Defaults to the region of --aws-region or the profile",
+				},
+				&cli.StringSliceFlag{
+					Name:  "role-arn",
+					Usage: "Role(s) to assume to scan other accounts (can be specified multiple times). Defaults to --assume-role-arn, if any",
+				},
+				&cli.StringSliceFlag{
+					Name:  "state",
+					Usage: "Show only connections in the given state(s), e.g. pending-acceptance, active, failed, expired",
+				},
+				&cli.StringFlag{
+					Name:  "requester-account",
+					Usage: "Show only connections requested by the given AWS account ID",
+				},
+				&cli.StringFlag{
+					Name:  "accept-pending-from",
+					Usage: "Accept every pending connection requested by the given AWS account ID (e.g. the TiDB Cloud account) before listing, regardless of --state and --requester-account",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "With --accept-pending-from, only check the connections that would be accepted",
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			},
+			Action: runPeeringListCmd,
+		},
+	},
+}
+
+// peeringScope is a pair of an account (via an assumed role) and a region to scan.
+type peeringScope struct {
+	RoleARN string
+	Region  string
+}
+
+// peeringScopes returns every combination of the given roles and regions.
+// An empty role or region means the one given by the global options.
+func peeringScopes(roles, regions []string) []peeringScope {
+	if len(roles) == 0 {
+		roles = []string{""}
+	}
+	if len(regions) == 0 {
+		regions = []string{""}
+	}
+
+	var scopes []peeringScope
+	for _, role := range roles {
+		for _, region := range regions {
+			scopes = append(scopes, peeringScope{RoleARN: role, Region: region})
+		}
+	}
+	return scopes
+}
+
+func runPeeringListCmd(ctx context.Context, c *cli.Command) error {
+	states := c.StringSlice("state")
+	for _, state := range states {
+		if !vpcpeering.ValidStates[state] {
+			return fmt.Errorf("invalid state %q, allowed states are: %s", state, strings.Join(sortedKeys(vpcpeering.ValidStates), ", "))
+		}
+	}
+
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	filter := vpcpeering.ListFilter{States: states, RequesterAccountID: c.String("requester-account")}
+	acceptFrom := c.String("accept-pending-from")
+	w := c.Root().Writer
+	// The progress of accepting goes to stderr in JSON, so that stdout stays a valid JSON document
+	acceptW := w
+	if outputFormat == "json" {
+		acceptW = c.Root().ErrWriter
+	}
+
+	var all []vpcpeering.PeeringConnection
+	for _, scope := range peeringScopes(c.StringSlice("role-arn"), c.StringSlice("region")) {
+		opts := awsOptions(c)
+		if scope.RoleARN != "" {
+			opts.AssumeRoleARN = scope.RoleARN
+		}
+		if scope.Region != "" {
+			opts.Region = scope.Region
+		}
+
+		cfg, err := util.LoadAWSConfig(ctx, opts)
+		if err != nil {
+			return err
+		}
+		account, err := util.GetCallerAccountID(ctx, sts.NewFromConfig(cfg))
+		if err != nil {
+			return fmt.Errorf("failed to get AWS account ID (role: %q, region: %q): %w", opts.AssumeRoleARN, cfg.Region, err)
+		}
+
+		ec2Client := ec2.NewFromConfig(cfg)
+		// Accepting comes first, regardless of --state and --requester-account, so that the listing shows the accepted
+		// connections in their new status
+		if acceptFrom != "" {
+			if _, err := vpcpeering.AcceptPendingFrom(ctx, ec2Client, account, cfg.Region, acceptFrom, c.Bool("dry-run"), acceptW); err != nil {
+				return err
+			}
+		}
+
+		connections, err := vpcpeering.ListVPCPeeringConnections(ctx, ec2Client, account, cfg.Region, filter)
+		if err != nil {
+			return err
+		}
+		all = append(all, connections...)
+	}
+
+	if outputFormat == "json" {
+		data, err := json.Marshal(all)
+		if err != nil {
+			return fmt.Errorf("error converting peering connections to JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	if len(all) == 0 {
+		fmt.Fprintln(w, "No VPC peering connections found")
+		return nil
+	}
+	return vpcpeering.PrintPeeringConnections(w, all)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeeringScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		regions []string
+		want    []peeringScope
+	}{
+		{"defaults", nil, nil, []peeringScope{{}}},
+		{"regionsOnly", nil, []string{"r1", "r2"}, []peeringScope{{Region: "r1"}, {Region: "r2"}}},
+		{"rolesAndRegions", []string{"a", "b"}, []string{"r1", "r2"}, []peeringScope{
+			{RoleARN: "a", Region: "r1"}, {RoleARN: "a", Region: "r2"},
+			{RoleARN: "b", Region: "r1"}, {RoleARN: "b", Region: "r2"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := peeringScopes(tt.roles, tt.regions); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/sgykfjsm/msk/internal/privatelink"
+	"github.com/urfave/cli/v3"
+)
+
+var PrivateEndpointCmd = &cli.Command{
+	Name:  "private-endpoint",
+	Usage: "Set up and tear down AWS PrivateLink connectivity to TiDB Cloud Dedicated clusters",
+	Commands: []*cli.Command{
+		{
+			Name:  "create",
+			Usage: "Create an interface VPC endpoint for the cluster, register it with TiDB Cloud and wait until it is available",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk private-endpoint create --cluster-id 1234567890 --tidb-node-group-id 9876543210 \
+  --vpc-id vpc-0123 --subnet-id subnet-0a --subnet-id subnet-0b --security-group-id sg-0123`,
+			Flags: append(newPrivateEndpointFlags(),
+				&cli.StringSliceFlag{
+					Name:     "subnet-id",
+					Usage:    "Subnet to create the endpoint network interfaces in. Can be specified multiple times, one per availability zone",
+					Required: true,
+				},
+				&cli.StringSliceFlag{
+					Name:     "security-group-id",
+					Usage:    "Security group to associate with the endpoint network interfaces. Can be specified multiple times",
+					Required: true,
+				},
+				&cli.DurationFlag{
+					Name:  "wait-timeout",
+					Usage: "How long to wait for the endpoint to become available. (duration, e.g. 10m)",
+					Value: 15 * time.Minute,
+				},
+				&cli.DurationFlag{
+					Name:  "poll-interval",
+					Usage: "Interval to check the state of the endpoint while waiting. (duration, e.g. 15s)",
+					Value: 15 * time.Second,
+				},
+			),
+			Action: func(ctx context.Context, c *cli.Command) error {
+				return runPrivateEndpointCmd(ctx, c, privatelink.Setup)
+			},
+		},
+		{
+			Name:      "delete",
+			Usage:     "Deregister the VPC endpoint of the cluster from TiDB Cloud and delete it",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk private-endpoint delete --cluster-id 1234567890 --tidb-node-group-id 9876543210 --vpc-id vpc-0123`,
+			Flags:     newPrivateEndpointFlags(),
+			Action: func(ctx context.Context, c *cli.Command) error {
+				return runPrivateEndpointCmd(ctx, c, privatelink.Teardown)
+			},
+		},
+	},
+}
+
+// newPrivateEndpointFlags returns the flags shared by the private-endpoint subcommands.
+func newPrivateEndpointFlags() []cli.Flag {
+	return append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "cluster-id",
+			Usage:    "ID of the TiDB Cloud Dedicated cluster",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "tidb-node-group-id",
+			Usage:    "ID of the TiDB node group of the cluster to connect to",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "vpc-id",
+			Usage:    "ID of our VPC to create the endpoint in. It should start with 'vpc-' prefix",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only print what would be done without making any changes",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:  "api-endpoint-base",
+			Usage: "TiDB Cloud Dedicated API endpoint base",
+			Value: privatelink.DefaultAPIEndpointBase,
+		},
+		&cli.DurationFlag{
+			Name:  "http-timeout",
+			Usage: "Timeout for each HTTP request to the TiDB Cloud API. (duration, e.g. 30s, 1m)",
+			Value: 30 * time.Second,
+		},
+	}, newAPICredentialFlags()...)
+}
+
+type privateEndpointAction func(ctx context.Context, ec2Client privatelink.EC2API, api privatelink.PrivateLinkAPI, p privatelink.Params, dryRun bool, w io.Writer) error
+
+func runPrivateEndpointCmd(ctx context.Context, c *cli.Command, action privateEndpointAction) error {
+	vpcID := c.String("vpc-id")
+	if !strings.HasPrefix(vpcID, "vpc-") {
+		return fmt.Errorf("vpc-id should start with 'vpc-' prefix, please provide the actual VPC ID with the prefix")
+	}
+	if c.String("api-key") == "" || c.String("api-secret") == "" {
+		return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+
+	p := privatelink.Params{
+		ClusterID:        c.String("cluster-id"),
+		TiDBNodeGroupID:  c.String("tidb-node-group-id"),
+		VPCID:            vpcID,
+		SubnetIDs:        c.StringSlice("subnet-id"),
+		SecurityGroupIDs: c.StringSlice("security-group-id"),
+		PollInterval:     c.Duration("poll-interval"),
+		WaitTimeout:      c.Duration("wait-timeout"),
+	}
+
+	cfg, err := loadAWSConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+	printAWSVariables(ctx, c, cfg)
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+	api := privatelink.NewAPIPrivateLinkClient(client, c.String("api-endpoint-base"))
+
+	return action(ctx, ec2.NewFromConfig(cfg), api, p, c.Bool("dry-run"), c.Root().Writer)
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/changes"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/inventory"
+	"github.com/sgykfjsm/msk/internal/versions"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultHistoryRetention is how long fetch-projects and fetch-clusters keep the sync history by default.
+const defaultHistoryRetention = 90 * 24 * time.Hour
+
+var ReportCmd = &cli.Command{
+	Name:  "report",
+	Usage: "Report on the inventory collected by fetch-projects and fetch-clusters",
+	Commands: []*cli.Command{
+		{
+			Name:  "changes",
+			Usage: "Report what changed in the inventory since a time, comparing the snapshots recorded by every sync",
+			UsageText: `msk report changes --since 24h
+msk report changes --since 168h --output markdown >> notice.md`,
+			Flags: append([]cli.Flag{
+				&cli.DurationFlag{
+					Name:  "since",
+					Usage: "How far back the changes are reported. (duration, e.g. 24h, 168h)",
+					Value: 24 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: fmt.Sprintf("Output format (%s) case-insensitive, defaults to text", strings.Join(changes.Formats, ", ")),
+					Value: changes.FormatText,
+				},
+			}, newDBFlags("reading the sync history")...),
+			Action: runReportChangesCmd,
+		},
+		{
+			Name:  "versions",
+			Usage: "Group the clusters by TiDB version and check the versions against a policy of the minimum version, blocked versions and end of support dates",
+			UsageText: `msk report versions --policy versions.yaml
+msk report versions --policy versions.yaml --strict --output json`,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:     "policy",
+					Usage:    "YAML file of the version policy",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "strict",
+					Usage: "Exit with an error if any cluster violates the policy, e.g. to gate CI",
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			}, newDBFlags("reading clusters")...),
+			Action: runReportVersionsCmd,
+		},
+		{
+			Name:  "inventory",
+			Usage: "Summarize the clusters, nodes, vCPU, RAM and storage by cloud provider, region, cluster type or project",
+			UsageText: `msk report inventory
+msk report inventory --group-by project --group-by cluster_type
+msk report inventory --group-by provider --pivot cluster_type --metric nodes
+msk report inventory --group-by region --output csv > inventory.csv`,
+			Flags:  newReportInventoryFlags(),
+			Action: runReportInventoryCmd,
+		},
+	},
+}
+
+func runReportChangesCmd(ctx context.Context, c *cli.Command) error {
+	since := c.Duration("since")
+	if since <= 0 {
+		return fmt.Errorf("since must be a positive duration")
+	}
+	outputFormat := strings.ToLower(c.String("output"))
+	if !slices.Contains(changes.Formats, outputFormat) {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: %s", outputFormat, strings.Join(changes.Formats, ", "))
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := changes.NewDBStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create sync history store: %w", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	report, err := changes.Build(ctx, store, now.Add(-since), now)
+	if err != nil {
+		return err
+	}
+
+	w := c.Root().Writer
+	switch outputFormat {
+	case changes.FormatJSON:
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("error converting change report to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case changes.FormatMarkdown:
+		return changes.WriteMarkdown(w, report)
+	default:
+		return changes.PrintReport(w, report)
+	}
+}
+
+func runReportVersionsCmd(ctx context.Context, c *cli.Command) error {
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	policy, err := versions.LoadPolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+
+	conn, err := openDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster inventory: %w", err)
+	}
+	defer conn.Close()
+	inventory := versions.NewDBInventory(db.New(conn))
+
+	clusters, err := inventory.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := versions.Check(policy, clusters, time.Now())
+	if outputFormat == "json" {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("error converting version report to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+	} else if err := versions.PrintReport(c.Root().Writer, report); err != nil {
+		return err
+	}
+
+	if c.Bool("strict") && report.Violations > 0 {
+		return fmt.Errorf("version policy violated by %d of %d clusters", report.Violations, report.ClusterCount)
+	}
+
+	return nil
+}
+
+// newReportInventoryFlags returns the flags of report inventory.
+func newReportInventoryFlags() []cli.Flag {
+	return append([]cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "group-by",
+			Usage: fmt.Sprintf("Dimension to group the clusters by (%s). Can be specified multiple times", strings.Join(inventory.Dimensions, ", ")),
+			Value: []string{inventory.DimensionProvider, inventory.DimensionRegion},
+		},
+		&cli.StringFlag{
+			Name:  "pivot",
+			Usage: "Dimension whose values become the columns of a pivot table of --metric. Without --group-by, it is left out of the default rows",
+		},
+		&cli.StringFlag{
+			Name:  "metric",
+			Usage: fmt.Sprintf("Metric in the cells of the pivot table (%s)", strings.Join(inventory.Metrics, ", ")),
+			Value: inventory.MetricClusters,
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "Output format (csv, json, text) case-insensitive, defaults to text",
+			Value: "text",
+		},
+	}, newDBFlags("reading projects and clusters")...)
+}
+
+// reportInventoryArgs are the arguments of report inventory.
+type reportInventoryArgs struct {
+	GroupBy []string
+	Column  string // Pivot dimension, empty for a summary
+	Metric  string
+	Output  string
+}
+
+// newReportInventoryArgs reads and validates the flags of report inventory.
+func newReportInventoryArgs(c *cli.Command) (*reportInventoryArgs, error) {
+	args := &reportInventoryArgs{
+		Column: strings.ToLower(c.String("pivot")),
+		Metric: strings.ToLower(c.String("metric")),
+		Output: strings.ToLower(c.String("output")),
+	}
+	if args.Output != "text" && args.Output != "csv" && args.Output != "json" {
+		return nil, fmt.Errorf("invalid output format: %s, allowed formats are: csv, json, text", args.Output)
+	}
+	if args.Column == "" && c.IsSet("metric") {
+		return nil, fmt.Errorf("--metric requires --pivot")
+	}
+
+	for _, d := range c.StringSlice("group-by") {
+		d = strings.ToLower(d)
+		// The pivot dimension is left out of the default rows, e.g. --pivot region is grouped by provider only
+		if !c.IsSet("group-by") && d == args.Column {
+			continue
+		}
+		args.GroupBy = append(args.GroupBy, d)
+	}
+
+	return args, nil
+}
+
+func runReportInventoryCmd(ctx context.Context, c *cli.Command) error {
+	args, err := newReportInventoryArgs(c)
+	if err != nil {
+		return err
+	}
+
+	conn, err := openDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster inventory: %w", err)
+	}
+	defer conn.Close()
+	store := inventory.NewDBInventory(db.New(conn))
+
+	clusters, err := store.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	var report any
+	var printText, writeCSV func(io.Writer) error
+	if args.Column != "" {
+		pivot, err := inventory.NewPivot(clusters, args.GroupBy, args.Column, args.Metric)
+		if err != nil {
+			return err
+		}
+		report = pivot
+		printText = func(w io.Writer) error { return inventory.PrintPivot(w, pivot) }
+		writeCSV = func(w io.Writer) error { return inventory.WritePivotCSV(w, pivot) }
+	} else {
+		summary, err := inventory.Summarize(clusters, args.GroupBy)
+		if err != nil {
+			return err
+		}
+		report = summary
+		printText = func(w io.Writer) error { return inventory.PrintSummary(w, summary) }
+		writeCSV = func(w io.Writer) error { return inventory.WriteSummaryCSV(w, summary) }
+	}
+
+	w := c.Root().Writer
+	switch args.Output {
+	case "json":
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("error converting inventory report to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case "csv":
+		return writeCSV(w)
+	default:
+		return printText(w)
+	}
+}
+
+// recordSyncHistory records the snapshot of a sync for report changes, and prunes the history older than the retention.
+func recordSyncHistory(ctx context.Context, dsn string, retention time.Duration, w io.Writer, record func(history *changes.DBStore) error) error {
+	history, err := changes.NewDBStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create sync history store: %w", err)
+	}
+	defer history.Close()
+
+	if err := record(history); err != nil {
+		return err
+	}
+	if retention == 0 {
+		return nil
+	}
+
+	pruned, err := history.Prune(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		fmt.Fprintf(w, "Pruned %d syncs older than %s from the sync history\n", pruned, retention)
+	}
+
+	return nil
+}
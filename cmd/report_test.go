@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestNewReportInventoryArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *reportInventoryArgs
+		wantErr string
+	}{
+		{"defaults", nil, &reportInventoryArgs{GroupBy: []string{"provider", "region"}, Metric: "clusters", Output: "text"}, ""},
+		{"pivot by a default dimension", []string{"--pivot", "region"}, &reportInventoryArgs{GroupBy: []string{"provider"}, Column: "region", Metric: "clusters", Output: "text"}, ""},
+		{"pivot by another dimension", []string{"--pivot", "cluster_type", "--metric", "nodes"}, &reportInventoryArgs{GroupBy: []string{"provider", "region"}, Column: "cluster_type", Metric: "nodes", Output: "text"}, ""},
+		{"explicit group-by kept", []string{"--group-by", "Region", "--pivot", "region"}, &reportInventoryArgs{GroupBy: []string{"region"}, Column: "region", Metric: "clusters", Output: "text"}, ""},
+		{"metric without pivot", []string{"--metric", "nodes"}, nil, "--metric requires --pivot"},
+		{"invalid output", []string{"--output", "xml"}, nil, "invalid output format: xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *reportInventoryArgs
+			var err error
+			cmd := &cli.Command{
+				Name:  "inventory",
+				Flags: newReportInventoryFlags(),
+				Action: func(ctx context.Context, c *cli.Command) error {
+					got, err = newReportInventoryArgs(c)
+					return nil
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"inventory"}, tt.args...)))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/restore"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/urfave/cli/v3"
+)
+
+var RestoreCmd = &cli.Command{
+	Name:  "restore",
+	Usage: "Restore clusters from backups with the node configuration of the original cluster",
+	Commands: []*cli.Command{
+		{
+			Name:  "create",
+			Usage: "Restore a backup as a new cluster, wait until it is available and record it in the inventory",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... MSK_RESTORE_ROOT_PASSWORD=... msk restore create --backup-id 1234567890 --name restored-cluster
+msk restore create --backup-id 1234567890 --name restored-cluster --dry-run`,
+			Flags: append(append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringFlag{
+					Name:     "backup-id",
+					Usage:    "ID of the backup to restore. It should have been fetched by fetch-clusters",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "name",
+					Usage:    "Name of the restored cluster",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "root-password",
+					Usage:   "Root password of the restored cluster",
+					Sources: cli.EnvVars("MSK_RESTORE_ROOT_PASSWORD"),
+					Hidden:  true, // accept only from environment variable
+				},
+				&cli.IntFlag{
+					Name:  "port",
+					Usage: "TiDB port of the restored cluster",
+					Value: 4000,
+				},
+				&cli.DurationFlag{
+					Name:  "wait-timeout",
+					Usage: "How long to wait for the restored cluster to become available. (duration, e.g. 2h)",
+					Value: 2 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:  "poll-interval",
+					Usage: "Interval to check the status of the restore while waiting. (duration, e.g. 30s)",
+					Value: 30 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print the restore that would be created without making any changes",
+					Value: false,
+				},
+			), newDBFlags("reading backups and recording the restored cluster")...),
+			Action: runRestoreCreateCmd,
+		},
+		{
+			Name:      "list",
+			Usage:     "List the restores of a project",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk restore list --project-id 1234567890`,
+			Flags: append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringFlag{
+					Name:     "project-id",
+					Usage:    "TiDB Cloud project ID",
+					Required: true,
+				},
+				&cli.IntFlag{
+					Name:  "page-size",
+					Usage: "Number of restores per page",
+					Value: 20,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			),
+			Action: runRestoreListCmd,
+		},
+		{
+			Name:      "status",
+			Usage:     "Show the status of a restore and its cluster",
+			UsageText: `MSK_API_KEY=... MSK_API_SECRET=... msk restore status --project-id 1234567890 --restore-id 9876543210`,
+			Flags: append(newTiDBCloudAPIFlags(util.TiDBCloudAPIEndpointBase),
+				&cli.StringFlag{
+					Name:     "project-id",
+					Usage:    "TiDB Cloud project ID",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "restore-id",
+					Usage:    "ID of the restore",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "Output format (json, text) case-insensitive, defaults to text",
+					Value: "text",
+				},
+			),
+			Action: runRestoreStatusCmd,
+		},
+	},
+}
+
+func runRestoreCreateCmd(ctx context.Context, c *cli.Command) error {
+	dryRun := c.Bool("dry-run")
+	if !dryRun && (c.String("api-key") == "" || c.String("api-secret") == "") {
+		return fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+	if !dryRun && c.String("root-password") == "" {
+		return fmt.Errorf("MSK_RESTORE_ROOT_PASSWORD is required")
+	}
+	port := c.Int("port")
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d is out of range", port)
+	}
+
+	dsn, err := dbConnectionString(c)
+	if err != nil {
+		return err
+	}
+	store, err := restore.NewDBRestoreStore(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create restore store: %w", err)
+	}
+	defer store.Close()
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+	api := restore.NewAPIRestoreClient(client, c.String("api-endpoint-base"))
+
+	p := restore.Params{
+		BackupID:     c.String("backup-id"),
+		Name:         c.String("name"),
+		RootPassword: c.String("root-password"),
+		Port:         port,
+		PollInterval: c.Duration("poll-interval"),
+		WaitTimeout:  c.Duration("wait-timeout"),
+	}
+
+	return restore.Create(ctx, api, store, p, dryRun, c.Root().Writer)
+}
+
+func runRestoreListCmd(ctx context.Context, c *cli.Command) error {
+	outputFormat, err := parseRestoreOutput(c)
+	if err != nil {
+		return err
+	}
+	pageSize := c.Int("page-size")
+	if pageSize <= 0 || pageSize > 100 {
+		return fmt.Errorf("page-size must be a positive integer less than or equal to 100")
+	}
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+
+	restores, err := restore.ListAll(ctx, restore.NewAPIRestoreClient(client, c.String("api-endpoint-base")), c.String("project-id"), pageSize)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		data, err := json.Marshal(restores)
+		if err != nil {
+			return fmt.Errorf("error converting restores to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+		return nil
+	}
+
+	return restore.PrintRestores(c.Root().Writer, restores)
+}
+
+func runRestoreStatusCmd(ctx context.Context, c *cli.Command) error {
+	outputFormat, err := parseRestoreOutput(c)
+	if err != nil {
+		return err
+	}
+
+	client := newTiDBCloudHTTPClient(c)
+	defer client.CloseIdleConnections()
+
+	r, err := restore.NewAPIRestoreClient(client, c.String("api-endpoint-base")).GetRestore(ctx, c.String("project-id"), c.String("restore-id"))
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("error converting restore to JSON: %w", err)
+		}
+		fmt.Fprintln(c.Root().Writer, string(data))
+		return nil
+	}
+
+	restore.PrintRestore(c.Root().Writer, *r)
+	return nil
+}
+
+// parseRestoreOutput validates the credentials and the output format shared by the read-only restore subcommands.
+func parseRestoreOutput(c *cli.Command) (string, error) {
+	if c.String("api-key") == "" || c.String("api-secret") == "" {
+		return "", fmt.Errorf("MSK_API_KEY and MSK_API_SECRET are required")
+	}
+	outputFormat := strings.ToLower(c.String("output"))
+	if outputFormat != "text" && outputFormat != "json" {
+		return "", fmt.Errorf("invalid output format: %s, allowed formats are: json, text", outputFormat)
+	}
+
+	return outputFormat, nil
+}
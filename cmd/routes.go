@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/sgykfjsm/msk/internal/vpcrtb"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultJournalLocation is where route table journals are saved unless --journal-location is given.
+const defaultJournalLocation = ".msk"
+
+func newJournalLocationFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "journal-location",
+		Usage: "Where route table journals are saved. A local directory or s3://bucket/prefix",
+		Value: defaultJournalLocation,
+	}
+}
+
+var RoutesCmd = &cli.Command{
+	Name:  "routes",
+	Usage: "Plan, apply and restore route changes for VPC peering",
+	Commands: []*cli.Command{
+		{
+			Name:      "plan",
+			Usage:     "Save the route operations update-routes would perform, with the observed route tables, to a plan file",
+			UsageText: "msk routes plan --vpc-id vpc-0123 --cidr 192.168.1.0/24 --peer-id pcx-0123 -out plan.json",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "vpc-id",
+					Usage:    "ID of the VPC to update routes for. It should start with 'vpc-' prefix",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "cidr",
+					Usage:    "CIDR block to route (e.g., 192.168.1.0/24)",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "peer-id",
+					Usage:    "Peer ID for the VPC route update. It should start with 'pcx-' prefix",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "out",
+					Usage: "Path of the plan file to write",
+					Value: "plan.json",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-cidr-check",
+					Usage: "Do not check the CIDR for overlaps with the VPC and conflicting routes",
+				},
+			},
+			Action: func(ctx context.Context, c *cli.Command) error {
+				vpcID, cidr, peerID := c.String("vpc-id"), c.String("cidr"), c.String("peer-id")
+				if err := validateRouteArgs(vpcID, cidr, peerID); err != nil {
+					return err
+				}
+
+				cfg, err := loadAWSConfig(ctx, c)
+				if err != nil {
+					return err
+				}
+
+				if !c.Bool("skip-cidr-check") {
+					if err := checkCIDRBeforeRouteChange(ctx, cfg, c.Root().Writer, vpcID, cidr, peerID, false); err != nil {
+						return err
+					}
+				}
+
+				plan, err := vpcrtb.PlanRoutes(ctx, ec2.NewFromConfig(cfg), vpcID, cidr, peerID)
+				if err != nil {
+					return err
+				}
+				if len(plan.Operations) == 0 {
+					return fmt.Errorf("no route tables found for VPC %s", vpcID)
+				}
+
+				plan.Print(c.Root().Writer)
+				if err := vpcrtb.WritePlanFile(c.String("out"), plan); err != nil {
+					return err
+				}
+				fmt.Fprintf(c.Root().Writer, "Plan saved to %s. Apply it with: msk routes apply %s\n", c.String("out"), c.String("out"))
+
+				return nil
+			},
+		},
+		{
+			Name:      "apply",
+			Usage:     "Apply a plan file saved by 'routes plan'. Refuses to run if the route tables have changed since",
+			ArgsUsage: "<plan file>",
+			UsageText: "msk routes apply plan.json",
+			Flags:     []cli.Flag{newJournalLocationFlag()},
+			Action: func(ctx context.Context, c *cli.Command) error {
+				path := c.Args().First()
+				if path == "" {
+					return fmt.Errorf("plan file is required, e.g. msk routes apply plan.json")
+				}
+
+				plan, err := vpcrtb.ReadPlanFile(path)
+				if err != nil {
+					return err
+				}
+
+				cfg, err := loadAWSConfig(ctx, c)
+				if err != nil {
+					return err
+				}
+
+				journals, err := openJournalStore(c, cfg)
+				if err != nil {
+					return err
+				}
+
+				return vpcrtb.ApplyPlan(ctx, ec2.NewFromConfig(cfg), plan, journals, c.Root().Writer)
+			},
+		},
+		{
+			Name:  "journals",
+			Usage: "List the saved route table journals",
+			Flags: []cli.Flag{newJournalLocationFlag()},
+			Action: func(ctx context.Context, c *cli.Command) error {
+				cfg, err := loadAWSConfig(ctx, c)
+				if err != nil {
+					return err
+				}
+
+				journals, err := openJournalStore(c, cfg)
+				if err != nil {
+					return err
+				}
+
+				ids, err := journals.List(ctx)
+				if err != nil {
+					return err
+				}
+				if len(ids) == 0 {
+					fmt.Fprintf(c.Root().Writer, "No journals found in %s\n", c.String("journal-location"))
+					return nil
+				}
+				for _, id := range ids {
+					fmt.Fprintln(c.Root().Writer, id)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "restore",
+			Usage:     "Put the routes recorded in a journal back to the route tables",
+			UsageText: "msk routes restore --journal 20250101T000000.000000000Z-vpc-0123456789abcdef0 --dry-run",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "journal",
+					Usage:    "ID of the journal to restore",
+					Required: true,
+				},
+				newJournalLocationFlag(),
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only show the diff between the journal and the current routes",
+				},
+			},
+			Action: func(ctx context.Context, c *cli.Command) error {
+				journalID := c.String("journal")
+				if journalID == "" {
+					return fmt.Errorf("journal is not allowed to be empty")
+				}
+
+				cfg, err := loadAWSConfig(ctx, c)
+				if err != nil {
+					return err
+				}
+
+				journals, err := openJournalStore(c, cfg)
+				if err != nil {
+					return err
+				}
+
+				j, err := journals.Load(ctx, journalID)
+				if err != nil {
+					return err
+				}
+
+				return vpcrtb.RestoreRoutes(ctx, ec2.NewFromConfig(cfg), j, journals, c.Bool("dry-run"), c.Root().Writer)
+			},
+		},
+	},
+}
+
+func openJournalStore(c *cli.Command, cfg aws.Config) (*vpcrtb.JournalStore, error) {
+	store, err := blob.Open(c.String("journal-location"), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal location: %w", err)
+	}
+
+	return vpcrtb.NewJournalStore(store), nil
+}
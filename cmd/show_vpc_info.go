@@ -5,14 +5,15 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/sgykfjsm/msk/internal/vpcinfo"
 	"github.com/urfave/cli/v3"
 )
 
 var ShowVPCInfoCmd = &cli.Command{
 	Name:  "show-vpc-info",
-	Usage: "Show CIDR block and AWS account ID of the VPC",
+	Usage: "Show CIDR blocks, subnets, route tables and peering connections of the VPC with the AWS account ID",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:     "vpc-id",
@@ -44,14 +45,19 @@ var ShowVPCInfoCmd = &cli.Command{
 			return fmt.Errorf("invalid output format: %s, allowed formats are: %v", outputFormat, allowedFormats)
 		}
 
+		// We expect AWS related variables to be set in the environment or given by the global flags
+		cfg, err := loadAWSConfig(ctx, c)
+		if err != nil {
+			return err
+		}
+
 		if c.Bool("dry-run") {
 			fmt.Printf("Dry run: would fetch VPC info for VPC ID %q with output format %q\n", vpcID, outputFormat)
-			util.PrintAWSVariables(ctx, c.Root().Writer)
+			printAWSVariables(ctx, c, cfg)
 			return nil
 		}
 
-		// We expect AWS related variables to be set in the environment
-		vpcInfo, err := vpcinfo.FetchVPCInfo(ctx, vpcID)
+		vpcInfo, err := vpcinfo.FetchVPCInfo(ctx, ec2.NewFromConfig(cfg), sts.NewFromConfig(cfg), cfg.Region, vpcID)
 		if err != nil {
 			return fmt.Errorf("failed to fetch VPC info: %w", err)
 		}
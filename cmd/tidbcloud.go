@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/icholy/digest"
+	"github.com/urfave/cli/v3"
+)
+
+// newAPICredentialFlags returns the flags for the TiDB Cloud API key pair, which are accepted only from environment variables.
+func newAPICredentialFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "api-key",
+			Usage:   "API key for authentication with TiDB Cloud API",
+			Sources: cli.EnvVars("MSK_API_KEY"),
+			Hidden:  true, // accept only from environment variable
+		},
+		&cli.StringFlag{
+			Name:    "api-secret",
+			Usage:   "API secret for authentication with TiDB Cloud API",
+			Sources: cli.EnvVars("MSK_API_SECRET"),
+			Hidden:  true, // accept only from environment variable
+		},
+	}
+}
+
+// newTiDBCloudAPIFlags returns the flags to call the TiDB Cloud API at the given endpoint base by default.
+func newTiDBCloudAPIFlags(endpointBase string) []cli.Flag {
+	return append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "api-endpoint-base",
+			Usage: "TiDB Cloud API endpoint base",
+			Value: endpointBase,
+		},
+		&cli.DurationFlag{
+			Name:  "http-timeout",
+			Usage: "Timeout for each HTTP request to the TiDB Cloud API. (duration, e.g. 30s, 1m)",
+			Value: 30 * time.Second,
+		},
+	}, newAPICredentialFlags()...)
+}
+
+// newTiDBCloudHTTPClient returns an HTTP client authenticating to the TiDB Cloud API with HTTP digest authentication.
+func newTiDBCloudHTTPClient(c *cli.Command) *http.Client {
+	transport := &digest.Transport{
+		Username: c.String("api-key"),
+		Password: c.String("api-secret"),
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   c.Duration("http-timeout"),
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"net"
 	"strings"
 
-	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/sgykfjsm/msk/internal/vpcpeering"
 	"github.com/sgykfjsm/msk/internal/vpcrtb"
 	"github.com/urfave/cli/v3"
@@ -36,44 +36,72 @@ var UpdateRoutesCmd = &cli.Command{
 			Usage: "If true, only simulate the update without making changes",
 			Value: false,
 		},
+		newJournalLocationFlag(),
+		&cli.BoolFlag{
+			Name:  "skip-cidr-check",
+			Usage: "Do not check the CIDR for overlaps with the VPC and conflicting routes before updating routes",
+		},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
-		vpcID := c.String("vpc-id")
-		if vpcID == "" {
-			return fmt.Errorf("vpc-id is not allowed to be empty")
-		} else if !strings.HasPrefix(vpcID, "vpc-") {
-			return fmt.Errorf("vpc-id should start with 'vpc-' prefix, please provide the actual VPC ID with the prefix")
-		}
-
-		cidr := c.String("cidr")
-		if cidr == "" {
-			return fmt.Errorf("cidr is not allowed to be empty")
-		} else if _, _, err := net.ParseCIDR(cidr); err != nil {
-			return fmt.Errorf("cidr %q should be in CIDR notation, e.g., 192.168.1.0/24", cidr)
+		vpcID, cidr, peerID := c.String("vpc-id"), c.String("cidr"), c.String("peer-id")
+		if err := validateRouteArgs(vpcID, cidr, peerID); err != nil {
+			return err
 		}
 
-		peerID := c.String("peer-id")
-		if peerID == "" {
-			return fmt.Errorf("peer-id is not allowed to be empty")
-		} else if !strings.HasPrefix(peerID, "pcx-") {
-			return fmt.Errorf("peer-id should start with 'pcx-' prefix, please provide the actual Peer ID with the prefix")
+		cfg, err := loadAWSConfig(ctx, c)
+		if err != nil {
+			return err
 		}
 
 		dryRun := c.Bool("dry-run")
 		if dryRun {
 			fmt.Fprintf(c.Root().Writer, "Dry run: would update routes for VPC %q with CIDR %q and peer ID %q\n", vpcID, cidr, peerID)
 			// Check if the target VPC peering connection is already accepted
-			if err := vpcpeering.AcceptVPCPeeringConnection(ctx, peerID, c.Root().Writer, true); err != nil {
+			if err := vpcpeering.AcceptVPCPeeringConnection(ctx, ec2.NewFromConfig(cfg), peerID, c.Root().Writer, true); err != nil {
 				fmt.Fprintf(c.Root().Writer, "failed to check VPC peering connection: %v\n", err)
 			}
 
-			util.PrintAWSVariables(ctx, c.Root().Writer)
+			printAWSVariables(ctx, c, cfg)
+		}
+
+		if !c.Bool("skip-cidr-check") {
+			if err := checkCIDRBeforeRouteChange(ctx, cfg, c.Root().Writer, vpcID, cidr, peerID, dryRun); err != nil {
+				return err
+			}
 		}
 
-		if err := vpcrtb.UpdateRoutes(ctx, vpcID, cidr, peerID, dryRun, c.Root().Writer); err != nil {
+		journals, err := openJournalStore(c, cfg)
+		if err != nil {
+			return err
+		}
+
+		if err := vpcrtb.UpdateRoutes(ctx, ec2.NewFromConfig(cfg), vpcID, cidr, peerID, dryRun, journals, c.Root().Writer); err != nil {
 			return err
 		}
 
 		return nil
 	},
 }
+
+// validateRouteArgs validates the VPC ID, CIDR and peer ID given to update-routes and routes plan.
+func validateRouteArgs(vpcID, cidr, peerID string) error {
+	if vpcID == "" {
+		return fmt.Errorf("vpc-id is not allowed to be empty")
+	} else if !strings.HasPrefix(vpcID, "vpc-") {
+		return fmt.Errorf("vpc-id should start with 'vpc-' prefix, please provide the actual VPC ID with the prefix")
+	}
+
+	if cidr == "" {
+		return fmt.Errorf("cidr is not allowed to be empty")
+	} else if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return fmt.Errorf("cidr %q should be in CIDR notation, e.g., 192.168.1.0/24", cidr)
+	}
+
+	if peerID == "" {
+		return fmt.Errorf("peer-id is not allowed to be empty")
+	} else if !strings.HasPrefix(peerID, "pcx-") {
+		return fmt.Errorf("peer-id should start with 'pcx-' prefix, please provide the actual Peer ID with the prefix")
+	}
+
+	return nil
+}
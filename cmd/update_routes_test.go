@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestValidateRouteArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		vpcID   string
+		cidr    string
+		peerID  string
+		wantErr bool
+	}{
+		{"valid", "vpc-1", "192.168.1.0/24", "pcx-1", false},
+		{"emptyVPC", "", "192.168.1.0/24", "pcx-1", true},
+		{"badVPCPrefix", "1234", "192.168.1.0/24", "pcx-1", true},
+		{"emptyCIDR", "vpc-1", "", "pcx-1", true},
+		{"badCIDR", "vpc-1", "192.168.1.0", "pcx-1", true},
+		{"emptyPeer", "vpc-1", "192.168.1.0/24", "", true},
+		{"badPeerPrefix", "vpc-1", "192.168.1.0/24", "vpc-2", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRouteArgs(tt.vpcID, tt.cidr, tt.peerID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err=%v wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
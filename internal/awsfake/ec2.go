@@ -0,0 +1,572 @@
+// Package awsfake provides in-memory stand-ins for the AWS APIs used by msk,
+// so that the route, peering and security group logic can be exercised end to end without AWS.
+//
+// The fakes implement only the subset of the APIs msk calls: they return all results in a single page,
+// support only the filters msk uses, and apply changes immediately (e.g. a created VPC endpoint is available at once).
+package awsfake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+// EC2 is an in-memory EC2 API. The zero value is not usable; use NewEC2.
+type EC2 struct {
+	mu             sync.Mutex
+	vpcs           []ec2types.Vpc
+	subnets        []ec2types.Subnet
+	routeTables    []ec2types.RouteTable
+	peerings       []ec2types.VpcPeeringConnection
+	securityGroups []ec2types.SecurityGroup
+	endpoints      []ec2types.VpcEndpoint
+	nextID         int
+}
+
+// NewEC2 returns an empty in-memory EC2 API.
+func NewEC2() *EC2 {
+	return &EC2{}
+}
+
+// AddVPC adds a VPC.
+func (f *EC2) AddVPC(vpc ec2types.Vpc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vpcs = append(f.vpcs, vpc)
+}
+
+// AddSubnet adds a subnet.
+func (f *EC2) AddSubnet(subnet ec2types.Subnet) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subnets = append(f.subnets, subnet)
+}
+
+// AddRouteTable adds a route table.
+func (f *EC2) AddRouteTable(rtb ec2types.RouteTable) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routeTables = append(f.routeTables, rtb)
+}
+
+// AddPeeringConnection adds a VPC peering connection.
+func (f *EC2) AddPeeringConnection(pcx ec2types.VpcPeeringConnection) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peerings = append(f.peerings, pcx)
+}
+
+// AddSecurityGroup adds a security group.
+func (f *EC2) AddSecurityGroup(sg ec2types.SecurityGroup) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.securityGroups = append(f.securityGroups, sg)
+}
+
+// RouteTable returns a copy of the route table with the given ID, or nil if there is none.
+func (f *EC2) RouteTable(id string) *ec2types.RouteTable {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := f.routeTableIndex(id)
+	if i < 0 {
+		return nil
+	}
+	rtb := f.routeTables[i]
+	rtb.Routes = slices.Clone(rtb.Routes)
+	return &rtb
+}
+
+// PeeringConnection returns a copy of the VPC peering connection with the given ID, or nil if there is none.
+func (f *EC2) PeeringConnection(id string) *ec2types.VpcPeeringConnection {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pcx := range f.peerings {
+		if aws.ToString(pcx.VpcPeeringConnectionId) == id {
+			return &pcx
+		}
+	}
+	return nil
+}
+
+// SecurityGroup returns a copy of the security group with the given ID, or nil if there is none.
+func (f *EC2) SecurityGroup(id string) *ec2types.SecurityGroup {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := f.securityGroupIndex(id)
+	if i < 0 {
+		return nil
+	}
+	sg := f.securityGroups[i]
+	sg.IpPermissions = slices.Clone(sg.IpPermissions)
+	sg.IpPermissionsEgress = slices.Clone(sg.IpPermissionsEgress)
+	return &sg
+}
+
+func (f *EC2) DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeVpcsOutput{}
+	for _, vpc := range f.vpcs {
+		ok, err := matchFilters(params.Filters, map[string]string{
+			"vpc-id":     aws.ToString(vpc.VpcId),
+			"owner-id":   aws.ToString(vpc.OwnerId),
+			"cidr-block": aws.ToString(vpc.CidrBlock),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.VpcIds, aws.ToString(vpc.VpcId)) {
+			output.Vpcs = append(output.Vpcs, vpc)
+		}
+	}
+	if err := requireFound(params.VpcIds, len(output.Vpcs), "InvalidVpcID.NotFound"); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (f *EC2) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeSubnetsOutput{}
+	for _, subnet := range f.subnets {
+		ok, err := matchFilters(params.Filters, map[string]string{
+			"vpc-id":    aws.ToString(subnet.VpcId),
+			"subnet-id": aws.ToString(subnet.SubnetId),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.SubnetIds, aws.ToString(subnet.SubnetId)) {
+			output.Subnets = append(output.Subnets, subnet)
+		}
+	}
+
+	return output, nil
+}
+
+func (f *EC2) DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeRouteTablesOutput{}
+	for _, rtb := range f.routeTables {
+		ok, err := matchFilters(params.Filters, map[string]string{
+			"vpc-id":         aws.ToString(rtb.VpcId),
+			"route-table-id": aws.ToString(rtb.RouteTableId),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.RouteTableIds, aws.ToString(rtb.RouteTableId)) {
+			rtb.Routes = slices.Clone(rtb.Routes)
+			output.RouteTables = append(output.RouteTables, rtb)
+		}
+	}
+	if err := requireFound(params.RouteTableIds, len(output.RouteTables), "InvalidRouteTableID.NotFound"); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (f *EC2) CreateRoute(ctx context.Context, params *ec2.CreateRouteInput, optFns ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.routeTableIndex(aws.ToString(params.RouteTableId))
+	if i < 0 {
+		return nil, notFound("InvalidRouteTableID.NotFound", aws.ToString(params.RouteTableId))
+	}
+	dest := destination(params.DestinationCidrBlock, params.DestinationIpv6CidrBlock, params.DestinationPrefixListId)
+	if routeIndex(f.routeTables[i].Routes, dest) >= 0 {
+		return nil, &smithy.GenericAPIError{Code: "RouteAlreadyExists", Message: fmt.Sprintf("The route identified by %s already exists.", dest)}
+	}
+
+	f.routeTables[i].Routes = append(f.routeTables[i].Routes, ec2types.Route{
+		DestinationCidrBlock:        params.DestinationCidrBlock,
+		DestinationIpv6CidrBlock:    params.DestinationIpv6CidrBlock,
+		DestinationPrefixListId:     params.DestinationPrefixListId,
+		GatewayId:                   params.GatewayId,
+		NatGatewayId:                params.NatGatewayId,
+		TransitGatewayId:            params.TransitGatewayId,
+		VpcPeeringConnectionId:      params.VpcPeeringConnectionId,
+		NetworkInterfaceId:          params.NetworkInterfaceId,
+		EgressOnlyInternetGatewayId: params.EgressOnlyInternetGatewayId,
+		LocalGatewayId:              params.LocalGatewayId,
+		CarrierGatewayId:            params.CarrierGatewayId,
+		CoreNetworkArn:              params.CoreNetworkArn,
+		Origin:                      ec2types.RouteOriginCreateRoute,
+		State:                       ec2types.RouteStateActive,
+	})
+
+	return &ec2.CreateRouteOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *EC2) ReplaceRoute(ctx context.Context, params *ec2.ReplaceRouteInput, optFns ...func(*ec2.Options)) (*ec2.ReplaceRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.routeTableIndex(aws.ToString(params.RouteTableId))
+	if i < 0 {
+		return nil, notFound("InvalidRouteTableID.NotFound", aws.ToString(params.RouteTableId))
+	}
+	dest := destination(params.DestinationCidrBlock, params.DestinationIpv6CidrBlock, params.DestinationPrefixListId)
+	j := routeIndex(f.routeTables[i].Routes, dest)
+	if j < 0 {
+		return nil, notFound("InvalidRoute.NotFound", dest)
+	}
+
+	f.routeTables[i].Routes[j] = ec2types.Route{
+		DestinationCidrBlock:        params.DestinationCidrBlock,
+		DestinationIpv6CidrBlock:    params.DestinationIpv6CidrBlock,
+		DestinationPrefixListId:     params.DestinationPrefixListId,
+		GatewayId:                   params.GatewayId,
+		NatGatewayId:                params.NatGatewayId,
+		TransitGatewayId:            params.TransitGatewayId,
+		VpcPeeringConnectionId:      params.VpcPeeringConnectionId,
+		NetworkInterfaceId:          params.NetworkInterfaceId,
+		EgressOnlyInternetGatewayId: params.EgressOnlyInternetGatewayId,
+		LocalGatewayId:              params.LocalGatewayId,
+		CarrierGatewayId:            params.CarrierGatewayId,
+		CoreNetworkArn:              params.CoreNetworkArn,
+		Origin:                      ec2types.RouteOriginCreateRoute,
+		State:                       ec2types.RouteStateActive,
+	}
+
+	return &ec2.ReplaceRouteOutput{}, nil
+}
+
+func (f *EC2) DeleteRoute(ctx context.Context, params *ec2.DeleteRouteInput, optFns ...func(*ec2.Options)) (*ec2.DeleteRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.routeTableIndex(aws.ToString(params.RouteTableId))
+	if i < 0 {
+		return nil, notFound("InvalidRouteTableID.NotFound", aws.ToString(params.RouteTableId))
+	}
+	dest := destination(params.DestinationCidrBlock, params.DestinationIpv6CidrBlock, params.DestinationPrefixListId)
+	j := routeIndex(f.routeTables[i].Routes, dest)
+	if j < 0 {
+		return nil, notFound("InvalidRoute.NotFound", dest)
+	}
+	f.routeTables[i].Routes = slices.Delete(f.routeTables[i].Routes, j, j+1)
+
+	return &ec2.DeleteRouteOutput{}, nil
+}
+
+func (f *EC2) DescribeVpcPeeringConnections(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeVpcPeeringConnectionsOutput{}
+	for _, pcx := range f.peerings {
+		values := map[string]string{"vpc-peering-connection-id": aws.ToString(pcx.VpcPeeringConnectionId)}
+		if pcx.Status != nil {
+			values["status-code"] = string(pcx.Status.Code)
+		}
+		if v := pcx.RequesterVpcInfo; v != nil {
+			values["requester-vpc-info.vpc-id"] = aws.ToString(v.VpcId)
+			values["requester-vpc-info.owner-id"] = aws.ToString(v.OwnerId)
+			values["requester-vpc-info.cidr-block"] = aws.ToString(v.CidrBlock)
+		}
+		if v := pcx.AccepterVpcInfo; v != nil {
+			values["accepter-vpc-info.vpc-id"] = aws.ToString(v.VpcId)
+			values["accepter-vpc-info.owner-id"] = aws.ToString(v.OwnerId)
+			values["accepter-vpc-info.cidr-block"] = aws.ToString(v.CidrBlock)
+		}
+		ok, err := matchFilters(params.Filters, values)
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.VpcPeeringConnectionIds, aws.ToString(pcx.VpcPeeringConnectionId)) {
+			output.VpcPeeringConnections = append(output.VpcPeeringConnections, pcx)
+		}
+	}
+	if err := requireFound(params.VpcPeeringConnectionIds, len(output.VpcPeeringConnections), "InvalidVpcPeeringConnectionID.NotFound"); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (f *EC2) AcceptVpcPeeringConnection(ctx context.Context, params *ec2.AcceptVpcPeeringConnectionInput, optFns ...func(*ec2.Options)) (*ec2.AcceptVpcPeeringConnectionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.ToString(params.VpcPeeringConnectionId)
+	for i, pcx := range f.peerings {
+		if aws.ToString(pcx.VpcPeeringConnectionId) != id {
+			continue
+		}
+		if pcx.Status == nil || pcx.Status.Code != ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance {
+			return nil, &smithy.GenericAPIError{Code: "InvalidStateTransition", Message: fmt.Sprintf("Invalid state transition for %s", id)}
+		}
+		f.peerings[i].Status = &ec2types.VpcPeeringConnectionStateReason{
+			Code:    ec2types.VpcPeeringConnectionStateReasonCodeActive,
+			Message: aws.String("Active"),
+		}
+		return &ec2.AcceptVpcPeeringConnectionOutput{VpcPeeringConnection: &f.peerings[i]}, nil
+	}
+
+	return nil, notFound("InvalidVpcPeeringConnectionID.NotFound", id)
+}
+
+func (f *EC2) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeSecurityGroupsOutput{}
+	for _, sg := range f.securityGroups {
+		ok, err := matchFilters(params.Filters, map[string]string{
+			"vpc-id":     aws.ToString(sg.VpcId),
+			"group-id":   aws.ToString(sg.GroupId),
+			"group-name": aws.ToString(sg.GroupName),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.GroupIds, aws.ToString(sg.GroupId)) {
+			sg.IpPermissions = slices.Clone(sg.IpPermissions)
+			sg.IpPermissionsEgress = slices.Clone(sg.IpPermissionsEgress)
+			output.SecurityGroups = append(output.SecurityGroups, sg)
+		}
+	}
+	if err := requireFound(params.GroupIds, len(output.SecurityGroups), "InvalidGroup.NotFound"); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (f *EC2) AuthorizeSecurityGroupIngress(ctx context.Context, params *ec2.AuthorizeSecurityGroupIngressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	if err := f.updatePermissions(aws.ToString(params.GroupId), params.IpPermissions, true, false); err != nil {
+		return nil, err
+	}
+	return &ec2.AuthorizeSecurityGroupIngressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *EC2) AuthorizeSecurityGroupEgress(ctx context.Context, params *ec2.AuthorizeSecurityGroupEgressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupEgressOutput, error) {
+	if err := f.updatePermissions(aws.ToString(params.GroupId), params.IpPermissions, false, false); err != nil {
+		return nil, err
+	}
+	return &ec2.AuthorizeSecurityGroupEgressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *EC2) RevokeSecurityGroupIngress(ctx context.Context, params *ec2.RevokeSecurityGroupIngressInput, optFns ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+	if err := f.updatePermissions(aws.ToString(params.GroupId), params.IpPermissions, true, true); err != nil {
+		return nil, err
+	}
+	return &ec2.RevokeSecurityGroupIngressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *EC2) RevokeSecurityGroupEgress(ctx context.Context, params *ec2.RevokeSecurityGroupEgressInput, optFns ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupEgressOutput, error) {
+	if err := f.updatePermissions(aws.ToString(params.GroupId), params.IpPermissions, false, true); err != nil {
+		return nil, err
+	}
+	return &ec2.RevokeSecurityGroupEgressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *EC2) DescribeVpcEndpoints(ctx context.Context, params *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DescribeVpcEndpointsOutput{}
+	for _, endpoint := range f.endpoints {
+		ok, err := matchFilters(params.Filters, map[string]string{
+			"vpc-id":          aws.ToString(endpoint.VpcId),
+			"service-name":    aws.ToString(endpoint.ServiceName),
+			"vpc-endpoint-id": aws.ToString(endpoint.VpcEndpointId),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchIDs(params.VpcEndpointIds, aws.ToString(endpoint.VpcEndpointId)) {
+			output.VpcEndpoints = append(output.VpcEndpoints, endpoint)
+		}
+	}
+
+	return output, nil
+}
+
+func (f *EC2) CreateVpcEndpoint(ctx context.Context, params *ec2.CreateVpcEndpointInput, optFns ...func(*ec2.Options)) (*ec2.CreateVpcEndpointOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.vpcIndex(aws.ToString(params.VpcId)) < 0 {
+		return nil, notFound("InvalidVpcID.NotFound", aws.ToString(params.VpcId))
+	}
+
+	endpoint := ec2types.VpcEndpoint{
+		VpcEndpointId:   aws.String(f.newID("vpce")),
+		VpcEndpointType: params.VpcEndpointType,
+		VpcId:           params.VpcId,
+		ServiceName:     params.ServiceName,
+		SubnetIds:       params.SubnetIds,
+		State:           ec2types.StateAvailable,
+	}
+	for _, id := range params.SecurityGroupIds {
+		endpoint.Groups = append(endpoint.Groups, ec2types.SecurityGroupIdentifier{GroupId: aws.String(id)})
+	}
+	for _, spec := range params.TagSpecifications {
+		endpoint.Tags = append(endpoint.Tags, spec.Tags...)
+	}
+	f.endpoints = append(f.endpoints, endpoint)
+
+	return &ec2.CreateVpcEndpointOutput{VpcEndpoint: &endpoint}, nil
+}
+
+func (f *EC2) DeleteVpcEndpoints(ctx context.Context, params *ec2.DeleteVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteVpcEndpointsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &ec2.DeleteVpcEndpointsOutput{}
+	for _, id := range params.VpcEndpointIds {
+		i := slices.IndexFunc(f.endpoints, func(e ec2types.VpcEndpoint) bool { return aws.ToString(e.VpcEndpointId) == id })
+		if i < 0 {
+			output.Unsuccessful = append(output.Unsuccessful, ec2types.UnsuccessfulItem{
+				ResourceId: aws.String(id),
+				Error:      &ec2types.UnsuccessfulItemError{Code: aws.String("InvalidVpcEndpoint.NotFound"), Message: aws.String("The VPC endpoint " + id + " does not exist")},
+			})
+			continue
+		}
+		f.endpoints = slices.Delete(f.endpoints, i, i+1)
+	}
+
+	return output, nil
+}
+
+// updatePermissions adds or removes the permissions of the security group.
+// Each permission is identified by its protocol, port range and a single CIDR or prefix list.
+func (f *EC2) updatePermissions(groupID string, perms []ec2types.IpPermission, ingress, revoke bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.securityGroupIndex(groupID)
+	if i < 0 {
+		return notFound("InvalidGroup.NotFound", groupID)
+	}
+	current := &f.securityGroups[i].IpPermissionsEgress
+	if ingress {
+		current = &f.securityGroups[i].IpPermissions
+	}
+
+	for _, perm := range splitPermissions(perms) {
+		j := slices.IndexFunc(*current, func(p ec2types.IpPermission) bool { return permissionKey(p) == permissionKey(perm) })
+		switch {
+		case revoke && j < 0:
+			return &smithy.GenericAPIError{Code: "InvalidPermission.NotFound", Message: "The specified rule does not exist in this security group."}
+		case revoke:
+			*current = slices.Delete(*current, j, j+1)
+		case j >= 0:
+			return &smithy.GenericAPIError{Code: "InvalidPermission.Duplicate", Message: "the specified rule already exists"}
+		default:
+			*current = append(*current, perm)
+		}
+	}
+
+	return nil
+}
+
+// splitPermissions splits the permissions so that each has a single CIDR or prefix list.
+func splitPermissions(perms []ec2types.IpPermission) []ec2types.IpPermission {
+	var split []ec2types.IpPermission
+	for _, perm := range perms {
+		for _, ipRange := range perm.IpRanges {
+			p := perm
+			p.IpRanges, p.PrefixListIds = []ec2types.IpRange{ipRange}, nil
+			split = append(split, p)
+		}
+		for _, pl := range perm.PrefixListIds {
+			p := perm
+			p.IpRanges, p.PrefixListIds = nil, []ec2types.PrefixListId{pl}
+			split = append(split, p)
+		}
+	}
+	return split
+}
+
+func permissionKey(p ec2types.IpPermission) string {
+	key := fmt.Sprintf("%s/%d-%d", aws.ToString(p.IpProtocol), aws.ToInt32(p.FromPort), aws.ToInt32(p.ToPort))
+	for _, ipRange := range p.IpRanges {
+		key += " " + aws.ToString(ipRange.CidrIp)
+	}
+	for _, pl := range p.PrefixListIds {
+		key += " " + aws.ToString(pl.PrefixListId)
+	}
+	return key
+}
+
+func (f *EC2) vpcIndex(id string) int {
+	return slices.IndexFunc(f.vpcs, func(v ec2types.Vpc) bool { return aws.ToString(v.VpcId) == id })
+}
+
+func (f *EC2) routeTableIndex(id string) int {
+	return slices.IndexFunc(f.routeTables, func(r ec2types.RouteTable) bool { return aws.ToString(r.RouteTableId) == id })
+}
+
+func (f *EC2) securityGroupIndex(id string) int {
+	return slices.IndexFunc(f.securityGroups, func(sg ec2types.SecurityGroup) bool { return aws.ToString(sg.GroupId) == id })
+}
+
+func (f *EC2) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%017x", prefix, f.nextID)
+}
+
+func destination(cidr, ipv6CIDR, prefixListID *string) string {
+	if cidr != nil {
+		return aws.ToString(cidr)
+	}
+	if ipv6CIDR != nil {
+		return aws.ToString(ipv6CIDR)
+	}
+	return aws.ToString(prefixListID)
+}
+
+func routeIndex(routes []ec2types.Route, dest string) int {
+	return slices.IndexFunc(routes, func(rt ec2types.Route) bool {
+		return destination(rt.DestinationCidrBlock, rt.DestinationIpv6CidrBlock, rt.DestinationPrefixListId) == dest
+	})
+}
+
+// matchFilters reports whether the values of a resource match all of the filters.
+// Filters whose names are not in values are rejected, so that a call relying on an unsupported filter fails loudly.
+func matchFilters(filters []ec2types.Filter, values map[string]string) (bool, error) {
+	for _, filter := range filters {
+		name := aws.ToString(filter.Name)
+		value, ok := values[name]
+		if !ok {
+			return false, &smithy.GenericAPIError{Code: "InvalidParameterValue", Message: fmt.Sprintf("The filter '%s' is not supported by awsfake", name)}
+		}
+		if !slices.Contains(filter.Values, value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchIDs(ids []string, id string) bool {
+	return len(ids) == 0 || slices.Contains(ids, id)
+}
+
+// requireFound returns the error EC2 returns when some of the IDs requested explicitly do not exist.
+func requireFound(ids []string, found int, code string) error {
+	if len(ids) > found {
+		return notFound(code, fmt.Sprintf("%v", ids))
+	}
+	return nil
+}
+
+func notFound(code, id string) error {
+	return &smithy.GenericAPIError{Code: code, Message: fmt.Sprintf("The ID '%s' does not exist", id)}
+}
@@ -0,0 +1,57 @@
+package awsfake_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"github.com/sgykfjsm/msk/internal/privatelink"
+	"github.com/sgykfjsm/msk/internal/util"
+	"github.com/sgykfjsm/msk/internal/vpcinfo"
+	"github.com/sgykfjsm/msk/internal/vpcpeering"
+	"github.com/sgykfjsm/msk/internal/vpcrtb"
+	"github.com/sgykfjsm/msk/internal/vpcsg"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ vpcinfo.EC2API     = (*awsfake.EC2)(nil)
+	_ vpcpeering.EC2API  = (*awsfake.EC2)(nil)
+	_ vpcrtb.EC2API      = (*awsfake.EC2)(nil)
+	_ vpcsg.EC2API       = (*awsfake.EC2)(nil)
+	_ privatelink.EC2API = (*awsfake.EC2)(nil)
+	_ util.STSAPI        = (*awsfake.STS)(nil)
+)
+
+func TestEC2_Routes(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddRouteTable(ec2types.RouteTable{RouteTableId: aws.String("rtb-1"), VpcId: aws.String("vpc-1")})
+
+	input := &ec2.CreateRouteInput{RouteTableId: aws.String("rtb-1"), DestinationCidrBlock: aws.String("172.16.0.0/21"), VpcPeeringConnectionId: aws.String("pcx-1")}
+	_, err := fake.CreateRoute(ctx, input)
+	require.NoError(t, err)
+	_, err = fake.CreateRoute(ctx, input)
+	require.ErrorContains(t, err, "RouteAlreadyExists")
+
+	_, err = fake.DeleteRoute(ctx, &ec2.DeleteRouteInput{RouteTableId: aws.String("rtb-1"), DestinationCidrBlock: aws.String("172.16.0.0/21")})
+	require.NoError(t, err)
+	_, err = fake.ReplaceRoute(ctx, &ec2.ReplaceRouteInput{RouteTableId: aws.String("rtb-1"), DestinationCidrBlock: aws.String("172.16.0.0/21")})
+	require.ErrorContains(t, err, "InvalidRoute.NotFound")
+
+	_, err = fake.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{RouteTableIds: []string{"rtb-missing"}})
+	require.ErrorContains(t, err, "InvalidRouteTableID.NotFound")
+}
+
+func TestEC2_UnsupportedFilter(t *testing.T) {
+	fake := awsfake.NewEC2()
+	fake.AddRouteTable(ec2types.RouteTable{RouteTableId: aws.String("rtb-1"), VpcId: aws.String("vpc-1")})
+
+	_, err := fake.DescribeRouteTables(context.Background(), &ec2.DescribeRouteTablesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("association.main"), Values: []string{"true"}}},
+	})
+	require.ErrorContains(t, err, "not supported")
+}
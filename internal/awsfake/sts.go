@@ -0,0 +1,27 @@
+package awsfake
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// STS is an in-memory STS API returning a fixed caller identity.
+type STS struct {
+	Account string
+	ARN     string
+}
+
+// NewSTS returns an STS API for the given account, identified as the root user of it.
+func NewSTS(account string) *STS {
+	return &STS{Account: account, ARN: "arn:aws:iam::" + account + ":root"}
+}
+
+func (f *STS) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(f.Account),
+		Arn:     aws.String(f.ARN),
+		UserId:  aws.String(f.Account),
+	}, nil
+}
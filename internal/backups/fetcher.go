@@ -0,0 +1,277 @@
+package backups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// Backup represents a backup of a cluster in the response of the backup API.
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Backup/operation/ListBackUpOfCluster
+// Note that this instance holds only required attributes for this module, and omit some properties.
+type Backup struct {
+	ID              string `json:"id,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Type            string `json:"type,omitempty"`             // MANUAL or AUTO
+	CreateTimestamp string `json:"create_timestamp,omitempty"` // Unix timestamp in seconds, as a string
+	Size            string `json:"size,omitempty"`             // bytes, as a string
+	Status          string `json:"status,omitempty"`           // PENDING, RUNNING, FAILED or SUCCESS
+}
+
+// Backups is a slice of Backup.
+type Backups []Backup
+
+// Statuses of a backup that has finished.
+const (
+	BackupStatusSuccess = "SUCCESS" // It can be restored from.
+	BackupStatusFailed  = "FAILED"
+)
+
+// ListBackupsResponse represents the successful response structure from the TiDB Cloud ListBackUpOfCluster API.
+type ListBackupsResponse struct {
+	Items Backups `json:"items,omitempty"`
+	Total int     `json:"total,omitempty"`
+}
+
+// BackupFetcher defines an interface for fetching the backups of a cluster from a remote source.
+type BackupFetcher interface {
+	FetchBackups(ctx context.Context, projectID, clusterID string, page, pageSize int) (Backups, int, error)
+}
+
+// APIBackupFetcher implements the BackupFetcher interface using the TiDB Cloud API.
+type APIBackupFetcher struct {
+	Client       *http.Client
+	EndpointBase string
+}
+
+// NewAPIBackupFetcher returns a new APIBackupFetcher with the given HTTP client and API endpoint base URL.
+func NewAPIBackupFetcher(client *http.Client, endpointBase string) *APIBackupFetcher {
+	return &APIBackupFetcher{
+		Client:       client,
+		EndpointBase: endpointBase,
+	}
+}
+
+// FetchBackups retrieves a page of backups of a cluster from the TiDB Cloud API.
+// The second return value is the total number of backups of the cluster.
+func (f *APIBackupFetcher) FetchBackups(ctx context.Context, projectID, clusterID string, page, pageSize int) (Backups, int, error) {
+	apiEndpoint, err := url.JoinPath(f.EndpointBase, "projects", projectID, "clusters", clusterID, "backups")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to construct API endpoint URL started with %s with projectID %s and clusterID %s: %w", f.EndpointBase, projectID, clusterID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	q := req.URL.Query()
+	if page <= 0 {
+		page = 1 // Default to page 1 if not provided or invalid
+	}
+	if pageSize <= 0 {
+		pageSize = 20 // Default to 20 if not provided or invalid
+	}
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("page_size", fmt.Sprintf("%d", pageSize))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var listBackupsResponse ListBackupsResponse
+	if err := util.DecodeTiDBCloudResponse(resp, apiEndpoint, &listBackupsResponse); err != nil {
+		return nil, 0, err
+	}
+
+	return listBackupsResponse.Items, listBackupsResponse.Total, nil
+}
+
+// BackupStore defines an interface for storing backups and reading what is needed to sync them.
+type BackupStore interface {
+	ListBackupTrackedProjects(ctx context.Context) ([]string, error)
+	ListClusterIDs(ctx context.Context, projectID string) ([]string, error)
+	StoreBackups(ctx context.Context, projectID, clusterID string, backups Backups) error
+}
+
+// DBBackupStore represents a database-backed implementation for persisting backups.
+type DBBackupStore struct {
+	conn    *sql.DB
+	Queries *db.Queries
+}
+
+// NewDBBackupStore initializes a new DBBackupStore using the given DSN and optional connection pool settings.
+// Returns an error if the connection fails or cannot be verified.
+func NewDBBackupStore(dsn string, poolConfig *db.PoolConfig) (*DBBackupStore, error) {
+	conn, err := db.Open(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBBackupStore{
+		Queries: db.New(conn),
+		conn:    conn,
+	}, nil
+}
+
+// SetBackupTracking enables or disables backup tracking for the project.
+// Returns an error if the project has not been fetched by fetch-projects yet.
+func (s *DBBackupStore) SetBackupTracking(ctx context.Context, projectID string, enabled bool) error {
+	// Check the existence first, as MySQL reports 0 rows affected both for an unknown project and for an unchanged value.
+	exists, err := s.Queries.ProjectExists(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to look up project %s: %w", projectID, err)
+	}
+	if !exists {
+		return fmt.Errorf("project %s not found, run fetch-projects first", projectID)
+	}
+
+	if _, err := s.Queries.SetProjectBackupTracking(ctx, db.SetProjectBackupTrackingParams{Enabled: enabled, ID: projectID}); err != nil {
+		return fmt.Errorf("failed to update backup tracking of project %s: %w", projectID, err)
+	}
+
+	return nil
+}
+
+// ListBackupTrackedProjects returns the IDs of the projects that opted in to backup tracking.
+func (s *DBBackupStore) ListBackupTrackedProjects(ctx context.Context) ([]string, error) {
+	projectIDs, err := s.Queries.ListBackupTrackedProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects with backup tracking enabled: %w", err)
+	}
+
+	return projectIDs, nil
+}
+
+// ListClusterIDs returns the IDs of the clusters of the project that are not marked as deleted.
+func (s *DBBackupStore) ListClusterIDs(ctx context.Context, projectID string) ([]string, error) {
+	clusterIDs, err := s.Queries.ListClusterIDsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters of project %s: %w", projectID, err)
+	}
+
+	return clusterIDs, nil
+}
+
+// StoreBackups inserts or updates the given backups of a cluster within a transaction scope.
+// This method is a no-op if the input slice is empty.
+func (s *DBBackupStore) StoreBackups(ctx context.Context, projectID, clusterID string, backups Backups) error {
+	if len(backups) == 0 {
+		return nil // No backups to store, nothing to do
+	}
+
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	qtx := s.Queries.WithTx(tx)
+	for _, backup := range backups {
+		values, err := newUpsertClusterBackupParams(projectID, clusterID, backup)
+		if err == nil {
+			err = qtx.UpsertClusterBackup(ctx, values)
+		}
+		if err != nil {
+			upsertErr := fmt.Errorf("failed to upsert backup %s of cluster %s: %w", backup.ID, clusterID, err)
+			if err := tx.Rollback(); err != nil {
+				return errors.Join(upsertErr, fmt.Errorf("failed to rollback transaction: %w", err))
+			}
+			return upsertErr
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// StoreBackup inserts or updates a single backup of a cluster, e.g. the final backup taken before deleting it.
+func (s *DBBackupStore) StoreBackup(ctx context.Context, projectID, clusterID string, backup Backup) error {
+	values, err := newUpsertClusterBackupParams(projectID, clusterID, backup)
+	if err == nil {
+		err = s.Queries.UpsertClusterBackup(ctx, values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upsert backup %s of cluster %s: %w", backup.ID, clusterID, err)
+	}
+
+	return nil
+}
+
+// ListLatestSuccessfulBackups returns the latest successful backup time of each cluster of the projects
+// with backup tracking enabled.
+func (s *DBBackupStore) ListLatestSuccessfulBackups(ctx context.Context) ([]ClusterBackupStatus, error) {
+	rows, err := s.Queries.ListLatestSuccessfulBackups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latest successful backups: %w", err)
+	}
+
+	statuses := make([]ClusterBackupStatus, 0, len(rows))
+	for _, row := range rows {
+		status := ClusterBackupStatus{
+			ProjectID:     row.ProjectID,
+			ClusterID:     row.ID,
+			ClusterName:   row.Name,
+			ClusterStatus: row.ClusterStatus,
+		}
+		if row.LatestBackupTimestamp > 0 {
+			latest := time.Unix(row.LatestBackupTimestamp, 0).UTC()
+			status.LatestBackupAt = &latest
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Close closes the underlying database connection held by the DBBackupStore.
+func (s *DBBackupStore) Close() error {
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			s.conn = nil // Set to nil to avoid double close
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newUpsertClusterBackupParams(projectID, clusterID string, backup Backup) (db.UpsertClusterBackupParams, error) {
+	createTimestamp, err := strconv.ParseInt(backup.CreateTimestamp, 10, 64)
+	if err != nil {
+		return db.UpsertClusterBackupParams{}, fmt.Errorf("invalid create_timestamp format for backup %s: %w", backup.ID, err)
+	}
+
+	var size int64
+	if backup.Size != "" {
+		size, err = strconv.ParseInt(backup.Size, 10, 64)
+		if err != nil {
+			return db.UpsertClusterBackupParams{}, fmt.Errorf("invalid size format for backup %s: %w", backup.ID, err)
+		}
+	}
+
+	return db.UpsertClusterBackupParams{
+		ID:              backup.ID,
+		ClusterID:       clusterID,
+		ProjectID:       projectID,
+		Name:            backup.Name,
+		BackupType:      backup.Type,
+		BackupStatus:    backup.Status,
+		SizeBytes:       size,
+		CreateTimestamp: createTimestamp,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package backups
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIBackupFetcher_FetchBackups_Success(t *testing.T) {
+	ctx := context.Background()
+
+	expectedBackups := Backups{
+		{ID: "b1", Name: "daily", Type: "AUTO", CreateTimestamp: "1656991448", Size: "1024", Status: BackupStatusSuccess},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/projects/p1/clusters/c1/backups", r.URL.Path)
+		require.Equal(t, "2", r.URL.Query().Get("page"))
+		require.Equal(t, "10", r.URL.Query().Get("page_size"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&ListBackupsResponse{Items: expectedBackups, Total: 11})
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	backups, total, err := NewAPIBackupFetcher(server.Client(), server.URL).FetchBackups(ctx, "p1", "c1", 2, 10)
+	require.NoError(t, err)
+	require.Equal(t, expectedBackups, backups)
+	require.Equal(t, 11, total)
+}
+
+func TestAPIBackupFetcher_FetchBackups_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "unauthorized"},
+		{name: "rate limit", status: http.StatusTooManyRequests, wantErr: "rate limit"},
+		{name: "api error", status: http.StatusNotFound, body: `{"code":49900007,"message":"cluster not found"}`, wantErr: "cluster not found"},
+		{name: "undecodable error", status: http.StatusInternalServerError, body: "oops", wantErr: "failed to decode error response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, _, err := NewAPIBackupFetcher(server.Client(), server.URL).FetchBackups(context.Background(), "p1", "c1", 1, 10)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewUpsertClusterBackupParams(t *testing.T) {
+	params, err := newUpsertClusterBackupParams("p1", "c1", Backup{ID: "b1", CreateTimestamp: "1656991448", Size: "2048", Status: "SUCCESS", Type: "MANUAL"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1656991448), params.CreateTimestamp)
+	require.Equal(t, int64(2048), params.SizeBytes)
+	require.Equal(t, "c1", params.ClusterID)
+
+	_, err = newUpsertClusterBackupParams("p1", "c1", Backup{ID: "b1", CreateTimestamp: "yesterday"})
+	require.ErrorContains(t, err, "invalid create_timestamp")
+}
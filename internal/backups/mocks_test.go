@@ -0,0 +1,467 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package backups
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockBackupFetcher creates a new instance of MockBackupFetcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBackupFetcher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBackupFetcher {
+	mock := &MockBackupFetcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBackupFetcher is an autogenerated mock type for the BackupFetcher type
+type MockBackupFetcher struct {
+	mock.Mock
+}
+
+type MockBackupFetcher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBackupFetcher) EXPECT() *MockBackupFetcher_Expecter {
+	return &MockBackupFetcher_Expecter{mock: &_m.Mock}
+}
+
+// FetchBackups provides a mock function for the type MockBackupFetcher
+func (_mock *MockBackupFetcher) FetchBackups(ctx context.Context, projectID string, clusterID string, page int, pageSize int) (Backups, int, error) {
+	ret := _mock.Called(ctx, projectID, clusterID, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchBackups")
+	}
+
+	var r0 Backups
+	var r1 int
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int, int) (Backups, int, error)); ok {
+		return returnFunc(ctx, projectID, clusterID, page, pageSize)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int, int) Backups); ok {
+		r0 = returnFunc(ctx, projectID, clusterID, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Backups)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, int, int) int); ok {
+		r1 = returnFunc(ctx, projectID, clusterID, page, pageSize)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string, string, int, int) error); ok {
+		r2 = returnFunc(ctx, projectID, clusterID, page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockBackupFetcher_FetchBackups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FetchBackups'
+type MockBackupFetcher_FetchBackups_Call struct {
+	*mock.Call
+}
+
+// FetchBackups is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID string
+//   - clusterID string
+//   - page int
+//   - pageSize int
+func (_e *MockBackupFetcher_Expecter) FetchBackups(ctx interface{}, projectID interface{}, clusterID interface{}, page interface{}, pageSize interface{}) *MockBackupFetcher_FetchBackups_Call {
+	return &MockBackupFetcher_FetchBackups_Call{Call: _e.mock.On("FetchBackups", ctx, projectID, clusterID, page, pageSize)}
+}
+
+func (_c *MockBackupFetcher_FetchBackups_Call) Run(run func(ctx context.Context, projectID string, clusterID string, page int, pageSize int)) *MockBackupFetcher_FetchBackups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		var arg4 int
+		if args[4] != nil {
+			arg4 = args[4].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupFetcher_FetchBackups_Call) Return(backups Backups, n int, err error) *MockBackupFetcher_FetchBackups_Call {
+	_c.Call.Return(backups, n, err)
+	return _c
+}
+
+func (_c *MockBackupFetcher_FetchBackups_Call) RunAndReturn(run func(ctx context.Context, projectID string, clusterID string, page int, pageSize int) (Backups, int, error)) *MockBackupFetcher_FetchBackups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockBackupStore creates a new instance of MockBackupStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBackupStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBackupStore {
+	mock := &MockBackupStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBackupStore is an autogenerated mock type for the BackupStore type
+type MockBackupStore struct {
+	mock.Mock
+}
+
+type MockBackupStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBackupStore) EXPECT() *MockBackupStore_Expecter {
+	return &MockBackupStore_Expecter{mock: &_m.Mock}
+}
+
+// ListBackupTrackedProjects provides a mock function for the type MockBackupStore
+func (_mock *MockBackupStore) ListBackupTrackedProjects(ctx context.Context) ([]string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBackupTrackedProjects")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBackupStore_ListBackupTrackedProjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBackupTrackedProjects'
+type MockBackupStore_ListBackupTrackedProjects_Call struct {
+	*mock.Call
+}
+
+// ListBackupTrackedProjects is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockBackupStore_Expecter) ListBackupTrackedProjects(ctx interface{}) *MockBackupStore_ListBackupTrackedProjects_Call {
+	return &MockBackupStore_ListBackupTrackedProjects_Call{Call: _e.mock.On("ListBackupTrackedProjects", ctx)}
+}
+
+func (_c *MockBackupStore_ListBackupTrackedProjects_Call) Run(run func(ctx context.Context)) *MockBackupStore_ListBackupTrackedProjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupStore_ListBackupTrackedProjects_Call) Return(strings []string, err error) *MockBackupStore_ListBackupTrackedProjects_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockBackupStore_ListBackupTrackedProjects_Call) RunAndReturn(run func(ctx context.Context) ([]string, error)) *MockBackupStore_ListBackupTrackedProjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListClusterIDs provides a mock function for the type MockBackupStore
+func (_mock *MockBackupStore) ListClusterIDs(ctx context.Context, projectID string) ([]string, error) {
+	ret := _mock.Called(ctx, projectID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListClusterIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return returnFunc(ctx, projectID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = returnFunc(ctx, projectID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, projectID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBackupStore_ListClusterIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListClusterIDs'
+type MockBackupStore_ListClusterIDs_Call struct {
+	*mock.Call
+}
+
+// ListClusterIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID string
+func (_e *MockBackupStore_Expecter) ListClusterIDs(ctx interface{}, projectID interface{}) *MockBackupStore_ListClusterIDs_Call {
+	return &MockBackupStore_ListClusterIDs_Call{Call: _e.mock.On("ListClusterIDs", ctx, projectID)}
+}
+
+func (_c *MockBackupStore_ListClusterIDs_Call) Run(run func(ctx context.Context, projectID string)) *MockBackupStore_ListClusterIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupStore_ListClusterIDs_Call) Return(strings []string, err error) *MockBackupStore_ListClusterIDs_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockBackupStore_ListClusterIDs_Call) RunAndReturn(run func(ctx context.Context, projectID string) ([]string, error)) *MockBackupStore_ListClusterIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StoreBackups provides a mock function for the type MockBackupStore
+func (_mock *MockBackupStore) StoreBackups(ctx context.Context, projectID string, clusterID string, backups Backups) error {
+	ret := _mock.Called(ctx, projectID, clusterID, backups)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StoreBackups")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, Backups) error); ok {
+		r0 = returnFunc(ctx, projectID, clusterID, backups)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockBackupStore_StoreBackups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StoreBackups'
+type MockBackupStore_StoreBackups_Call struct {
+	*mock.Call
+}
+
+// StoreBackups is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID string
+//   - clusterID string
+//   - backups Backups
+func (_e *MockBackupStore_Expecter) StoreBackups(ctx interface{}, projectID interface{}, clusterID interface{}, backups interface{}) *MockBackupStore_StoreBackups_Call {
+	return &MockBackupStore_StoreBackups_Call{Call: _e.mock.On("StoreBackups", ctx, projectID, clusterID, backups)}
+}
+
+func (_c *MockBackupStore_StoreBackups_Call) Run(run func(ctx context.Context, projectID string, clusterID string, backups Backups)) *MockBackupStore_StoreBackups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 Backups
+		if args[3] != nil {
+			arg3 = args[3].(Backups)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupStore_StoreBackups_Call) Return(err error) *MockBackupStore_StoreBackups_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockBackupStore_StoreBackups_Call) RunAndReturn(run func(ctx context.Context, projectID string, clusterID string, backups Backups) error) *MockBackupStore_StoreBackups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockBackupService creates a new instance of MockBackupService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBackupService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBackupService {
+	mock := &MockBackupService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBackupService is an autogenerated mock type for the BackupService type
+type MockBackupService struct {
+	mock.Mock
+}
+
+type MockBackupService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBackupService) EXPECT() *MockBackupService_Expecter {
+	return &MockBackupService_Expecter{mock: &_m.Mock}
+}
+
+// FetchAndStoreBackups provides a mock function for the type MockBackupService
+func (_mock *MockBackupService) FetchAndStoreBackups(ctx context.Context, projectIDs []string, pageSize int) (int, int, int, error) {
+	ret := _mock.Called(ctx, projectIDs, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchAndStoreBackups")
+	}
+
+	var r0 int
+	var r1 int
+	var r2 int
+	var r3 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string, int) (int, int, int, error)); ok {
+		return returnFunc(ctx, projectIDs, pageSize)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string, int) int); ok {
+		r0 = returnFunc(ctx, projectIDs, pageSize)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string, int) int); ok {
+		r1 = returnFunc(ctx, projectIDs, pageSize)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, []string, int) int); ok {
+		r2 = returnFunc(ctx, projectIDs, pageSize)
+	} else {
+		r2 = ret.Get(2).(int)
+	}
+	if returnFunc, ok := ret.Get(3).(func(context.Context, []string, int) error); ok {
+		r3 = returnFunc(ctx, projectIDs, pageSize)
+	} else {
+		r3 = ret.Error(3)
+	}
+	return r0, r1, r2, r3
+}
+
+// MockBackupService_FetchAndStoreBackups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FetchAndStoreBackups'
+type MockBackupService_FetchAndStoreBackups_Call struct {
+	*mock.Call
+}
+
+// FetchAndStoreBackups is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectIDs []string
+//   - pageSize int
+func (_e *MockBackupService_Expecter) FetchAndStoreBackups(ctx interface{}, projectIDs interface{}, pageSize interface{}) *MockBackupService_FetchAndStoreBackups_Call {
+	return &MockBackupService_FetchAndStoreBackups_Call{Call: _e.mock.On("FetchAndStoreBackups", ctx, projectIDs, pageSize)}
+}
+
+func (_c *MockBackupService_FetchAndStoreBackups_Call) Run(run func(ctx context.Context, projectIDs []string, pageSize int)) *MockBackupService_FetchAndStoreBackups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupService_FetchAndStoreBackups_Call) Return(n int, n1 int, n2 int, err error) *MockBackupService_FetchAndStoreBackups_Call {
+	_c.Call.Return(n, n1, n2, err)
+	return _c
+}
+
+func (_c *MockBackupService_FetchAndStoreBackups_Call) RunAndReturn(run func(ctx context.Context, projectIDs []string, pageSize int) (int, int, int, error)) *MockBackupService_FetchAndStoreBackups_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,48 @@
+package backups
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// ClusterBackupStatus is the latest successful backup of a cluster.
+type ClusterBackupStatus struct {
+	ProjectID      string     `json:"project_id"`
+	ClusterID      string     `json:"cluster_id"`
+	ClusterName    string     `json:"cluster_name"`
+	ClusterStatus  string     `json:"cluster_status"`
+	LatestBackupAt *time.Time `json:"latest_backup_at"` // nil if the cluster has no successful backup
+}
+
+// FindStaleBackups returns the clusters whose latest successful backup is older than threshold at now,
+// or which have no successful backup at all.
+func FindStaleBackups(statuses []ClusterBackupStatus, now time.Time, threshold time.Duration) []ClusterBackupStatus {
+	cutoff := now.Add(-threshold)
+
+	var stale []ClusterBackupStatus
+	for _, status := range statuses {
+		if status.LatestBackupAt == nil || status.LatestBackupAt.Before(cutoff) {
+			stale = append(stale, status)
+		}
+	}
+
+	return stale
+}
+
+// PrintStaleBackups writes the clusters as a table, with the age of the latest backup at now.
+func PrintStaleBackups(w io.Writer, statuses []ClusterBackupStatus, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tCLUSTER ID\tNAME\tSTATUS\tLATEST BACKUP\tAGE")
+	for _, status := range statuses {
+		latest, age := "none", "-"
+		if status.LatestBackupAt != nil {
+			latest = status.LatestBackupAt.UTC().Format(time.RFC3339)
+			age = now.Sub(*status.LatestBackupAt).Truncate(time.Minute).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", status.ProjectID, status.ClusterID, status.ClusterName, status.ClusterStatus, latest, age)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,44 @@
+package backups
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindStaleBackups(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Hour)
+	old := now.Add(-49 * time.Hour)
+
+	statuses := []ClusterBackupStatus{
+		{ClusterID: "recent", LatestBackupAt: &recent},
+		{ClusterID: "old", LatestBackupAt: &old},
+		{ClusterID: "none"},
+	}
+
+	stale := FindStaleBackups(statuses, now, 48*time.Hour)
+	require.Len(t, stale, 2)
+	require.Equal(t, "old", stale[0].ClusterID)
+	require.Equal(t, "none", stale[1].ClusterID)
+
+	require.Len(t, FindStaleBackups(statuses, now, time.Hour), 3)
+}
+
+func TestPrintStaleBackups(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-49*time.Hour - 30*time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintStaleBackups(&buf, []ClusterBackupStatus{
+		{ProjectID: "p1", ClusterID: "c1", ClusterName: "app", ClusterStatus: "AVAILABLE", LatestBackupAt: &old},
+		{ProjectID: "p1", ClusterID: "c2", ClusterName: "batch", ClusterStatus: "PAUSED"},
+	}, now))
+
+	require.Equal(t, ""+
+		"PROJECT  CLUSTER ID  NAME   STATUS     LATEST BACKUP         AGE\n"+
+		"p1       c1          app    AVAILABLE  2025-06-29T10:59:30Z  49h0m0s\n"+
+		"p1       c2          batch  PAUSED     none                  -\n", buf.String())
+}
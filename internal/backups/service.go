@@ -0,0 +1,74 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type BackupService interface {
+	FetchAndStoreBackups(ctx context.Context, projectIDs []string, pageSize int) (int, int, int, error)
+}
+
+type backupService struct {
+	fetcher BackupFetcher
+	store   BackupStore
+}
+
+func NewBackupService(fetcher BackupFetcher, store BackupStore) *backupService {
+	return &backupService{
+		fetcher: fetcher,
+		store:   store,
+	}
+}
+
+// FetchAndStoreBackups fetches and stores the backups of all active clusters of the given projects.
+// Projects that have not opted in to backup tracking are skipped.
+// It returns the number of processed projects, clusters and backups.
+func (s *backupService) FetchAndStoreBackups(ctx context.Context, projectIDs []string, pageSize int) (int, int, int, error) {
+	trackedProjectIDs, err := s.store.ListBackupTrackedProjects(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var totalProcessedProjectNum, totalProcessedClusterNum, totalProcessedBackupNum int
+	for _, projectID := range projectIDs {
+		if !slices.Contains(trackedProjectIDs, projectID) {
+			continue
+		}
+
+		clusterIDs, err := s.store.ListClusterIDs(ctx, projectID)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		for _, clusterID := range clusterIDs {
+			var processedBackupNum int
+			page := 1
+
+			for {
+				backups, total, err := s.fetcher.FetchBackups(ctx, projectID, clusterID, page, pageSize)
+				if err != nil {
+					return 0, 0, 0, fmt.Errorf("failed to fetch backups for cluster %s of project %s: %w", clusterID, projectID, err)
+				}
+
+				if err := s.store.StoreBackups(ctx, projectID, clusterID, backups); err != nil {
+					return 0, 0, 0, fmt.Errorf("failed to store %d backups for cluster %s of project %s: %w", len(backups), clusterID, projectID, err)
+				}
+
+				processedBackupNum += len(backups)
+				if processedBackupNum >= total || len(backups) == 0 {
+					break
+				}
+				page++
+			}
+
+			totalProcessedClusterNum++
+			totalProcessedBackupNum += processedBackupNum
+		}
+
+		totalProcessedProjectNum++
+	}
+
+	return totalProcessedProjectNum, totalProcessedClusterNum, totalProcessedBackupNum, nil
+}
@@ -0,0 +1,85 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupService_FetchAndStoreBackups(t *testing.T) {
+	ctx := context.Background()
+	pageSize := 2
+
+	page1 := Backups{{ID: "b1"}, {ID: "b2"}}
+	page2 := Backups{{ID: "b3"}}
+
+	tests := []struct {
+		name             string
+		projectIDs       []string
+		setupMocks       func(fetcher *MockBackupFetcher, store *MockBackupStore)
+		expectedProjects int
+		expectedClusters int
+		expectedBackups  int
+		expectedErrMsg   string
+	}{
+		{
+			name:       "Success with pagination and untracked project",
+			projectIDs: []string{"p1", "p2"},
+			setupMocks: func(fetcher *MockBackupFetcher, store *MockBackupStore) {
+				store.EXPECT().ListBackupTrackedProjects(ctx).Return([]string{"p1"}, nil).Once()
+				store.EXPECT().ListClusterIDs(ctx, "p1").Return([]string{"c1", "c2"}, nil).Once()
+				fetcher.EXPECT().FetchBackups(ctx, "p1", "c1", 1, pageSize).Return(page1, 3, nil).Once()
+				store.EXPECT().StoreBackups(ctx, "p1", "c1", page1).Return(nil).Once()
+				fetcher.EXPECT().FetchBackups(ctx, "p1", "c1", 2, pageSize).Return(page2, 3, nil).Once()
+				store.EXPECT().StoreBackups(ctx, "p1", "c1", page2).Return(nil).Once()
+				fetcher.EXPECT().FetchBackups(ctx, "p1", "c2", 1, pageSize).Return(Backups{}, 0, nil).Once()
+				store.EXPECT().StoreBackups(ctx, "p1", "c2", Backups{}).Return(nil).Once()
+			},
+			expectedProjects: 1,
+			expectedClusters: 2,
+			expectedBackups:  3,
+		},
+		{
+			name:       "Fetcher fails",
+			projectIDs: []string{"p1"},
+			setupMocks: func(fetcher *MockBackupFetcher, store *MockBackupStore) {
+				store.EXPECT().ListBackupTrackedProjects(ctx).Return([]string{"p1"}, nil).Once()
+				store.EXPECT().ListClusterIDs(ctx, "p1").Return([]string{"c1"}, nil).Once()
+				fetcher.EXPECT().FetchBackups(ctx, "p1", "c1", 1, pageSize).Return(nil, 0, errors.New("API error")).Once()
+			},
+			expectedErrMsg: "failed to fetch backups for cluster c1 of project p1",
+		},
+		{
+			name:       "Store fails",
+			projectIDs: []string{"p1"},
+			setupMocks: func(fetcher *MockBackupFetcher, store *MockBackupStore) {
+				store.EXPECT().ListBackupTrackedProjects(ctx).Return([]string{"p1"}, nil).Once()
+				store.EXPECT().ListClusterIDs(ctx, "p1").Return([]string{"c1"}, nil).Once()
+				fetcher.EXPECT().FetchBackups(ctx, "p1", "c1", 1, pageSize).Return(page1, 2, nil).Once()
+				store.EXPECT().StoreBackups(ctx, "p1", "c1", page1).Return(errors.New("DB error")).Once()
+			},
+			expectedErrMsg: "failed to store 2 backups for cluster c1 of project p1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewMockBackupFetcher(t)
+			store := NewMockBackupStore(t)
+			tt.setupMocks(fetcher, store)
+
+			svc := NewBackupService(fetcher, store)
+			projects, clusters, backups, err := svc.FetchAndStoreBackups(ctx, tt.projectIDs, pageSize)
+			if tt.expectedErrMsg != "" {
+				require.ErrorContains(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedProjects, projects)
+			require.Equal(t, tt.expectedClusters, clusters)
+			require.Equal(t, tt.expectedBackups, backups)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// This module provides a tiny key/value storage abstraction for artifacts that msk writes and reads back later,
+// such as route table journals. Two backends are available:
+// - Local directory (e.g. ".msk/journals")
+// - Amazon S3 (e.g. "s3://my-bucket/msk/journals")
+
+// ErrNotFound is returned by Store.Get when the given key does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// Store defines the interface for persisting opaque blobs under a key.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys starting with the given prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Open returns a Store for the given location.
+// A location starting with "s3://" is treated as an S3 bucket (and optional key prefix), anything else as a local directory.
+// The AWS configuration is used only for S3.
+func Open(location string, cfg aws.Config) (Store, error) {
+	if location == "" {
+		return nil, fmt.Errorf("blob store location is not allowed to be empty")
+	}
+
+	if !strings.HasPrefix(location, "s3://") {
+		return NewLocalStore(location), nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 location %q: %w", location, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 location %q: bucket name is missing", location)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// Local stand-ins such as LocalStack do not resolve virtual-hosted-style bucket names
+		o.UsePathStyle = cfg.BaseEndpoint != nil
+	})
+
+	return NewS3Store(client, u.Host, strings.Trim(u.Path, "/")), nil
+}
+
+// LocalStore implements Store on top of a local directory.
+type LocalStore struct {
+	Dir string
+}
+
+// NewLocalStore returns a new LocalStore rooted at the given directory.
+// The directory is created on the first Put if it does not exist.
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{Dir: dir}
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, data []byte) error {
+	p := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", p, err)
+	}
+
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p, err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) ([]byte, error) {
+	p := filepath.Join(s.Dir, filepath.FromSlash(key))
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, p)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+
+	return data, nil
+}
+
+func (s *LocalStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.Dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // Nothing has been written yet
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", s.Dir, err)
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// S3API is the subset of the S3 client used by S3Store.
+type S3API interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3Store implements Store on top of an S3 bucket. All keys are placed under Prefix.
+type S3Store struct {
+	Client S3API
+	Bucket string
+	Prefix string
+}
+
+// NewS3Store returns a new S3Store for the given bucket and key prefix.
+func NewS3Store(client S3API, bucket, prefix string) *S3Store {
+	return &S3Store{
+		Client: client,
+		Bucket: bucket,
+		Prefix: prefix,
+	}
+}
+
+func (s *S3Store) objectKey(key string) string {
+	if s.Prefix == "" {
+		return key
+	}
+	return path.Join(s.Prefix, key)
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3#Client.PutObject
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put s3://%s/%s: %w", s.Bucket, s.objectKey(key), err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3#Client.GetObject
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		var nsk *s3types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, fmt.Errorf("%w: s3://%s/%s", ErrNotFound, s.Bucket, s.objectKey(key))
+		}
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.Bucket, s.objectKey(key), err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", s.Bucket, s.objectKey(key), err)
+	}
+
+	return data, nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	param := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.objectKey(prefix)),
+	}
+
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3#NewListObjectsV2Paginator
+	paginator := s3.NewListObjectsV2Paginator(s.Client, param)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.Bucket, s.objectKey(prefix), err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if s.Prefix != "" {
+				key = strings.TrimPrefix(key, s.Prefix+"/")
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
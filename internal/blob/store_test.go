@@ -0,0 +1,59 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutGetList(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "a/2.json", []byte("two")))
+	require.NoError(t, store.Put(ctx, "a/1.json", []byte("one")))
+	require.NoError(t, store.Put(ctx, "b/3.json", []byte("three")))
+
+	data, err := store.Get(ctx, "a/1.json")
+	require.NoError(t, err)
+	require.Equal(t, "one", string(data))
+
+	keys, err := store.List(ctx, "a/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/1.json", "a/2.json"}, keys)
+}
+
+func TestLocalStore_GetNotFound(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	_, err := store.Get(context.Background(), "missing.json")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestLocalStore_ListMissingDir(t *testing.T) {
+	store := NewLocalStore(t.TempDir() + "/not-yet-created")
+
+	keys, err := store.List(context.Background(), "")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestOpen_InvalidLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+	}{
+		{"empty", ""},
+		{"noBucket", "s3:///prefix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(tt.location, aws.Config{})
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package changes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+)
+
+// This module reports what changed in the inventory over a period. fetch-projects and fetch-clusters record
+// a snapshot of the inventory after every sync, and the snapshot of the sync at the start of the period is
+// compared with the latest one. A cluster missing from the latest snapshot is reported as deleted, i.e. it was
+// marked as deleted by fetch-clusters (MarkStaleClustersAsDeleted) or by cluster delete.
+
+// Kinds of the syncs recorded in sync_runs.
+const (
+	SyncProjects = "projects"
+	SyncClusters = "clusters"
+)
+
+// Kinds of the changes, in the order of the report.
+const (
+	KindProjectAdded    = "project_added"
+	KindProjectRemoved  = "project_removed"
+	KindClusterAdded    = "cluster_added"
+	KindClusterDeleted  = "cluster_deleted"
+	KindStatusChanged   = "status_changed"
+	KindVersionChanged  = "version_changed"
+	KindTopologyChanged = "topology_changed"
+)
+
+var Kinds = []string{
+	KindProjectAdded,
+	KindProjectRemoved,
+	KindClusterAdded,
+	KindClusterDeleted,
+	KindStatusChanged,
+	KindVersionChanged,
+	KindTopologyChanged,
+}
+
+// Project is a project in a snapshot.
+type Project struct {
+	ID   string
+	Name string
+}
+
+// Cluster is a cluster in a snapshot.
+type Cluster struct {
+	ID          string
+	ProjectID   string
+	Name        string
+	Status      string
+	TiDBVersion string
+	Topology    string // e.g. "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)", empty if the cluster has no nodes
+}
+
+// Snapshot is the inventory after a sync. Only the projects or the clusters are set, depending on the kind of the sync.
+type Snapshot struct {
+	SyncedAt time.Time
+	Projects []Project
+	Clusters []Cluster
+}
+
+// Period is the pair of the syncs compared.
+type Period struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Change is a change of a project or a cluster. From and To are the values before and after the change,
+// e.g. the statuses for status_changed, and the topology of the cluster for cluster_added and cluster_deleted.
+type Change struct {
+	Kind        string `json:"kind"`
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	ClusterID   string `json:"cluster_id,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+}
+
+// Report holds the changes since a time.
+type Report struct {
+	Since    time.Time `json:"since"`
+	Projects *Period   `json:"projects,omitempty"` // nil if no sync of projects is recorded
+	Clusters *Period   `json:"clusters,omitempty"` // nil if no sync of clusters is recorded
+	Changes  []Change  `json:"changes"`
+}
+
+// Store defines an interface for reading the recorded snapshots.
+type Store interface {
+	// GetSnapshot returns the snapshot of the kind at the time, i.e. of the latest sync finished at or before it,
+	// or of the earliest sync after it if there is none. It returns nil if no sync of the kind is recorded.
+	GetSnapshot(ctx context.Context, kind string, at time.Time) (*Snapshot, error)
+}
+
+// Build returns the changes between the snapshots at since and now.
+// If the history starts after since, the changes are counted from the first recorded sync.
+func Build(ctx context.Context, store Store, since, now time.Time) (*Report, error) {
+	report := &Report{Since: since, Changes: []Change{}}
+
+	var names map[string]string
+	from, to, err := getSnapshots(ctx, store, SyncProjects, since, now)
+	if err != nil {
+		return nil, err
+	}
+	if to != nil {
+		report.Projects = &Period{From: from.SyncedAt, To: to.SyncedAt}
+		report.Changes = append(report.Changes, DiffProjects(from.Projects, to.Projects)...)
+		names = projectNames(from.Projects, to.Projects)
+	}
+
+	if from, to, err = getSnapshots(ctx, store, SyncClusters, since, now); err != nil {
+		return nil, err
+	}
+	if to != nil {
+		report.Clusters = &Period{From: from.SyncedAt, To: to.SyncedAt}
+		report.Changes = append(report.Changes, DiffClusters(from.Clusters, to.Clusters, names)...)
+	}
+
+	if report.Projects == nil && report.Clusters == nil {
+		return nil, errors.New("no sync is recorded, run fetch-projects and fetch-clusters first")
+	}
+	sortChanges(report.Changes)
+
+	return report, nil
+}
+
+func getSnapshots(ctx context.Context, store Store, kind string, since, now time.Time) (*Snapshot, *Snapshot, error) {
+	from, err := store.GetSnapshot(ctx, kind, since)
+	if err != nil || from == nil {
+		return nil, nil, err
+	}
+	to, err := store.GetSnapshot(ctx, kind, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return from, to, nil
+}
+
+// projectNames returns the project names by ID, preferring the latest ones.
+func projectNames(from, to []Project) map[string]string {
+	names := make(map[string]string, len(to))
+	for _, projects := range [][]Project{from, to} {
+		for _, p := range projects {
+			names[p.ID] = p.Name
+		}
+	}
+
+	return names
+}
+
+// DiffProjects returns the projects added and removed between the snapshots.
+func DiffProjects(from, to []Project) []Change {
+	before := make(map[string]Project, len(from))
+	for _, p := range from {
+		before[p.ID] = p
+	}
+	after := make(map[string]Project, len(to))
+	for _, p := range to {
+		after[p.ID] = p
+	}
+
+	var changes []Change
+	for _, p := range to {
+		if _, ok := before[p.ID]; !ok {
+			changes = append(changes, Change{Kind: KindProjectAdded, ProjectID: p.ID, ProjectName: p.Name})
+		}
+	}
+	for _, p := range from {
+		if _, ok := after[p.ID]; !ok {
+			changes = append(changes, Change{Kind: KindProjectRemoved, ProjectID: p.ID, ProjectName: p.Name})
+		}
+	}
+
+	return changes
+}
+
+// DiffClusters returns the clusters added and deleted between the snapshots, and the changes of the status,
+// the TiDB version and the topology of the others. The project names are looked up by ID.
+func DiffClusters(from, to []Cluster, projectNames map[string]string) []Change {
+	before := make(map[string]Cluster, len(from))
+	for _, c := range from {
+		before[c.ID] = c
+	}
+	after := make(map[string]Cluster, len(to))
+	for _, c := range to {
+		after[c.ID] = c
+	}
+
+	var changes []Change
+	change := func(kind string, c Cluster, from, to string) {
+		changes = append(changes, Change{
+			Kind:        kind,
+			ProjectID:   c.ProjectID,
+			ProjectName: projectNames[c.ProjectID],
+			ClusterID:   c.ID,
+			ClusterName: c.Name,
+			From:        from,
+			To:          to,
+		})
+	}
+	for _, c := range to {
+		prev, ok := before[c.ID]
+		if !ok {
+			change(KindClusterAdded, c, "", c.Topology)
+			continue
+		}
+		if prev.Status != c.Status {
+			change(KindStatusChanged, c, prev.Status, c.Status)
+		}
+		if prev.TiDBVersion != c.TiDBVersion {
+			change(KindVersionChanged, c, prev.TiDBVersion, c.TiDBVersion)
+		}
+		if prev.Topology != c.Topology {
+			change(KindTopologyChanged, c, prev.Topology, c.Topology)
+		}
+	}
+	for _, c := range from {
+		if _, ok := after[c.ID]; !ok {
+			change(KindClusterDeleted, c, c.Topology, "")
+		}
+	}
+
+	return changes
+}
+
+// sortChanges sorts the changes of projects first, then the ones of clusters by project, cluster and kind.
+func sortChanges(changes []Change) {
+	order := make(map[string]int, len(Kinds))
+	for i, kind := range Kinds {
+		order[kind] = i
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if (a.ClusterID == "") != (b.ClusterID == "") {
+			return a.ClusterID == ""
+		}
+		if a.ProjectName != b.ProjectName {
+			return a.ProjectName < b.ProjectName
+		}
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.ClusterName != b.ClusterName {
+			return a.ClusterName < b.ClusterName
+		}
+		if a.ClusterID != b.ClusterID {
+			return a.ClusterID < b.ClusterID
+		}
+		return order[a.Kind] < order[b.Kind]
+	})
+}
+
+// DBStore records the snapshots in the sync_runs table and implements Store on top of it.
+type DBStore struct {
+	conn    *sql.DB
+	Queries *db.Queries
+}
+
+// NewDBStore initializes a new DBStore using the given DSN and optional connection pool settings.
+// Returns an error if the connection fails or cannot be verified.
+func NewDBStore(dsn string, poolConfig *db.PoolConfig) (*DBStore, error) {
+	conn, err := db.Open(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBStore{
+		Queries: db.New(conn),
+		conn:    conn,
+	}, nil
+}
+
+// RecordProjects records the projects listed by a sync of fetch-projects started at startedAt.
+func (s *DBStore) RecordProjects(ctx context.Context, startedAt time.Time, projects []Project) error {
+	return s.record(ctx, SyncProjects, startedAt, func(q *db.Queries, runID int64) error {
+		for _, p := range projects {
+			if err := q.InsertProjectSnapshot(ctx, db.InsertProjectSnapshotParams{SyncRunID: runID, ProjectID: p.ID, Name: p.Name}); err != nil {
+				return fmt.Errorf("failed to record project %s: %w", p.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// RecordClusters records the stored clusters not marked as deleted after a sync of fetch-clusters started at startedAt.
+func (s *DBStore) RecordClusters(ctx context.Context, startedAt time.Time) error {
+	rows, err := s.Queries.ListClusters(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+	nodeMaps, err := (&clusters.DBClusterStore{Queries: s.Queries}).ListNodeMaps(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.record(ctx, SyncClusters, startedAt, func(q *db.Queries, runID int64) error {
+		for _, row := range rows {
+			if row.IsDeleted {
+				continue
+			}
+			if err := q.InsertClusterSnapshot(ctx, db.InsertClusterSnapshotParams{
+				SyncRunID:     runID,
+				ClusterID:     row.ID,
+				ProjectID:     row.ProjectID,
+				Name:          row.Name,
+				ClusterStatus: row.ClusterStatus,
+				TidbVersion:   row.TidbVersion,
+				Topology:      clusters.ComponentsFromNodeMap(nodeMaps[row.ID]).String(),
+			}); err != nil {
+				return fmt.Errorf("failed to record cluster %s: %w", row.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// record inserts a sync run finished now and its snapshot in a transaction.
+func (s *DBStore) record(ctx context.Context, kind string, startedAt time.Time, insert func(q *db.Queries, runID int64) error) (err error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to rollback transaction after error: %w", rbErr))
+			}
+		}
+	}()
+
+	qtx := s.Queries.WithTx(tx)
+	runID, err := qtx.InsertSyncRun(ctx, db.InsertSyncRunParams{Kind: kind, StartedAt: startedAt, FinishedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to record sync of %s: %w", kind, err)
+	}
+	if err := insert(qtx, runID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the syncs finished before the time with their snapshots, and returns the number of deleted syncs.
+func (s *DBStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	n, err := s.Queries.DeleteSyncRunsBefore(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sync history: %w", err)
+	}
+	return n, nil
+}
+
+func (s *DBStore) GetSnapshot(ctx context.Context, kind string, at time.Time) (*Snapshot, error) {
+	run, err := s.Queries.GetLatestSyncRunBefore(ctx, db.GetLatestSyncRunBeforeParams{Kind: kind, Before: at})
+	if errors.Is(err, sql.ErrNoRows) {
+		run, err = s.Queries.GetEarliestSyncRunAfter(ctx, db.GetEarliestSyncRunAfterParams{Kind: kind, After: at})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get sync of %s at %s: %w", kind, at.Format(time.RFC3339), err)
+	}
+
+	snapshot := &Snapshot{SyncedAt: run.FinishedAt}
+	switch kind {
+	case SyncProjects:
+		rows, err := s.Queries.ListProjectSnapshots(ctx, run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects of sync %d: %w", run.ID, err)
+		}
+		for _, row := range rows {
+			snapshot.Projects = append(snapshot.Projects, Project{ID: row.ProjectID, Name: row.Name})
+		}
+	case SyncClusters:
+		rows, err := s.Queries.ListClusterSnapshots(ctx, run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clusters of sync %d: %w", run.ID, err)
+		}
+		for _, row := range rows {
+			snapshot.Clusters = append(snapshot.Clusters, Cluster{
+				ID:          row.ClusterID,
+				ProjectID:   row.ProjectID,
+				Name:        row.Name,
+				Status:      row.ClusterStatus,
+				TiDBVersion: row.TidbVersion,
+				Topology:    row.Topology,
+			})
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Close closes the underlying database connection held by the DBStore.
+func (s *DBStore) Close() error {
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
+	}
+	return nil
+}
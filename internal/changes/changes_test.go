@@ -0,0 +1,134 @@
+package changes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeStore returns the snapshots by kind, sorted by SyncedAt.
+type fakeStore struct {
+	snapshots map[string][]*Snapshot
+}
+
+func (f *fakeStore) GetSnapshot(ctx context.Context, kind string, at time.Time) (*Snapshot, error) {
+	snapshots := f.snapshots[kind]
+	var found *Snapshot
+	for _, s := range snapshots {
+		if !s.SyncedAt.After(at) {
+			found = s
+		}
+	}
+	if found == nil && len(snapshots) > 0 {
+		found = snapshots[0]
+	}
+	return found, nil
+}
+
+func testStore() *fakeStore {
+	return &fakeStore{snapshots: map[string][]*Snapshot{
+		SyncProjects: {
+			{SyncedAt: testNow.Add(-48 * time.Hour), Projects: []Project{{ID: "p1", Name: "payments"}, {ID: "p9", Name: "legacy"}}},
+			{SyncedAt: testNow.Add(-25 * time.Hour), Projects: []Project{{ID: "p1", Name: "payments"}, {ID: "p2", Name: "analytics"}}},
+			{SyncedAt: testNow.Add(-time.Hour), Projects: []Project{{ID: "p1", Name: "payments"}, {ID: "p2", Name: "analytics"}, {ID: "p3", Name: "search"}}},
+		},
+		SyncClusters: {
+			{SyncedAt: testNow.Add(-26 * time.Hour), Clusters: []Cluster{
+				{ID: "c1", ProjectID: "p1", Name: "pay-main", Status: "AVAILABLE", TiDBVersion: "v7.5.1", Topology: "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)"},
+				{ID: "c2", ProjectID: "p1", Name: "pay-dev", Status: "AVAILABLE", TiDBVersion: "v7.5.1", Topology: "TiDB 1 x 4C16G, TiKV 3 x 4C16G (200 GiB)"},
+				{ID: "c3", ProjectID: "p2", Name: "bi", Status: "PAUSED", TiDBVersion: "v8.1.0"},
+			}},
+			{SyncedAt: testNow.Add(-30 * time.Minute), Clusters: []Cluster{
+				{ID: "c1", ProjectID: "p1", Name: "pay-main", Status: "AVAILABLE", TiDBVersion: "v8.1.0", Topology: "TiDB 2 x 8C16G, TiKV 4 x 8C32G (500 GiB)"},
+				{ID: "c3", ProjectID: "p2", Name: "bi", Status: "AVAILABLE", TiDBVersion: "v8.1.0"},
+				{ID: "c4", ProjectID: "p3", Name: "search-main", Status: "CREATING", TiDBVersion: "v8.1.0", Topology: "TiDB 1 x 8C16G"},
+			}},
+		},
+	}}
+}
+
+func TestBuild(t *testing.T) {
+	r, err := Build(context.Background(), testStore(), testNow.Add(-24*time.Hour), testNow)
+	require.NoError(t, err)
+
+	require.Equal(t, &Period{From: testNow.Add(-25 * time.Hour), To: testNow.Add(-time.Hour)}, r.Projects)
+	require.Equal(t, &Period{From: testNow.Add(-26 * time.Hour), To: testNow.Add(-30 * time.Minute)}, r.Clusters)
+	require.Equal(t, []Change{
+		{Kind: KindProjectAdded, ProjectID: "p3", ProjectName: "search"},
+		{Kind: KindStatusChanged, ProjectID: "p2", ProjectName: "analytics", ClusterID: "c3", ClusterName: "bi", From: "PAUSED", To: "AVAILABLE"},
+		{Kind: KindClusterDeleted, ProjectID: "p1", ProjectName: "payments", ClusterID: "c2", ClusterName: "pay-dev", From: "TiDB 1 x 4C16G, TiKV 3 x 4C16G (200 GiB)"},
+		{Kind: KindVersionChanged, ProjectID: "p1", ProjectName: "payments", ClusterID: "c1", ClusterName: "pay-main", From: "v7.5.1", To: "v8.1.0"},
+		{Kind: KindTopologyChanged, ProjectID: "p1", ProjectName: "payments", ClusterID: "c1", ClusterName: "pay-main",
+			From: "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)", To: "TiDB 2 x 8C16G, TiKV 4 x 8C32G (500 GiB)"},
+		{Kind: KindClusterAdded, ProjectID: "p3", ProjectName: "search", ClusterID: "c4", ClusterName: "search-main", To: "TiDB 1 x 8C16G"},
+	}, r.Changes)
+}
+
+func TestBuild_History(t *testing.T) {
+	ctx := context.Background()
+
+	// The history starts after since, so the changes are counted from the first sync
+	r, err := Build(ctx, testStore(), testNow.Add(-72*time.Hour), testNow)
+	require.NoError(t, err)
+	require.Equal(t, testNow.Add(-48*time.Hour), r.Projects.From)
+	require.Contains(t, r.Changes, Change{Kind: KindProjectRemoved, ProjectID: "p9", ProjectName: "legacy"})
+
+	// No sync after since
+	r, err = Build(ctx, testStore(), testNow.Add(-10*time.Minute), testNow)
+	require.NoError(t, err)
+	require.Empty(t, r.Changes)
+
+	store := testStore()
+	delete(store.snapshots, SyncProjects)
+	r, err = Build(ctx, store, testNow.Add(-24*time.Hour), testNow)
+	require.NoError(t, err)
+	require.Nil(t, r.Projects)
+	require.Equal(t, "c2", r.Changes[0].ClusterID)
+	require.Empty(t, r.Changes[0].ProjectName)
+
+	_, err = Build(ctx, &fakeStore{}, testNow.Add(-24*time.Hour), testNow)
+	require.ErrorContains(t, err, "no sync is recorded")
+}
+
+func TestPrintReport(t *testing.T) {
+	r, err := Build(context.Background(), testStore(), testNow.Add(-24*time.Hour), testNow)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintReport(&buf, r))
+	require.Contains(t, buf.String(), "Changes since 2025-05-31T12:00:00Z\n")
+	require.Contains(t, buf.String(), "Compared the syncs of clusters at 2025-05-31T10:00:00Z and 2025-06-01T11:30:00Z\n")
+	require.Contains(t, buf.String(), "version_changed   p1          payments      c1          pay-main      v7.5.1")
+
+	buf.Reset()
+	require.NoError(t, PrintReport(&buf, &Report{Since: testNow}))
+	require.Equal(t, "Changes since 2025-06-01T12:00:00Z\nNo sync of projects is recorded\nNo sync of clusters is recorded\nNo changes\n", buf.String())
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r, err := Build(context.Background(), testStore(), testNow.Add(-24*time.Hour), testNow)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	require.Contains(t, buf.String(), "## Inventory changes since 2025-05-31 12:00 UTC\n\n- Compared the syncs of projects at 2025-05-31 11:00 UTC and 2025-06-01 11:00 UTC\n")
+	require.Contains(t, buf.String(), "| New project | search (p3) |  |  |  |\n")
+	require.Contains(t, buf.String(), "| Status | analytics (p2) | bi (c3) | PAUSED | AVAILABLE |\n")
+
+	r.Changes = []Change{{Kind: KindClusterAdded, ProjectID: "p1", ClusterID: "c5", ClusterName: "a|b"}}
+	buf.Reset()
+	require.NoError(t, WriteMarkdown(&buf, r))
+	require.Contains(t, buf.String(), `| New cluster | p1 | a\|b (c5) |  |  |`)
+}
+
+func TestReport_JSON(t *testing.T) {
+	data, err := json.Marshal(&Report{Since: testNow, Changes: []Change{{Kind: KindProjectAdded, ProjectID: "p3", ProjectName: "search"}}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"since":"2025-06-01T12:00:00Z","changes":[{"kind":"project_added","project_id":"p3","project_name":"search"}]}`, string(data))
+}
@@ -0,0 +1,90 @@
+package changes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats of report changes.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+var Formats = []string{FormatText, FormatJSON, FormatMarkdown}
+
+var kindLabels = map[string]string{
+	KindProjectAdded:    "New project",
+	KindProjectRemoved:  "Removed project",
+	KindClusterAdded:    "New cluster",
+	KindClusterDeleted:  "Deleted cluster",
+	KindStatusChanged:   "Status",
+	KindVersionChanged:  "TiDB version",
+	KindTopologyChanged: "Topology",
+}
+
+// PrintReport writes the report as a table.
+func PrintReport(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "Changes since %s\n", r.Since.UTC().Format(time.RFC3339))
+	printPeriod(w, "", SyncProjects, r.Projects, time.RFC3339)
+	printPeriod(w, "", SyncClusters, r.Clusters, time.RFC3339)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tPROJECT ID\tPROJECT NAME\tCLUSTER ID\tCLUSTER NAME\tFROM\tTO")
+	for _, c := range r.Changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Kind, c.ProjectID, c.ProjectName, c.ClusterID, c.ClusterName, c.From, c.To)
+	}
+
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the report as a Markdown section, to be included in a notice.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	const layout = "2006-01-02 15:04 UTC"
+
+	fmt.Fprintf(w, "## Inventory changes since %s\n\n", r.Since.UTC().Format(layout))
+	printPeriod(w, "- ", SyncProjects, r.Projects, layout)
+	printPeriod(w, "- ", SyncClusters, r.Clusters, layout)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "\nNo changes.")
+		return nil
+	}
+
+	fmt.Fprintln(w, "\n| Change | Project | Cluster | From | To |")
+	fmt.Fprintln(w, "|---|---|---|---|---|")
+	for _, c := range r.Changes {
+		project := c.ProjectID
+		if c.ProjectName != "" {
+			project = fmt.Sprintf("%s (%s)", c.ProjectName, c.ProjectID)
+		}
+		cluster := ""
+		if c.ClusterID != "" {
+			cluster = fmt.Sprintf("%s (%s)", c.ClusterName, c.ClusterID)
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", kindLabels[c.Kind], escapeMarkdown(project), escapeMarkdown(cluster), escapeMarkdown(c.From), escapeMarkdown(c.To))
+	}
+
+	return nil
+}
+
+// printPeriod writes the line of the syncs compared for the kind, or that none is recorded.
+func printPeriod(w io.Writer, prefix, kind string, p *Period, layout string) {
+	if p == nil {
+		fmt.Fprintf(w, "%sNo sync of %s is recorded\n", prefix, kind)
+		return
+	}
+	fmt.Fprintf(w, "%sCompared the syncs of %s at %s and %s\n", prefix, kind, p.From.UTC().Format(layout), p.To.UTC().Format(layout))
+}
+
+// escapeMarkdown escapes the pipes, which would split the cell of a table.
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
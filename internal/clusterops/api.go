@@ -0,0 +1,215 @@
+package clusterops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// This module changes TiDB Cloud Dedicated clusters through the TiDB Cloud API and keeps the clusters table
+// up to date with the result, so that the other commands do not have to wait for the next fetch-clusters.
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Cluster
+
+// ProviderRegion is the available specification of a cluster type in a region of a cloud provider.
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Cluster/operation/ListProviderRegions
+type ProviderRegion struct {
+	ClusterType   string          `json:"cluster_type,omitempty"`
+	CloudProvider string          `json:"cloud_provider,omitempty"`
+	Region        string          `json:"region,omitempty"`
+	TiDB          []NodeSpecRange `json:"tidb,omitempty"`
+	TiKV          []NodeSpecRange `json:"tikv,omitempty"`
+	TiFlash       []NodeSpecRange `json:"tiflash,omitempty"`
+}
+
+// NodeSpecRange is an available node size of a component, with the allowed node quantity and storage size.
+type NodeSpecRange struct {
+	NodeSize            string       `json:"node_size,omitempty"`
+	NodeQuantityRange   QuantityStep `json:"node_quantity_range"`
+	StorageSizeGibRange *MinMax      `json:"storage_size_gib_range,omitempty"` // Only for TiKV and TiFlash
+}
+
+// QuantityStep is the allowed node quantity, i.e. min, min+step, min+2*step, ...
+type QuantityStep struct {
+	Min  int `json:"min,omitempty"`
+	Step int `json:"step,omitempty"`
+}
+
+// MinMax is an allowed range, inclusive.
+type MinMax struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// ListProviderRegionsResponse is the response of the ListProviderRegions API.
+type ListProviderRegionsResponse struct {
+	Items []ProviderRegion `json:"items,omitempty"`
+}
+
+// CreateClusterRequest is the request body of the CreateCluster API.
+type CreateClusterRequest struct {
+	Name          string        `json:"name"`
+	ClusterType   string        `json:"cluster_type"`
+	CloudProvider string        `json:"cloud_provider"`
+	Region        string        `json:"region"`
+	Config        ClusterConfig `json:"config"`
+}
+
+// ClusterConfig is the configuration of the created cluster.
+type ClusterConfig struct {
+	RootPassword string              `json:"root_password"`
+	Port         int                 `json:"port"`
+	Components   clusters.Components `json:"components"`
+	IPAccessList []IPAccess          `json:"ip_access_list,omitempty"`
+}
+
+// IPAccess is an entry of the IP access list of a cluster.
+type IPAccess struct {
+	CIDR        string `json:"cidr"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateClusterResponse is the response of the CreateCluster API.
+type CreateClusterResponse struct {
+	ID string `json:"id,omitempty"`
+}
+
+// UpdateClusterRequest is the request body of the UpdateCluster API.
+type UpdateClusterRequest struct {
+	Config UpdateClusterConfig `json:"config"`
+}
+
+// UpdateClusterConfig is the changed configuration of the cluster. Omitted components are not changed.
+type UpdateClusterConfig struct {
+	Components clusters.Components `json:"components"`
+}
+
+// CreateBackupRequest is the request body of the CreateBackup API.
+type CreateBackupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateBackupResponse is the response of the CreateBackup API.
+type CreateBackupResponse struct {
+	ID string `json:"id,omitempty"`
+}
+
+// ClusterAPI defines the TiDB Cloud operations needed to change clusters and follow their progress.
+type ClusterAPI interface {
+	ListProviderRegions(ctx context.Context) ([]ProviderRegion, error)
+	CreateCluster(ctx context.Context, projectID string, req CreateClusterRequest) (string, error)
+	GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error)
+	UpdateCluster(ctx context.Context, projectID, clusterID string, req UpdateClusterRequest) error
+	DeleteCluster(ctx context.Context, projectID, clusterID string) error
+	CreateBackup(ctx context.Context, projectID, clusterID string, req CreateBackupRequest) (string, error)
+	GetBackup(ctx context.Context, projectID, clusterID, backupID string) (*backups.Backup, error)
+}
+
+// APIClusterClient implements ClusterAPI using the TiDB Cloud API.
+type APIClusterClient struct {
+	Client       *http.Client
+	EndpointBase string
+}
+
+// NewAPIClusterClient returns a new APIClusterClient with the given HTTP client (expected to handle digest auth).
+func NewAPIClusterClient(client *http.Client, endpointBase string) *APIClusterClient {
+	if endpointBase == "" {
+		endpointBase = util.TiDBCloudAPIEndpointBase
+	}
+
+	return &APIClusterClient{
+		Client:       client,
+		EndpointBase: endpointBase,
+	}
+}
+
+func (c *APIClusterClient) ListProviderRegions(ctx context.Context) ([]ProviderRegion, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "clusters", "provider", "regions")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListProviderRegionsResponse
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list provider regions: %w", err)
+	}
+
+	return resp.Items, nil
+}
+
+func (c *APIClusterClient) CreateCluster(ctx context.Context, projectID string, req CreateClusterRequest) (string, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "clusters")
+	if err != nil {
+		return "", err
+	}
+
+	var resp CreateClusterResponse
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodPost, endpoint, &req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create cluster %s in project %s: %w", req.Name, projectID, err)
+	}
+
+	return resp.ID, nil
+}
+
+func (c *APIClusterClient) GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error) {
+	return clusters.NewAPIClusterFetcher(c.Client, c.EndpointBase).FetchCluster(ctx, projectID, clusterID)
+}
+
+func (c *APIClusterClient) UpdateCluster(ctx context.Context, projectID, clusterID string, req UpdateClusterRequest) error {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "clusters", clusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodPatch, endpoint, &req, nil); err != nil {
+		return fmt.Errorf("failed to update cluster %s in project %s: %w", clusterID, projectID, err)
+	}
+
+	return nil
+}
+
+func (c *APIClusterClient) DeleteCluster(ctx context.Context, projectID, clusterID string) error {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "clusters", clusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodDelete, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete cluster %s in project %s: %w", clusterID, projectID, err)
+	}
+
+	return nil
+}
+
+func (c *APIClusterClient) CreateBackup(ctx context.Context, projectID, clusterID string, req CreateBackupRequest) (string, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "clusters", clusterID, "backups")
+	if err != nil {
+		return "", err
+	}
+
+	var resp CreateBackupResponse
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodPost, endpoint, &req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create backup of cluster %s in project %s: %w", clusterID, projectID, err)
+	}
+
+	return resp.ID, nil
+}
+
+func (c *APIClusterClient) GetBackup(ctx context.Context, projectID, clusterID, backupID string) (*backups.Backup, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "clusters", clusterID, "backups", backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup backups.Backup
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint, nil, &backup); err != nil {
+		return nil, fmt.Errorf("failed to get backup %s of cluster %s: %w", backupID, clusterID, err)
+	}
+
+	return &backup, nil
+}
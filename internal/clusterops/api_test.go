@@ -0,0 +1,145 @@
+package clusterops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClusterClient_CreateCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/projects/p1/clusters", r.URL.Path)
+
+		var in CreateClusterRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		require.Equal(t, "team-a-main", in.Name)
+		require.Equal(t, "secret", in.Config.RootPassword)
+		require.Equal(t, 3, in.Config.Components.TiKV.NodeQuantity)
+
+		_ = json.NewEncoder(w).Encode(CreateClusterResponse{ID: "c1"})
+	}))
+	defer server.Close()
+
+	id, err := NewAPIClusterClient(server.Client(), server.URL).CreateCluster(context.Background(), "p1", CreateClusterRequest{
+		Name:          "team-a-main",
+		ClusterType:   "DEDICATED",
+		CloudProvider: "AWS",
+		Region:        "us-west-2",
+		Config: ClusterConfig{
+			RootPassword: "secret",
+			Port:         4000,
+			Components: clusters.Components{
+				TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+				TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "c1", id)
+}
+
+func TestAPIClusterClient_ListProviderRegions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/clusters/provider/regions", r.URL.Path)
+		_, _ = w.Write([]byte(`{"items":[{"cluster_type":"DEDICATED","cloud_provider":"AWS","region":"us-west-2",
+			"tidb":[{"node_size":"8C16G","node_quantity_range":{"min":1,"step":1}}],
+			"tikv":[{"node_size":"8C32G","node_quantity_range":{"min":3,"step":3},"storage_size_gib_range":{"min":200,"max":4096}}]}]}`))
+	}))
+	defer server.Close()
+
+	regions, err := NewAPIClusterClient(server.Client(), server.URL).ListProviderRegions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Equal(t, QuantityStep{Min: 3, Step: 3}, regions[0].TiKV[0].NodeQuantityRange)
+	require.Equal(t, &MinMax{Min: 200, Max: 4096}, regions[0].TiKV[0].StorageSizeGibRange)
+}
+
+func TestAPIClusterClient_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "unauthorized"},
+		{name: "rate limit", status: http.StatusTooManyRequests, wantErr: "rate limit"},
+		{name: "api error", status: http.StatusBadRequest, body: `{"code":3,"message":"invalid node quantity"}`, wantErr: "invalid node quantity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewAPIClusterClient(server.Client(), server.URL).CreateCluster(context.Background(), "p1", CreateClusterRequest{Name: "a"})
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAPIClusterClient_UpdateCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "/projects/p1/clusters/c1", r.URL.Path)
+
+		var in map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		require.Equal(t, map[string]any{"config": map[string]any{"components": map[string]any{
+			"tikv": map[string]any{"node_size": "8C32G", "storage_size_gib": float64(500), "node_quantity": float64(6)},
+		}}}, in)
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	err := NewAPIClusterClient(server.Client(), server.URL).UpdateCluster(context.Background(), "p1", "c1", UpdateClusterRequest{
+		Config: UpdateClusterConfig{Components: clusters.Components{TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 6}}},
+	})
+	require.NoError(t, err)
+}
+
+func TestAPIClusterClient_Backup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/p1/clusters/c1/backups":
+			var in CreateBackupRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+			require.Equal(t, "final-team-a-old", in.Name)
+			_, _ = w.Write([]byte(`{"id":"b1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/p1/clusters/c1/backups/b1":
+			_, _ = w.Write([]byte(`{"id":"b1","type":"MANUAL","status":"RUNNING"}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClusterClient(server.Client(), server.URL)
+	id, err := client.CreateBackup(context.Background(), "p1", "c1", CreateBackupRequest{Name: "final-team-a-old"})
+	require.NoError(t, err)
+	require.Equal(t, "b1", id)
+
+	backup, err := client.GetBackup(context.Background(), "p1", "c1", "b1")
+	require.NoError(t, err)
+	require.Equal(t, "RUNNING", backup.Status)
+}
+
+func TestAPIClusterClient_DeleteCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "/projects/p1/clusters/c1", r.URL.Path)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewAPIClusterClient(server.Client(), server.URL).DeleteCluster(context.Background(), "p1", "c1"))
+}
@@ -0,0 +1,189 @@
+package clusterops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/clusterspec"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// ClusterStore defines an interface for recording the changed clusters. clusters.DBClusterStore implements it.
+type ClusterStore interface {
+	StoreClusters(ctx context.Context, clusters clusters.Clusters) error
+}
+
+// WaitOptions controls how long to wait for a cluster to become AVAILABLE after a change.
+type WaitOptions struct {
+	PollInterval time.Duration
+	WaitTimeout  time.Duration
+}
+
+// NewComponents converts the declared components into the node configuration of the API.
+func NewComponents(spec clusterspec.Components) clusters.Components {
+	convert := func(c *clusterspec.ComponentSpec) *clusters.ComponentConfig {
+		if c == nil {
+			return nil
+		}
+		return &clusters.ComponentConfig{NodeSize: c.NodeSize, StorageSizeGib: c.StorageSizeGib, NodeQuantity: c.NodeQuantity}
+	}
+
+	return clusters.Components{
+		TiDB:    convert(spec.TiDB),
+		TiKV:    convert(spec.TiKV),
+		TiFlash: convert(spec.TiFlash),
+	}
+}
+
+type namedComponent struct {
+	name   string // tidb, tikv or tiflash
+	label  string // TiDB, TiKV or TiFlash
+	config *clusters.ComponentConfig
+}
+
+// listComponents returns the components in the order of TiDB, TiKV and TiFlash.
+func listComponents(c clusters.Components) []namedComponent {
+	return []namedComponent{
+		{clusters.ComponentTiDB, "TiDB", c.TiDB},
+		{clusters.ComponentTiKV, "TiKV", c.TiKV},
+		{clusters.ComponentTiFlash, "TiFlash", c.TiFlash},
+	}
+}
+
+// ValidateComponents checks the components against the node sizes, node quantities and storage sizes
+// available for the cluster type in the region of the cloud provider.
+func ValidateComponents(regions []ProviderRegion, clusterType, cloudProvider, region string, components clusters.Components) error {
+	idx := slices.IndexFunc(regions, func(r ProviderRegion) bool {
+		return strings.EqualFold(r.ClusterType, clusterType) && strings.EqualFold(r.CloudProvider, cloudProvider) && r.Region == region
+	})
+	if idx < 0 {
+		return fmt.Errorf("%s clusters are not available in %s %s", clusterType, cloudProvider, region)
+	}
+	available := regions[idx]
+
+	specs := map[string][]NodeSpecRange{
+		clusters.ComponentTiDB:    available.TiDB,
+		clusters.ComponentTiKV:    available.TiKV,
+		clusters.ComponentTiFlash: available.TiFlash,
+	}
+	for _, component := range listComponents(components) {
+		// No nodes, e.g. TiFlash being removed, is not subject to the constraints
+		if component.config == nil || component.config.NodeQuantity == 0 {
+			continue
+		}
+		if err := validateComponent(component.name, *component.config, specs[component.name]); err != nil {
+			return fmt.Errorf("%w in %s %s", err, cloudProvider, region)
+		}
+	}
+
+	return nil
+}
+
+func validateComponent(name string, config clusters.ComponentConfig, specs []NodeSpecRange) error {
+	if len(specs) == 0 {
+		return fmt.Errorf("%s is not available", name)
+	}
+
+	idx := slices.IndexFunc(specs, func(s NodeSpecRange) bool { return s.NodeSize == config.NodeSize })
+	if idx < 0 {
+		sizes := make([]string, 0, len(specs))
+		for _, s := range specs {
+			sizes = append(sizes, s.NodeSize)
+		}
+		return fmt.Errorf("%s node_size %s is not available (available: %s)", name, config.NodeSize, strings.Join(sizes, ", "))
+	}
+	spec := specs[idx]
+
+	quantity := spec.NodeQuantityRange
+	if config.NodeQuantity < quantity.Min || (quantity.Step > 0 && (config.NodeQuantity-quantity.Min)%quantity.Step != 0) {
+		return fmt.Errorf("%s node_quantity %d of %s is not allowed (minimum %d, step %d)", name, config.NodeQuantity, config.NodeSize, quantity.Min, quantity.Step)
+	}
+
+	if storage := spec.StorageSizeGibRange; storage != nil && config.StorageSizeGib > 0 {
+		if config.StorageSizeGib < storage.Min || (storage.Max > 0 && config.StorageSizeGib > storage.Max) {
+			return fmt.Errorf("%s storage_size_gib %d of %s is out of range (%d-%d)", name, config.StorageSizeGib, config.NodeSize, storage.Min, storage.Max)
+		}
+	}
+
+	return nil
+}
+
+// Create validates the template, creates the cluster, waits until it is AVAILABLE and records it in the inventory.
+// If dryRun is true, the template is validated and only the planned cluster is printed.
+func Create(ctx context.Context, api ClusterAPI, store ClusterStore, t clusterspec.Template, rootPassword string, wait WaitOptions, dryRun bool, w io.Writer) error {
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("invalid template %s: %w", t.Source, err)
+	}
+
+	components := NewComponents(t.Components)
+	regions, err := api.ListProviderRegions(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ValidateComponents(regions, t.ClusterType, t.CloudProvider, t.Region, components); err != nil {
+		return fmt.Errorf("invalid template %s: %w", t.Source, err)
+	}
+	if t.TiDBVersion != "" {
+		fmt.Fprintf(w, "[SKIP] tidb_version %s cannot be chosen at creation, the default version of the region is used\n", t.TiDBVersion)
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would create %s cluster %s in project %s on %s %s with %s\n", t.ClusterType, t.Name, t.ProjectID, t.CloudProvider, t.Region, components)
+		return nil
+	}
+
+	req := CreateClusterRequest{
+		Name:          t.Name,
+		ClusterType:   strings.ToUpper(t.ClusterType),
+		CloudProvider: strings.ToUpper(t.CloudProvider),
+		Region:        t.Region,
+		Config: ClusterConfig{
+			RootPassword: rootPassword,
+			Port:         t.Port,
+			Components:   components,
+		},
+	}
+	for _, entry := range t.IPAccessList {
+		req.Config.IPAccessList = append(req.Config.IPAccessList, IPAccess{CIDR: entry.CIDR, Description: entry.Description})
+	}
+
+	clusterID, err := api.CreateCluster(ctx, t.ProjectID, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is being created with %s\n", clusterID, t.Name, components)
+
+	return waitAndStore(ctx, api, store, t.ProjectID, clusterID, wait, w)
+}
+
+// waitAndStore waits until the cluster is AVAILABLE and records it in the inventory.
+func waitAndStore(ctx context.Context, api ClusterAPI, store ClusterStore, projectID, clusterID string, wait WaitOptions, w io.Writer) error {
+	fmt.Fprintf(w, "Waiting for cluster %s to become %s...\n", clusterID, clusters.ClusterStatusAvailable)
+	var cluster *clusters.Cluster
+	err := util.WaitUntil(ctx, wait.PollInterval, wait.WaitTimeout, func() (bool, error) {
+		var err error
+		cluster, err = api.GetCluster(ctx, projectID, clusterID)
+		if err != nil {
+			return false, err
+		}
+		return cluster.Status.ClusterStatus == clusters.ClusterStatusAvailable, nil
+	})
+	if err != nil {
+		return fmt.Errorf("cluster %s did not become available: %w", clusterID, err)
+	}
+	if cluster.ProjectID == "" {
+		cluster.ProjectID = projectID
+	}
+
+	if err := store.StoreClusters(ctx, clusters.Clusters{*cluster}); err != nil {
+		return fmt.Errorf("cluster %s is available, but failed to record it: %w", clusterID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is %s and recorded in the inventory\n", cluster.ID, cluster.Name, cluster.Status.ClusterStatus)
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package clusterops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/clusterspec"
+	"github.com/stretchr/testify/require"
+)
+
+var testRegions = []ProviderRegion{{
+	ClusterType:   "DEDICATED",
+	CloudProvider: "AWS",
+	Region:        "us-west-2",
+	TiDB:          []NodeSpecRange{{NodeSize: "8C16G", NodeQuantityRange: QuantityStep{Min: 1, Step: 1}}},
+	TiKV: []NodeSpecRange{
+		{NodeSize: "8C32G", NodeQuantityRange: QuantityStep{Min: 3, Step: 3}, StorageSizeGibRange: &MinMax{Min: 200, Max: 4096}},
+		{NodeSize: "16C64G", NodeQuantityRange: QuantityStep{Min: 3, Step: 3}, StorageSizeGibRange: &MinMax{Min: 200, Max: 6144}},
+	},
+}}
+
+// fakeAPI is an in-memory ClusterAPI whose clusters become available after the given number of polls.
+type fakeAPI struct {
+	clusters  map[string]*clusters.Cluster
+	created   []CreateClusterRequest
+	updated   []UpdateClusterRequest
+	deleted   []string
+	backups   []CreateBackupRequest
+	pollsLeft int
+
+	backupStatus string // Status of the created backups, SUCCESS if empty
+}
+
+func newFakeAPI(pollsLeft int) *fakeAPI {
+	return &fakeAPI{clusters: make(map[string]*clusters.Cluster), pollsLeft: pollsLeft}
+}
+
+func (f *fakeAPI) ListProviderRegions(ctx context.Context) ([]ProviderRegion, error) {
+	return testRegions, nil
+}
+
+func (f *fakeAPI) CreateCluster(ctx context.Context, projectID string, req CreateClusterRequest) (string, error) {
+	f.created = append(f.created, req)
+	id := fmt.Sprintf("c%d", len(f.created))
+	f.clusters[id] = &clusters.Cluster{ID: id, ProjectID: projectID, Name: req.Name, Status: clusters.ClusterStatus{ClusterStatus: "CREATING"}}
+	return id, nil
+}
+
+func (f *fakeAPI) GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error) {
+	cluster, ok := f.clusters[clusterID]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s not found", clusterID)
+	}
+	if f.pollsLeft--; f.pollsLeft <= 0 {
+		cluster.Status.ClusterStatus = clusters.ClusterStatusAvailable
+	}
+	copied := *cluster
+	return &copied, nil
+}
+
+// UpdateCluster applies the change to the node map at once and makes the cluster MODIFYING until the next polls.
+func (f *fakeAPI) UpdateCluster(ctx context.Context, projectID, clusterID string, req UpdateClusterRequest) error {
+	cluster, ok := f.clusters[clusterID]
+	if !ok {
+		return fmt.Errorf("cluster %s not found", clusterID)
+	}
+	f.updated = append(f.updated, req)
+
+	components := clusters.ComponentsFromNodeMap(cluster.Status.NodeMap)
+	for _, c := range []struct{ current, changed **clusters.ComponentConfig }{
+		{&components.TiDB, &req.Config.Components.TiDB},
+		{&components.TiKV, &req.Config.Components.TiKV},
+		{&components.TiFlash, &req.Config.Components.TiFlash},
+	} {
+		if *c.changed != nil {
+			*c.current = *c.changed
+		}
+	}
+	cluster.Status.NodeMap = testNodeMap(components)
+	cluster.Status.ClusterStatus = "MODIFYING"
+	return nil
+}
+
+func (f *fakeAPI) DeleteCluster(ctx context.Context, projectID, clusterID string) error {
+	if _, ok := f.clusters[clusterID]; !ok {
+		return fmt.Errorf("cluster %s not found", clusterID)
+	}
+	f.deleted = append(f.deleted, clusterID)
+	delete(f.clusters, clusterID)
+	return nil
+}
+
+func (f *fakeAPI) CreateBackup(ctx context.Context, projectID, clusterID string, req CreateBackupRequest) (string, error) {
+	f.backups = append(f.backups, req)
+	return fmt.Sprintf("b%d", len(f.backups)), nil
+}
+
+func (f *fakeAPI) GetBackup(ctx context.Context, projectID, clusterID, backupID string) (*backups.Backup, error) {
+	status := f.backupStatus
+	if status == "" {
+		status = backups.BackupStatusSuccess
+	}
+	return &backups.Backup{ID: backupID, Name: "final", Type: "MANUAL", CreateTimestamp: "1750000000", Status: status}, nil
+}
+
+// testNodeMap returns a node map of NORMAL nodes with the given configuration.
+func testNodeMap(components clusters.Components) clusters.NodeMap {
+	nodes := func(name string, c *clusters.ComponentConfig) clusters.Nodes {
+		if c == nil {
+			return nil
+		}
+		var nodes clusters.Nodes
+		for i := range c.NodeQuantity {
+			nodes = append(nodes, clusters.Node{NodeName: fmt.Sprintf("%s-%d", name, i), NodeSize: c.NodeSize, StorageSizeGib: c.StorageSizeGib, Status: NodeStatusNormal})
+		}
+		return nodes
+	}
+
+	return clusters.NodeMap{
+		Tidb:    nodes(clusters.ComponentTiDB, components.TiDB),
+		Tikv:    nodes(clusters.ComponentTiKV, components.TiKV),
+		Tiflash: nodes(clusters.ComponentTiFlash, components.TiFlash),
+	}
+}
+
+// fakeStore is an in-memory ClusterStore.
+type fakeStore struct {
+	stored clusters.Clusters
+}
+
+func (f *fakeStore) StoreClusters(ctx context.Context, clusters clusters.Clusters) error {
+	f.stored = append(f.stored, clusters...)
+	return nil
+}
+
+func TestValidateComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     string
+		components clusters.Components
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			region:     "us-west-2",
+			components: clusters.Components{TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2}, TiKV: &clusters.ComponentConfig{NodeSize: "16C64G", NodeQuantity: 6, StorageSizeGib: 5000}},
+		},
+		{
+			name:       "unknown region",
+			region:     "eu-west-1",
+			components: clusters.Components{TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2}},
+			wantErr:    "DEDICATED clusters are not available in AWS eu-west-1",
+		},
+		{
+			name:       "unknown node size",
+			region:     "us-west-2",
+			components: clusters.Components{TiDB: &clusters.ComponentConfig{NodeSize: "4C16G", NodeQuantity: 2}},
+			wantErr:    "tidb node_size 4C16G is not available (available: 8C16G) in AWS us-west-2",
+		},
+		{
+			name:       "quantity step",
+			region:     "us-west-2",
+			components: clusters.Components{TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", NodeQuantity: 4, StorageSizeGib: 500}},
+			wantErr:    "tikv node_quantity 4 of 8C32G is not allowed (minimum 3, step 3)",
+		},
+		{
+			name:       "storage range",
+			region:     "us-west-2",
+			components: clusters.Components{TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", NodeQuantity: 3, StorageSizeGib: 5000}},
+			wantErr:    "tikv storage_size_gib 5000 of 8C32G is out of range (200-4096)",
+		},
+		{
+			name:       "no tiflash",
+			region:     "us-west-2",
+			components: clusters.Components{TiFlash: &clusters.ComponentConfig{NodeSize: "8C64G", NodeQuantity: 1, StorageSizeGib: 500}},
+			wantErr:    "tiflash is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateComponents(testRegions, "DEDICATED", "AWS", tt.region, tt.components)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	tmpl := clusterspec.Template{
+		ClusterSpec: clusterspec.ClusterSpec{
+			ProjectID:     "p1",
+			Name:          "team-a-main",
+			CloudProvider: "aws",
+			Region:        "us-west-2",
+			Components: clusterspec.Components{
+				TiDB: &clusterspec.ComponentSpec{NodeSize: "8C16G", NodeQuantity: 2},
+				TiKV: &clusterspec.ComponentSpec{NodeSize: "8C32G", NodeQuantity: 3, StorageSizeGib: 500},
+			},
+			Source: "team-a.yaml",
+		},
+		IPAccessList: []clusterspec.IPAccess{{CIDR: "10.0.0.0/8", Description: "corporate"}},
+	}
+	wait := WaitOptions{PollInterval: time.Millisecond, WaitTimeout: time.Second}
+
+	t.Run("dry run", func(t *testing.T) {
+		api, store := newFakeAPI(1), &fakeStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Create(ctx, api, store, tmpl, "", wait, true, &buf))
+		require.Contains(t, buf.String(), "[DRY RUN] Would create DEDICATED cluster team-a-main in project p1 on aws us-west-2 with TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)")
+		require.Empty(t, api.created)
+	})
+
+	t.Run("created and recorded", func(t *testing.T) {
+		api, store := newFakeAPI(3), &fakeStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Create(ctx, api, store, tmpl, "secret", wait, false, &buf))
+		require.Len(t, api.created, 1)
+		require.Equal(t, "AWS", api.created[0].CloudProvider)
+		require.Equal(t, 4000, api.created[0].Config.Port)
+		require.Equal(t, []IPAccess{{CIDR: "10.0.0.0/8", Description: "corporate"}}, api.created[0].Config.IPAccessList)
+		require.Len(t, store.stored, 1)
+		require.Equal(t, clusters.ClusterStatusAvailable, store.stored[0].Status.ClusterStatus)
+		require.Contains(t, buf.String(), "[SUCCESS] Cluster c1 (team-a-main) is AVAILABLE and recorded in the inventory")
+	})
+
+	t.Run("rejected by the region constraints", func(t *testing.T) {
+		invalid := tmpl
+		invalid.Components.TiKV = &clusterspec.ComponentSpec{NodeSize: "8C32G", NodeQuantity: 4, StorageSizeGib: 500}
+		api := newFakeAPI(1)
+		err := Create(ctx, api, &fakeStore{}, invalid, "secret", wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "invalid template team-a.yaml: tikv node_quantity 4")
+		require.Empty(t, api.created)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		api, store := newFakeAPI(1<<30), &fakeStore{}
+		err := Create(ctx, api, store, tmpl, "secret", WaitOptions{PollInterval: time.Millisecond, WaitTimeout: 10 * time.Millisecond}, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "cluster c1 did not become available: timed out")
+		require.Empty(t, store.stored)
+	})
+}
@@ -0,0 +1,204 @@
+package clusterops
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// ActionDelete is the action recorded when a cluster is deleted.
+const ActionDelete = "delete"
+
+// Action is a change made to a cluster, recorded in the audit log.
+type Action struct {
+	ClusterID   string
+	ProjectID   string
+	ClusterName string
+	Action      string
+	BackupID    string
+	Operator    string
+	Detail      string
+}
+
+// ActionStore defines an interface for recording the deleted clusters, their final backups and the audit log.
+type ActionStore interface {
+	MarkClusterDeleted(ctx context.Context, clusterID string) error
+	StoreBackup(ctx context.Context, projectID, clusterID string, backup backups.Backup) error
+	RecordAction(ctx context.Context, action Action) error
+}
+
+// DBActionStore implements ClusterStore and ActionStore with the clusters, cluster_backups and cluster_actions tables.
+type DBActionStore struct {
+	*clusters.DBClusterStore
+	backups *backups.DBBackupStore
+}
+
+// NewDBActionStore initializes a new DBActionStore using the given DSN and optional connection pool settings.
+func NewDBActionStore(dsn string, poolConfig *db.PoolConfig) (*DBActionStore, error) {
+	store, err := clusters.NewDBClusterStore(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBActionStore{DBClusterStore: store, backups: &backups.DBBackupStore{Queries: store.Queries}}, nil
+}
+
+// MarkClusterDeleted marks the cluster as deleted without waiting for the next fetch-clusters.
+func (s *DBActionStore) MarkClusterDeleted(ctx context.Context, clusterID string) error {
+	if err := s.Queries.MarkClusterAsDeleted(ctx, clusterID); err != nil {
+		return fmt.Errorf("failed to mark cluster %s as deleted: %w", clusterID, err)
+	}
+	return nil
+}
+
+// StoreBackup records the backup with the other backups of the cluster, so that it can be restored from
+// once the cluster is marked as deleted and no longer synced by fetch-clusters.
+func (s *DBActionStore) StoreBackup(ctx context.Context, projectID, clusterID string, backup backups.Backup) error {
+	return s.backups.StoreBackup(ctx, projectID, clusterID, backup)
+}
+
+// RecordAction appends the action to the audit log.
+func (s *DBActionStore) RecordAction(ctx context.Context, action Action) error {
+	err := s.Queries.InsertClusterAction(ctx, db.InsertClusterActionParams{
+		ClusterID:   action.ClusterID,
+		ProjectID:   action.ProjectID,
+		ClusterName: action.ClusterName,
+		Action:      action.Action,
+		BackupID:    action.BackupID,
+		Operator:    action.Operator,
+		Detail:      action.Detail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s of cluster %s: %w", action.Action, action.ClusterID, err)
+	}
+	return nil
+}
+
+// Confirm returns an error unless the deletion of the cluster with the given name is confirmed.
+type Confirm func(name string) error
+
+// ConfirmByName confirms the deletion if confirmName matches the cluster name. It is for non-interactive use.
+func ConfirmByName(confirmName string) Confirm {
+	return func(name string) error {
+		if confirmName != name {
+			return fmt.Errorf("confirmation name %q does not match the cluster name %q", confirmName, name)
+		}
+		return nil
+	}
+}
+
+// ConfirmByPrompt asks to type the cluster name and confirms the deletion if it matches.
+func ConfirmByPrompt(r io.Reader, w io.Writer) Confirm {
+	return func(name string) error {
+		fmt.Fprintf(w, "Type the cluster name %q to confirm the deletion: ", name)
+		line, err := bufio.NewReader(r).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if strings.TrimSpace(line) != name {
+			return fmt.Errorf("typed name %q does not match the cluster name %q", strings.TrimSpace(line), name)
+		}
+		return nil
+	}
+}
+
+// DeleteParams holds the parameters for deleting a cluster.
+type DeleteParams struct {
+	ProjectID string
+	ClusterID string
+	Operator  string // Recorded in the audit log
+}
+
+// Delete takes a final backup of the cluster, waits for it to succeed, and deletes the cluster once confirmed.
+// The deletion is recorded with the backup ID, the backup is stored so that msk restore create can restore it,
+// and the cluster is marked as deleted in the inventory right away. If dryRun is true, only the planned deletion is printed.
+func Delete(ctx context.Context, api ClusterAPI, store ActionStore, p DeleteParams, confirm Confirm, wait WaitOptions, dryRun bool, w io.Writer) error {
+	cluster, err := api.GetCluster(ctx, p.ProjectID, p.ClusterID)
+	if err != nil {
+		return err
+	}
+	components := clusters.ComponentsFromNodeMap(cluster.Status.NodeMap)
+	fmt.Fprintf(w, "Cluster %s (%s) in project %s: %s, %s\n", cluster.ID, cluster.Name, p.ProjectID, cluster.Status.ClusterStatus, components)
+
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would take a final backup of cluster %s (%s) and delete it\n", cluster.ID, cluster.Name)
+		return nil
+	}
+	if err := confirm(cluster.Name); err != nil {
+		return fmt.Errorf("cluster %s is not deleted: %w", cluster.ID, err)
+	}
+
+	backup, err := finalBackup(ctx, api, p.ProjectID, cluster, wait, w)
+	if err != nil {
+		return fmt.Errorf("cluster %s is not deleted: %w", cluster.ID, err)
+	}
+
+	if err := api.DeleteCluster(ctx, p.ProjectID, p.ClusterID); err != nil {
+		return fmt.Errorf("%w (final backup %s is taken)", err, backup.ID)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is being deleted\n", cluster.ID, cluster.Name)
+
+	// The cluster is gone, so every record is attempted even if another fails. The audit log goes first,
+	// since it is the record of the backup to restore the cluster from.
+	recordErr := store.RecordAction(ctx, Action{
+		ClusterID:   cluster.ID,
+		ProjectID:   p.ProjectID,
+		ClusterName: cluster.Name,
+		Action:      ActionDelete,
+		BackupID:    backup.ID,
+		Operator:    p.Operator,
+		Detail:      components.String(),
+	})
+	backupErr := store.StoreBackup(ctx, p.ProjectID, cluster.ID, *backup)
+	markErr := store.MarkClusterDeleted(ctx, cluster.ID)
+	if err := errors.Join(recordErr, backupErr, markErr); err != nil {
+		return fmt.Errorf("cluster %s is being deleted with final backup %s, but failed to record it: %w", cluster.ID, backup.ID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is marked as deleted and the deletion is recorded with backup %s\n", cluster.ID, cluster.Name, backup.ID)
+
+	return nil
+}
+
+// finalBackup takes a manual backup of the cluster, waits for it to succeed and returns it.
+func finalBackup(ctx context.Context, api ClusterAPI, projectID string, cluster *clusters.Cluster, wait WaitOptions, w io.Writer) (*backups.Backup, error) {
+	if cluster.Status.ClusterStatus != clusters.ClusterStatusAvailable {
+		return nil, fmt.Errorf("a final backup cannot be taken while the cluster is %s", cluster.Status.ClusterStatus)
+	}
+
+	req := CreateBackupRequest{
+		Name:        fmt.Sprintf("final-%s-%s", cluster.Name, time.Now().UTC().Format("20060102150405")),
+		Description: "Final backup taken by msk cluster delete",
+	}
+	backupID, err := api.CreateBackup(ctx, projectID, cluster.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "Waiting for final backup %s (%s) to succeed...\n", backupID, req.Name)
+
+	var backup *backups.Backup
+	err = util.WaitUntil(ctx, wait.PollInterval, wait.WaitTimeout, func() (bool, error) {
+		backup, err = api.GetBackup(ctx, projectID, cluster.ID, backupID)
+		if err != nil {
+			return false, err
+		}
+		if backup.Status == backups.BackupStatusFailed {
+			return false, errors.New("backup failed")
+		}
+		return backup.Status == backups.BackupStatusSuccess, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("final backup %s did not succeed: %w", backupID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Final backup %s (%s) succeeded\n", backupID, req.Name)
+
+	return backup, nil
+}
@@ -0,0 +1,182 @@
+package clusterops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/restore"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeActionStore is an in-memory ActionStore, and a restore.RestoreStore reading the backups stored by it.
+// If err is set, it fails to update the inventory, but still records the actions.
+type fakeActionStore struct {
+	nodeMaps map[string]clusters.NodeMap // Stored by fetch-clusters
+	deleted  []string
+	sources  map[string]*restore.Source
+	actions  []Action
+	restored []clusters.Cluster
+	err      error
+}
+
+func (f *fakeActionStore) MarkClusterDeleted(ctx context.Context, clusterID string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.deleted = append(f.deleted, clusterID)
+	return nil
+}
+
+func (f *fakeActionStore) StoreBackup(ctx context.Context, projectID, clusterID string, backup backups.Backup) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.sources == nil {
+		f.sources = make(map[string]*restore.Source)
+	}
+	f.sources[backup.ID] = &restore.Source{BackupID: backup.ID, BackupStatus: backup.Status, ProjectID: projectID, ClusterID: clusterID, NodeMap: f.nodeMaps[clusterID]}
+	return nil
+}
+
+func (f *fakeActionStore) RecordAction(ctx context.Context, action Action) error {
+	f.actions = append(f.actions, action)
+	return nil
+}
+
+func (f *fakeActionStore) GetSource(ctx context.Context, backupID string) (*restore.Source, error) {
+	src, ok := f.sources[backupID]
+	if !ok {
+		return nil, fmt.Errorf("backup %s not found", backupID)
+	}
+	return src, nil
+}
+
+func (f *fakeActionStore) StoreCluster(ctx context.Context, cluster clusters.Cluster) error {
+	f.restored = append(f.restored, cluster)
+	return nil
+}
+
+// The restore operations of fakeAPI create the restored cluster as available at once.
+
+func (f *fakeAPI) CreateRestore(ctx context.Context, projectID string, req restore.CreateRestoreRequest) (*restore.CreateRestoreResponse, error) {
+	f.clusters["restored"] = &clusters.Cluster{ID: "restored", ProjectID: projectID, Name: req.Name, Status: clusters.ClusterStatus{ClusterStatus: clusters.ClusterStatusAvailable}}
+	return &restore.CreateRestoreResponse{ID: "r1", ClusterID: "restored"}, nil
+}
+
+func (f *fakeAPI) GetRestore(ctx context.Context, projectID, restoreID string) (*restore.Restore, error) {
+	return &restore.Restore{ID: restoreID, Status: restore.StatusSuccess}, nil
+}
+
+func (f *fakeAPI) ListRestores(ctx context.Context, projectID string, page, pageSize int) ([]restore.Restore, int, error) {
+	return nil, 0, nil
+}
+
+func TestConfirmByPrompt(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ConfirmByPrompt(strings.NewReader("team-a-old\n"), &buf)("team-a-old"))
+	require.Equal(t, `Type the cluster name "team-a-old" to confirm the deletion: `, buf.String())
+
+	err := ConfirmByPrompt(strings.NewReader("team-a\n"), &bytes.Buffer{})("team-a-old")
+	require.ErrorContains(t, err, `typed name "team-a" does not match the cluster name "team-a-old"`)
+
+	err = ConfirmByPrompt(strings.NewReader(""), &bytes.Buffer{})("team-a-old")
+	require.ErrorContains(t, err, "does not match")
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	newAPI := func(status string) *fakeAPI {
+		api := newFakeAPI(1 << 30)
+		api.clusters["c1"] = &clusters.Cluster{
+			ID: "c1", ProjectID: "p1", Name: "team-a-old",
+			Status: clusters.ClusterStatus{ClusterStatus: status, NodeMap: testNodeMap(clusters.Components{
+				TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+				TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+			})},
+		}
+		return api
+	}
+	p := DeleteParams{ProjectID: "p1", ClusterID: "c1", Operator: "alice"}
+	wait := WaitOptions{PollInterval: time.Millisecond, WaitTimeout: time.Second}
+
+	t.Run("dry run", func(t *testing.T) {
+		api, store := newAPI(clusters.ClusterStatusAvailable), &fakeActionStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, true, &buf))
+		require.Contains(t, buf.String(), "[DRY RUN] Would take a final backup of cluster c1 (team-a-old) and delete it")
+		require.Empty(t, api.backups)
+		require.Empty(t, api.deleted)
+	})
+
+	t.Run("backed up, deleted and recorded", func(t *testing.T) {
+		api, store := newAPI(clusters.ClusterStatusAvailable), &fakeActionStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, false, &buf))
+		require.Len(t, api.backups, 1)
+		require.True(t, strings.HasPrefix(api.backups[0].Name, "final-team-a-old-"))
+		require.Equal(t, []string{"c1"}, api.deleted)
+		require.Equal(t, []string{"c1"}, store.deleted)
+		require.Equal(t, []Action{{
+			ClusterID: "c1", ProjectID: "p1", ClusterName: "team-a-old", Action: ActionDelete, BackupID: "b1", Operator: "alice",
+			Detail: "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)",
+		}}, store.actions)
+		require.Contains(t, buf.String(), "[SUCCESS] Cluster c1 (team-a-old) is marked as deleted and the deletion is recorded with backup b1")
+	})
+
+	t.Run("final backup restored", func(t *testing.T) {
+		api := newAPI(clusters.ClusterStatusAvailable)
+		store := &fakeActionStore{nodeMaps: map[string]clusters.NodeMap{"c1": api.clusters["c1"].Status.NodeMap}}
+		require.NoError(t, Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, false, &bytes.Buffer{}))
+
+		var buf bytes.Buffer
+		params := restore.Params{BackupID: "b1", Name: "team-a-old-restored", PollInterval: time.Millisecond, WaitTimeout: time.Second}
+		require.NoError(t, restore.Create(ctx, api, store, params, false, &buf))
+		require.Contains(t, buf.String(), "from backup b1 as cluster restored (team-a-old-restored) with TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)")
+		require.Len(t, store.restored, 1)
+		require.Equal(t, "team-a-old-restored", store.restored[0].Name)
+	})
+
+	t.Run("recorded even if the inventory fails", func(t *testing.T) {
+		api := newAPI(clusters.ClusterStatusAvailable)
+		store := &fakeActionStore{err: errors.New("connection refused")}
+		var buf bytes.Buffer
+		err := Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, false, &buf)
+		require.ErrorContains(t, err, "cluster c1 is being deleted with final backup b1, but failed to record it: connection refused")
+		require.Equal(t, []string{"c1"}, api.deleted)
+		require.Len(t, store.actions, 1)
+		require.Equal(t, "b1", store.actions[0].BackupID)
+		require.Empty(t, store.deleted)
+		require.NotContains(t, buf.String(), "is marked as deleted")
+	})
+
+	t.Run("not confirmed", func(t *testing.T) {
+		api, store := newAPI(clusters.ClusterStatusAvailable), &fakeActionStore{}
+		err := Delete(ctx, api, store, p, ConfirmByName("team-a"), wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "cluster c1 is not deleted: confirmation name")
+		require.Empty(t, api.backups)
+		require.Empty(t, api.deleted)
+	})
+
+	t.Run("backup failed", func(t *testing.T) {
+		api, store := newAPI(clusters.ClusterStatusAvailable), &fakeActionStore{}
+		api.backupStatus = backups.BackupStatusFailed
+		err := Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "cluster c1 is not deleted: final backup b1 did not succeed: backup failed")
+		require.Empty(t, api.deleted)
+		require.Empty(t, store.actions)
+	})
+
+	t.Run("cluster is not available for a backup", func(t *testing.T) {
+		api, store := newAPI("PAUSED"), &fakeActionStore{}
+		err := Delete(ctx, api, store, p, ConfirmByName("team-a-old"), wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "a final backup cannot be taken while the cluster is PAUSED")
+		require.Empty(t, api.deleted)
+	})
+}
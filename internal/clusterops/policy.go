@@ -0,0 +1,165 @@
+package clusterops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy holds the price data and the limits used as guardrails when changing clusters.
+// It is read from a YAML file like:
+//
+//	pricing:
+//	  currency: USD
+//	  node_hourly:            # Price per node per hour, by component and node size
+//	    tidb: {8C16G: 0.82, 16C32G: 1.64}
+//	    tikv: {8C32G: 1.04}
+//	  storage_gib_hourly:     # Price per GiB of storage per hour, by component
+//	    tikv: 0.00034
+//	limits:
+//	  default:
+//	    max_nodes: {tidb: 4, tikv: 6, tiflash: 2}
+//	    max_hourly_cost: 20
+//	  projects:
+//	    "1234567890":
+//	      max_nodes: {tikv: 12}
+//	      allowed_node_sizes: [8C16G, 8C32G, 16C64G]
+//
+// The limits of a project override the default ones field by field.
+type Policy struct {
+	Pricing *Pricing `yaml:"pricing,omitempty"`
+	Limits  Limits   `yaml:"limits,omitempty"`
+}
+
+// Pricing is the price data of the nodes and the storage.
+type Pricing struct {
+	Currency         string                        `yaml:"currency,omitempty"`
+	NodeHourly       map[string]map[string]float64 `yaml:"node_hourly,omitempty"`
+	StorageGibHourly map[string]float64            `yaml:"storage_gib_hourly,omitempty"`
+}
+
+// Limits are the default and per-project limits.
+type Limits struct {
+	Default  ProjectLimits            `yaml:"default,omitempty"`
+	Projects map[string]ProjectLimits `yaml:"projects,omitempty"`
+}
+
+// ProjectLimits are the limits of the clusters of a project. Zero values are not limited.
+type ProjectLimits struct {
+	MaxNodes         map[string]int `yaml:"max_nodes,omitempty"` // By component
+	AllowedNodeSizes []string       `yaml:"allowed_node_sizes,omitempty"`
+	MaxHourlyCost    float64        `yaml:"max_hourly_cost,omitempty"`
+}
+
+// HoursPerMonth is used to show the monthly cost.
+const HoursPerMonth = 730
+
+// LoadPolicy reads the policy file. An empty path returns an empty policy, i.e. no price data and no limits.
+func LoadPolicy(path string) (Policy, error) {
+	if path == "" {
+		return Policy{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // Unknown fields are rejected to catch typos
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return Policy{}, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+
+	return policy, nil
+}
+
+// HourlyCost returns the hourly cost of the components. It returns an error if there is no price data for any of them.
+func (p *Pricing) HourlyCost(components clusters.Components) (float64, error) {
+	if p == nil {
+		return 0, errors.New("no price data")
+	}
+
+	var cost float64
+	for _, component := range listComponents(components) {
+		if component.config == nil || component.config.NodeQuantity == 0 {
+			continue
+		}
+		price, ok := p.NodeHourly[component.name][component.config.NodeSize]
+		if !ok {
+			return 0, fmt.Errorf("no price data for %s %s", component.name, component.config.NodeSize)
+		}
+		cost += price * float64(component.config.NodeQuantity)
+
+		if component.config.StorageSizeGib > 0 {
+			price, ok := p.StorageGibHourly[component.name]
+			if !ok {
+				return 0, fmt.Errorf("no price data for %s storage", component.name)
+			}
+			cost += price * float64(component.config.StorageSizeGib*component.config.NodeQuantity)
+		}
+	}
+
+	return cost, nil
+}
+
+// For returns the limits of the project, i.e. the default limits overridden by the ones of the project.
+func (l Limits) For(projectID string) ProjectLimits {
+	limits := ProjectLimits{
+		MaxNodes:         make(map[string]int),
+		AllowedNodeSizes: l.Default.AllowedNodeSizes,
+		MaxHourlyCost:    l.Default.MaxHourlyCost,
+	}
+	for component, max := range l.Default.MaxNodes {
+		limits.MaxNodes[component] = max
+	}
+
+	project, ok := l.Projects[projectID]
+	if !ok {
+		return limits
+	}
+	for component, max := range project.MaxNodes {
+		limits.MaxNodes[component] = max
+	}
+	if len(project.AllowedNodeSizes) > 0 {
+		limits.AllowedNodeSizes = project.AllowedNodeSizes
+	}
+	if project.MaxHourlyCost > 0 {
+		limits.MaxHourlyCost = project.MaxHourlyCost
+	}
+
+	return limits
+}
+
+// Check returns an error if the components exceed the limits. costErr is the error of estimating hourlyCost, if any.
+func (l ProjectLimits) Check(components clusters.Components, hourlyCost float64, costErr error) error {
+	for _, component := range listComponents(components) {
+		if component.config == nil {
+			continue
+		}
+		if max, ok := l.MaxNodes[component.name]; ok && max > 0 && component.config.NodeQuantity > max {
+			return fmt.Errorf("%s node_quantity %d exceeds the limit %d", component.name, component.config.NodeQuantity, max)
+		}
+		if component.config.NodeQuantity > 0 && len(l.AllowedNodeSizes) > 0 && !slices.Contains(l.AllowedNodeSizes, component.config.NodeSize) {
+			return fmt.Errorf("%s node_size %s is not allowed (allowed: %v)", component.name, component.config.NodeSize, l.AllowedNodeSizes)
+		}
+	}
+
+	if l.MaxHourlyCost > 0 {
+		if costErr != nil {
+			return fmt.Errorf("cannot enforce max_hourly_cost %.2f: %w", l.MaxHourlyCost, costErr)
+		}
+		if hourlyCost > l.MaxHourlyCost {
+			return fmt.Errorf("estimated hourly cost %.2f exceeds the limit %.2f", hourlyCost, l.MaxHourlyCost)
+		}
+	}
+
+	return nil
+}
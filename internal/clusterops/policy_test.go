@@ -0,0 +1,97 @@
+package clusterops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+pricing:
+  node_hourly:
+    tidb: {8C16G: 0.8}
+limits:
+  default:
+    max_nodes: {tikv: 6}
+    max_hourly_cost: 20
+  projects:
+    "p1":
+      max_nodes: {tikv: 12}
+      allowed_node_sizes: [8C16G]
+`), 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	require.Equal(t, 0.8, policy.Pricing.NodeHourly["tidb"]["8C16G"])
+	require.Equal(t, ProjectLimits{MaxNodes: map[string]int{"tikv": 12}, AllowedNodeSizes: []string{"8C16G"}, MaxHourlyCost: 20}, policy.Limits.For("p1"))
+	require.Equal(t, ProjectLimits{MaxNodes: map[string]int{"tikv": 6}, MaxHourlyCost: 20}, policy.Limits.For("p2"))
+
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  default:\n    max_node: {tikv: 6}\n"), 0o600))
+	_, err = LoadPolicy(path)
+	require.ErrorContains(t, err, "field max_node not found")
+
+	policy, err = LoadPolicy("")
+	require.NoError(t, err)
+	require.Nil(t, policy.Pricing)
+}
+
+func TestProjectLimits_Check(t *testing.T) {
+	components := clusters.Components{
+		TiDB:    &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+		TiKV:    &clusters.ComponentConfig{NodeSize: "16C64G", StorageSizeGib: 500, NodeQuantity: 6},
+		TiFlash: &clusters.ComponentConfig{NodeSize: "16C128G", NodeQuantity: 0}, // being removed
+	}
+
+	tests := []struct {
+		name    string
+		limits  ProjectLimits
+		cost    float64
+		costErr error
+		wantErr string
+	}{
+		{name: "no limits", costErr: errors.New("no price data")},
+		{name: "within the limits", limits: ProjectLimits{MaxNodes: map[string]int{"tikv": 6}, AllowedNodeSizes: []string{"8C16G", "16C64G"}, MaxHourlyCost: 20}, cost: 15},
+		{name: "too many nodes", limits: ProjectLimits{MaxNodes: map[string]int{"tikv": 3}}, wantErr: "tikv node_quantity 6 exceeds the limit 3"},
+		{name: "size not allowed", limits: ProjectLimits{AllowedNodeSizes: []string{"8C16G"}}, wantErr: "tikv node_size 16C64G is not allowed"},
+		{name: "too expensive", limits: ProjectLimits{MaxHourlyCost: 10}, cost: 15, wantErr: "estimated hourly cost 15.00 exceeds the limit 10.00"},
+		{name: "no price data for the cost limit", limits: ProjectLimits{MaxHourlyCost: 10}, costErr: errors.New("no price data for tikv 16C64G"), wantErr: "cannot enforce max_hourly_cost 10.00: no price data for tikv 16C64G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Check(components, tt.cost, tt.costErr)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPricing_HourlyCost(t *testing.T) {
+	pricing := &Pricing{
+		NodeHourly:       map[string]map[string]float64{"tidb": {"8C16G": 1}, "tikv": {"8C32G": 2}},
+		StorageGibHourly: map[string]float64{"tikv": 0.001},
+	}
+
+	cost, err := pricing.HourlyCost(clusters.Components{
+		TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+		TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 2+6+1.5, cost, 1e-9)
+
+	_, err = pricing.HourlyCost(clusters.Components{TiDB: &clusters.ComponentConfig{NodeSize: "16C32G", NodeQuantity: 2}})
+	require.ErrorContains(t, err, "no price data for tidb 16C32G")
+
+	_, err = (*Pricing)(nil).HourlyCost(clusters.Components{})
+	require.ErrorContains(t, err, "no price data")
+}
@@ -0,0 +1,283 @@
+package clusterops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// NodeStatusNormal is the status of a node that is serving.
+const NodeStatusNormal = "NORMAL"
+
+// ComponentChange is a requested change of a component. Empty values keep the current configuration.
+type ComponentChange struct {
+	NodeSize       string
+	NodeQuantity   *int // nil keeps the current quantity. 0 removes the nodes, which is allowed only for TiFlash.
+	StorageSizeGib int
+}
+
+// IsZero reports whether nothing is changed.
+func (c ComponentChange) IsZero() bool {
+	return c.NodeSize == "" && c.NodeQuantity == nil && c.StorageSizeGib == 0
+}
+
+// Changes are the requested changes of each component.
+type Changes struct {
+	TiDB    ComponentChange
+	TiKV    ComponentChange
+	TiFlash ComponentChange
+}
+
+// ScaleParams holds the parameters for scaling a cluster.
+type ScaleParams struct {
+	ProjectID string
+	ClusterID string
+	Changes   Changes
+}
+
+// Apply returns the node configuration after applying the changes to the current one.
+func (c Changes) Apply(current clusters.Components) (clusters.Components, error) {
+	var target clusters.Components
+	for _, component := range []struct {
+		name    string
+		current *clusters.ComponentConfig
+		change  ComponentChange
+		target  **clusters.ComponentConfig
+	}{
+		{clusters.ComponentTiDB, current.TiDB, c.TiDB, &target.TiDB},
+		{clusters.ComponentTiKV, current.TiKV, c.TiKV, &target.TiKV},
+		{clusters.ComponentTiFlash, current.TiFlash, c.TiFlash, &target.TiFlash},
+	} {
+		config, err := applyChange(component.name, component.current, component.change)
+		if err != nil {
+			return clusters.Components{}, err
+		}
+		*component.target = config
+	}
+
+	return target, nil
+}
+
+func applyChange(name string, current *clusters.ComponentConfig, change ComponentChange) (*clusters.ComponentConfig, error) {
+	if change.IsZero() {
+		return current, nil
+	}
+
+	if current == nil {
+		if change.NodeQuantity == nil || *change.NodeQuantity <= 0 || change.NodeSize == "" || (name != clusters.ComponentTiDB && change.StorageSizeGib <= 0) {
+			return nil, fmt.Errorf("%s has no nodes: node quantity, node size and storage size are required to add them", name)
+		}
+		return &clusters.ComponentConfig{NodeSize: change.NodeSize, StorageSizeGib: change.StorageSizeGib, NodeQuantity: *change.NodeQuantity}, nil
+	}
+
+	target := *current
+	if change.NodeSize != "" {
+		target.NodeSize = change.NodeSize
+	}
+	if change.NodeQuantity != nil {
+		quantity := *change.NodeQuantity
+		if quantity < 0 || (quantity == 0 && name != clusters.ComponentTiFlash) {
+			return nil, fmt.Errorf("%s node quantity must be positive: %d", name, quantity)
+		}
+		target.NodeQuantity = quantity
+	}
+	if change.StorageSizeGib != 0 {
+		if name == clusters.ComponentTiDB {
+			return nil, fmt.Errorf("%s has no storage to change", name)
+		}
+		if change.StorageSizeGib < current.StorageSizeGib {
+			return nil, fmt.Errorf("%s storage size cannot be decreased: %d GiB to %d GiB", name, current.StorageSizeGib, change.StorageSizeGib)
+		}
+		target.StorageSizeGib = change.StorageSizeGib
+	}
+
+	return &target, nil
+}
+
+// changedComponents returns only the components whose configuration differs between current and target.
+func changedComponents(current, target clusters.Components) clusters.Components {
+	changed := func(current, target *clusters.ComponentConfig) *clusters.ComponentConfig {
+		if target == nil || (current != nil && *current == *target) {
+			return nil
+		}
+		return target
+	}
+
+	return clusters.Components{
+		TiDB:    changed(current.TiDB, target.TiDB),
+		TiKV:    changed(current.TiKV, target.TiKV),
+		TiFlash: changed(current.TiFlash, target.TiFlash),
+	}
+}
+
+// Scale changes the node configuration of the cluster against its current node map, within the limits of the policy,
+// waits until the node statuses settle and records the cluster in the inventory.
+// If dryRun is true, only the plan and the cost delta are printed.
+func Scale(ctx context.Context, api ClusterAPI, store ClusterStore, p ScaleParams, policy Policy, wait WaitOptions, dryRun bool, w io.Writer) error {
+	cluster, err := api.GetCluster(ctx, p.ProjectID, p.ClusterID)
+	if err != nil {
+		return err
+	}
+	if cluster.Status.ClusterStatus != clusters.ClusterStatusAvailable {
+		return fmt.Errorf("cluster %s is %s, only %s clusters can be scaled", p.ClusterID, cluster.Status.ClusterStatus, clusters.ClusterStatusAvailable)
+	}
+
+	current := clusters.ComponentsFromNodeMap(cluster.Status.NodeMap)
+	target, err := p.Changes.Apply(current)
+	if err != nil {
+		return fmt.Errorf("invalid change of cluster %s: %w", p.ClusterID, err)
+	}
+	changed := changedComponents(current, target)
+	if changed == (clusters.Components{}) {
+		fmt.Fprintf(w, "[SKIP] Cluster %s (%s) already has %s\n", cluster.ID, cluster.Name, current)
+		return nil
+	}
+
+	printScalePlan(w, cluster, current, target)
+	targetCost, costErr := printCostDelta(w, policy.Pricing, current, target)
+
+	regions, err := api.ListProviderRegions(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ValidateComponents(regions, cluster.ClusterType, cluster.CloudProvider, cluster.Region, target); err != nil {
+		return fmt.Errorf("invalid change of cluster %s: %w", p.ClusterID, err)
+	}
+	if err := policy.Limits.For(p.ProjectID).Check(target, targetCost, costErr); err != nil {
+		return fmt.Errorf("change of cluster %s exceeds the limits of project %s: %w", p.ClusterID, p.ProjectID, err)
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would update cluster %s (%s) with %s\n", cluster.ID, cluster.Name, changed)
+		return nil
+	}
+
+	if err := api.UpdateCluster(ctx, p.ProjectID, p.ClusterID, UpdateClusterRequest{Config: UpdateClusterConfig{Components: changed}}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is being scaled to %s\n", cluster.ID, cluster.Name, target)
+
+	return waitForNodes(ctx, api, store, p.ProjectID, p.ClusterID, target, wait, w)
+}
+
+func printScalePlan(w io.Writer, cluster *clusters.Cluster, current, target clusters.Components) {
+	fmt.Fprintf(w, "Plan for cluster %s (%s) in project %s:\n", cluster.ID, cluster.Name, cluster.ProjectID)
+	describe := func(c *clusters.ComponentConfig) string {
+		if c == nil || c.NodeQuantity == 0 {
+			return "no nodes"
+		}
+		return c.String()
+	}
+
+	targets := listComponents(target)
+	for i, component := range listComponents(current) {
+		to := targets[i].config
+		if component.config == nil && to == nil {
+			continue
+		}
+		if component.config != nil && to != nil && *component.config == *to {
+			fmt.Fprintf(w, "  %s: %s (unchanged)\n", component.label, describe(to))
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %s -> %s\n", component.label, describe(component.config), describe(to))
+	}
+}
+
+// printCostDelta prints the estimated cost before and after the change, if there is price data for both.
+// It returns the estimated hourly cost after the change.
+func printCostDelta(w io.Writer, pricing *Pricing, current, target clusters.Components) (float64, error) {
+	targetCost, err := pricing.HourlyCost(target)
+	if err != nil {
+		fmt.Fprintf(w, "[SKIP] Cost delta is not available: %v\n", err)
+		return 0, err
+	}
+	currentCost, err := pricing.HourlyCost(current)
+	if err != nil {
+		fmt.Fprintf(w, "[SKIP] Cost delta is not available: %v\n", err)
+		return targetCost, nil
+	}
+
+	currency := pricing.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	delta := targetCost - currentCost
+	fmt.Fprintf(w, "Estimated cost: %.2f -> %.2f %s/hour (%+.2f %s/hour, %+.2f %s/month)\n",
+		currentCost, targetCost, currency, delta, currency, delta*HoursPerMonth, currency)
+
+	return targetCost, nil
+}
+
+// waitForNodes waits until the cluster is AVAILABLE and its nodes match the target and are NORMAL,
+// printing the progress whenever it changes, and records the cluster in the inventory.
+func waitForNodes(ctx context.Context, api ClusterAPI, store ClusterStore, projectID, clusterID string, target clusters.Components, wait WaitOptions, w io.Writer) error {
+	fmt.Fprintf(w, "Waiting for the nodes of cluster %s to settle...\n", clusterID)
+	var cluster *clusters.Cluster
+	var lastProgress string
+	err := util.WaitUntil(ctx, wait.PollInterval, wait.WaitTimeout, func() (bool, error) {
+		var err error
+		cluster, err = api.GetCluster(ctx, projectID, clusterID)
+		if err != nil {
+			return false, err
+		}
+
+		settled, progress := nodeProgress(cluster, target)
+		if progress != lastProgress {
+			fmt.Fprintf(w, "  %s\n", progress)
+			lastProgress = progress
+		}
+		return settled, nil
+	})
+	if err != nil {
+		return fmt.Errorf("nodes of cluster %s did not settle: %w", clusterID, err)
+	}
+	if cluster.ProjectID == "" {
+		cluster.ProjectID = projectID
+	}
+
+	if err := store.StoreClusters(ctx, clusters.Clusters{*cluster}); err != nil {
+		return fmt.Errorf("cluster %s is scaled, but failed to record it: %w", clusterID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is %s with %s and recorded in the inventory\n", cluster.ID, cluster.Name, cluster.Status.ClusterStatus, clusters.ComponentsFromNodeMap(cluster.Status.NodeMap))
+
+	return nil
+}
+
+// nodeProgress reports whether the nodes of the cluster have settled to the target,
+// along with the progress in a human readable form, e.g. "AVAILABLE: TiDB 2/2, TiKV 4/6 NORMAL".
+func nodeProgress(cluster *clusters.Cluster, target clusters.Components) (bool, string) {
+	settled := cluster.Status.ClusterStatus == clusters.ClusterStatusAvailable
+	nodes := map[string]clusters.Nodes{
+		clusters.ComponentTiDB:    cluster.Status.NodeMap.Tidb,
+		clusters.ComponentTiKV:    cluster.Status.NodeMap.Tikv,
+		clusters.ComponentTiFlash: cluster.Status.NodeMap.Tiflash,
+	}
+
+	var parts []string
+	for _, component := range listComponents(target) {
+		var want clusters.ComponentConfig
+		if component.config != nil {
+			want = *component.config
+		}
+		if want.NodeQuantity == 0 && len(nodes[component.name]) == 0 {
+			continue
+		}
+
+		var ready int
+		for _, node := range nodes[component.name] {
+			if node.Status == NodeStatusNormal && node.NodeSize == want.NodeSize && (want.StorageSizeGib == 0 || node.StorageSizeGib == want.StorageSizeGib) {
+				ready++
+			}
+		}
+		if ready != want.NodeQuantity || len(nodes[component.name]) != want.NodeQuantity {
+			settled = false
+		}
+		parts = append(parts, fmt.Sprintf("%s %d/%d", component.label, ready, want.NodeQuantity))
+	}
+
+	return settled, fmt.Sprintf("%s: %s %s", cluster.Status.ClusterStatus, strings.Join(parts, ", "), NodeStatusNormal)
+}
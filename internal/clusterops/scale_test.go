@@ -0,0 +1,154 @@
+package clusterops
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestChanges_Apply(t *testing.T) {
+	current := clusters.Components{
+		TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+		TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+	}
+
+	tests := []struct {
+		name    string
+		changes Changes
+		want    clusters.Components
+		wantErr string
+	}{
+		{
+			name:    "scale out tikv and resize tidb",
+			changes: Changes{TiDB: ComponentChange{NodeSize: "16C32G"}, TiKV: ComponentChange{NodeQuantity: intPtr(6)}},
+			want: clusters.Components{
+				TiDB: &clusters.ComponentConfig{NodeSize: "16C32G", NodeQuantity: 2},
+				TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 6},
+			},
+		},
+		{
+			name:    "add tiflash",
+			changes: Changes{TiFlash: ComponentChange{NodeSize: "8C64G", NodeQuantity: intPtr(2), StorageSizeGib: 500}},
+			want: clusters.Components{
+				TiDB:    current.TiDB,
+				TiKV:    current.TiKV,
+				TiFlash: &clusters.ComponentConfig{NodeSize: "8C64G", StorageSizeGib: 500, NodeQuantity: 2},
+			},
+		},
+		{
+			name:    "add tiflash without size",
+			changes: Changes{TiFlash: ComponentChange{NodeQuantity: intPtr(2)}},
+			wantErr: "tiflash has no nodes",
+		},
+		{
+			name:    "remove tidb",
+			changes: Changes{TiDB: ComponentChange{NodeQuantity: intPtr(0)}},
+			wantErr: "tidb node quantity must be positive",
+		},
+		{
+			name:    "decrease storage",
+			changes: Changes{TiKV: ComponentChange{StorageSizeGib: 200}},
+			wantErr: "tikv storage size cannot be decreased: 500 GiB to 200 GiB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.changes.Apply(current)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	ctx := context.Background()
+	current := clusters.Components{
+		TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+		TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+	}
+	newAPI := func(pollsLeft int) *fakeAPI {
+		api := newFakeAPI(pollsLeft)
+		api.clusters["c1"] = &clusters.Cluster{
+			ID: "c1", ProjectID: "p1", Name: "team-a-main", ClusterType: "DEDICATED", CloudProvider: "AWS", Region: "us-west-2",
+			Status: clusters.ClusterStatus{ClusterStatus: clusters.ClusterStatusAvailable, NodeMap: testNodeMap(current)},
+		}
+		return api
+	}
+	policy := Policy{
+		Pricing: &Pricing{
+			NodeHourly:       map[string]map[string]float64{"tidb": {"8C16G": 1}, "tikv": {"8C32G": 2}},
+			StorageGibHourly: map[string]float64{"tikv": 0.001},
+		},
+		Limits: Limits{Default: ProjectLimits{MaxNodes: map[string]int{"tikv": 6}}},
+	}
+	scaleOut := ScaleParams{ProjectID: "p1", ClusterID: "c1", Changes: Changes{TiKV: ComponentChange{NodeQuantity: intPtr(6)}}}
+	wait := WaitOptions{PollInterval: time.Millisecond, WaitTimeout: time.Second}
+
+	t.Run("dry run", func(t *testing.T) {
+		api, store := newAPI(3), &fakeStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Scale(ctx, api, store, scaleOut, policy, wait, true, &buf))
+		require.Contains(t, buf.String(), "  TiDB: 2 x 8C16G (unchanged)\n")
+		require.Contains(t, buf.String(), "  TiKV: 3 x 8C32G (500 GiB) -> 6 x 8C32G (500 GiB)\n")
+		require.Contains(t, buf.String(), "Estimated cost: 9.50 -> 17.00 USD/hour (+7.50 USD/hour, +5475.00 USD/month)")
+		require.Contains(t, buf.String(), "[DRY RUN] Would update cluster c1 (team-a-main) with TiKV 6 x 8C32G (500 GiB)")
+		require.Empty(t, api.updated)
+	})
+
+	t.Run("scaled and recorded", func(t *testing.T) {
+		api, store := newAPI(3), &fakeStore{}
+		var buf bytes.Buffer
+		require.NoError(t, Scale(ctx, api, store, scaleOut, Policy{}, wait, false, &buf))
+		require.Len(t, api.updated, 1)
+		require.Nil(t, api.updated[0].Config.Components.TiDB)
+		require.Equal(t, 6, api.updated[0].Config.Components.TiKV.NodeQuantity)
+		require.Contains(t, buf.String(), "[SKIP] Cost delta is not available: no price data")
+		require.Contains(t, buf.String(), "  MODIFYING: TiDB 2/2, TiKV 6/6 NORMAL\n")
+		require.Contains(t, buf.String(), "  AVAILABLE: TiDB 2/2, TiKV 6/6 NORMAL\n")
+		require.Len(t, store.stored, 1)
+		require.Len(t, store.stored[0].Status.NodeMap.Tikv, 6)
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		api := newAPI(1)
+		var buf bytes.Buffer
+		p := ScaleParams{ProjectID: "p1", ClusterID: "c1", Changes: Changes{TiDB: ComponentChange{NodeQuantity: intPtr(2)}}}
+		require.NoError(t, Scale(ctx, api, &fakeStore{}, p, policy, wait, false, &buf))
+		require.Contains(t, buf.String(), "[SKIP] Cluster c1 (team-a-main) already has")
+		require.Empty(t, api.updated)
+	})
+
+	t.Run("exceeds the limits", func(t *testing.T) {
+		api := newAPI(1)
+		p := ScaleParams{ProjectID: "p1", ClusterID: "c1", Changes: Changes{TiKV: ComponentChange{NodeQuantity: intPtr(9)}}}
+		err := Scale(ctx, api, &fakeStore{}, p, policy, wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "exceeds the limits of project p1: tikv node_quantity 9 exceeds the limit 6")
+		require.Empty(t, api.updated)
+	})
+
+	t.Run("rejected by the region constraints", func(t *testing.T) {
+		api := newAPI(1)
+		p := ScaleParams{ProjectID: "p1", ClusterID: "c1", Changes: Changes{TiKV: ComponentChange{NodeQuantity: intPtr(4)}}}
+		err := Scale(ctx, api, &fakeStore{}, p, Policy{}, wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "tikv node_quantity 4 of 8C32G is not allowed")
+		require.Empty(t, api.updated)
+	})
+
+	t.Run("cluster is not available", func(t *testing.T) {
+		api := newAPI(1 << 30)
+		api.clusters["c1"].Status.ClusterStatus = "PAUSED"
+		err := Scale(ctx, api, &fakeStore{}, scaleOut, Policy{}, wait, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "cluster c1 is PAUSED")
+	})
+}
@@ -0,0 +1,63 @@
+package clusters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Components is the node configuration of each component of a cluster, as sent to the TiDB Cloud API
+// to create, scale or restore a cluster.
+type Components struct {
+	TiDB    *ComponentConfig `json:"tidb,omitempty"`
+	TiKV    *ComponentConfig `json:"tikv,omitempty"`
+	TiFlash *ComponentConfig `json:"tiflash,omitempty"`
+}
+
+// ComponentConfig is the node configuration of a component.
+type ComponentConfig struct {
+	NodeSize       string `json:"node_size"`
+	StorageSizeGib int    `json:"storage_size_gib,omitempty"` // Only for TiKV and TiFlash
+	NodeQuantity   int    `json:"node_quantity"`
+}
+
+// ComponentsFromNodeMap returns the node configuration of the node map. Components without nodes are nil.
+// Nodes of a component are expected to have the same size, so the first node is taken as representative.
+func ComponentsFromNodeMap(nodeMap NodeMap) Components {
+	convert := func(nodes Nodes) *ComponentConfig {
+		if len(nodes) == 0 {
+			return nil
+		}
+		return &ComponentConfig{NodeSize: nodes[0].NodeSize, StorageSizeGib: nodes[0].StorageSizeGib, NodeQuantity: len(nodes)}
+	}
+
+	return Components{
+		TiDB:    convert(nodeMap.Tidb),
+		TiKV:    convert(nodeMap.Tikv),
+		TiFlash: convert(nodeMap.Tiflash),
+	}
+}
+
+// String returns the node configuration in a human readable form, e.g. "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)".
+func (c Components) String() string {
+	var parts []string
+	for _, component := range []struct {
+		label  string
+		config *ComponentConfig
+	}{{"TiDB", c.TiDB}, {"TiKV", c.TiKV}, {"TiFlash", c.TiFlash}} {
+		if component.config == nil {
+			continue
+		}
+		parts = append(parts, component.label+" "+component.config.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// String returns the node configuration in a human readable form, e.g. "3 x 8C32G (500 GiB)".
+func (c ComponentConfig) String() string {
+	s := fmt.Sprintf("%d x %s", c.NodeQuantity, c.NodeSize)
+	if c.StorageSizeGib > 0 {
+		s += fmt.Sprintf(" (%d GiB)", c.StorageSizeGib)
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package clusters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentsFromNodeMap(t *testing.T) {
+	nodeMap := NodeMap{
+		Tidb: Nodes{{NodeSize: "8C16G"}, {NodeSize: "8C16G"}},
+		Tikv: Nodes{{NodeSize: "8C32G", StorageSizeGib: 500}, {NodeSize: "8C32G", StorageSizeGib: 500}, {NodeSize: "8C32G", StorageSizeGib: 500}},
+	}
+
+	components := ComponentsFromNodeMap(nodeMap)
+	require.Equal(t, &ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2}, components.TiDB)
+	require.Equal(t, &ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3}, components.TiKV)
+	require.Nil(t, components.TiFlash)
+	require.Equal(t, "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)", components.String())
+
+	require.Empty(t, ComponentsFromNodeMap(NodeMap{}).String())
+}
@@ -3,7 +3,6 @@ package clusters
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,9 +10,8 @@ import (
 	"strconv"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
-
 	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
 )
 
 // Cluster represents the response structure for listing clusters.
@@ -61,6 +59,16 @@ type Node struct {
 // Nodes is a slice of Node.
 type Nodes []Node
 
+// Component names of the nodes in the node map
+const (
+	ComponentTiDB    = "tidb"
+	ComponentTiKV    = "tikv"
+	ComponentTiFlash = "tiflash"
+)
+
+// ClusterStatusAvailable is the status of a cluster that is ready to use.
+const ClusterStatusAvailable = "AVAILABLE"
+
 // ListClustersResponse represents the successful response structure from the TiDB Cloud ListClustersOfProject API.
 type ListClustersResponse struct {
 	Items Clusters `json:"items,omitempty"`
@@ -68,11 +76,7 @@ type ListClustersResponse struct {
 }
 
 // ListClusterResponseError represents the error response from the TiDB Cloud API.
-type ListClusterResponseError struct {
-	Message string   `json:"message,omitempty"`
-	Code    int      `json:"code,omitempty"`
-	Details []string `json:"details,omitempty"`
-}
+type ListClusterResponseError = util.TiDBCloudAPIError
 
 // ClusterFetcher defines an interface for fetching cluster metadata from a remote source.
 type ClusterFetcher interface {
@@ -85,8 +89,6 @@ type APIClusterFetcher struct {
 	EndpointBase string
 }
 
-const defaultAPIEndpointBase = "https://api.tidbcloud.com/api/v1beta"
-
 // NewAPIClusterFetcher returns a new APIClusterFetcher with the given HTTP client and API endpoint base URL.
 // This function allows for injection of custom clients for testing and tracing.
 func NewAPIClusterFetcher(client *http.Client, endpointBase string) *APIClusterFetcher {
@@ -130,27 +132,39 @@ func (f *APIClusterFetcher) FetchClusters(ctx context.Context, projectID string,
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		var listClustersResponse ListClustersResponse
-		if err := json.NewDecoder(resp.Body).Decode(&listClustersResponse); err != nil {
-			return nil, 0, fmt.Errorf("succeeded to request, but failed to decode response from TiDB Cloud API: %w", err)
-		}
+	var listClustersResponse ListClustersResponse
+	if err := util.DecodeTiDBCloudResponse(resp, apiEndpoint, &listClustersResponse); err != nil {
+		return nil, 0, err
+	}
 
-		return listClustersResponse.Items, listClustersResponse.Total, nil
+	return listClustersResponse.Items, listClustersResponse.Total, nil
+}
+
+// FetchCluster retrieves a single cluster of a project from the TiDB Cloud API.
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Cluster/operation/GetCluster
+func (f *APIClusterFetcher) FetchCluster(ctx context.Context, projectID, clusterID string) (*Cluster, error) {
+	apiEndpoint, err := url.JoinPath(f.EndpointBase, "projects", projectID, "clusters", clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct API endpoint URL started with %s with projectID %s and clusterID %s: %w", f.EndpointBase, projectID, clusterID, err)
 	}
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, 0, errors.New("unauthorized: check your API key")
-	} else if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, 0, errors.New("rate limit: retry after some minutes. See https://docs.pingcap.com/tidbcloud/api/v1beta/#section/Rate-Limiting")
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var apiError ListClusterResponseError
-	if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode error response from TiDB Cloud API: %w, status: %s", err, resp.Status)
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var cluster Cluster
+	if err := util.DecodeTiDBCloudResponse(resp, apiEndpoint, &cluster); err != nil {
+		return nil, err
 	}
 
-	return nil, 0, fmt.Errorf("error from TiDB Cloud API: %s (code: %d, details: %v, endpoint: %s), status: %s", apiError.Message, apiError.Code, apiError.Details, apiEndpoint, resp.Status)
+	return &cluster, nil
 }
 
 // ClusterStore defines an interface for storing cluster metadata.
@@ -170,22 +184,9 @@ type DBClusterStore struct {
 // It opens a connection to the database, verifies connectivity, and prepares SQLC-generated query methods.
 // Returns an error if the connection fails or cannot be verified.
 func NewDBClusterStore(dsn string, poolConfig *db.PoolConfig) (*DBClusterStore, error) {
-	conn, err := sql.Open("mysql", dsn)
+	conn, err := db.Open(dsn, poolConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	if poolConfig == nil {
-		poolConfig = db.NewPoolConfig()
-	}
-	conn.SetMaxOpenConns(poolConfig.MaxOpenConns)
-	conn.SetMaxIdleConns(poolConfig.MaxIdleConns)
-	conn.SetConnMaxLifetime(poolConfig.ConnMaxLifetime)
-
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	if err := conn.PingContext(timeoutCtx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
 	return &DBClusterStore{
@@ -195,6 +196,7 @@ func NewDBClusterStore(dsn string, poolConfig *db.PoolConfig) (*DBClusterStore,
 }
 
 // StoreClusters inserts or updates the given list of clusters into the database within a transaction scope.
+// The stored node map of each cluster is replaced with the given one.
 // If any operation fails, the transaction will be rolled back and the error returned.
 // This method is a no-op if the input slice is empty.
 func (s *DBClusterStore) StoreClusters(ctx context.Context, clusters Clusters) error {
@@ -232,6 +234,14 @@ func (s *DBClusterStore) StoreClusters(ctx context.Context, clusters Clusters) e
 			}
 			return upsertErr
 		}
+
+		if err := storeClusterNodes(ctx, qtx, cluster); err != nil {
+			nodesErr := fmt.Errorf("failed to store nodes of cluster %s: %w", cluster.ID, err)
+			if err := tx.Rollback(); err != nil {
+				return errors.Join(nodesErr, fmt.Errorf("failed to rollback transaction: %w", err))
+			}
+			return nodesErr
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -241,6 +251,134 @@ func (s *DBClusterStore) StoreClusters(ctx context.Context, clusters Clusters) e
 	return nil
 }
 
+// storeClusterNodes replaces the stored node map of the cluster.
+func storeClusterNodes(ctx context.Context, q *db.Queries, cluster Cluster) error {
+	params, err := newInsertClusterNodeParams(cluster)
+	if err != nil {
+		return err
+	}
+
+	if err := q.DeleteClusterNodes(ctx, cluster.ID); err != nil {
+		return err
+	}
+	for _, p := range params {
+		if err := q.InsertClusterNode(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newInsertClusterNodeParams(cluster Cluster) ([]db.InsertClusterNodeParams, error) {
+	components := []struct {
+		name  string
+		nodes Nodes
+	}{
+		{ComponentTiDB, cluster.Status.NodeMap.Tidb},
+		{ComponentTiKV, cluster.Status.NodeMap.Tikv},
+		{ComponentTiFlash, cluster.Status.NodeMap.Tiflash},
+	}
+
+	var params []db.InsertClusterNodeParams
+	for _, component := range components {
+		for _, node := range component.nodes {
+			var ramBytes int64
+			if node.RAMBytes != "" {
+				var err error
+				ramBytes, err = strconv.ParseInt(node.RAMBytes, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ram_bytes format for node %s: %w", node.NodeName, err)
+				}
+			}
+
+			params = append(params, db.InsertClusterNodeParams{
+				ClusterID:        cluster.ID,
+				Component:        component.name,
+				NodeName:         node.NodeName,
+				AvailabilityZone: node.AvailabilityZone,
+				NodeSize:         node.NodeSize,
+				VcpuNum:          int32(node.VcpuNum),
+				RamBytes:         ramBytes,
+				StorageSizeGib:   int32(node.StorageSizeGib),
+				NodeStatus:       node.Status,
+			})
+		}
+	}
+
+	return params, nil
+}
+
+// GetProjectID returns the project ID of the stored cluster.
+func (s *DBClusterStore) GetProjectID(ctx context.Context, clusterID string) (string, error) {
+	cluster, err := s.Queries.GetCluster(ctx, clusterID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("cluster %s not found, run fetch-clusters first", clusterID)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get cluster %s: %w", clusterID, err)
+	}
+
+	return cluster.ProjectID, nil
+}
+
+// GetNodeMap returns the stored node map of the cluster, which is kept after the cluster is deleted.
+func (s *DBClusterStore) GetNodeMap(ctx context.Context, clusterID string) (NodeMap, error) {
+	rows, err := s.Queries.ListClusterNodes(ctx, clusterID)
+	if err != nil {
+		return NodeMap{}, fmt.Errorf("failed to list nodes of cluster %s: %w", clusterID, err)
+	}
+
+	return newNodeMap(rows), nil
+}
+
+// ListNodeMaps returns the stored node maps of the clusters that are not marked as deleted, keyed by cluster ID.
+func (s *DBClusterStore) ListNodeMaps(ctx context.Context) (map[string]NodeMap, error) {
+	rows, err := s.Queries.ListActiveClusterNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of active clusters: %w", err)
+	}
+
+	rowsByCluster := make(map[string][]db.ClusterNode)
+	for _, row := range rows {
+		rowsByCluster[row.ClusterID] = append(rowsByCluster[row.ClusterID], row)
+	}
+
+	nodeMaps := make(map[string]NodeMap, len(rowsByCluster))
+	for clusterID, rows := range rowsByCluster {
+		nodeMaps[clusterID] = newNodeMap(rows)
+	}
+
+	return nodeMaps, nil
+}
+
+func newNodeMap(rows []db.ClusterNode) NodeMap {
+	var nodeMap NodeMap
+	for _, row := range rows {
+		node := Node{
+			NodeName:         row.NodeName,
+			AvailabilityZone: row.AvailabilityZone,
+			NodeSize:         row.NodeSize,
+			VcpuNum:          int(row.VcpuNum),
+			StorageSizeGib:   int(row.StorageSizeGib),
+			Status:           row.NodeStatus,
+		}
+		if row.RamBytes > 0 {
+			node.RAMBytes = strconv.FormatInt(row.RamBytes, 10)
+		}
+
+		switch row.Component {
+		case ComponentTiDB:
+			nodeMap.Tidb = append(nodeMap.Tidb, node)
+		case ComponentTiKV:
+			nodeMap.Tikv = append(nodeMap.Tikv, node)
+		case ComponentTiFlash:
+			nodeMap.Tiflash = append(nodeMap.Tiflash, node)
+		}
+	}
+
+	return nodeMap
+}
+
 func (s *DBClusterStore) MarkStaleClustersAsDeleted(ctx context.Context, projectID string, syncedAt time.Time) (rowsAffected int64, err error) {
 	tx, err := s.conn.Begin()
 	if err != nil {
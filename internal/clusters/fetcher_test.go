@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/sgykfjsm/msk/internal/db"
 	"github.com/stretchr/testify/require"
 )
 
@@ -129,3 +130,69 @@ func TestAPIClusterFetcher_FetchClusters_DecodeErrorResponseError(t *testing.T)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to decode error response from TiDB Cloud API")
 }
+
+func TestAPIClusterFetcher_FetchCluster(t *testing.T) {
+	expected := Cluster{
+		ID:        "c1",
+		ProjectID: "p1",
+		Name:      "restored",
+		Status:    ClusterStatus{ClusterStatus: ClusterStatusAvailable},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/projects/p1/clusters/c1", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	fetcher := NewAPIClusterFetcher(server.Client(), server.URL)
+	cluster, err := fetcher.FetchCluster(context.Background(), "p1", "c1")
+
+	require.NoError(t, err)
+	require.Equal(t, expected, *cluster)
+}
+
+func TestNodeMapRoundTrip(t *testing.T) {
+	cluster := Cluster{
+		ID: "c1",
+		Status: ClusterStatus{
+			NodeMap: NodeMap{
+				Tidb: Nodes{
+					{NodeName: "tidb-0", AvailabilityZone: "us-west-2a", NodeSize: "8C16G", VcpuNum: 8, RAMBytes: "17179869184", Status: "NORMAL"},
+				},
+				Tikv: Nodes{
+					{NodeName: "tikv-0", AvailabilityZone: "us-west-2a", NodeSize: "8C32G", VcpuNum: 8, StorageSizeGib: 500, Status: "NORMAL"},
+					{NodeName: "tikv-1", AvailabilityZone: "us-west-2b", NodeSize: "8C32G", VcpuNum: 8, StorageSizeGib: 500, Status: "NORMAL"},
+				},
+			},
+		},
+	}
+
+	params, err := newInsertClusterNodeParams(cluster)
+	require.NoError(t, err)
+	require.Len(t, params, 3)
+	require.Equal(t, ComponentTiDB, params[0].Component)
+	require.Equal(t, int64(17179869184), params[0].RamBytes)
+
+	rows := make([]db.ClusterNode, 0, len(params))
+	for _, p := range params {
+		rows = append(rows, db.ClusterNode{
+			ClusterID:        p.ClusterID,
+			Component:        p.Component,
+			NodeName:         p.NodeName,
+			AvailabilityZone: p.AvailabilityZone,
+			NodeSize:         p.NodeSize,
+			VcpuNum:          p.VcpuNum,
+			RamBytes:         p.RamBytes,
+			StorageSizeGib:   p.StorageSizeGib,
+			NodeStatus:       p.NodeStatus,
+		})
+	}
+	require.Equal(t, cluster.Status.NodeMap, newNodeMap(rows))
+
+	_, err = newInsertClusterNodeParams(Cluster{ID: "c2", Status: ClusterStatus{NodeMap: NodeMap{Tidb: Nodes{{NodeName: "tidb-0", RAMBytes: "16G"}}}}})
+	require.ErrorContains(t, err, "invalid ram_bytes format for node tidb-0")
+}
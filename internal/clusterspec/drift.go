@@ -0,0 +1,253 @@
+package clusterspec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+)
+
+// Kind is the kind of a difference between the specs and the clusters.
+type Kind string
+
+const (
+	KindAdded     Kind = "added"     // Declared in a spec, but the cluster does not exist yet
+	KindRemoved   Kind = "removed"   // Declared in a spec, but the cluster has been deleted
+	KindConfig    Kind = "config"    // The cluster type, cloud provider or region differs
+	KindVersion   Kind = "version"   // The TiDB version differs
+	KindTopology  Kind = "topology"  // The node size, quantity or storage of a component differs
+	KindUnmanaged Kind = "unmanaged" // The cluster exists, but is not declared in any spec
+)
+
+// Drift is a difference between a spec and the stored state of the cluster.
+type Drift struct {
+	Kind        Kind   `json:"kind"`
+	ProjectID   string `json:"project_id"`
+	ClusterName string `json:"cluster_name"`
+	ClusterID   string `json:"cluster_id,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Spec        string `json:"spec,omitempty"`
+	Actual      string `json:"actual,omitempty"`
+	Source      string `json:"source,omitempty"` // The spec file, if any
+}
+
+// ActualCluster is the stored state of a cluster, as fetched by fetch-clusters.
+type ActualCluster struct {
+	ID            string
+	ProjectID     string
+	Name          string
+	ClusterType   string
+	CloudProvider string
+	Region        string
+	TiDBVersion   string
+	IsDeleted     bool
+	NodeMap       clusters.NodeMap
+}
+
+// Inventory defines an interface for reading the stored clusters.
+type Inventory interface {
+	ListClusters(ctx context.Context) ([]ActualCluster, error)
+}
+
+// DBInventory implements Inventory on top of the tables filled by fetch-clusters.
+type DBInventory struct {
+	clusters *clusters.DBClusterStore
+}
+
+// NewDBInventory returns a DBInventory reading with the given queries. The caller owns the connection under them.
+func NewDBInventory(queries *db.Queries) *DBInventory {
+	return &DBInventory{clusters: &clusters.DBClusterStore{Queries: queries}}
+}
+
+// ListClusters returns all stored clusters, including the deleted ones, with the node map of the active ones.
+func (s *DBInventory) ListClusters(ctx context.Context) ([]ActualCluster, error) {
+	rows, err := s.clusters.Queries.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	nodeMaps, err := s.clusters.ListNodeMaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := make([]ActualCluster, 0, len(rows))
+	for _, row := range rows {
+		actual = append(actual, ActualCluster{
+			ID:            row.ID,
+			ProjectID:     row.ProjectID,
+			Name:          row.Name,
+			ClusterType:   row.ClusterType,
+			CloudProvider: row.CloudProvider,
+			Region:        row.Region,
+			TiDBVersion:   row.TidbVersion,
+			IsDeleted:     row.IsDeleted,
+			NodeMap:       nodeMaps[row.ID],
+		})
+	}
+
+	return actual, nil
+}
+
+// Compare returns the differences between the specs and the stored clusters.
+// Differences of the declared clusters come first in the order of the specs, followed by the unmanaged clusters.
+func Compare(specs []ClusterSpec, actual []ActualCluster) []Drift {
+	active := make(map[string]ActualCluster)
+	deleted := make(map[string]ActualCluster)
+	for _, c := range actual {
+		key := ClusterSpec{ProjectID: c.ProjectID, Name: c.Name}.Key()
+		if c.IsDeleted {
+			deleted[key] = c
+		} else if _, ok := active[key]; !ok {
+			active[key] = c
+		}
+	}
+
+	var drifts []Drift
+	declared := make(map[string]bool)
+	for _, spec := range specs {
+		declared[spec.Key()] = true
+		c, ok := active[spec.Key()]
+		if !ok {
+			drift := Drift{Kind: KindAdded, ProjectID: spec.ProjectID, ClusterName: spec.Name, Source: spec.Source}
+			if d, ok := deleted[spec.Key()]; ok {
+				drift.Kind = KindRemoved
+				drift.ClusterID = d.ID
+			}
+			drifts = append(drifts, drift)
+			continue
+		}
+
+		drifts = append(drifts, compareCluster(spec, c)...)
+	}
+
+	var unmanaged []Drift
+	for _, c := range actual {
+		key := ClusterSpec{ProjectID: c.ProjectID, Name: c.Name}.Key()
+		// A cluster is managed if it is the one matched with a spec. Another active cluster with the same name is not.
+		if c.IsDeleted || (declared[key] && active[key].ID == c.ID) {
+			continue
+		}
+		unmanaged = append(unmanaged, Drift{Kind: KindUnmanaged, ProjectID: c.ProjectID, ClusterName: c.Name, ClusterID: c.ID})
+	}
+	slices.SortFunc(unmanaged, func(a, b Drift) int {
+		return strings.Compare(a.ProjectID+"/"+a.ClusterName, b.ProjectID+"/"+b.ClusterName)
+	})
+
+	return append(drifts, unmanaged...)
+}
+
+func compareCluster(spec ClusterSpec, c ActualCluster) []Drift {
+	var drifts []Drift
+	add := func(kind Kind, field, want, got string) {
+		drifts = append(drifts, Drift{
+			Kind:        kind,
+			ProjectID:   spec.ProjectID,
+			ClusterName: spec.Name,
+			ClusterID:   c.ID,
+			Field:       field,
+			Spec:        want,
+			Actual:      got,
+			Source:      spec.Source,
+		})
+	}
+
+	for _, attr := range []struct{ field, want, got string }{
+		{"cluster_type", spec.ClusterType, c.ClusterType},
+		{"cloud_provider", spec.CloudProvider, c.CloudProvider},
+		{"region", spec.Region, c.Region},
+	} {
+		if attr.want != "" && !strings.EqualFold(attr.want, attr.got) {
+			add(KindConfig, attr.field, attr.want, attr.got)
+		}
+	}
+
+	if spec.TiDBVersion != "" && strings.TrimPrefix(spec.TiDBVersion, "v") != strings.TrimPrefix(c.TiDBVersion, "v") {
+		add(KindVersion, "tidb_version", spec.TiDBVersion, c.TiDBVersion)
+	}
+
+	if spec.Components.IsZero() {
+		return drifts
+	}
+	if len(c.NodeMap.Tidb) == 0 && len(c.NodeMap.Tikv) == 0 && len(c.NodeMap.Tiflash) == 0 {
+		add(KindTopology, "components", "declared", "unknown (run fetch-clusters)")
+		return drifts
+	}
+
+	for _, component := range []struct {
+		name  string
+		spec  *ComponentSpec
+		nodes clusters.Nodes
+	}{
+		{clusters.ComponentTiDB, spec.Components.TiDB, c.NodeMap.Tidb},
+		{clusters.ComponentTiKV, spec.Components.TiKV, c.NodeMap.Tikv},
+		{clusters.ComponentTiFlash, spec.Components.TiFlash, c.NodeMap.Tiflash},
+	} {
+		want := component.spec
+		if want == nil {
+			want = &ComponentSpec{}
+		}
+
+		if want.NodeQuantity != len(component.nodes) {
+			add(KindTopology, component.name+".node_quantity", strconv.Itoa(want.NodeQuantity), strconv.Itoa(len(component.nodes)))
+		}
+		if want.NodeQuantity == 0 || len(component.nodes) == 0 {
+			continue
+		}
+
+		sizes, storages := nodeSizes(component.nodes)
+		if sizes != want.NodeSize {
+			add(KindTopology, component.name+".node_size", want.NodeSize, sizes)
+		}
+		if want.StorageSizeGib > 0 && storages != strconv.Itoa(want.StorageSizeGib) {
+			add(KindTopology, component.name+".storage_size_gib", strconv.Itoa(want.StorageSizeGib), storages)
+		}
+	}
+
+	return drifts
+}
+
+// nodeSizes returns the distinct node sizes and storage sizes of the nodes, joined with a comma.
+func nodeSizes(nodes clusters.Nodes) (string, string) {
+	var sizes, storages []string
+	for _, node := range nodes {
+		if !slices.Contains(sizes, node.NodeSize) {
+			sizes = append(sizes, node.NodeSize)
+		}
+		if storage := strconv.Itoa(node.StorageSizeGib); !slices.Contains(storages, storage) {
+			storages = append(storages, storage)
+		}
+	}
+	slices.Sort(sizes)
+	slices.Sort(storages)
+
+	return strings.Join(sizes, ","), strings.Join(storages, ",")
+}
+
+// CountDrifts returns the number of differences that should fail the check.
+// Unmanaged clusters are counted only if includeUnmanaged is true.
+func CountDrifts(drifts []Drift, includeUnmanaged bool) int {
+	var count int
+	for _, d := range drifts {
+		if d.Kind != KindUnmanaged || includeUnmanaged {
+			count++
+		}
+	}
+	return count
+}
+
+// PrintDrifts writes the differences as a table.
+func PrintDrifts(w io.Writer, drifts []Drift) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tPROJECT\tCLUSTER\tID\tFIELD\tSPEC\tACTUAL")
+	for _, d := range drifts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Kind, d.ProjectID, d.ClusterName, d.ClusterID, d.Field, d.Spec, d.Actual)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,137 @@
+package clusterspec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+func nodes(n int, size string, storage int) clusters.Nodes {
+	var result clusters.Nodes
+	for range n {
+		result = append(result, clusters.Node{NodeSize: size, StorageSizeGib: storage})
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	spec := ClusterSpec{
+		ProjectID:     "p1",
+		Name:          "prod-main",
+		ClusterType:   "DEDICATED",
+		CloudProvider: "AWS",
+		Region:        "us-west-2",
+		TiDBVersion:   "v7.5.2",
+		Components: Components{
+			TiDB: &ComponentSpec{NodeSize: "8C16G", NodeQuantity: 2},
+			TiKV: &ComponentSpec{NodeSize: "8C32G", NodeQuantity: 3, StorageSizeGib: 500},
+		},
+		Source: "prod.yaml",
+	}
+	inSync := ActualCluster{
+		ID:            "c1",
+		ProjectID:     "p1",
+		Name:          "prod-main",
+		ClusterType:   "DEDICATED",
+		CloudProvider: "AWS",
+		Region:        "us-west-2",
+		TiDBVersion:   "7.5.2",
+		NodeMap:       clusters.NodeMap{Tidb: nodes(2, "8C16G", 0), Tikv: nodes(3, "8C32G", 500)},
+	}
+
+	tests := []struct {
+		name   string
+		specs  []ClusterSpec
+		actual []ActualCluster
+		want   []Drift
+	}{
+		{
+			name:   "in sync",
+			specs:  []ClusterSpec{spec},
+			actual: []ActualCluster{inSync},
+		},
+		{
+			name:  "added",
+			specs: []ClusterSpec{spec},
+			want:  []Drift{{Kind: KindAdded, ProjectID: "p1", ClusterName: "prod-main", Source: "prod.yaml"}},
+		},
+		{
+			name:   "removed",
+			specs:  []ClusterSpec{spec},
+			actual: []ActualCluster{{ID: "c0", ProjectID: "p1", Name: "prod-main", IsDeleted: true}},
+			want:   []Drift{{Kind: KindRemoved, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c0", Source: "prod.yaml"}},
+		},
+		{
+			name:  "version, config and topology",
+			specs: []ClusterSpec{spec},
+			actual: []ActualCluster{func() ActualCluster {
+				c := inSync
+				c.Region = "us-east-1"
+				c.TiDBVersion = "v8.1.0"
+				c.NodeMap = clusters.NodeMap{
+					Tidb:    append(nodes(1, "8C16G", 0), nodes(1, "16C32G", 0)...),
+					Tikv:    nodes(2, "8C32G", 1000),
+					Tiflash: nodes(1, "8C64G", 1024),
+				}
+				return c
+			}()},
+			want: []Drift{
+				{Kind: KindConfig, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "region", Spec: "us-west-2", Actual: "us-east-1", Source: "prod.yaml"},
+				{Kind: KindVersion, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tidb_version", Spec: "v7.5.2", Actual: "v8.1.0", Source: "prod.yaml"},
+				{Kind: KindTopology, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tidb.node_size", Spec: "8C16G", Actual: "16C32G,8C16G", Source: "prod.yaml"},
+				{Kind: KindTopology, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tikv.node_quantity", Spec: "3", Actual: "2", Source: "prod.yaml"},
+				{Kind: KindTopology, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tikv.storage_size_gib", Spec: "500", Actual: "1000", Source: "prod.yaml"},
+				{Kind: KindTopology, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tiflash.node_quantity", Spec: "0", Actual: "1", Source: "prod.yaml"},
+			},
+		},
+		{
+			name:  "node map not fetched",
+			specs: []ClusterSpec{spec},
+			actual: []ActualCluster{func() ActualCluster {
+				c := inSync
+				c.NodeMap = clusters.NodeMap{}
+				return c
+			}()},
+			want: []Drift{{Kind: KindTopology, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "components", Spec: "declared", Actual: "unknown (run fetch-clusters)", Source: "prod.yaml"}},
+		},
+		{
+			name:  "unmanaged",
+			specs: []ClusterSpec{{ProjectID: "p1", Name: "prod-main"}},
+			actual: []ActualCluster{
+				{ID: "c3", ProjectID: "p2", Name: "zeta"},
+				inSync,
+				{ID: "c2", ProjectID: "p1", Name: "prod-main"}, // Same name as the managed one
+				{ID: "c4", ProjectID: "p2", Name: "alpha"},
+				{ID: "c5", ProjectID: "p2", Name: "gone", IsDeleted: true},
+			},
+			want: []Drift{
+				{Kind: KindUnmanaged, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c2"},
+				{Kind: KindUnmanaged, ProjectID: "p2", ClusterName: "alpha", ClusterID: "c4"},
+				{Kind: KindUnmanaged, ProjectID: "p2", ClusterName: "zeta", ClusterID: "c3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Compare(tt.specs, tt.actual))
+		})
+	}
+}
+
+func TestCountDriftsAndPrint(t *testing.T) {
+	drifts := []Drift{
+		{Kind: KindVersion, ProjectID: "p1", ClusterName: "prod-main", ClusterID: "c1", Field: "tidb_version", Spec: "v7.5.2", Actual: "v8.1.0"},
+		{Kind: KindUnmanaged, ProjectID: "p2", ClusterName: "alpha", ClusterID: "c4"},
+	}
+	require.Equal(t, 1, CountDrifts(drifts, false))
+	require.Equal(t, 2, CountDrifts(drifts, true))
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintDrifts(&buf, drifts))
+	require.Contains(t, buf.String(), "KIND")
+	require.Contains(t, buf.String(), "unmanaged")
+	require.Contains(t, buf.String(), "v8.1.0")
+}
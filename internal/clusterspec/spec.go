@@ -0,0 +1,167 @@
+package clusterspec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// This module reads declarative cluster specs checked into git. A spec file is a YAML document like:
+//
+//	clusters:
+//	  - project_id: "1234567890"
+//	    name: prod-main
+//	    cluster_type: DEDICATED
+//	    cloud_provider: AWS
+//	    region: us-west-2
+//	    tidb_version: v7.5.2
+//	    components:
+//	      tidb: {node_size: 8C16G, node_quantity: 2}
+//	      tikv: {node_size: 8C32G, node_quantity: 3, storage_size_gib: 500}
+//
+// Clusters are identified by project ID and name, since their IDs are assigned by TiDB Cloud.
+// Empty attributes are not checked.
+
+// File is the content of a spec file.
+type File struct {
+	Clusters []ClusterSpec `yaml:"clusters"`
+}
+
+// ClusterSpec is the declared state of a cluster.
+type ClusterSpec struct {
+	ProjectID     string     `json:"project_id" yaml:"project_id"`
+	Name          string     `json:"name" yaml:"name"`
+	ClusterType   string     `json:"cluster_type,omitempty" yaml:"cluster_type,omitempty"`
+	CloudProvider string     `json:"cloud_provider,omitempty" yaml:"cloud_provider,omitempty"`
+	Region        string     `json:"region,omitempty" yaml:"region,omitempty"`
+	TiDBVersion   string     `json:"tidb_version,omitempty" yaml:"tidb_version,omitempty"`
+	Components    Components `json:"components,omitzero" yaml:"components,omitempty"`
+	Source        string     `json:"-" yaml:"-"` // The file the spec was read from
+}
+
+// Components is the declared node configuration of each component.
+// A nil component is declared to have no nodes, unless all of them are nil.
+type Components struct {
+	TiDB    *ComponentSpec `json:"tidb,omitempty" yaml:"tidb,omitempty"`
+	TiKV    *ComponentSpec `json:"tikv,omitempty" yaml:"tikv,omitempty"`
+	TiFlash *ComponentSpec `json:"tiflash,omitempty" yaml:"tiflash,omitempty"`
+}
+
+// ComponentSpec is the declared node configuration of a component.
+type ComponentSpec struct {
+	NodeSize       string `json:"node_size" yaml:"node_size"`
+	NodeQuantity   int    `json:"node_quantity" yaml:"node_quantity"`
+	StorageSizeGib int    `json:"storage_size_gib,omitempty" yaml:"storage_size_gib,omitempty"` // Only for TiKV and TiFlash
+}
+
+// Key returns the identity of the cluster, i.e. "<project ID>/<name>".
+func (c ClusterSpec) Key() string {
+	return c.ProjectID + "/" + c.Name
+}
+
+// IsZero reports whether no component is declared.
+func (c Components) IsZero() bool {
+	return c.TiDB == nil && c.TiKV == nil && c.TiFlash == nil
+}
+
+// Load reads the specs from the given files and directories. Directories are read non-recursively,
+// and only the files with the .yaml or .yml extension are read.
+// Returns an error if a cluster is declared more than once.
+func Load(paths []string) ([]ClusterSpec, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec directory %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	var specs []ClusterSpec
+	sources := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec %s: %w", file, err)
+		}
+
+		fileSpecs, err := Parse(bytes.NewReader(data), file)
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range fileSpecs {
+			if source, ok := sources[spec.Key()]; ok {
+				return nil, fmt.Errorf("cluster %s is declared in both %s and %s", spec.Key(), source, spec.Source)
+			}
+			sources[spec.Key()] = spec.Source
+			specs = append(specs, spec)
+		}
+	}
+
+	slices.SortFunc(specs, func(a, b ClusterSpec) int { return strings.Compare(a.Key(), b.Key()) })
+	return specs, nil
+}
+
+// Parse reads the specs of a single file. Unknown fields are rejected to catch typos.
+func Parse(r io.Reader, source string) ([]ClusterSpec, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", source, err)
+	}
+
+	for i := range file.Clusters {
+		file.Clusters[i].Source = source
+		if err := file.Clusters[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid spec #%d in %s: %w", i+1, source, err)
+		}
+	}
+
+	return file.Clusters, nil
+}
+
+func (c ClusterSpec) validate() error {
+	if c.ProjectID == "" || c.Name == "" {
+		return errors.New("project_id and name are required")
+	}
+	if c.Components.IsZero() {
+		return nil
+	}
+	if c.Components.TiDB == nil || c.Components.TiKV == nil {
+		return fmt.Errorf("cluster %s: tidb and tikv are required if components are declared", c.Name)
+	}
+
+	for name, component := range map[string]*ComponentSpec{"tidb": c.Components.TiDB, "tikv": c.Components.TiKV, "tiflash": c.Components.TiFlash} {
+		if component == nil {
+			continue
+		}
+		if component.NodeSize == "" || component.NodeQuantity <= 0 {
+			return fmt.Errorf("cluster %s: %s requires node_size and a positive node_quantity", c.Name, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package clusterspec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const prodSpec = `clusters:
+  - project_id: "p1"
+    name: prod-main
+    cluster_type: DEDICATED
+    cloud_provider: AWS
+    region: us-west-2
+    tidb_version: v7.5.2
+    components:
+      tidb: {node_size: 8C16G, node_quantity: 2}
+      tikv: {node_size: 8C32G, node_quantity: 3, storage_size_gib: 500}
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []ClusterSpec
+		wantErr string
+	}{
+		{
+			name:  "full spec",
+			input: prodSpec,
+			want: []ClusterSpec{{
+				ProjectID:     "p1",
+				Name:          "prod-main",
+				ClusterType:   "DEDICATED",
+				CloudProvider: "AWS",
+				Region:        "us-west-2",
+				TiDBVersion:   "v7.5.2",
+				Components: Components{
+					TiDB: &ComponentSpec{NodeSize: "8C16G", NodeQuantity: 2},
+					TiKV: &ComponentSpec{NodeSize: "8C32G", NodeQuantity: 3, StorageSizeGib: 500},
+				},
+				Source: "prod.yaml",
+			}},
+		},
+		{
+			name:  "empty file",
+			input: "",
+		},
+		{
+			name:    "unknown field",
+			input:   "clusters:\n  - project_id: p1\n    name: a\n    tidb_verison: v7.5.2\n",
+			wantErr: "field tidb_verison not found",
+		},
+		{
+			name:    "missing name",
+			input:   "clusters:\n  - project_id: p1\n",
+			wantErr: "invalid spec #1 in prod.yaml: project_id and name are required",
+		},
+		{
+			name:    "tikv missing",
+			input:   "clusters:\n  - project_id: p1\n    name: a\n    components:\n      tidb: {node_size: 8C16G, node_quantity: 2}\n",
+			wantErr: "tidb and tikv are required",
+		},
+		{
+			name:    "zero quantity",
+			input:   "clusters:\n  - project_id: p1\n    name: a\n    components:\n      tidb: {node_size: 8C16G, node_quantity: 2}\n      tikv: {node_size: 8C32G}\n",
+			wantErr: "tikv requires node_size and a positive node_quantity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := Parse(strings.NewReader(tt.input), "prod.yaml")
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, specs)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prod.yaml"), []byte(prodSpec), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev.yml"), []byte("clusters:\n  - project_id: p0\n    name: dev\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a spec"), 0o644))
+
+	specs, err := Load([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	require.Equal(t, "p0/dev", specs[0].Key())
+	require.Equal(t, "p1/prod-main", specs[1].Key())
+
+	duplicate := filepath.Join(t.TempDir(), "copy.yaml")
+	require.NoError(t, os.WriteFile(duplicate, []byte(prodSpec), 0o644))
+	_, err = Load([]string{dir, duplicate})
+	require.ErrorContains(t, err, "cluster p1/prod-main is declared in both")
+}
@@ -0,0 +1,156 @@
+package clusterspec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is the definition of a cluster to create. It has the attributes of a ClusterSpec at the top level,
+// plus the settings that are only used at creation:
+//
+//	project_id: "1234567890"
+//	name: team-a-main
+//	cloud_provider: AWS
+//	region: us-west-2
+//	port: 4000
+//	components:
+//	  tidb: {node_size: 8C16G, node_quantity: 2}
+//	  tikv: {node_size: 8C32G, node_quantity: 3, storage_size_gib: 500}
+//	ip_access_list:
+//	  - {cidr: 10.0.0.0/16, description: office}
+//
+// A template can be split into a reusable base and per-team overrides, see LoadTemplate.
+type Template struct {
+	ClusterSpec  `yaml:",inline"`
+	Port         int        `json:"port,omitempty" yaml:"port,omitempty"`
+	IPAccessList []IPAccess `json:"ip_access_list,omitempty" yaml:"ip_access_list,omitempty"`
+}
+
+// IPAccess is an entry of the IP access list of a cluster.
+type IPAccess struct {
+	CIDR        string `json:"cidr" yaml:"cidr"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// ClusterTypeDedicated is the only cluster type that can be created from a template.
+const ClusterTypeDedicated = "DEDICATED"
+
+// DefaultPort is the TiDB port of a cluster created from a template without a port.
+const DefaultPort = 4000
+
+// clusterNamePattern is the rule of the cluster name in the TiDB Cloud console:
+// 4-64 characters of letters, digits and hyphens, starting with a letter and ending with a letter or a digit.
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]{2,62}[A-Za-z0-9]$`)
+
+// LoadTemplate reads the template files and merges them in order. Mappings are merged recursively and
+// any other value, including a list, is replaced by the later file. This allows a reusable base template
+// to be combined with per-team overrides, e.g. `-f base.yaml -f team-a.yaml`.
+func LoadTemplate(paths []string) (Template, error) {
+	if len(paths) == 0 {
+		return Template{}, errors.New("no template file is given")
+	}
+
+	merged := make(map[string]any)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Template{}, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+
+		var values map[string]any
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return Template{}, fmt.Errorf("failed to parse template %s: %w", path, err)
+		}
+		mergeValues(merged, values)
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to merge templates: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // Unknown fields are rejected to catch typos
+	var t Template
+	if err := decoder.Decode(&t); err != nil {
+		return Template{}, fmt.Errorf("failed to parse templates %s: %w", strings.Join(paths, ", "), err)
+	}
+	t.Source = strings.Join(paths, ", ")
+
+	return t, nil
+}
+
+// mergeValues merges src into dst recursively.
+func mergeValues(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			// Copy not to share the nested maps with src
+			copied := make(map[string]any)
+			mergeValues(copied, srcMap)
+			value = copied
+		}
+		dst[key] = value
+	}
+}
+
+// Validate checks the template against the constraints that do not depend on the cloud provider and region,
+// and fills in the defaults of the cluster type and the port.
+func (t *Template) Validate() error {
+	if t.ProjectID == "" {
+		return errors.New("project_id is required")
+	}
+	if !clusterNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("name %q must be 4-64 characters of letters, digits and hyphens, starting with a letter and ending with a letter or a digit", t.Name)
+	}
+	if t.CloudProvider == "" || t.Region == "" {
+		return errors.New("cloud_provider and region are required")
+	}
+
+	if t.ClusterType == "" {
+		t.ClusterType = ClusterTypeDedicated
+	}
+	if !strings.EqualFold(t.ClusterType, ClusterTypeDedicated) {
+		return fmt.Errorf("cluster_type %s is not supported, only %s clusters can be created", t.ClusterType, ClusterTypeDedicated)
+	}
+
+	if t.Port == 0 {
+		t.Port = DefaultPort
+	}
+	if t.Port < 1024 || t.Port > 65535 {
+		return fmt.Errorf("port %d is out of range (1024-65535)", t.Port)
+	}
+
+	if t.Components.TiDB == nil || t.Components.TiKV == nil {
+		return errors.New("components.tidb and components.tikv are required")
+	}
+	if err := t.ClusterSpec.validate(); err != nil {
+		return err
+	}
+	if t.Components.TiKV.StorageSizeGib <= 0 {
+		return errors.New("components.tikv.storage_size_gib is required")
+	}
+	if t.Components.TiFlash != nil && t.Components.TiFlash.StorageSizeGib <= 0 {
+		return errors.New("components.tiflash.storage_size_gib is required")
+	}
+
+	for _, entry := range t.IPAccessList {
+		if _, _, err := net.ParseCIDR(entry.CIDR); err != nil {
+			return fmt.Errorf("ip_access_list: cidr %q should be in CIDR notation, e.g., 192.168.1.0/24", entry.CIDR)
+		}
+	}
+
+	return nil
+}
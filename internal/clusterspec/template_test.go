@@ -0,0 +1,103 @@
+package clusterspec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const baseTemplate = `cluster_type: DEDICATED
+cloud_provider: AWS
+region: us-west-2
+components:
+  tidb: {node_size: 8C16G, node_quantity: 2}
+  tikv: {node_size: 8C32G, node_quantity: 3, storage_size_gib: 500}
+ip_access_list:
+  - {cidr: 10.0.0.0/8, description: corporate}
+`
+
+const teamOverride = `project_id: "p1"
+name: team-a-main
+components:
+  tikv: {node_quantity: 6}
+ip_access_list:
+  - {cidr: 10.1.0.0/16, description: team-a}
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadTemplate(t *testing.T) {
+	base := writeFile(t, "base.yaml", baseTemplate)
+	team := writeFile(t, "team-a.yaml", teamOverride)
+
+	tmpl, err := LoadTemplate([]string{base, team})
+	require.NoError(t, err)
+	require.Equal(t, "p1", tmpl.ProjectID)
+	require.Equal(t, "team-a-main", tmpl.Name)
+	require.Equal(t, "us-west-2", tmpl.Region)
+	require.Equal(t, &ComponentSpec{NodeSize: "8C16G", NodeQuantity: 2}, tmpl.Components.TiDB)
+	require.Equal(t, &ComponentSpec{NodeSize: "8C32G", NodeQuantity: 6, StorageSizeGib: 500}, tmpl.Components.TiKV)
+	require.Equal(t, []IPAccess{{CIDR: "10.1.0.0/16", Description: "team-a"}}, tmpl.IPAccessList) // Lists are replaced
+	require.NoError(t, tmpl.Validate())
+	require.Equal(t, DefaultPort, tmpl.Port)
+
+	_, err = LoadTemplate([]string{base, writeFile(t, "typo.yaml", "regoin: us-east-1\n")})
+	require.ErrorContains(t, err, "field regoin not found")
+
+	_, err = LoadTemplate(nil)
+	require.ErrorContains(t, err, "no template file")
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	valid := func() Template {
+		return Template{
+			ClusterSpec: ClusterSpec{
+				ProjectID:     "p1",
+				Name:          "team-a-main",
+				CloudProvider: "AWS",
+				Region:        "us-west-2",
+				Components: Components{
+					TiDB: &ComponentSpec{NodeSize: "8C16G", NodeQuantity: 2},
+					TiKV: &ComponentSpec{NodeSize: "8C32G", NodeQuantity: 3, StorageSizeGib: 500},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Template)
+		wantErr string
+	}{
+		{name: "valid", modify: func(t *Template) {}},
+		{name: "no project", modify: func(t *Template) { t.ProjectID = "" }, wantErr: "project_id is required"},
+		{name: "invalid name", modify: func(t *Template) { t.Name = "-team" }, wantErr: "must be 4-64 characters"},
+		{name: "no region", modify: func(t *Template) { t.Region = "" }, wantErr: "cloud_provider and region are required"},
+		{name: "serverless", modify: func(t *Template) { t.ClusterType = "SERVERLESS" }, wantErr: "only DEDICATED clusters can be created"},
+		{name: "port", modify: func(t *Template) { t.Port = 80 }, wantErr: "port 80 is out of range"},
+		{name: "no tikv", modify: func(t *Template) { t.Components.TiKV = nil }, wantErr: "components.tidb and components.tikv are required"},
+		{name: "no tikv storage", modify: func(t *Template) { t.Components.TiKV.StorageSizeGib = 0 }, wantErr: "components.tikv.storage_size_gib is required"},
+		{name: "invalid cidr", modify: func(t *Template) { t.IPAccessList = []IPAccess{{CIDR: "10.0.0.1"}} }, wantErr: `cidr "10.0.0.1" should be in CIDR notation`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := valid()
+			tt.modify(&tmpl)
+			err := tmpl.Validate()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ClusterTypeDedicated, tmpl.ClusterType)
+		})
+	}
+}
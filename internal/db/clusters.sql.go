@@ -11,6 +11,303 @@ import (
 	"time"
 )
 
+const deleteClusterNodes = `-- name: DeleteClusterNodes :exec
+DELETE FROM cluster_nodes
+WHERE cluster_id = ?
+`
+
+func (q *Queries) DeleteClusterNodes(ctx context.Context, clusterID string) error {
+	_, err := q.db.ExecContext(ctx, deleteClusterNodes, clusterID)
+	return err
+}
+
+const getCluster = `-- name: GetCluster :one
+SELECT id,
+    project_id,
+    name,
+    cluster_type,
+    cloud_provider,
+    region,
+    create_timestamp,
+    tidb_version,
+    cluster_status,
+    is_deleted,
+    created_at,
+    updated_at,
+    deleted_at
+FROM clusters
+WHERE id = ?
+`
+
+func (q *Queries) GetCluster(ctx context.Context, id string) (Cluster, error) {
+	row := q.db.QueryRowContext(ctx, getCluster, id)
+	var i Cluster
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.ClusterType,
+		&i.CloudProvider,
+		&i.Region,
+		&i.CreateTimestamp,
+		&i.TidbVersion,
+		&i.ClusterStatus,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const insertClusterNode = `-- name: InsertClusterNode :exec
+INSERT INTO cluster_nodes (
+        cluster_id,
+        component,
+        node_name,
+        availability_zone,
+        node_size,
+        vcpu_num,
+        ram_bytes,
+        storage_size_gib,
+        node_status
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type InsertClusterNodeParams struct {
+	ClusterID        string
+	Component        string
+	NodeName         string
+	AvailabilityZone string
+	NodeSize         string
+	VcpuNum          int32
+	RamBytes         int64
+	StorageSizeGib   int32
+	NodeStatus       string
+}
+
+func (q *Queries) InsertClusterNode(ctx context.Context, arg InsertClusterNodeParams) error {
+	_, err := q.db.ExecContext(ctx, insertClusterNode,
+		arg.ClusterID,
+		arg.Component,
+		arg.NodeName,
+		arg.AvailabilityZone,
+		arg.NodeSize,
+		arg.VcpuNum,
+		arg.RamBytes,
+		arg.StorageSizeGib,
+		arg.NodeStatus,
+	)
+	return err
+}
+
+const listActiveClusterNodes = `-- name: ListActiveClusterNodes :many
+SELECT n.cluster_id,
+    n.component,
+    n.node_name,
+    n.availability_zone,
+    n.node_size,
+    n.vcpu_num,
+    n.ram_bytes,
+    n.storage_size_gib,
+    n.node_status,
+    n.fetched_at
+FROM cluster_nodes n
+    JOIN clusters c ON c.id = n.cluster_id
+WHERE c.is_deleted = FALSE
+ORDER BY n.cluster_id,
+    n.component,
+    n.node_name
+`
+
+// ListActiveClusterNodes returns the nodes of the clusters that are not marked as deleted.
+func (q *Queries) ListActiveClusterNodes(ctx context.Context) ([]ClusterNode, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveClusterNodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClusterNode
+	for rows.Next() {
+		var i ClusterNode
+		if err := rows.Scan(
+			&i.ClusterID,
+			&i.Component,
+			&i.NodeName,
+			&i.AvailabilityZone,
+			&i.NodeSize,
+			&i.VcpuNum,
+			&i.RamBytes,
+			&i.StorageSizeGib,
+			&i.NodeStatus,
+			&i.FetchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClusterIDsByProject = `-- name: ListClusterIDsByProject :many
+SELECT id
+FROM clusters
+WHERE project_id = ?
+    AND is_deleted = FALSE
+ORDER BY id
+`
+
+func (q *Queries) ListClusterIDsByProject(ctx context.Context, projectID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listClusterIDsByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClusterNodes = `-- name: ListClusterNodes :many
+SELECT cluster_id,
+    component,
+    node_name,
+    availability_zone,
+    node_size,
+    vcpu_num,
+    ram_bytes,
+    storage_size_gib,
+    node_status,
+    fetched_at
+FROM cluster_nodes
+WHERE cluster_id = ?
+ORDER BY component,
+    node_name
+`
+
+func (q *Queries) ListClusterNodes(ctx context.Context, clusterID string) ([]ClusterNode, error) {
+	rows, err := q.db.QueryContext(ctx, listClusterNodes, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClusterNode
+	for rows.Next() {
+		var i ClusterNode
+		if err := rows.Scan(
+			&i.ClusterID,
+			&i.Component,
+			&i.NodeName,
+			&i.AvailabilityZone,
+			&i.NodeSize,
+			&i.VcpuNum,
+			&i.RamBytes,
+			&i.StorageSizeGib,
+			&i.NodeStatus,
+			&i.FetchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClusters = `-- name: ListClusters :many
+SELECT id,
+    project_id,
+    name,
+    cluster_type,
+    cloud_provider,
+    region,
+    create_timestamp,
+    tidb_version,
+    cluster_status,
+    is_deleted,
+    created_at,
+    updated_at,
+    deleted_at
+FROM clusters
+ORDER BY project_id,
+    name
+`
+
+// ListClusters returns all clusters including the ones marked as deleted.
+func (q *Queries) ListClusters(ctx context.Context) ([]Cluster, error) {
+	rows, err := q.db.QueryContext(ctx, listClusters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Cluster
+	for rows.Next() {
+		var i Cluster
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.ClusterType,
+			&i.CloudProvider,
+			&i.Region,
+			&i.CreateTimestamp,
+			&i.TidbVersion,
+			&i.ClusterStatus,
+			&i.IsDeleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markClusterAsDeleted = `-- name: MarkClusterAsDeleted :exec
+UPDATE clusters
+SET is_deleted = TRUE,
+    deleted_at = CURRENT_TIMESTAMP
+WHERE id = ?
+    AND is_deleted = FALSE
+`
+
+func (q *Queries) MarkClusterAsDeleted(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, markClusterAsDeleted, id)
+	return err
+}
+
 const markStaleClustersAsDeleted = `-- name: MarkStaleClustersAsDeleted :execresult
 UPDATE clusters
 SET is_deleted = TRUE,
@@ -25,13 +25,104 @@ type Cluster struct {
 	DeletedAt       sql.NullTime
 }
 
-type Project struct {
+type ClusterAction struct {
+	ID          int64
+	ClusterID   string
+	ProjectID   string
+	ClusterName string
+	Action      string
+	BackupID    string
+	Operator    string
+	Detail      string
+	CreatedAt   time.Time
+}
+
+type ClusterBackup struct {
 	ID              string
-	OrgID           string
+	ClusterID       string
+	ProjectID       string
 	Name            string
-	ClusterCount    int32
-	UserCount       int32
+	BackupType      string
+	BackupStatus    string
+	SizeBytes       int64
 	CreateTimestamp int64
-	AwsCmekEnabled  bool
 	FetchedAt       time.Time
 }
+
+type ClusterExemption struct {
+	ID        int64
+	ClusterID string
+	Reason    string
+	CreatedBy string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type ClusterNode struct {
+	ClusterID        string
+	Component        string
+	NodeName         string
+	AvailabilityZone string
+	NodeSize         string
+	VcpuNum          int32
+	RamBytes         int64
+	StorageSizeGib   int32
+	NodeStatus       string
+	FetchedAt        time.Time
+}
+
+type ClusterSnapshot struct {
+	SyncRunID     int64
+	ClusterID     string
+	ProjectID     string
+	Name          string
+	ClusterStatus string
+	TidbVersion   string
+	Topology      string
+}
+
+type NotificationState struct {
+	ClusterID       string
+	FindingKind     string
+	Level           int32
+	FirstNotifiedAt time.Time
+	LastNotifiedAt  time.Time
+	NotifyCount     int32
+}
+
+type Owner struct {
+	ID             int64
+	ProjectID      string
+	ClusterPattern string
+	Name           string
+	Team           string
+	Email          string
+	SlackUserID    string
+	Source         string
+	ImportedAt     time.Time
+}
+
+type Project struct {
+	ID                    string
+	OrgID                 string
+	Name                  string
+	ClusterCount          int32
+	UserCount             int32
+	CreateTimestamp       int64
+	AwsCmekEnabled        bool
+	BackupTrackingEnabled bool
+	FetchedAt             time.Time
+}
+
+type ProjectSnapshot struct {
+	SyncRunID int64
+	ProjectID string
+	Name      string
+}
+
+type SyncRun struct {
+	ID         int64
+	Kind       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// pingTimeout bounds the connectivity check of Open.
+const pingTimeout = 3 * time.Second
+
+// Open opens a pool of connections to the MySQL compatible database (e.g. TiDB) with the given DSN and optional
+// connection pool settings, and verifies the connectivity. The stores build their Queries on top of it with New.
+func Open(dsn string, poolConfig *PoolConfig) (*sql.DB, error) {
+	conn, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if poolConfig == nil {
+		poolConfig = NewPoolConfig() // Use default pool config if none provided
+	}
+	conn.SetMaxOpenConns(poolConfig.MaxOpenConns)
+	conn.SetMaxIdleConns(poolConfig.MaxIdleConns)
+	conn.SetConnMaxLifetime(poolConfig.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(poolConfig.ConnMaxIdleTime)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := conn.PingContext(timeoutCtx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return conn, nil
+}
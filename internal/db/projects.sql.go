@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const listActiveProjects = `-- name: ListActiveProjects :many
@@ -41,6 +42,38 @@ func (q *Queries) ListActiveProjects(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const listBackupTrackedProjects = `-- name: ListBackupTrackedProjects :many
+SELECT
+    id
+FROM
+    projects
+WHERE
+    backup_tracking_enabled = TRUE
+`
+
+func (q *Queries) ListBackupTrackedProjects(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listBackupTrackedProjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjectIDsWithClusters = `-- name: ListProjectIDsWithClusters :many
 SELECT
     id
@@ -79,6 +112,94 @@ func (q *Queries) ListProjectIDsWithClusters(ctx context.Context) ([]string, err
 	return items, nil
 }
 
+const listProjects = `-- name: ListProjects :many
+SELECT
+    id,
+    org_id,
+    name,
+    cluster_count,
+    user_count,
+    create_timestamp,
+    aws_cmek_enabled,
+    backup_tracking_enabled,
+    fetched_at
+FROM
+    projects
+ORDER BY
+    name,
+    id
+`
+
+func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.Name,
+			&i.ClusterCount,
+			&i.UserCount,
+			&i.CreateTimestamp,
+			&i.AwsCmekEnabled,
+			&i.BackupTrackingEnabled,
+			&i.FetchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const projectExists = `-- name: ProjectExists :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            projects
+        WHERE
+            id = ?
+    ) AS project_exists
+`
+
+func (q *Queries) ProjectExists(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, projectExists, id)
+	var project_exists bool
+	err := row.Scan(&project_exists)
+	return project_exists, err
+}
+
+const setProjectBackupTracking = `-- name: SetProjectBackupTracking :execresult
+UPDATE
+    projects
+SET
+    backup_tracking_enabled = ?
+WHERE
+    id = ?
+`
+
+type SetProjectBackupTrackingParams struct {
+	Enabled bool
+	ID      string
+}
+
+func (q *Queries) SetProjectBackupTracking(ctx context.Context, arg SetProjectBackupTrackingParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, setProjectBackupTracking, arg.Enabled, arg.ID)
+}
+
 const upsertProject = `-- name: UpsertProject :exec
 INSERT INTO
     projects (
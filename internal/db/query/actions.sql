@@ -0,0 +1,11 @@
+-- name: InsertClusterAction :exec
+INSERT INTO cluster_actions (
+        cluster_id,
+        project_id,
+        cluster_name,
+        action,
+        backup_id,
+        operator,
+        detail
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?);
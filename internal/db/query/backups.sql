@@ -0,0 +1,55 @@
+-- name: UpsertClusterBackup :exec
+INSERT INTO cluster_backups (
+        id,
+        cluster_id,
+        project_id,
+        name,
+        backup_type,
+        backup_status,
+        size_bytes,
+        create_timestamp
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY
+UPDATE
+    cluster_id = VALUES(cluster_id),
+    project_id = VALUES(project_id),
+    name = VALUES(name),
+    backup_type = VALUES(backup_type),
+    backup_status = VALUES(backup_status),
+    size_bytes = VALUES(size_bytes),
+    create_timestamp = VALUES(create_timestamp);
+
+-- name: ListLatestSuccessfulBackups :many
+-- ListLatestSuccessfulBackups returns every active cluster of the projects with backup tracking enabled,
+-- with the create timestamp of its latest successful backup, or 0 if there is none.
+SELECT c.id,
+    c.project_id,
+    c.name,
+    c.cluster_status,
+    CAST(COALESCE(MAX(b.create_timestamp), 0) AS SIGNED) AS latest_backup_timestamp
+FROM clusters c
+    JOIN projects p ON p.id = c.project_id
+    LEFT JOIN cluster_backups b ON b.cluster_id = c.id
+    AND b.backup_status = 'SUCCESS'
+WHERE c.is_deleted = FALSE
+    AND p.backup_tracking_enabled = TRUE
+GROUP BY c.id,
+    c.project_id,
+    c.name,
+    c.cluster_status
+ORDER BY c.project_id,
+    c.name;
+
+
+-- name: GetClusterBackup :one
+SELECT id,
+    cluster_id,
+    project_id,
+    name,
+    backup_type,
+    backup_status,
+    size_bytes,
+    create_timestamp,
+    fetched_at
+FROM cluster_backups
+WHERE id = ?;
\ No newline at end of file
@@ -29,3 +29,106 @@ SET is_deleted = TRUE,
 WHERE project_id = sqlc.arg('project_id')
     AND updated_at < sqlc.arg('synced_at')
     AND is_deleted = FALSE;
+
+-- name: MarkClusterAsDeleted :exec
+UPDATE clusters
+SET is_deleted = TRUE,
+    deleted_at = CURRENT_TIMESTAMP
+WHERE id = ?
+    AND is_deleted = FALSE;
+
+-- name: ListClusterIDsByProject :many
+SELECT id
+FROM clusters
+WHERE project_id = ?
+    AND is_deleted = FALSE
+ORDER BY id;
+
+
+-- name: DeleteClusterNodes :exec
+DELETE FROM cluster_nodes
+WHERE cluster_id = ?;
+
+-- name: InsertClusterNode :exec
+INSERT INTO cluster_nodes (
+        cluster_id,
+        component,
+        node_name,
+        availability_zone,
+        node_size,
+        vcpu_num,
+        ram_bytes,
+        storage_size_gib,
+        node_status
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListClusterNodes :many
+SELECT cluster_id,
+    component,
+    node_name,
+    availability_zone,
+    node_size,
+    vcpu_num,
+    ram_bytes,
+    storage_size_gib,
+    node_status,
+    fetched_at
+FROM cluster_nodes
+WHERE cluster_id = ?
+ORDER BY component,
+    node_name;
+
+-- name: ListClusters :many
+-- ListClusters returns all clusters including the ones marked as deleted.
+SELECT id,
+    project_id,
+    name,
+    cluster_type,
+    cloud_provider,
+    region,
+    create_timestamp,
+    tidb_version,
+    cluster_status,
+    is_deleted,
+    created_at,
+    updated_at,
+    deleted_at
+FROM clusters
+ORDER BY project_id,
+    name;
+
+-- name: ListActiveClusterNodes :many
+-- ListActiveClusterNodes returns the nodes of the clusters that are not marked as deleted.
+SELECT n.cluster_id,
+    n.component,
+    n.node_name,
+    n.availability_zone,
+    n.node_size,
+    n.vcpu_num,
+    n.ram_bytes,
+    n.storage_size_gib,
+    n.node_status,
+    n.fetched_at
+FROM cluster_nodes n
+    JOIN clusters c ON c.id = n.cluster_id
+WHERE c.is_deleted = FALSE
+ORDER BY n.cluster_id,
+    n.component,
+    n.node_name;
+-- name: GetCluster :one
+SELECT id,
+    project_id,
+    name,
+    cluster_type,
+    cloud_provider,
+    region,
+    create_timestamp,
+    tidb_version,
+    cluster_status,
+    is_deleted,
+    created_at,
+    updated_at,
+    deleted_at
+FROM clusters
+WHERE id = ?;
@@ -0,0 +1,26 @@
+-- name: DeleteClusterExemption :execrows
+DELETE FROM cluster_exemptions
+WHERE id = ?;
+
+-- name: DeleteClusterExemptionsByCluster :execrows
+DELETE FROM cluster_exemptions
+WHERE cluster_id = ?;
+
+-- name: InsertClusterExemption :execlastid
+INSERT INTO cluster_exemptions (cluster_id, reason, created_by, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: ListClusterExemptions :many
+-- ListClusterExemptions returns the exemptions expiring after the given time, with the name of their clusters.
+SELECT e.id,
+    e.cluster_id,
+    COALESCE(c.name, '') AS cluster_name,
+    e.reason,
+    e.created_by,
+    e.created_at,
+    e.expires_at
+FROM cluster_exemptions e
+    LEFT JOIN clusters c ON c.id = e.cluster_id
+WHERE e.expires_at > ?
+ORDER BY e.expires_at,
+    e.id;
@@ -0,0 +1,67 @@
+-- name: DeleteSyncRunsBefore :execrows
+DELETE FROM sync_runs
+WHERE finished_at < ?;
+
+-- name: GetEarliestSyncRunAfter :one
+SELECT id,
+    kind,
+    started_at,
+    finished_at
+FROM sync_runs
+WHERE kind = sqlc.arg('kind')
+    AND finished_at > sqlc.arg('after')
+ORDER BY finished_at,
+    id
+LIMIT 1;
+
+-- name: GetLatestSyncRunBefore :one
+SELECT id,
+    kind,
+    started_at,
+    finished_at
+FROM sync_runs
+WHERE kind = sqlc.arg('kind')
+    AND finished_at <= sqlc.arg('before')
+ORDER BY finished_at DESC,
+    id DESC
+LIMIT 1;
+
+-- name: InsertClusterSnapshot :exec
+INSERT INTO cluster_snapshots (
+        sync_run_id,
+        cluster_id,
+        project_id,
+        name,
+        cluster_status,
+        tidb_version,
+        topology
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: InsertProjectSnapshot :exec
+INSERT INTO project_snapshots (sync_run_id, project_id, name)
+VALUES (?, ?, ?);
+
+-- name: InsertSyncRun :execlastid
+INSERT INTO sync_runs (kind, started_at, finished_at)
+VALUES (?, ?, ?);
+
+-- name: ListClusterSnapshots :many
+SELECT sync_run_id,
+    cluster_id,
+    project_id,
+    name,
+    cluster_status,
+    tidb_version,
+    topology
+FROM cluster_snapshots
+WHERE sync_run_id = ?
+ORDER BY cluster_id;
+
+-- name: ListProjectSnapshots :many
+SELECT sync_run_id,
+    project_id,
+    name
+FROM project_snapshots
+WHERE sync_run_id = ?
+ORDER BY project_id;
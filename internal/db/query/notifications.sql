@@ -0,0 +1,30 @@
+-- name: DeleteNotificationState :exec
+DELETE FROM notification_states
+WHERE cluster_id = ?
+    AND finding_kind = ?;
+
+-- name: ListNotificationStates :many
+SELECT cluster_id,
+    finding_kind,
+    level,
+    first_notified_at,
+    last_notified_at,
+    notify_count
+FROM notification_states
+ORDER BY cluster_id,
+    finding_kind;
+
+-- name: UpsertNotificationState :exec
+-- UpsertNotificationState records that the finding is notified at the given time.
+INSERT INTO notification_states (
+        cluster_id,
+        finding_kind,
+        level,
+        first_notified_at,
+        last_notified_at
+    )
+VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY
+UPDATE
+    level = VALUES(level),
+    last_notified_at = VALUES(last_notified_at),
+    notify_count = notify_count + 1;
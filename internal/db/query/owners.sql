@@ -0,0 +1,27 @@
+-- name: DeleteOwners :exec
+DELETE FROM owners;
+
+-- name: InsertOwner :exec
+INSERT INTO owners (
+        project_id,
+        cluster_pattern,
+        name,
+        team,
+        email,
+        slack_user_id,
+        source
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListOwners :many
+SELECT id,
+    project_id,
+    cluster_pattern,
+    name,
+    team,
+    email,
+    slack_user_id,
+    source,
+    imported_at
+FROM owners
+ORDER BY id;
@@ -40,3 +40,47 @@ FROM
     projects
 WHERE
     cluster_count > 0;
+
+-- name: SetProjectBackupTracking :execresult
+UPDATE
+    projects
+SET
+    backup_tracking_enabled = sqlc.arg('enabled')
+WHERE
+    id = sqlc.arg('id');
+
+-- name: ProjectExists :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            projects
+        WHERE
+            id = sqlc.arg('id')
+    ) AS project_exists;
+
+-- name: ListBackupTrackedProjects :many
+SELECT
+    id
+FROM
+    projects
+WHERE
+    backup_tracking_enabled = TRUE;
+
+-- name: ListProjects :many
+SELECT
+    id,
+    org_id,
+    name,
+    cluster_count,
+    user_count,
+    create_timestamp,
+    aws_cmek_enabled,
+    backup_tracking_enabled,
+    fetched_at
+FROM
+    projects
+ORDER BY
+    name,
+    id;
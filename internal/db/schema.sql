@@ -8,6 +8,9 @@ CREATE TABLE IF NOT EXISTS projects (
     user_count INT NOT NULL DEFAULT 0,
     create_timestamp BIGINT NOT NULL, -- Use BIGINT to store timestamp in seconds for API compatibility
     aws_cmek_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Set by `msk track-backups`. Backups are fetched in fetch-clusters only for projects with this flag.
+    -- For existing databases: ALTER TABLE projects ADD COLUMN backup_tracking_enabled BOOLEAN NOT NULL DEFAULT FALSE AFTER aws_cmek_enabled;
+    backup_tracking_enabled BOOLEAN NOT NULL DEFAULT FALSE,
     fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
 
@@ -30,3 +33,124 @@ CREATE TABLE IF NOT EXISTS clusters (
     deleted_at DATETIME,
     FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
 );
+
+-- This table holds the node map of the clusters in the response from the TiDB Cloud API "Get a cluster by ID."
+-- The nodes are replaced on every sync and kept after the cluster is deleted,
+-- so that the cluster can be restored from a backup with the same node configuration.
+CREATE TABLE IF NOT EXISTS cluster_nodes (
+    cluster_id VARCHAR(64) NOT NULL,
+    component VARCHAR(16) NOT NULL, -- tidb, tikv or tiflash
+    node_name VARCHAR(255) NOT NULL,
+    availability_zone VARCHAR(64) NOT NULL,
+    node_size VARCHAR(32) NOT NULL,
+    vcpu_num INT NOT NULL DEFAULT 0,
+    ram_bytes BIGINT NOT NULL DEFAULT 0,
+    storage_size_gib INT NOT NULL DEFAULT 0,
+    node_status VARCHAR(32) NOT NULL,
+    fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (cluster_id, component, node_name),
+    FOREIGN KEY (cluster_id) REFERENCES clusters (id) ON DELETE CASCADE
+);
+
+-- This table is based on the response from the TiDB Cloud API "List the backups for a cluster."
+-- https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Backup/operation/ListBackUpOfCluster
+CREATE TABLE IF NOT EXISTS cluster_backups (
+    id VARCHAR(64) PRIMARY KEY,
+    cluster_id VARCHAR(64) NOT NULL,
+    project_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    backup_type VARCHAR(32) NOT NULL, -- MANUAL or AUTO
+    backup_status VARCHAR(32) NOT NULL, -- PENDING, RUNNING, FAILED or SUCCESS
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    create_timestamp BIGINT NOT NULL,
+    fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_cluster_backups_cluster_status (cluster_id, backup_status, create_timestamp),
+    FOREIGN KEY (cluster_id) REFERENCES clusters (id) ON DELETE CASCADE
+);
+
+-- Audit log of the changes made to clusters by msk, e.g. `msk cluster delete`.
+-- It has no foreign key to clusters so that the log outlives the clusters.
+CREATE TABLE IF NOT EXISTS cluster_actions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    cluster_id VARCHAR(64) NOT NULL,
+    project_id VARCHAR(64) NOT NULL,
+    cluster_name VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL, -- delete
+    backup_id VARCHAR(64) NOT NULL DEFAULT '', -- The backup taken before the action, if any
+    operator VARCHAR(255) NOT NULL DEFAULT '', -- The OS user who ran msk
+    detail VARCHAR(1024) NOT NULL DEFAULT '', -- e.g. the node configuration at the time of the action
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_cluster_actions_cluster (cluster_id, created_at)
+);
+
+-- Owners of the clusters, replaced by `msk owners import` with the rows of a YAML or CSV mapping.
+-- A cluster is owned by every row whose project and cluster name pattern match it.
+CREATE TABLE IF NOT EXISTS owners (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id VARCHAR(64) NOT NULL DEFAULT '*', -- '*' for all projects
+    cluster_pattern VARCHAR(255) NOT NULL DEFAULT '*', -- Glob of the cluster names, e.g. 'pay-*'
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    team VARCHAR(255) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    slack_user_id VARCHAR(64) NOT NULL DEFAULT '',
+    source VARCHAR(1024) NOT NULL DEFAULT '', -- The mapping file the row was imported from
+    imported_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Notification state of the findings of the clusters, updated by `msk notify` after sending them.
+-- A row is deleted when its finding is resolved, so that the finding is announced again if it comes back.
+CREATE TABLE IF NOT EXISTS notification_states (
+    cluster_id VARCHAR(64) NOT NULL,
+    finding_kind VARCHAR(32) NOT NULL, -- long_running or stale_backup
+    level INT NOT NULL DEFAULT 0, -- Number of escalation thresholds crossed when it was last notified
+    first_notified_at DATETIME NOT NULL,
+    last_notified_at DATETIME NOT NULL,
+    notify_count INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (cluster_id, finding_kind)
+);
+
+-- Exemptions of clusters from the long-running analysis, managed by `msk exempt`.
+-- Expired exemptions are kept as a history until they are removed.
+-- It has no foreign key to clusters so that the history outlives the clusters.
+CREATE TABLE IF NOT EXISTS cluster_exemptions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    cluster_id VARCHAR(64) NOT NULL,
+    reason VARCHAR(1024) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    INDEX idx_cluster_exemptions_cluster (cluster_id, expires_at),
+    INDEX idx_cluster_exemptions_expires (expires_at)
+);
+
+-- History of the syncs of fetch-projects and fetch-clusters, read by `msk report changes`.
+-- Each run keeps a snapshot of the inventory after the sync, deleted with the run when it is pruned.
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL, -- projects or clusters
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    INDEX idx_sync_runs_kind_finished (kind, finished_at)
+);
+
+-- The projects listed by a run of fetch-projects.
+CREATE TABLE IF NOT EXISTS project_snapshots (
+    sync_run_id BIGINT NOT NULL,
+    project_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (sync_run_id, project_id),
+    FOREIGN KEY (sync_run_id) REFERENCES sync_runs (id) ON DELETE CASCADE
+);
+
+-- The clusters not marked as deleted after a run of fetch-clusters.
+CREATE TABLE IF NOT EXISTS cluster_snapshots (
+    sync_run_id BIGINT NOT NULL,
+    cluster_id VARCHAR(64) NOT NULL,
+    project_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    cluster_status VARCHAR(32) NOT NULL,
+    tidb_version VARCHAR(32) NOT NULL,
+    topology VARCHAR(1024) NOT NULL DEFAULT '', -- e.g. 'TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)'
+    PRIMARY KEY (sync_run_id, cluster_id),
+    FOREIGN KEY (sync_run_id) REFERENCES sync_runs (id) ON DELETE CASCADE
+);
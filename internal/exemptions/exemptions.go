@@ -0,0 +1,232 @@
+package exemptions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/db"
+)
+
+// This module manages the exemptions of clusters that are intentionally long running, e.g. shared staging
+// clusters and performance benchmarks. While an exemption is active, the cluster is not reported by the
+// long-running analysis of generate-notice (and hence by notify). Other findings, e.g. stale backups,
+// are still reported. msk has no auto-pause schedule yet; one should skip the clusters of Active as well.
+
+// Exemption exempts a cluster from the long-running analysis until it expires.
+type Exemption struct {
+	ID          int64     `json:"id"`
+	ClusterID   string    `json:"cluster_id"`
+	ClusterName string    `json:"cluster_name"` // Empty if the cluster is not fetched by fetch-clusters
+	Reason      string    `json:"reason"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// IsActive reports whether the exemption has not expired at now.
+func (e Exemption) IsActive(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Active returns the exemptions active at now by cluster ID. If a cluster has several, the one expiring last wins.
+func Active(exemptions []Exemption, now time.Time) map[string]Exemption {
+	active := make(map[string]Exemption)
+	for _, e := range exemptions {
+		if !e.IsActive(now) {
+			continue
+		}
+		if current, ok := active[e.ClusterID]; !ok || e.ExpiresAt.After(current.ExpiresAt) {
+			active[e.ClusterID] = e
+		}
+	}
+	return active
+}
+
+// ParseUntil parses the expiry of an exemption: an RFC 3339 time, a date (the exemption lasts until the end of
+// the day in UTC), or a duration from now with the "d" unit for days, e.g. "30d" or "36h".
+func ParseUntil(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	var until time.Time
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		until = t
+	} else if t, err := time.Parse(time.DateOnly, value); err == nil {
+		until = t.Add(24 * time.Hour)
+	} else if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry %q: %w", value, err)
+		}
+		until = now.Add(time.Duration(n) * 24 * time.Hour)
+	} else if d, err := time.ParseDuration(value); err == nil {
+		until = now.Add(d)
+	} else {
+		return time.Time{}, fmt.Errorf("invalid expiry %q: use an RFC 3339 time, a date like 2006-01-02 or a duration like 30d", value)
+	}
+
+	if !until.After(now) {
+		return time.Time{}, fmt.Errorf("expiry %s is not in the future", until.Format(time.RFC3339))
+	}
+	return until, nil
+}
+
+// Store defines an interface for managing the exemptions.
+type Store interface {
+	// GetClusterName returns the name of the stored cluster, or an error if it is not stored or deleted.
+	GetClusterName(ctx context.Context, clusterID string) (string, error)
+	AddExemption(ctx context.Context, e Exemption) (int64, error)
+	// ListExemptions returns the exemptions expiring after the given time, ordered by expiry.
+	ListExemptions(ctx context.Context, expiresAfter time.Time) ([]Exemption, error)
+	RemoveExemption(ctx context.Context, id int64) (bool, error)
+	RemoveClusterExemptions(ctx context.Context, clusterID string) (int64, error)
+}
+
+// DBExemptionStore implements Store on top of the cluster_exemptions table.
+type DBExemptionStore struct {
+	conn    *sql.DB
+	Queries *db.Queries
+}
+
+// NewDBExemptionStore initializes a new DBExemptionStore using the given DSN and optional connection pool settings.
+// Returns an error if the connection fails or cannot be verified.
+func NewDBExemptionStore(dsn string, poolConfig *db.PoolConfig) (*DBExemptionStore, error) {
+	conn, err := db.Open(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBExemptionStore{
+		Queries: db.New(conn),
+		conn:    conn,
+	}, nil
+}
+
+func (s *DBExemptionStore) GetClusterName(ctx context.Context, clusterID string) (string, error) {
+	cluster, err := s.Queries.GetCluster(ctx, clusterID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("cluster %s not found, run fetch-clusters first", clusterID)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get cluster %s: %w", clusterID, err)
+	}
+	if cluster.IsDeleted {
+		return "", fmt.Errorf("cluster %s (%s) is deleted", clusterID, cluster.Name)
+	}
+	return cluster.Name, nil
+}
+
+func (s *DBExemptionStore) AddExemption(ctx context.Context, e Exemption) (int64, error) {
+	id, err := s.Queries.InsertClusterExemption(ctx, db.InsertClusterExemptionParams{
+		ClusterID: e.ClusterID,
+		Reason:    e.Reason,
+		CreatedBy: e.CreatedBy,
+		ExpiresAt: e.ExpiresAt,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to add exemption of cluster %s: %w", e.ClusterID, err)
+	}
+	return id, nil
+}
+
+func (s *DBExemptionStore) ListExemptions(ctx context.Context, expiresAfter time.Time) ([]Exemption, error) {
+	rows, err := s.Queries.ListClusterExemptions(ctx, expiresAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exemptions: %w", err)
+	}
+
+	exemptions := make([]Exemption, 0, len(rows))
+	for _, row := range rows {
+		exemptions = append(exemptions, Exemption{
+			ID:          row.ID,
+			ClusterID:   row.ClusterID,
+			ClusterName: row.ClusterName,
+			Reason:      row.Reason,
+			CreatedBy:   row.CreatedBy,
+			CreatedAt:   row.CreatedAt,
+			ExpiresAt:   row.ExpiresAt,
+		})
+	}
+	return exemptions, nil
+}
+
+func (s *DBExemptionStore) RemoveExemption(ctx context.Context, id int64) (bool, error) {
+	n, err := s.Queries.DeleteClusterExemption(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove exemption %d: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+func (s *DBExemptionStore) RemoveClusterExemptions(ctx context.Context, clusterID string) (int64, error) {
+	n, err := s.Queries.DeleteClusterExemptionsByCluster(ctx, clusterID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove exemptions of cluster %s: %w", clusterID, err)
+	}
+	return n, nil
+}
+
+// Close closes the underlying database connection held by the DBExemptionStore.
+func (s *DBExemptionStore) Close() error {
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// AddParams holds the exemption to add.
+type AddParams struct {
+	ClusterID string
+	Reason    string
+	CreatedBy string
+	ExpiresAt time.Time
+}
+
+// Add exempts the stored cluster until the expiry. If dryRun is true, only the exemption to add is printed.
+func Add(ctx context.Context, store Store, p AddParams, dryRun bool, w io.Writer) error {
+	if strings.TrimSpace(p.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if p.CreatedBy == "" {
+		return errors.New("the user adding the exemption is unknown, set it explicitly")
+	}
+
+	name, err := store.GetClusterName(ctx, p.ClusterID)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would exempt cluster %s (%s) until %s: %s\n", p.ClusterID, name, p.ExpiresAt.Format(time.RFC3339), p.Reason)
+		return nil
+	}
+
+	id, err := store.AddExemption(ctx, Exemption{ClusterID: p.ClusterID, Reason: p.Reason, CreatedBy: p.CreatedBy, ExpiresAt: p.ExpiresAt})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "[SUCCESS] Exemption %d of cluster %s (%s) is added by %s until %s\n", id, p.ClusterID, name, p.CreatedBy, p.ExpiresAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// PrintExemptions writes the exemptions as a table, with their status at now.
+func PrintExemptions(w io.Writer, exemptions []Exemption, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCLUSTER ID\tCLUSTER NAME\tREASON\tCREATED BY\tCREATED\tEXPIRES\tSTATUS")
+	for _, e := range exemptions {
+		status := "active"
+		if !e.IsActive(now) {
+			status = "expired"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.ID, e.ClusterID, e.ClusterName, e.Reason, e.CreatedBy,
+			e.CreatedAt.UTC().Format(time.RFC3339), e.ExpiresAt.UTC().Format(time.RFC3339), status)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,129 @@
+package exemptions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeStore is an in-memory Store.
+type fakeStore struct {
+	clusters   map[string]string
+	exemptions []Exemption
+}
+
+func (f *fakeStore) GetClusterName(ctx context.Context, clusterID string) (string, error) {
+	name, ok := f.clusters[clusterID]
+	if !ok {
+		return "", fmt.Errorf("cluster %s not found, run fetch-clusters first", clusterID)
+	}
+	return name, nil
+}
+
+func (f *fakeStore) AddExemption(ctx context.Context, e Exemption) (int64, error) {
+	e.ID = int64(len(f.exemptions) + 1)
+	f.exemptions = append(f.exemptions, e)
+	return e.ID, nil
+}
+
+func (f *fakeStore) ListExemptions(ctx context.Context, expiresAfter time.Time) ([]Exemption, error) {
+	return f.exemptions, nil
+}
+
+func (f *fakeStore) RemoveExemption(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStore) RemoveClusterExemptions(ctx context.Context, clusterID string) (int64, error) {
+	return 0, nil
+}
+
+func TestParseUntil(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{value: "2025-07-01T09:00:00+09:00", want: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2025-07-01", want: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "30d", want: testNow.Add(30 * 24 * time.Hour)},
+		{value: "36h", want: testNow.Add(36 * time.Hour)},
+		{value: "2025-05-01", wantErr: "is not in the future"},
+		{value: "xd", wantErr: `invalid expiry "xd"`},
+		{value: "next week", wantErr: "use an RFC 3339 time, a date like 2006-01-02 or a duration like 30d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseUntil(tt.value, testNow)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestActive(t *testing.T) {
+	active := Active([]Exemption{
+		{ID: 1, ClusterID: "c1", ExpiresAt: testNow.Add(time.Hour)},
+		{ID: 2, ClusterID: "c1", ExpiresAt: testNow.Add(48 * time.Hour)},
+		{ID: 3, ClusterID: "c2", ExpiresAt: testNow},
+	}, testNow)
+
+	require.Len(t, active, 1)
+	require.Equal(t, int64(2), active["c1"].ID)
+}
+
+func TestAdd(t *testing.T) {
+	ctx := context.Background()
+	p := AddParams{ClusterID: "c1", Reason: "perf benchmark", CreatedBy: "alice", ExpiresAt: testNow.Add(24 * time.Hour)}
+
+	t.Run("added", func(t *testing.T) {
+		store := &fakeStore{clusters: map[string]string{"c1": "bench"}}
+		var buf bytes.Buffer
+		require.NoError(t, Add(ctx, store, p, false, &buf))
+		require.Equal(t, "[SUCCESS] Exemption 1 of cluster c1 (bench) is added by alice until 2025-06-02T12:00:00Z\n", buf.String())
+		require.Equal(t, "perf benchmark", store.exemptions[0].Reason)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		store := &fakeStore{clusters: map[string]string{"c1": "bench"}}
+		var buf bytes.Buffer
+		require.NoError(t, Add(ctx, store, p, true, &buf))
+		require.Contains(t, buf.String(), "[DRY RUN] Would exempt cluster c1 (bench) until 2025-06-02T12:00:00Z: perf benchmark")
+		require.Empty(t, store.exemptions)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := &fakeStore{}
+		require.ErrorContains(t, Add(ctx, store, p, false, &bytes.Buffer{}), "cluster c1 not found")
+
+		noReason := p
+		noReason.Reason = " "
+		require.ErrorContains(t, Add(ctx, store, noReason, false, &bytes.Buffer{}), "reason is required")
+
+		noUser := p
+		noUser.CreatedBy = ""
+		require.ErrorContains(t, Add(ctx, store, noUser, false, &bytes.Buffer{}), "the user adding the exemption is unknown")
+	})
+}
+
+func TestPrintExemptions(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintExemptions(&buf, []Exemption{
+		{ID: 1, ClusterID: "c1", ClusterName: "bench", Reason: "perf", CreatedBy: "alice", CreatedAt: testNow.Add(-time.Hour), ExpiresAt: testNow.Add(time.Hour)},
+		{ID: 2, ClusterID: "c2", Reason: "staging", CreatedBy: "bob", CreatedAt: testNow.Add(-48 * time.Hour), ExpiresAt: testNow.Add(-time.Hour)},
+	}, testNow))
+
+	require.Contains(t, buf.String(), "1   c1          bench         perf     alice       2025-06-01T11:00:00Z  2025-06-01T13:00:00Z  active")
+	require.Contains(t, buf.String(), "expired")
+}
@@ -0,0 +1,301 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sgykfjsm/msk/internal/db"
+)
+
+// This module aggregates the clusters stored by fetch-clusters and their nodes, to summarize the fleet by
+// cloud provider, region, cluster type and project. The clusters marked as deleted are not counted.
+
+// Dimensions the clusters are grouped by.
+const (
+	DimensionProvider    = "provider"
+	DimensionRegion      = "region"
+	DimensionClusterType = "cluster_type"
+	DimensionProject     = "project"
+)
+
+var Dimensions = []string{DimensionProvider, DimensionRegion, DimensionClusterType, DimensionProject}
+
+// Metrics shown in the cells of a pivot table.
+const (
+	MetricClusters = "clusters"
+	MetricNodes    = "nodes"
+	MetricVCPU     = "vcpu"
+	MetricRAM      = "ram_gib"
+	MetricStorage  = "storage_gib"
+)
+
+var Metrics = []string{MetricClusters, MetricNodes, MetricVCPU, MetricRAM, MetricStorage}
+
+const gib = 1 << 30
+
+// Cluster is a cluster with the totals of its nodes.
+type Cluster struct {
+	ID            string
+	ProjectID     string
+	ProjectName   string
+	Name          string
+	ClusterType   string
+	CloudProvider string
+	Region        string
+	Nodes         int
+	VCPU          int
+	RAMBytes      int64
+	StorageGiB    int
+}
+
+// value returns the value of the dimension. A project is shown with its name, if known.
+func (c Cluster) value(dimension string) string {
+	var v string
+	switch dimension {
+	case DimensionProvider:
+		v = c.CloudProvider
+	case DimensionRegion:
+		v = c.Region
+	case DimensionClusterType:
+		v = c.ClusterType
+	case DimensionProject:
+		v = c.ProjectID
+		if c.ProjectName != "" {
+			v = fmt.Sprintf("%s (%s)", c.ProjectName, c.ProjectID)
+		}
+	}
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
+
+// Totals are the sums over a group of clusters.
+type Totals struct {
+	Clusters   int     `json:"clusters"`
+	Nodes      int     `json:"nodes"`
+	VCPU       int     `json:"vcpu"`
+	RAMGiB     float64 `json:"ram_gib"`
+	StorageGiB int     `json:"storage_gib"`
+}
+
+func (t *Totals) add(c Cluster) {
+	t.Clusters++
+	t.Nodes += c.Nodes
+	t.VCPU += c.VCPU
+	t.RAMGiB += float64(c.RAMBytes) / gib
+	t.StorageGiB += c.StorageGiB
+}
+
+// metric returns the value of the metric.
+func (t Totals) metric(name string) float64 {
+	switch name {
+	case MetricNodes:
+		return float64(t.Nodes)
+	case MetricVCPU:
+		return float64(t.VCPU)
+	case MetricRAM:
+		return t.RAMGiB
+	case MetricStorage:
+		return float64(t.StorageGiB)
+	}
+	return float64(t.Clusters)
+}
+
+// Row is the totals of the clusters sharing the values of the dimensions.
+type Row struct {
+	Keys []string `json:"keys"` // In the order of the dimensions
+	Totals
+}
+
+// Summary holds the totals of the clusters grouped by the dimensions.
+type Summary struct {
+	GroupBy []string `json:"group_by"`
+	Rows    []Row    `json:"rows"`
+	Total   Totals   `json:"total"`
+}
+
+// Summarize groups the clusters by the dimensions, sorted by their values.
+func Summarize(clusters []Cluster, groupBy []string) (*Summary, error) {
+	if err := validateDimensions(groupBy); err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{GroupBy: groupBy, Rows: []Row{}}
+	index := make(map[string]int)
+	for _, c := range clusters {
+		keys := keysOf(c, groupBy)
+		id := strings.Join(keys, "\x00")
+		i, ok := index[id]
+		if !ok {
+			i = len(summary.Rows)
+			index[id] = i
+			summary.Rows = append(summary.Rows, Row{Keys: keys})
+		}
+		summary.Rows[i].add(c)
+		summary.Total.add(c)
+	}
+	sort.Slice(summary.Rows, func(i, j int) bool {
+		return slices.Compare(summary.Rows[i].Keys, summary.Rows[j].Keys) < 0
+	})
+
+	return summary, nil
+}
+
+// PivotRow is a row of a pivot table.
+type PivotRow struct {
+	Keys   []string  `json:"keys"`
+	Values []float64 `json:"values"` // In the order of the columns
+	Total  float64   `json:"total"`
+}
+
+// Pivot holds a metric of the clusters grouped by the row dimensions and the column dimension.
+type Pivot struct {
+	GroupBy []string   `json:"group_by"`
+	Column  string     `json:"column"`
+	Metric  string     `json:"metric"`
+	Columns []string   `json:"columns"`
+	Rows    []PivotRow `json:"rows"`
+	Totals  []float64  `json:"totals"` // By column
+	Total   float64    `json:"total"`
+}
+
+// NewPivot returns the metric of the clusters with the rows grouped by the dimensions and a column per value of the column dimension.
+func NewPivot(clusters []Cluster, groupBy []string, column, metric string) (*Pivot, error) {
+	if err := validateDimensions(append(slices.Clone(groupBy), column)); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(Metrics, metric) {
+		return nil, fmt.Errorf("invalid metric: %s, allowed metrics are: %s", metric, strings.Join(Metrics, ", "))
+	}
+
+	columns := []string{}
+	for _, c := range clusters {
+		if v := c.value(column); !slices.Contains(columns, v) {
+			columns = append(columns, v)
+		}
+	}
+	sort.Strings(columns)
+
+	// Each cell is the row of the summary grouped by the dimensions and the column
+	summary, err := Summarize(clusters, append(slices.Clone(groupBy), column))
+	if err != nil {
+		return nil, err
+	}
+	pivot := &Pivot{GroupBy: groupBy, Column: column, Metric: metric, Columns: columns, Rows: []PivotRow{}, Totals: make([]float64, len(columns))}
+	for _, cell := range summary.Rows {
+		keys := slices.Clone(cell.Keys[:len(groupBy)])
+		if n := len(pivot.Rows); n == 0 || !slices.Equal(pivot.Rows[n-1].Keys, keys) {
+			pivot.Rows = append(pivot.Rows, PivotRow{Keys: keys, Values: make([]float64, len(columns))})
+		}
+		row := &pivot.Rows[len(pivot.Rows)-1]
+		value := cell.metric(metric)
+		i := slices.Index(columns, cell.Keys[len(groupBy)])
+		row.Values[i] += value
+		row.Total += value
+		pivot.Totals[i] += value
+		pivot.Total += value
+	}
+
+	return pivot, nil
+}
+
+func validateDimensions(dimensions []string) error {
+	if len(dimensions) == 0 {
+		return errors.New("at least one dimension to group the clusters by is required")
+	}
+	for i, d := range dimensions {
+		if !slices.Contains(Dimensions, d) {
+			return fmt.Errorf("invalid dimension: %s, allowed dimensions are: %s", d, strings.Join(Dimensions, ", "))
+		}
+		if slices.Contains(dimensions[:i], d) {
+			return fmt.Errorf("dimension %s is specified more than once", d)
+		}
+	}
+
+	return nil
+}
+
+func keysOf(c Cluster, dimensions []string) []string {
+	keys := make([]string, 0, len(dimensions))
+	for _, d := range dimensions {
+		keys = append(keys, c.value(d))
+	}
+	return keys
+}
+
+// formatValue formats a metric, with up to one decimal for the RAM in GiB.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// Inventory defines an interface for reading the stored clusters.
+type Inventory interface {
+	ListClusters(ctx context.Context) ([]Cluster, error)
+}
+
+// DBInventory implements Inventory on top of the tables filled by fetch-projects and fetch-clusters.
+type DBInventory struct {
+	queries *db.Queries
+}
+
+// NewDBInventory returns a DBInventory reading with the given queries. The caller owns the connection under them.
+func NewDBInventory(queries *db.Queries) *DBInventory {
+	return &DBInventory{queries: queries}
+}
+
+// ListClusters returns the stored clusters that are not marked as deleted, with the totals of their nodes.
+func (s *DBInventory) ListClusters(ctx context.Context) ([]Cluster, error) {
+	rows, err := s.queries.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	nodes, err := s.queries.ListActiveClusterNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of active clusters: %w", err)
+	}
+	projects, err := s.queries.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	projectNames := make(map[string]string, len(projects))
+	for _, p := range projects {
+		projectNames[p.ID] = p.Name
+	}
+	nodesByCluster := make(map[string][]db.ClusterNode)
+	for _, n := range nodes {
+		nodesByCluster[n.ClusterID] = append(nodesByCluster[n.ClusterID], n)
+	}
+
+	var active []Cluster
+	for _, row := range rows {
+		if row.IsDeleted {
+			continue
+		}
+		c := Cluster{
+			ID:            row.ID,
+			ProjectID:     row.ProjectID,
+			ProjectName:   projectNames[row.ProjectID],
+			Name:          row.Name,
+			ClusterType:   row.ClusterType,
+			CloudProvider: row.CloudProvider,
+			Region:        row.Region,
+		}
+		for _, n := range nodesByCluster[row.ID] {
+			c.Nodes++
+			c.VCPU += int(n.VcpuNum)
+			c.RAMBytes += n.RamBytes
+			c.StorageGiB += int(n.StorageSizeGib)
+		}
+		active = append(active, c)
+	}
+
+	return active, nil
+}
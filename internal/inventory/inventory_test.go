@@ -0,0 +1,113 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testClusters() []Cluster {
+	return []Cluster{
+		{ID: "c1", ProjectID: "p1", ProjectName: "payments", Name: "pay-main", ClusterType: "DEDICATED", CloudProvider: "AWS", Region: "us-east-1",
+			Nodes: 5, VCPU: 40, RAMBytes: 128 << 30, StorageGiB: 1500},
+		{ID: "c2", ProjectID: "p1", ProjectName: "payments", Name: "pay-dev", ClusterType: "DEVELOPER", CloudProvider: "AWS", Region: "us-east-1"},
+		{ID: "c3", ProjectID: "p2", Name: "bi", ClusterType: "DEDICATED", CloudProvider: "GCP", Region: "us-central1",
+			Nodes: 4, VCPU: 40, RAMBytes: 128<<30 + 1<<29, StorageGiB: 600},
+		{ID: "c4", ProjectID: "p2", Name: "bi-eu", ClusterType: "DEDICATED", CloudProvider: "AWS", Region: "eu-west-1",
+			Nodes: 3, VCPU: 12, RAMBytes: 48 << 30, StorageGiB: 300},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s, err := Summarize(testClusters(), []string{DimensionProvider, DimensionRegion})
+	require.NoError(t, err)
+	require.Equal(t, []Row{
+		{Keys: []string{"AWS", "eu-west-1"}, Totals: Totals{Clusters: 1, Nodes: 3, VCPU: 12, RAMGiB: 48, StorageGiB: 300}},
+		{Keys: []string{"AWS", "us-east-1"}, Totals: Totals{Clusters: 2, Nodes: 5, VCPU: 40, RAMGiB: 128, StorageGiB: 1500}},
+		{Keys: []string{"GCP", "us-central1"}, Totals: Totals{Clusters: 1, Nodes: 4, VCPU: 40, RAMGiB: 128.5, StorageGiB: 600}},
+	}, s.Rows)
+	require.Equal(t, Totals{Clusters: 4, Nodes: 12, VCPU: 92, RAMGiB: 304.5, StorageGiB: 2400}, s.Total)
+
+	s, err = Summarize(testClusters(), []string{DimensionProject})
+	require.NoError(t, err)
+	require.Equal(t, []string{"p2"}, s.Rows[0].Keys)
+	require.Equal(t, []string{"payments (p1)"}, s.Rows[1].Keys)
+
+	_, err = Summarize(testClusters(), []string{"zone"})
+	require.ErrorContains(t, err, "invalid dimension: zone, allowed dimensions are: provider, region, cluster_type, project")
+	_, err = Summarize(testClusters(), []string{DimensionRegion, DimensionRegion})
+	require.ErrorContains(t, err, "dimension region is specified more than once")
+	_, err = Summarize(testClusters(), nil)
+	require.ErrorContains(t, err, "at least one dimension")
+}
+
+func TestNewPivot(t *testing.T) {
+	p, err := NewPivot(testClusters(), []string{DimensionProvider}, DimensionClusterType, MetricNodes)
+	require.NoError(t, err)
+	require.Equal(t, []string{"DEDICATED", "DEVELOPER"}, p.Columns)
+	require.Equal(t, []PivotRow{
+		{Keys: []string{"AWS"}, Values: []float64{8, 0}, Total: 8},
+		{Keys: []string{"GCP"}, Values: []float64{4, 0}, Total: 4},
+	}, p.Rows)
+	require.Equal(t, []float64{12, 0}, p.Totals)
+	require.Equal(t, float64(12), p.Total)
+
+	p, err = NewPivot(testClusters(), []string{DimensionRegion}, DimensionProvider, MetricClusters)
+	require.NoError(t, err)
+	require.Equal(t, []PivotRow{
+		{Keys: []string{"eu-west-1"}, Values: []float64{1, 0}, Total: 1},
+		{Keys: []string{"us-central1"}, Values: []float64{0, 1}, Total: 1},
+		{Keys: []string{"us-east-1"}, Values: []float64{2, 0}, Total: 2},
+	}, p.Rows)
+
+	_, err = NewPivot(testClusters(), []string{DimensionProvider}, DimensionProvider, MetricNodes)
+	require.ErrorContains(t, err, "dimension provider is specified more than once")
+	_, err = NewPivot(testClusters(), []string{DimensionProvider}, DimensionRegion, "cost")
+	require.ErrorContains(t, err, "invalid metric: cost")
+}
+
+func TestPrintSummary(t *testing.T) {
+	s, err := Summarize(testClusters(), []string{DimensionProvider, DimensionClusterType})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintSummary(&buf, s))
+	require.Equal(t, `PROVIDER  CLUSTER TYPE  CLUSTERS  NODES  VCPU  RAM (GiB)  STORAGE (GiB)
+AWS       DEDICATED     2         8      52    176        1800
+AWS       DEVELOPER     1         0      0     0          0
+GCP       DEDICATED     1         4      40    128.5      600
+TOTAL                   4         12     92    304.5      2400
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteSummaryCSV(&buf, s))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"provider", "cluster_type", "clusters", "nodes", "vcpu", "ram_gib", "storage_gib"},
+		{"AWS", "DEDICATED", "2", "8", "52", "176", "1800"},
+		{"AWS", "DEVELOPER", "1", "0", "0", "0", "0"},
+		{"GCP", "DEDICATED", "1", "4", "40", "128.5", "600"},
+		{"total", "", "4", "12", "92", "304.5", "2400"},
+	}, records)
+}
+
+func TestPrintPivot(t *testing.T) {
+	p, err := NewPivot(testClusters(), []string{DimensionProject}, DimensionProvider, MetricVCPU)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintPivot(&buf, p))
+	require.Equal(t, `vcpu by provider
+PROJECT        AWS  GCP  TOTAL
+p2             12   40   52
+payments (p1)  40   0    40
+TOTAL          52   40   92
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WritePivotCSV(&buf, p))
+	require.Equal(t, "project,AWS,GCP,total\np2,12,40,52\npayments (p1),40,0,40\ntotal,52,40,92\n", buf.String())
+}
@@ -0,0 +1,109 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// PrintSummary writes the summary as a table, followed by the total.
+func PrintSummary(w io.Writer, s *Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(append(upper(s.GroupBy), "CLUSTERS", "NODES", "VCPU", "RAM (GiB)", "STORAGE (GiB)"), "\t"))
+	for _, row := range s.Rows {
+		fmt.Fprintln(tw, strings.Join(append(append([]string{}, row.Keys...), totalsRecord(row.Totals)...), "\t"))
+	}
+	fmt.Fprintln(tw, strings.Join(append(totalKeys(len(s.GroupBy)), totalsRecord(s.Total)...), "\t"))
+
+	return tw.Flush()
+}
+
+// WriteSummaryCSV writes the summary as CSV with a header, followed by the total.
+func WriteSummaryCSV(w io.Writer, s *Summary) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{append(append([]string{}, s.GroupBy...), MetricClusters, MetricNodes, MetricVCPU, MetricRAM, MetricStorage)}
+	for _, row := range s.Rows {
+		records = append(records, append(append([]string{}, row.Keys...), totalsRecord(row.Totals)...))
+	}
+	records = append(records, append(csvTotalKeys(len(s.GroupBy)), totalsRecord(s.Total)...))
+
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write inventory in CSV: %w", err)
+	}
+	return nil
+}
+
+// PrintPivot writes the pivot table with a column per value of the column dimension, followed by the totals.
+func PrintPivot(w io.Writer, p *Pivot) error {
+	fmt.Fprintf(w, "%s by %s\n", p.Metric, p.Column)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(append(append(upper(p.GroupBy), p.Columns...), "TOTAL"), "\t"))
+	for _, row := range p.Rows {
+		fmt.Fprintln(tw, strings.Join(append(append([]string{}, row.Keys...), valuesRecord(row.Values, row.Total)...), "\t"))
+	}
+	fmt.Fprintln(tw, strings.Join(append(totalKeys(len(p.GroupBy)), valuesRecord(p.Totals, p.Total)...), "\t"))
+
+	return tw.Flush()
+}
+
+// WritePivotCSV writes the pivot table as CSV with a header, followed by the totals.
+func WritePivotCSV(w io.Writer, p *Pivot) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{append(append(append([]string{}, p.GroupBy...), p.Columns...), "total")}
+	for _, row := range p.Rows {
+		records = append(records, append(append([]string{}, row.Keys...), valuesRecord(row.Values, row.Total)...))
+	}
+	records = append(records, append(csvTotalKeys(len(p.GroupBy)), valuesRecord(p.Totals, p.Total)...))
+
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write inventory in CSV: %w", err)
+	}
+	return nil
+}
+
+func totalsRecord(t Totals) []string {
+	return []string{
+		strconv.Itoa(t.Clusters),
+		strconv.Itoa(t.Nodes),
+		strconv.Itoa(t.VCPU),
+		formatValue(t.RAMGiB),
+		strconv.Itoa(t.StorageGiB),
+	}
+}
+
+func valuesRecord(values []float64, total float64) []string {
+	record := make([]string, 0, len(values)+1)
+	for _, v := range values {
+		record = append(record, formatValue(v))
+	}
+	return append(record, formatValue(total))
+}
+
+func upper(dimensions []string) []string {
+	headers := make([]string, 0, len(dimensions))
+	for _, d := range dimensions {
+		headers = append(headers, strings.ToUpper(strings.ReplaceAll(d, "_", " ")))
+	}
+	return headers
+}
+
+// totalKeys returns the keys of the total row of a table, "TOTAL" in the first column.
+func totalKeys(n int) []string {
+	keys := make([]string, n)
+	if n > 0 {
+		keys[0] = "TOTAL"
+	}
+	return keys
+}
+
+// csvTotalKeys returns the keys of the total row of CSV, "total" in the first column.
+func csvTotalKeys(n int) []string {
+	keys := totalKeys(n)
+	if n > 0 {
+		keys[0] = "total"
+	}
+	return keys
+}
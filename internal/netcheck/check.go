@@ -0,0 +1,155 @@
+package netcheck
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+
+	"github.com/sgykfjsm/msk/internal/vpcinfo"
+)
+
+// This module checks whether a TiDB Cloud project CIDR can be peered with, and routed from, one of our VPCs.
+// Peering fails, or traffic is silently misrouted, when the CIDR overlaps:
+// - the primary or a secondary CIDR block of the VPC (and therefore its subnets)
+// - the primary or a secondary CIDR block of another VPC already peered with the VPC
+// - a more specific route in one of the route tables pointing somewhere else
+
+// Severity tells whether a finding blocks the peering (error) or only needs attention (warning).
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single overlap or conflict found by CheckCIDR.
+type Finding struct {
+	Severity Severity `json:"severity" yaml:"severity"`
+	Kind     string   `json:"kind" yaml:"kind"`         // vpc-cidr, secondary-cidr, subnet, peering, route
+	Resource string   `json:"resource" yaml:"resource"` // ID of the VPC, subnet, peering connection or route table
+	CIDR     string   `json:"cidr" yaml:"cidr"`         // The CIDR of the resource that overlaps
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// Result holds the findings of CheckCIDR.
+type Result struct {
+	CIDR     string    `json:"cidr" yaml:"cidr"`
+	VPCID    string    `json:"vpc_id" yaml:"vpc_id"`
+	Findings []Finding `json:"findings" yaml:"findings"`
+}
+
+// HasErrors reports whether any of the findings is an error.
+func (r *Result) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the findings in a human-readable form.
+func (r *Result) Print(w io.Writer) {
+	if len(r.Findings) == 0 {
+		fmt.Fprintf(w, "[OK] CIDR %s does not overlap with VPC %s, its subnets, peerings or routes\n", r.CIDR, r.VPCID)
+		return
+	}
+
+	for _, f := range r.Findings {
+		label := "[WARN]"
+		if f.Severity == SeverityError {
+			label = "[ERROR]"
+		}
+		fmt.Fprintf(w, "%s %s %s (%s): %s\n", label, f.Kind, f.Resource, f.CIDR, f.Message)
+	}
+}
+
+// CheckCIDR compares the CIDR with everything known about the VPC and reports overlaps and conflicting routes.
+// peerID is the VPC peering connection the CIDR is (or will be) routed to. It may be empty when unknown.
+// Only IPv4 CIDRs are compared; TiDB Cloud project CIDRs are IPv4.
+func CheckCIDR(cidr string, info *vpcinfo.VPCInfo, peerID string) (*Result, error) {
+	target, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("cidr %q should be in CIDR notation, e.g., 192.168.1.0/24: %w", cidr, err)
+	}
+	target = target.Masked()
+
+	result := &Result{CIDR: target.String(), VPCID: info.VPCID}
+	add := func(severity Severity, kind, resource, cidr, message string) {
+		result.Findings = append(result.Findings, Finding{Severity: severity, Kind: kind, Resource: resource, CIDR: cidr, Message: message})
+	}
+
+	if overlaps(target, info.CIDRBlock) {
+		add(SeverityError, "vpc-cidr", info.VPCID, info.CIDRBlock, "overlaps with the primary CIDR block of the VPC")
+	}
+
+	for _, c := range info.SecondaryCIDRBlocks {
+		if overlaps(target, c.CIDRBlock) {
+			add(SeverityError, "secondary-cidr", info.VPCID, c.CIDRBlock, fmt.Sprintf("overlaps with the secondary CIDR block (%s) of the VPC", c.State))
+		}
+	}
+
+	for _, s := range info.Subnets {
+		if overlaps(target, s.CIDRBlock) {
+			add(SeverityError, "subnet", s.SubnetID, s.CIDRBlock, fmt.Sprintf("overlaps with subnet %q in %s", s.Name, s.AvailabilityZoneID))
+		}
+	}
+
+	for _, p := range info.PeeringConnections {
+		if p.PeeringConnectionID == peerID || (p.Status != "active" && p.Status != "pending-acceptance" && p.Status != "provisioning") {
+			continue
+		}
+		for _, otherCIDR := range peerCIDRBlocks(p, info.VPCID) {
+			if overlaps(target, otherCIDR) {
+				add(SeverityError, "peering", p.PeeringConnectionID, otherCIDR, fmt.Sprintf("overlaps with the VPC on the other side of the %s peering connection", p.Status))
+			}
+		}
+	}
+
+	for _, rtb := range info.RouteTables {
+		for _, rt := range rtb.Routes {
+			dest, err := netip.ParsePrefix(rt.Destination)
+			if err != nil || !dest.Addr().Is4() || rt.Target == "local" || !dest.Overlaps(target) {
+				continue // Prefix lists, IPv6 and non-overlapping routes are out of scope
+			}
+			if peerID != "" && rt.Target == peerID {
+				continue
+			}
+
+			switch {
+			case dest.Bits() > target.Bits():
+				// A more specific route always wins, so part of the CIDR would be routed elsewhere.
+				add(SeverityError, "route", rtb.RouteTableID, rt.Destination,
+					fmt.Sprintf("more specific route in %q sends part of the CIDR to %s", rtb.Name, rt.Target))
+			case dest.Bits() == target.Bits():
+				add(SeverityWarning, "route", rtb.RouteTableID, rt.Destination,
+					fmt.Sprintf("route in %q sends the CIDR to %s; update-routes would replace it", rtb.Name, rt.Target))
+			}
+			// A less specific route (e.g. 0.0.0.0/0) is overridden by the route to the CIDR and is not a conflict.
+		}
+	}
+
+	return result, nil
+}
+
+// peerCIDRBlocks returns the CIDR blocks of the VPC on the other side of the peering, including the secondary ones.
+func peerCIDRBlocks(p vpcinfo.PeeringConnection, vpcID string) []string {
+	primary, blocks := p.RequesterCIDRBlock, p.RequesterCIDRBlocks
+	if p.RequesterVPCID == vpcID {
+		primary, blocks = p.AccepterCIDRBlock, p.AccepterCIDRBlocks
+	}
+	if primary != "" && !slices.Contains(blocks, primary) {
+		blocks = append([]string{primary}, blocks...)
+	}
+	return blocks
+}
+
+// overlaps reports whether the CIDR overlaps with the target. Invalid or empty CIDRs never overlap.
+func overlaps(target netip.Prefix, cidr string) bool {
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return false
+	}
+	return p.Overlaps(target)
+}
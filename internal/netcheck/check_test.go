@@ -0,0 +1,86 @@
+package netcheck
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/vpcinfo"
+	"github.com/stretchr/testify/require"
+)
+
+func testVPCInfo() *vpcinfo.VPCInfo {
+	return &vpcinfo.VPCInfo{
+		VPCID:               "vpc-1",
+		CIDRBlock:           "10.0.0.0/16",
+		SecondaryCIDRBlocks: []vpcinfo.CIDRBlockAssociation{{CIDRBlock: "100.64.0.0/16", State: "associated"}},
+		Subnets: []vpcinfo.Subnet{
+			{SubnetID: "subnet-1", CIDRBlock: "10.0.1.0/24"},
+			{SubnetID: "subnet-2", CIDRBlock: "100.64.1.0/24"},
+		},
+		RouteTables: []vpcinfo.RouteTable{{
+			RouteTableID: "rtb-1",
+			Routes: []vpcinfo.Route{
+				{Destination: "10.0.0.0/16", Target: "local"},
+				{Destination: "0.0.0.0/0", Target: "igw-1"},
+				{Destination: "172.16.0.0/21", Target: "pcx-tidb"},
+				{Destination: "192.168.10.0/24", Target: "tgw-1"},
+				{Destination: "192.168.20.0/22", Target: "pcx-other"},
+				{Destination: "pl-1234", Target: "vpce-1"},
+			},
+		}},
+		PeeringConnections: []vpcinfo.PeeringConnection{
+			{PeeringConnectionID: "pcx-other", Status: "active", RequesterVPCID: "vpc-1", AccepterCIDRBlock: "192.168.16.0/20", AccepterCIDRBlocks: []string{"192.168.16.0/20", "198.18.0.0/24"}},
+			{PeeringConnectionID: "pcx-deleted", Status: "deleted", RequesterVPCID: "vpc-1", AccepterCIDRBlock: "172.31.0.0/16"},
+		},
+	}
+}
+
+func TestCheckCIDR(t *testing.T) {
+	tests := []struct {
+		name      string
+		cidr      string
+		peerID    string
+		kinds     []string
+		hasErrors bool
+	}{
+		{"noOverlap", "172.16.0.0/21", "pcx-tidb", nil, false},
+		{"routeToOtherTargetSameCIDR", "172.16.0.0/21", "pcx-new", []string{"route"}, false},
+		{"primaryCIDR", "10.0.0.0/8", "", []string{"vpc-cidr", "subnet"}, true},
+		{"secondaryCIDR", "100.64.1.0/24", "", []string{"secondary-cidr", "subnet"}, true},
+		{"moreSpecificRoute", "192.168.0.0/16", "", []string{"peering", "route", "route"}, true},
+		{"deletedPeeringIgnored", "172.31.0.0/20", "", nil, false},
+		{"peerSecondaryCIDR", "198.18.0.0/16", "", []string{"peering"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CheckCIDR(tt.cidr, testVPCInfo(), tt.peerID)
+			require.NoError(t, err)
+
+			var kinds []string
+			for _, f := range result.Findings {
+				kinds = append(kinds, f.Kind)
+			}
+			require.Equal(t, tt.kinds, kinds)
+			require.Equal(t, tt.hasErrors, result.HasErrors())
+		})
+	}
+}
+
+func TestCheckCIDR_InvalidCIDR(t *testing.T) {
+	_, err := CheckCIDR("172.16.0.0", testVPCInfo(), "")
+	require.Error(t, err)
+}
+
+func TestResult_Print(t *testing.T) {
+	var buf bytes.Buffer
+	result, err := CheckCIDR("172.16.0.0/21", testVPCInfo(), "pcx-tidb")
+	require.NoError(t, err)
+	result.Print(&buf)
+	require.Equal(t, "[OK] CIDR 172.16.0.0/21 does not overlap with VPC vpc-1, its subnets, peerings or routes\n", buf.String())
+
+	buf.Reset()
+	result, err = CheckCIDR("100.64.1.0/24", testVPCInfo(), "")
+	require.NoError(t, err)
+	result.Print(&buf)
+	require.Contains(t, buf.String(), "[ERROR] secondary-cidr vpc-1 (100.64.0.0/16)")
+}
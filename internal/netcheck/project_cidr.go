@@ -0,0 +1,96 @@
+package netcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// NetworkContainer is the CIDR reserved for a TiDB Cloud project in a region (the "Project CIDR" in the console).
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta1/dedicated/#tag/NetworkContainerService
+type NetworkContainer struct {
+	NetworkContainerID string `json:"networkContainerId,omitempty"`
+	ProjectID          string `json:"projectId,omitempty"`
+	CloudProvider      string `json:"cloudProvider,omitempty"`
+	RegionID           string `json:"regionId,omitempty"`
+	CIDRNotation       string `json:"cidrNotation,omitempty"`
+	State              string `json:"state,omitempty"`
+}
+
+// ListNetworkContainersResponse is the response of the ListNetworkContainers API.
+type ListNetworkContainersResponse struct {
+	NetworkContainers []NetworkContainer `json:"networkContainers,omitempty"`
+	NextPageToken     string             `json:"nextPageToken,omitempty"`
+}
+
+// ProjectCIDRFetcher defines an interface for fetching the CIDRs of a TiDB Cloud project.
+type ProjectCIDRFetcher interface {
+	FetchProjectCIDRs(ctx context.Context, projectID string) ([]NetworkContainer, error)
+}
+
+// APIProjectCIDRFetcher implements ProjectCIDRFetcher using the TiDB Cloud Dedicated API.
+type APIProjectCIDRFetcher struct {
+	Client       *http.Client
+	EndpointBase string
+}
+
+// DefaultDedicatedAPIEndpointBase is the base URL of the TiDB Cloud Dedicated API.
+const DefaultDedicatedAPIEndpointBase = "https://dedicated.tidbapi.com/v1beta1"
+
+// NewAPIProjectCIDRFetcher returns a new APIProjectCIDRFetcher with the given HTTP client (expected to handle digest auth).
+func NewAPIProjectCIDRFetcher(client *http.Client, endpointBase string) *APIProjectCIDRFetcher {
+	if endpointBase == "" {
+		endpointBase = DefaultDedicatedAPIEndpointBase
+	}
+
+	return &APIProjectCIDRFetcher{
+		Client:       client,
+		EndpointBase: endpointBase,
+	}
+}
+
+// FetchProjectCIDRs returns all network containers (project CIDRs) of the project, following pagination.
+func (f *APIProjectCIDRFetcher) FetchProjectCIDRs(ctx context.Context, projectID string) ([]NetworkContainer, error) {
+	apiEndpoint, err := url.JoinPath(f.EndpointBase, "networkContainers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct API endpoint URL started with %s: %w", f.EndpointBase, err)
+	}
+
+	var containers []NetworkContainer
+	pageToken := ""
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiEndpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Set("projectId", projectID)
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := f.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page ListNetworkContainersResponse
+		err = util.DecodeTiDBCloudResponse(resp, apiEndpoint, &page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network containers of project %s: %w", projectID, err)
+		}
+
+		containers = append(containers, page.NetworkContainers...)
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return containers, nil
+}
@@ -0,0 +1,47 @@
+package netcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIProjectCIDRFetcher_FetchProjectCIDRs_Pagination(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/networkContainers", r.URL.Path)
+		require.Equal(t, "p1", r.URL.Query().Get("projectId"))
+
+		resp := ListNetworkContainersResponse{
+			NetworkContainers: []NetworkContainer{{NetworkContainerID: "nc-1", RegionID: "aws-us-west-2", CIDRNotation: "172.16.0.0/21"}},
+			NextPageToken:     "next",
+		}
+		if r.URL.Query().Get("pageToken") == "next" {
+			resp = ListNetworkContainersResponse{
+				NetworkContainers: []NetworkContainer{{NetworkContainerID: "nc-2", RegionID: "aws-ap-northeast-1", CIDRNotation: "172.16.8.0/21"}},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	containers, err := NewAPIProjectCIDRFetcher(server.Client(), server.URL).FetchProjectCIDRs(context.Background(), "p1")
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+	require.Equal(t, "172.16.8.0/21", containers[1].CIDRNotation)
+}
+
+func TestAPIProjectCIDRFetcher_FetchProjectCIDRs_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewAPIProjectCIDRFetcher(server.Client(), server.URL).FetchProjectCIDRs(context.Background(), "p1")
+	require.ErrorContains(t, err, "unauthorized")
+}
@@ -0,0 +1,379 @@
+package notice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusterops"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/exemptions"
+)
+
+// This module builds the usage summary of the clusters collected by fetch-clusters and renders it as a notice.
+// The summary is the data model given to the notice templates, so the exported fields of the types below
+// are the documented template interface: renaming or removing them breaks user-defined templates.
+
+// Kinds of findings of the analysis.
+const (
+	FindingLongRunning = "long_running" // The cluster has been available for longer than the running threshold
+	FindingStaleBackup = "stale_backup" // The latest successful backup is older than the backup threshold, or there is none
+)
+
+// Notice is the usage summary, given to the templates as ".".
+type Notice struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	Currency     string    `json:"currency"`
+	Projects     []Project `json:"projects"`      // Projects with at least one active cluster, ordered by name
+	Findings     []Finding `json:"findings"`      // All findings, ordered by project and cluster
+	ClusterCount int       `json:"cluster_count"` // Active clusters
+	NodeCount    int       `json:"node_count"`
+	HourlyCost   float64   `json:"hourly_cost"`   // Estimated cost of the clusters with price data
+	HasCost      bool      `json:"has_cost"`      // At least one cluster has price data
+	CostComplete bool      `json:"cost_complete"` // All clusters have price data
+}
+
+// Project is a project with its active clusters.
+type Project struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Clusters     []Cluster `json:"clusters"` // Ordered by name
+	HourlyCost   float64   `json:"hourly_cost"`
+	CostComplete bool      `json:"cost_complete"`
+}
+
+// Cluster is an active cluster with its nodes, estimated cost and findings.
+type Cluster struct {
+	ID            string        `json:"id"`
+	ProjectID     string        `json:"project_id"`
+	ProjectName   string        `json:"project_name"`
+	Name          string        `json:"name"`
+	ClusterType   string        `json:"cluster_type"`
+	CloudProvider string        `json:"cloud_provider"`
+	Region        string        `json:"region"`
+	TiDBVersion   string        `json:"tidb_version"`
+	Status        string        `json:"status"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Age           time.Duration `json:"-"`          // Since CreatedAt at GeneratedAt. Written as "age_seconds" in JSON
+	Components    string        `json:"components"` // e.g. "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)"
+	Nodes         []Node        `json:"nodes"`
+	HourlyCost    float64       `json:"hourly_cost"` // Estimated from the node configuration, regardless of the status
+	HasCost       bool          `json:"has_cost"`
+	Findings      []Finding     `json:"findings"`
+	// Exemption is the active exemption of the cluster from the long-running analysis, if any.
+	Exemption *exemptions.Exemption `json:"exemption,omitempty"`
+}
+
+// Node is a node of a cluster.
+type Node struct {
+	Component        string `json:"component"` // tidb, tikv or tiflash
+	Name             string `json:"name"`
+	AvailabilityZone string `json:"availability_zone"`
+	Size             string `json:"size"`
+	VCPU             int    `json:"vcpu"`
+	RAMBytes         int64  `json:"ram_bytes"`
+	StorageSizeGib   int    `json:"storage_size_gib"`
+	Status           string `json:"status"`
+}
+
+// Finding is a result of the analysis that needs attention.
+type Finding struct {
+	Kind        string `json:"kind"`
+	ProjectID   string `json:"project_id"`
+	ClusterID   string `json:"cluster_id"`
+	ClusterName string `json:"cluster_name"`
+	Message     string `json:"message"`
+	// Seconds is how long the condition has lasted: the age of the cluster for long_running, and the age of
+	// the latest successful backup for stale_backup, or of the cluster if there is no successful backup.
+	Seconds int64 `json:"seconds"`
+}
+
+// Options controls the analysis and the cost estimation.
+type Options struct {
+	Now              time.Time
+	RunningThreshold time.Duration       // 0 disables the long_running findings
+	BackupThreshold  time.Duration       // 0 disables the stale_backup findings
+	Pricing          *clusterops.Pricing // nil if there is no price data
+}
+
+// Store defines an interface for reading the inventory collected by fetch-projects and fetch-clusters.
+type Store interface {
+	ListProjects(ctx context.Context) ([]db.Project, error)
+	ListClusters(ctx context.Context) ([]db.Cluster, error)
+	ListNodeMaps(ctx context.Context) (map[string]clusters.NodeMap, error)
+	ListLatestSuccessfulBackups(ctx context.Context) ([]backups.ClusterBackupStatus, error)
+	ListExemptions(ctx context.Context, expiresAfter time.Time) ([]exemptions.Exemption, error)
+}
+
+// DBStore implements Store on top of the tables filled by fetch-projects and fetch-clusters.
+type DBStore struct {
+	clusters   *clusters.DBClusterStore
+	backups    *backups.DBBackupStore
+	exemptions *exemptions.DBExemptionStore
+}
+
+// NewDBStore returns a DBStore reading with the given queries. The caller owns the connection under them.
+func NewDBStore(queries *db.Queries) *DBStore {
+	return &DBStore{
+		clusters:   &clusters.DBClusterStore{Queries: queries},
+		backups:    &backups.DBBackupStore{Queries: queries},
+		exemptions: &exemptions.DBExemptionStore{Queries: queries},
+	}
+}
+
+func (s *DBStore) ListProjects(ctx context.Context) ([]db.Project, error) {
+	projects, err := s.clusters.Queries.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	return projects, nil
+}
+
+func (s *DBStore) ListClusters(ctx context.Context) ([]db.Cluster, error) {
+	clusters, err := s.clusters.Queries.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	return clusters, nil
+}
+
+func (s *DBStore) ListNodeMaps(ctx context.Context) (map[string]clusters.NodeMap, error) {
+	return s.clusters.ListNodeMaps(ctx)
+}
+
+func (s *DBStore) ListLatestSuccessfulBackups(ctx context.Context) ([]backups.ClusterBackupStatus, error) {
+	return s.backups.ListLatestSuccessfulBackups(ctx)
+}
+
+func (s *DBStore) ListExemptions(ctx context.Context, expiresAfter time.Time) ([]exemptions.Exemption, error) {
+	return s.exemptions.ListExemptions(ctx, expiresAfter)
+}
+
+// Build reads the inventory from the store and builds the notice.
+func Build(ctx context.Context, store Store, opts Options) (*Notice, error) {
+	projects, err := store.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodeMaps, err := store.ListNodeMaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []backups.ClusterBackupStatus
+	if opts.BackupThreshold > 0 {
+		statuses, err = store.ListLatestSuccessfulBackups(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	exempted, err := store.ListExemptions(ctx, opts.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(projects, rows, nodeMaps, statuses, exempted, opts), nil
+}
+
+// New builds the notice of the active clusters. Deleted clusters and projects without active clusters are left out.
+// The clusters with an active exemption have no long_running finding.
+func New(projects []db.Project, rows []db.Cluster, nodeMaps map[string]clusters.NodeMap, statuses []backups.ClusterBackupStatus, exempted []exemptions.Exemption, opts Options) *Notice {
+	// Lists are never nil, so that they are written as [] rather than null in JSON
+	n := &Notice{GeneratedAt: opts.Now.UTC(), Currency: "USD", Projects: []Project{}}
+	if opts.Pricing != nil && opts.Pricing.Currency != "" {
+		n.Currency = opts.Pricing.Currency
+	}
+
+	active := exemptions.Active(exempted, opts.Now)
+	staleBackups := make(map[string]backups.ClusterBackupStatus)
+	for _, status := range backups.FindStaleBackups(statuses, opts.Now, opts.BackupThreshold) {
+		staleBackups[status.ClusterID] = status
+	}
+
+	names := make(map[string]string, len(projects))
+	order := make(map[string]int, len(projects))
+	for i, p := range projects {
+		names[p.ID] = p.Name
+		order[p.ID] = i
+	}
+
+	byProject := make(map[string]*Project)
+	for _, row := range rows {
+		if row.IsDeleted {
+			continue
+		}
+
+		var exemption *exemptions.Exemption
+		if e, ok := active[row.ID]; ok {
+			exemption = &e
+		}
+		cluster := newCluster(row, nodeMaps[row.ID], names[row.ProjectID], exemption, opts)
+		if status, ok := staleBackups[row.ID]; ok {
+			message, since := "no successful backup", cluster.Age
+			if status.LatestBackupAt != nil {
+				since = opts.Now.Sub(*status.LatestBackupAt)
+				message = fmt.Sprintf("latest successful backup is %s old", formatDuration(since))
+			}
+			cluster.Findings = append(cluster.Findings, cluster.finding(FindingStaleBackup, message, since))
+		}
+
+		p, ok := byProject[row.ProjectID]
+		if !ok {
+			p = &Project{ID: row.ProjectID, Name: names[row.ProjectID]}
+			byProject[row.ProjectID] = p
+		}
+		p.Clusters = append(p.Clusters, cluster)
+	}
+
+	for _, p := range byProject {
+		sort.SliceStable(p.Clusters, func(i, j int) bool { return p.Clusters[i].Name < p.Clusters[j].Name })
+		n.Projects = append(n.Projects, *p)
+	}
+	sort.SliceStable(n.Projects, func(i, j int) bool {
+		oi, iKnown := order[n.Projects[i].ID]
+		oj, jKnown := order[n.Projects[j].ID]
+		if iKnown != jKnown {
+			return iKnown // Projects not fetched by fetch-projects come last
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return n.Projects[i].ID < n.Projects[j].ID
+	})
+	n.summarize()
+
+	return n
+}
+
+// summarize computes the totals and collects the findings of the projects and their clusters.
+func (n *Notice) summarize() {
+	n.Findings = []Finding{}
+	n.ClusterCount, n.NodeCount = 0, 0
+	n.HourlyCost, n.HasCost, n.CostComplete = 0, false, len(n.Projects) > 0
+
+	for i := range n.Projects {
+		p := &n.Projects[i]
+		p.HourlyCost, p.CostComplete = 0, true
+		for _, c := range p.Clusters {
+			p.HourlyCost += c.HourlyCost
+			p.CostComplete = p.CostComplete && c.HasCost
+
+			n.ClusterCount++
+			n.NodeCount += len(c.Nodes)
+			n.HourlyCost += c.HourlyCost
+			n.HasCost = n.HasCost || c.HasCost
+			n.CostComplete = n.CostComplete && c.HasCost
+			n.Findings = append(n.Findings, c.Findings...)
+		}
+	}
+}
+
+// ForProjects returns a copy of the notice with only the given projects, or all of them for "*".
+func (n *Notice) ForProjects(projectIDs []string) *Notice {
+	all := slices.Contains(projectIDs, "*")
+	return n.Filter(func(c Cluster) bool { return all || slices.Contains(projectIDs, c.ProjectID) })
+}
+
+// Filter returns a copy of the notice with only the clusters to keep. Projects left without clusters are removed.
+func (n *Notice) Filter(keep func(c Cluster) bool) *Notice {
+	filtered := *n
+	filtered.Projects = []Project{}
+	for _, p := range n.Projects {
+		clusters := []Cluster{}
+		for _, c := range p.Clusters {
+			if keep(c) {
+				clusters = append(clusters, c)
+			}
+		}
+		if len(clusters) > 0 {
+			p.Clusters = clusters
+			filtered.Projects = append(filtered.Projects, p)
+		}
+	}
+	filtered.summarize()
+
+	return &filtered
+}
+
+// FilterFindings returns a copy of the notice with only the findings to keep, and only the clusters with
+// at least one of them.
+func (n *Notice) FilterFindings(keep func(f Finding) bool) *Notice {
+	filtered := n.Filter(func(c Cluster) bool { return slices.ContainsFunc(c.Findings, keep) })
+	for i := range filtered.Projects {
+		p := &filtered.Projects[i]
+		p.Clusters = slices.Clone(p.Clusters) // Not to change the clusters of the original
+		for j := range p.Clusters {
+			p.Clusters[j].Findings = slices.DeleteFunc(slices.Clone(p.Clusters[j].Findings), func(f Finding) bool { return !keep(f) })
+		}
+	}
+	filtered.summarize()
+
+	return filtered
+}
+
+func newCluster(row db.Cluster, nodeMap clusters.NodeMap, projectName string, exemption *exemptions.Exemption, opts Options) Cluster {
+	createdAt := time.Unix(row.CreateTimestamp, 0).UTC()
+	components := clusters.ComponentsFromNodeMap(nodeMap)
+	cluster := Cluster{
+		ID:            row.ID,
+		ProjectID:     row.ProjectID,
+		ProjectName:   projectName,
+		Name:          row.Name,
+		ClusterType:   row.ClusterType,
+		CloudProvider: row.CloudProvider,
+		Region:        row.Region,
+		TiDBVersion:   row.TidbVersion,
+		Status:        row.ClusterStatus,
+		CreatedAt:     createdAt,
+		Age:           opts.Now.Sub(createdAt),
+		Components:    components.String(),
+		Nodes:         []Node{},
+		Findings:      []Finding{},
+		Exemption:     exemption,
+	}
+
+	for _, component := range []struct {
+		name  string
+		nodes clusters.Nodes
+	}{
+		{clusters.ComponentTiDB, nodeMap.Tidb},
+		{clusters.ComponentTiKV, nodeMap.Tikv},
+		{clusters.ComponentTiFlash, nodeMap.Tiflash},
+	} {
+		for _, node := range component.nodes {
+			ramBytes, _ := strconv.ParseInt(node.RAMBytes, 10, 64) // Unknown RAM is left as 0
+			cluster.Nodes = append(cluster.Nodes, Node{
+				Component:        component.name,
+				Name:             node.NodeName,
+				AvailabilityZone: node.AvailabilityZone,
+				Size:             node.NodeSize,
+				VCPU:             node.VcpuNum,
+				RAMBytes:         ramBytes,
+				StorageSizeGib:   node.StorageSizeGib,
+				Status:           node.Status,
+			})
+		}
+	}
+
+	if cost, err := opts.Pricing.HourlyCost(components); err == nil && len(cluster.Nodes) > 0 {
+		cluster.HourlyCost, cluster.HasCost = cost, true
+	}
+
+	if opts.RunningThreshold > 0 && exemption == nil && row.ClusterStatus == clusters.ClusterStatusAvailable && cluster.Age >= opts.RunningThreshold {
+		cluster.Findings = append(cluster.Findings, cluster.finding(FindingLongRunning, fmt.Sprintf("available and created %s ago", formatDuration(cluster.Age)), cluster.Age))
+	}
+
+	return cluster
+}
+
+func (c Cluster) finding(kind, message string, since time.Duration) Finding {
+	return Finding{Kind: kind, ProjectID: c.ProjectID, ClusterID: c.ID, ClusterName: c.Name, Message: message, Seconds: int64(since / time.Second)}
+}
@@ -0,0 +1,171 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusterops"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/exemptions"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeStore is an in-memory Store.
+type fakeStore struct {
+	projects []db.Project
+	clusters []db.Cluster
+	nodeMaps map[string]clusters.NodeMap
+	statuses []backups.ClusterBackupStatus
+	exempted []exemptions.Exemption
+}
+
+func (f *fakeStore) ListProjects(ctx context.Context) ([]db.Project, error) { return f.projects, nil }
+func (f *fakeStore) ListClusters(ctx context.Context) ([]db.Cluster, error) { return f.clusters, nil }
+func (f *fakeStore) ListNodeMaps(ctx context.Context) (map[string]clusters.NodeMap, error) {
+	return f.nodeMaps, nil
+}
+func (f *fakeStore) ListLatestSuccessfulBackups(ctx context.Context) ([]backups.ClusterBackupStatus, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeStore) ListExemptions(ctx context.Context, expiresAfter time.Time) ([]exemptions.Exemption, error) {
+	return f.exempted, nil
+}
+
+func newTestStore() *fakeStore {
+	node := func(name, size string, storage int) clusters.Node {
+		return clusters.Node{NodeName: name, NodeSize: size, VcpuNum: 8, RAMBytes: "17179869184", StorageSizeGib: storage, Status: "NORMAL"}
+	}
+	lastBackup := testNow.Add(-48 * time.Hour)
+
+	return &fakeStore{
+		projects: []db.Project{{ID: "p2", Name: "analytics"}, {ID: "p1", Name: "payments"}},
+		clusters: []db.Cluster{
+			{ID: "c1", ProjectID: "p1", Name: "pay-main", CloudProvider: "AWS", Region: "us-west-2", ClusterStatus: "AVAILABLE", TidbVersion: "v7.5.1", CreateTimestamp: testNow.Add(-30 * 24 * time.Hour).Unix()},
+			{ID: "c2", ProjectID: "p1", Name: "pay-old", ClusterStatus: "AVAILABLE", IsDeleted: true},
+			{ID: "c3", ProjectID: "p2", Name: "bi", CloudProvider: "GCP", Region: "us-central1", ClusterStatus: "PAUSED", TidbVersion: "v8.1.0", CreateTimestamp: testNow.Add(-40 * 24 * time.Hour).Unix()},
+			{ID: "c4", ProjectID: "p1", Name: "pay-dev", ClusterStatus: "AVAILABLE", TidbVersion: "v8.1.0", CreateTimestamp: testNow.Add(-2 * time.Hour).Unix()},
+		},
+		nodeMaps: map[string]clusters.NodeMap{
+			"c1": {
+				Tidb: clusters.Nodes{node("tidb-0", "8C16G", 0), node("tidb-1", "8C16G", 0)},
+				Tikv: clusters.Nodes{node("tikv-0", "8C32G", 500), node("tikv-1", "8C32G", 500), node("tikv-2", "8C32G", 500)},
+			},
+			"c3": {
+				Tidb: clusters.Nodes{node("tidb-0", "16C32G", 0)},
+				Tikv: clusters.Nodes{node("tikv-0", "8C32G", 200), node("tikv-1", "8C32G", 200), node("tikv-2", "8C32G", 200)},
+			},
+			"c4": {
+				Tidb: clusters.Nodes{node("tidb-0", "8C16G", 0)},
+				Tikv: clusters.Nodes{node("tikv-0", "8C32G", 200), node("tikv-1", "8C32G", 200), node("tikv-2", "8C32G", 200)},
+			},
+		},
+		statuses: []backups.ClusterBackupStatus{
+			{ProjectID: "p1", ClusterID: "c1", ClusterName: "pay-main", LatestBackupAt: &lastBackup},
+			{ProjectID: "p1", ClusterID: "c4", ClusterName: "pay-dev"},
+		},
+	}
+}
+
+var testPricing = &clusterops.Pricing{
+	NodeHourly:       map[string]map[string]float64{"tidb": {"8C16G": 1}, "tikv": {"8C32G": 2}},
+	StorageGibHourly: map[string]float64{"tikv": 0.001},
+}
+
+func TestBuild(t *testing.T) {
+	n, err := Build(context.Background(), newTestStore(), Options{
+		Now:              testNow,
+		RunningThreshold: 7 * 24 * time.Hour,
+		BackupThreshold:  24 * time.Hour,
+		Pricing:          testPricing,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, n.ClusterCount)
+	require.Equal(t, 13, n.NodeCount)
+	require.Equal(t, "USD", n.Currency)
+
+	// Ordered by project name, deleted clusters are left out
+	require.Len(t, n.Projects, 2)
+	require.Equal(t, "analytics", n.Projects[0].Name)
+	require.Equal(t, []string{"pay-dev", "pay-main"}, []string{n.Projects[1].Clusters[0].Name, n.Projects[1].Clusters[1].Name})
+
+	// c3 has no price data for 16C32G, so the costs are partial
+	main := n.Projects[1].Clusters[1]
+	require.True(t, main.HasCost)
+	require.InDelta(t, 2+6+1.5, main.HourlyCost, 1e-9)
+	require.Equal(t, "TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)", main.Components)
+	require.Equal(t, int64(16<<30), main.Nodes[0].RAMBytes)
+	require.False(t, n.Projects[0].Clusters[0].HasCost)
+	require.True(t, n.HasCost)
+	require.False(t, n.CostComplete)
+	require.True(t, n.Projects[1].CostComplete)
+
+	require.Equal(t, []Finding{
+		{Kind: FindingStaleBackup, ProjectID: "p1", ClusterID: "c4", ClusterName: "pay-dev", Message: "no successful backup", Seconds: 2 * 60 * 60},
+		{Kind: FindingLongRunning, ProjectID: "p1", ClusterID: "c1", ClusterName: "pay-main", Message: "available and created 30d 0h ago", Seconds: 30 * 24 * 60 * 60},
+		{Kind: FindingStaleBackup, ProjectID: "p1", ClusterID: "c1", ClusterName: "pay-main", Message: "latest successful backup is 2d 0h old", Seconds: 48 * 60 * 60},
+	}, n.Findings)
+}
+
+func TestBuild_FindingsDisabled(t *testing.T) {
+	n, err := Build(context.Background(), newTestStore(), Options{Now: testNow})
+	require.NoError(t, err)
+	require.Empty(t, n.Findings)
+	require.False(t, n.HasCost)
+}
+
+func TestNotice_ForProjects(t *testing.T) {
+	n := testNotice(t)
+
+	p1 := n.ForProjects([]string{"p1", "unknown"})
+	require.Len(t, p1.Projects, 1)
+	require.Equal(t, 2, p1.ClusterCount)
+	require.Equal(t, 9, p1.NodeCount)
+	require.Len(t, p1.Findings, 3)
+	require.Equal(t, 3, n.ClusterCount) // The original is not changed
+
+	require.Equal(t, n.ClusterCount, n.ForProjects([]string{"*"}).ClusterCount)
+	require.Zero(t, n.ForProjects([]string{"unknown"}).ClusterCount)
+}
+
+func TestNotice_FilterFindings(t *testing.T) {
+	n := testNotice(t)
+	require.Len(t, n.Findings, 3)
+
+	filtered := n.FilterFindings(func(f Finding) bool { return f.Kind == FindingStaleBackup })
+	require.Equal(t, 2, filtered.ClusterCount)
+	require.Len(t, filtered.Findings, 2)
+	require.Len(t, filtered.Projects[0].Clusters[0].Findings, 1)
+	require.Equal(t, int64(2*60*60), filtered.Findings[0].Seconds) // pay-dev has no successful backup, so its age
+	require.Len(t, n.Projects[1].Clusters[1].Findings, 2)          // The original is not changed
+}
+
+func TestBuild_Exemption(t *testing.T) {
+	store := newTestStore()
+	store.exempted = []exemptions.Exemption{
+		{ID: 1, ClusterID: "c1", Reason: "shared staging", CreatedBy: "alice", ExpiresAt: testNow.Add(24 * time.Hour)},
+		{ID: 2, ClusterID: "c4", Reason: "expired", CreatedBy: "bob", ExpiresAt: testNow.Add(-time.Hour)},
+	}
+	n, err := Build(context.Background(), store, Options{Now: testNow, RunningThreshold: time.Hour, BackupThreshold: 24 * time.Hour})
+	require.NoError(t, err)
+
+	// pay-main is exempted from the long-running analysis only, and the exemption of pay-dev has expired
+	var kinds []string
+	for _, f := range n.Findings {
+		kinds = append(kinds, f.ClusterName+" "+f.Kind)
+	}
+	require.Equal(t, []string{"pay-dev long_running", "pay-dev stale_backup", "pay-main stale_backup"}, kinds)
+	require.Equal(t, "shared staging", n.Projects[1].Clusters[1].Exemption.Reason)
+	require.Nil(t, n.Projects[1].Clusters[0].Exemption)
+
+	var buf bytes.Buffer
+	require.NoError(t, DefaultTemplate().Execute(&buf, n))
+	require.Contains(t, buf.String(), "| pay-main (c1) | AVAILABLE (exempt until 2025-06-02 12:00 UTC) |")
+}
@@ -0,0 +1,254 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/sgykfjsm/msk/internal/clusterops"
+	"github.com/sgykfjsm/msk/internal/clusters"
+)
+
+// Formats of the rendered notice.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatMarkdown, FormatHTML, FormatJSON, FormatCSV}
+
+// JSONSchemaVersion is the version of the JSON document written by JSONRenderer.
+// Adding fields keeps the version, while renaming, removing or changing the meaning of fields bumps it.
+const JSONSchemaVersion = 1
+
+// Renderer renders a notice in a format.
+type Renderer interface {
+	Render(w io.Writer, n *Notice) error
+	// Extension is the file extension of the rendered notice, without the leading dot.
+	Extension() string
+}
+
+// NewRenderer returns the renderer of the format. Markdown and HTML are rendered with the given template,
+// or with the built-in one of the format if it is nil.
+func NewRenderer(format string, tmpl *Template) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case FormatMarkdown:
+		if tmpl == nil {
+			tmpl = DefaultTemplate()
+		}
+		return &TemplateRenderer{Template: tmpl, ext: "md"}, nil
+	case FormatHTML:
+		if tmpl == nil {
+			tmpl = DefaultHTMLTemplate()
+		}
+		return &TemplateRenderer{Template: tmpl, ext: "html"}, nil
+	case FormatJSON:
+		return JSONRenderer{}, nil
+	case FormatCSV:
+		return CSVRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("invalid format: %s, allowed formats are: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// KeyPrefix is the prefix of the keys of the notices in the blob store.
+const KeyPrefix = "notices/"
+
+// Key returns the key of the notice rendered with the extension, e.g. "notices/20250601T120000Z/notice.json".
+// The keys sort by the time of generation, so the latest notice is listed last.
+func Key(generatedAt time.Time, ext string) string {
+	return KeyPrefix + generatedAt.UTC().Format("20060102T150405Z") + "/notice." + ext
+}
+
+// Save renders the notice with each renderer and writes them to the store. It returns the written keys.
+func Save(ctx context.Context, store blob.Store, n *Notice, renderers []Renderer) ([]string, error) {
+	keys := make([]string, 0, len(renderers))
+	for _, r := range renderers {
+		var buf bytes.Buffer
+		if err := r.Render(&buf, n); err != nil {
+			return keys, err
+		}
+		key := Key(n.GeneratedAt, r.Extension())
+		if err := store.Put(ctx, key, buf.Bytes()); err != nil {
+			return keys, fmt.Errorf("failed to save notice %s: %w", key, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// TemplateRenderer renders a notice with a template.
+type TemplateRenderer struct {
+	Template *Template
+	ext      string
+}
+
+func (r *TemplateRenderer) Render(w io.Writer, n *Notice) error {
+	return r.Template.Execute(w, n)
+}
+
+func (r *TemplateRenderer) Extension() string {
+	return r.ext
+}
+
+// JSONRenderer renders a notice as a JSON document: the fields of Notice with "schema_version".
+// Durations are written in seconds, e.g. "age_seconds" of the clusters.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(w io.Writer, n *Notice) error {
+	doc := struct {
+		SchemaVersion int `json:"schema_version"`
+		*Notice
+	}{JSONSchemaVersion, n}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode notice to JSON: %w", err)
+	}
+	return nil
+}
+
+func (JSONRenderer) Extension() string {
+	return "json"
+}
+
+// MarshalJSON writes Age as "age_seconds" instead of nanoseconds.
+func (c Cluster) MarshalJSON() ([]byte, error) {
+	type cluster Cluster // Without the methods, to avoid recursion
+	return json.Marshal(struct {
+		cluster
+		AgeSeconds int64 `json:"age_seconds"`
+	}{cluster(c), int64(c.Age / time.Second)})
+}
+
+// UnmarshalJSON reads Age from "age_seconds".
+func (c *Cluster) UnmarshalJSON(data []byte) error {
+	type cluster Cluster // Without the methods, to avoid recursion
+	var doc struct {
+		cluster
+		AgeSeconds int64 `json:"age_seconds"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*c = Cluster(doc.cluster)
+	c.Age = time.Duration(doc.AgeSeconds) * time.Second
+	return nil
+}
+
+// Decode reads a notice written by JSONRenderer. It fails if the schema version is not supported.
+func Decode(data []byte) (*Notice, error) {
+	doc := struct {
+		SchemaVersion int `json:"schema_version"`
+		*Notice
+	}{Notice: &Notice{}}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode notice: %w", err)
+	}
+	if doc.SchemaVersion != JSONSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema_version %d of notice, expected %d", doc.SchemaVersion, JSONSchemaVersion)
+	}
+
+	return doc.Notice, nil
+}
+
+// LoadLatest reads the latest notice saved in JSON to the store. It returns the notice and its key.
+func LoadLatest(ctx context.Context, store blob.Store) (*Notice, string, error) {
+	keys, err := store.List(ctx, KeyPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list notices: %w", err)
+	}
+
+	var latest string
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/notice."+JSONRenderer{}.Extension()) {
+			latest = key // Keys are listed in lexical order, which is the order of generation
+		}
+	}
+	if latest == "" {
+		return nil, "", errors.New("no notice in JSON is found, run generate-notice with --format json and --location first")
+	}
+
+	data, err := store.Get(ctx, latest)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read notice %s: %w", latest, err)
+	}
+	n, err := Decode(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", latest, err)
+	}
+
+	return n, latest, nil
+}
+
+// CSVRenderer renders a notice as a CSV file with one row per cluster, for importing into spreadsheets and BI tools.
+// The cost columns are empty for the clusters without price data.
+type CSVRenderer struct{}
+
+var csvHeader = []string{
+	"project_id", "project_name", "cluster_id", "cluster_name", "cluster_type", "cloud_provider", "region",
+	"tidb_version", "status", "created_at", "age_hours", "tidb_nodes", "tikv_nodes", "tiflash_nodes", "components",
+	"hourly_cost", "monthly_cost", "currency", "findings", "exempt_until",
+}
+
+func (CSVRenderer) Render(w io.Writer, n *Notice) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, p := range n.Projects {
+		for _, c := range p.Clusters {
+			counts := make(map[string]int)
+			for _, node := range c.Nodes {
+				counts[node.Component]++
+			}
+			var hourly, monthly string
+			if c.HasCost {
+				hourly = strconv.FormatFloat(c.HourlyCost, 'f', 2, 64)
+				monthly = strconv.FormatFloat(c.HourlyCost*clusterops.HoursPerMonth, 'f', 2, 64)
+			}
+			var exemptUntil string
+			if c.Exemption != nil {
+				exemptUntil = c.Exemption.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+			kinds := make([]string, 0, len(c.Findings))
+			for _, f := range c.Findings {
+				kinds = append(kinds, f.Kind)
+			}
+
+			row := []string{
+				p.ID, p.Name, c.ID, c.Name, c.ClusterType, c.CloudProvider, c.Region,
+				c.TiDBVersion, c.Status, c.CreatedAt.Format(time.RFC3339), strconv.FormatInt(int64(c.Age/time.Hour), 10),
+				strconv.Itoa(counts[clusters.ComponentTiDB]), strconv.Itoa(counts[clusters.ComponentTiKV]), strconv.Itoa(counts[clusters.ComponentTiFlash]),
+				c.Components, hourly, monthly, n.Currency, strings.Join(kinds, ";"), exemptUntil,
+			}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func (CSVRenderer) Extension() string {
+	return "csv"
+}
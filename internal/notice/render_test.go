@@ -0,0 +1,129 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSONRenderer{}.Render(&buf, testNotice(t)))
+
+	var doc struct {
+		SchemaVersion int    `json:"schema_version"`
+		GeneratedAt   string `json:"generated_at"`
+		ClusterCount  int    `json:"cluster_count"`
+		Projects      []struct {
+			ID       string `json:"id"`
+			Clusters []struct {
+				ID         string  `json:"id"`
+				AgeSeconds int64   `json:"age_seconds"`
+				HourlyCost float64 `json:"hourly_cost"`
+				Nodes      []Node  `json:"nodes"`
+			} `json:"clusters"`
+		} `json:"projects"`
+		Findings []Finding `json:"findings"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, JSONSchemaVersion, doc.SchemaVersion)
+	require.Equal(t, "2025-06-01T12:00:00Z", doc.GeneratedAt)
+	require.Equal(t, 3, doc.ClusterCount)
+	require.Equal(t, "p1", doc.Projects[1].ID)
+	require.Equal(t, "c1", doc.Projects[1].Clusters[1].ID)
+	require.Equal(t, int64(30*24*3600), doc.Projects[1].Clusters[1].AgeSeconds)
+	require.InDelta(t, 9.5, doc.Projects[1].Clusters[1].HourlyCost, 1e-9)
+	require.Len(t, doc.Projects[1].Clusters[1].Nodes, 5)
+	require.Len(t, doc.Findings, 3)
+	require.NotContains(t, buf.String(), `"age":`)
+}
+
+func TestCSVRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, CSVRenderer{}.Render(&buf, testNotice(t)))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, []string{
+		"p2", "analytics", "c3", "bi", "", "GCP", "us-central1", "v8.1.0", "PAUSED", "2025-04-22T12:00:00Z", "960",
+		"1", "3", "0", "TiDB 1 x 16C32G, TiKV 3 x 8C32G (200 GiB)", "", "", "USD", "", "",
+	}, records[1])
+	require.Equal(t, []string{"9.50", "6935.00", "USD", "long_running;stale_backup", ""}, records[3][15:])
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range Formats {
+		r, err := NewRenderer(format, nil)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, r.Render(&buf, testNotice(t)), format)
+		require.NotEmpty(t, buf.String(), format)
+	}
+
+	r, err := NewRenderer("HTML", nil)
+	require.NoError(t, err)
+	require.Equal(t, "html", r.Extension())
+
+	_, err = NewRenderer("pdf", nil)
+	require.ErrorContains(t, err, "invalid format: pdf")
+}
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	store := blob.NewLocalStore(t.TempDir())
+	var renderers []Renderer
+	for _, format := range Formats {
+		r, err := NewRenderer(format, nil)
+		require.NoError(t, err)
+		renderers = append(renderers, r)
+	}
+
+	keys, err := Save(ctx, store, testNotice(t), renderers)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"notices/20250601T120000Z/notice.md",
+		"notices/20250601T120000Z/notice.html",
+		"notices/20250601T120000Z/notice.json",
+		"notices/20250601T120000Z/notice.csv",
+	}, keys)
+
+	data, err := store.Get(ctx, "notices/20250601T120000Z/notice.html")
+	require.NoError(t, err)
+	require.Contains(t, string(data), "<h2>payments (p1)</h2>")
+}
+
+func TestLoadLatest(t *testing.T) {
+	ctx := context.Background()
+	store := blob.NewLocalStore(t.TempDir())
+
+	_, _, err := LoadLatest(ctx, store)
+	require.ErrorContains(t, err, "no notice in JSON is found")
+
+	older := testNotice(t)
+	older.GeneratedAt = older.GeneratedAt.Add(-24 * time.Hour)
+	_, err = Save(ctx, store, older, []Renderer{JSONRenderer{}})
+	require.NoError(t, err)
+	want := testNotice(t)
+	_, err = Save(ctx, store, want, []Renderer{JSONRenderer{}, CSVRenderer{}})
+	require.NoError(t, err)
+
+	got, key, err := LoadLatest(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, "notices/20250601T120000Z/notice.json", key)
+	require.Equal(t, want.ClusterCount, got.ClusterCount)
+	require.Equal(t, want.Findings, got.Findings)
+	require.Equal(t, want.Projects[0].Clusters[0].Age, got.Projects[0].Clusters[0].Age)
+
+	require.NoError(t, store.Put(ctx, "notices/20250602T000000Z/notice.json", []byte(`{"schema_version": 99}`)))
+	_, _, err = LoadLatest(ctx, store)
+	require.ErrorContains(t, err, "unsupported schema_version 99")
+}
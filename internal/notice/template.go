@@ -0,0 +1,250 @@
+package notice
+
+import (
+	"cmp"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusterops"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Names of the built-in templates used when no template is given.
+const (
+	DefaultTemplateName     = "notice.md.tmpl"
+	DefaultHTMLTemplateName = "notice.html.tmpl"
+)
+
+// Template renders a notice with text/template, or with html/template for the files ending with .html or .htm
+// so that the values are escaped. Templates can use the functions of FuncMap in addition to the built-in ones.
+type Template struct {
+	html  bool
+	files []templateFile // The first one is executed, the others can define templates used by it
+}
+
+type templateFile struct {
+	name string
+	text string
+}
+
+// DefaultTemplate returns the built-in Markdown template.
+func DefaultTemplate() *Template {
+	return builtinTemplate(DefaultTemplateName, false)
+}
+
+// DefaultHTMLTemplate returns the built-in HTML template.
+func DefaultHTMLTemplate() *Template {
+	return builtinTemplate(DefaultHTMLTemplateName, true)
+}
+
+func builtinTemplate(name string, html bool) *Template {
+	text, err := builtinTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("built-in template %s is missing: %v", name, err)) // Embedded at build time
+	}
+	return &Template{html: html, files: []templateFile{{name: name, text: string(text)}}}
+}
+
+// IsHTML reports whether the template is an HTML template.
+func (t *Template) IsHTML() bool {
+	return t.html
+}
+
+// LoadTemplate reads and parses the template files. The first file is executed, and the others can define
+// templates used by it. The files are either all HTML templates or all text templates, judged by the first file.
+func LoadTemplate(paths []string) (*Template, error) {
+	if len(paths) == 0 {
+		return DefaultTemplate(), nil
+	}
+
+	t := &Template{html: isHTML(paths[0])}
+	for _, path := range paths {
+		if isHTML(path) != t.html {
+			return nil, fmt.Errorf("template %s cannot be mixed with %s: HTML and text templates are parsed differently", path, paths[0])
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		t.files = append(t.files, templateFile{name: filepath.Base(path), text: string(data)})
+	}
+
+	// Parse once to report syntax errors before the notice is built
+	if _, err := t.parse(FuncMap("")); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func isHTML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".html" || ext == ".htm"
+}
+
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+func (t *Template) parse(funcs map[string]any) (executor, error) {
+	// Each file is parsed as a template named after it, and the first one is the root
+	if t.html {
+		root := htmltemplate.New(t.files[0].name).Funcs(funcs)
+		for i, f := range t.files {
+			tmpl := root
+			if i > 0 {
+				tmpl = root.New(f.name)
+			}
+			if _, err := tmpl.Parse(f.text); err != nil {
+				return nil, fmt.Errorf("failed to parse template %s: %w", f.name, err)
+			}
+		}
+		return root, nil
+	}
+
+	root := template.New(t.files[0].name).Funcs(funcs)
+	for i, f := range t.files {
+		tmpl := root
+		if i > 0 {
+			tmpl = root.New(f.name)
+		}
+		if _, err := tmpl.Parse(f.text); err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", f.name, err)
+		}
+	}
+	return root, nil
+}
+
+// Execute renders the notice.
+func (t *Template) Execute(w io.Writer, n *Notice) error {
+	tmpl, err := t.parse(FuncMap(n.Currency))
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, n); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", t.files[0].name, err)
+	}
+	return nil
+}
+
+// FuncMap returns the helper functions available to the templates:
+//
+//	duration d             "3d 4h", "5h 12m" or "42m"
+//	currency f             "12.34 USD", in the currency of the price data
+//	monthly f              the monthly amount of an hourly one
+//	bytes n                "16 GiB"
+//	date t                 "2025-01-02 15:04 UTC"
+//	sortClusters key list  a sorted copy of the clusters by name, age, cost or nodes. A "-" prefix reverses the order, e.g. "-cost"
+//	sortProjects key list  a sorted copy of the projects by name, cost or clusters. A "-" prefix reverses the order
+//	upper s, lower s, join list sep
+func FuncMap(currency string) map[string]any {
+	return map[string]any{
+		"duration": formatDuration,
+		"currency": func(amount float64) string {
+			return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+		},
+		"monthly": func(hourly float64) float64 {
+			return hourly * clusterops.HoursPerMonth
+		},
+		"bytes": formatBytes,
+		"date": func(t time.Time) string {
+			return t.UTC().Format("2006-01-02 15:04 UTC")
+		},
+		"sortClusters": sortClusters,
+		"sortProjects": sortProjects,
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+		"join":         strings.Join,
+	}
+}
+
+// formatDuration returns the duration rounded down to the two largest units, e.g. "3d 4h", "5h 12m" or "42m".
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// formatBytes returns the size in binary units, e.g. "16 GiB".
+func formatBytes(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	size := float64(n)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	if size == float64(int64(size)) {
+		return fmt.Sprintf("%d %s", int64(size), units[i])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[i])
+}
+
+func sortClusters(key string, list []Cluster) ([]Cluster, error) {
+	key, desc := strings.CutPrefix(key, "-")
+	var compareFunc func(a, b Cluster) int
+	switch key {
+	case "name":
+		compareFunc = func(a, b Cluster) int { return strings.Compare(a.Name, b.Name) }
+	case "age":
+		compareFunc = func(a, b Cluster) int { return cmp.Compare(a.Age, b.Age) }
+	case "cost":
+		compareFunc = func(a, b Cluster) int { return cmp.Compare(a.HourlyCost, b.HourlyCost) }
+	case "nodes":
+		compareFunc = func(a, b Cluster) int { return cmp.Compare(len(a.Nodes), len(b.Nodes)) }
+	default:
+		return nil, fmt.Errorf("unknown sort key of clusters %q: must be name, age, cost or nodes", key)
+	}
+
+	return sortedCopy(list, compareFunc, desc), nil
+}
+
+func sortProjects(key string, list []Project) ([]Project, error) {
+	key, desc := strings.CutPrefix(key, "-")
+	var compareFunc func(a, b Project) int
+	switch key {
+	case "name":
+		compareFunc = func(a, b Project) int { return strings.Compare(a.Name, b.Name) }
+	case "cost":
+		compareFunc = func(a, b Project) int { return cmp.Compare(a.HourlyCost, b.HourlyCost) }
+	case "clusters":
+		compareFunc = func(a, b Project) int { return cmp.Compare(len(a.Clusters), len(b.Clusters)) }
+	default:
+		return nil, fmt.Errorf("unknown sort key of projects %q: must be name, cost or clusters", key)
+	}
+
+	return sortedCopy(list, compareFunc, desc), nil
+}
+
+func sortedCopy[T any](list []T, cmp func(a, b T) int, desc bool) []T {
+	sorted := slices.Clone(list)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		if desc {
+			return cmp(b, a)
+		}
+		return cmp(a, b)
+	})
+	return sorted
+}
@@ -0,0 +1,91 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testNotice(t *testing.T) *Notice {
+	n, err := Build(context.Background(), newTestStore(), Options{
+		Now:              testNow,
+		RunningThreshold: 7 * 24 * time.Hour,
+		BackupThreshold:  24 * time.Hour,
+		Pricing:          testPricing,
+	})
+	require.NoError(t, err)
+	return n
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, DefaultTemplate().Execute(&buf, testNotice(t)))
+
+	out := buf.String()
+	require.Contains(t, out, "# TiDB Cloud usage notice (2025-06-01 12:00 UTC)")
+	require.Contains(t, out, "3 active clusters with 13 nodes in 2 projects. Estimated cost: 17.10 USD/hour, 12483.00 USD/month (clusters without price data are excluded).")
+	require.Contains(t, out, "- **pay-main** (c1, project p1): available and created 30d 0h ago\n")
+	require.Contains(t, out, "## payments (p1)\n")
+	require.Contains(t, out, "| pay-main (c1) | AVAILABLE | v7.5.1 | AWS us-west-2 | TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB) | 30d 0h | 9.50 USD |\n| pay-dev (c4)")
+	require.Contains(t, out, "| bi (c3) | PAUSED | v8.1.0 | GCP us-central1 | TiDB 1 x 16C32G, TiKV 3 x 8C32G (200 GiB) | 40d 0h | - |")
+}
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(text), 0o600))
+		return path
+	}
+
+	t.Run("text with partials and helpers", func(t *testing.T) {
+		main := write("main.tmpl", `{{range sortProjects "-cost" .Projects}}{{template "project" .}}{{end}}`)
+		partial := write("partial.tmpl", `{{define "project"}}{{upper .Name}}: {{currency (monthly .HourlyCost)}}{{range sortClusters "-nodes" .Clusters}} {{.Name}}={{duration .Age}}/{{bytes (index .Nodes 0).RAMBytes}}{{end}}
+{{end}}`)
+		tmpl, err := LoadTemplate([]string{main, partial})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, tmpl.Execute(&buf, testNotice(t)))
+		require.Equal(t, "PAYMENTS: 12483.00 USD pay-main=30d 0h/16 GiB pay-dev=2h 0m/16 GiB\nANALYTICS: 0.00 USD bi=40d 0h/16 GiB\n", buf.String())
+	})
+
+	t.Run("html is escaped", func(t *testing.T) {
+		n := testNotice(t)
+		n.Projects[0].Name = "<script>"
+		tmpl, err := LoadTemplate([]string{write("notice.html", `<h1>{{(index .Projects 0).Name}}</h1>`)})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, tmpl.Execute(&buf, n))
+		require.Equal(t, "<h1>&lt;script&gt;</h1>", buf.String())
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := LoadTemplate([]string{write("broken.tmpl", `{{range .Projects}}`)})
+		require.ErrorContains(t, err, "failed to parse template broken.tmpl")
+	})
+
+	t.Run("unknown sort key", func(t *testing.T) {
+		tmpl, err := LoadTemplate([]string{write("sort.tmpl", `{{range sortClusters "size" (index .Projects 0).Clusters}}{{end}}`)})
+		require.NoError(t, err)
+		require.ErrorContains(t, tmpl.Execute(&bytes.Buffer{}, testNotice(t)), `unknown sort key of clusters "size"`)
+	})
+
+	t.Run("mixed html and text", func(t *testing.T) {
+		_, err := LoadTemplate([]string{write("a.html", ``), write("b.tmpl", ``)})
+		require.ErrorContains(t, err, "cannot be mixed")
+	})
+}
+
+func TestFormatDuration(t *testing.T) {
+	require.Equal(t, "0m", formatDuration(-time.Minute))
+	require.Equal(t, "42m", formatDuration(42*time.Minute+30*time.Second))
+	require.Equal(t, "5h 12m", formatDuration(5*time.Hour+12*time.Minute))
+	require.Equal(t, "3d 4h", formatDuration(76*time.Hour+59*time.Minute))
+}
@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+	"gopkg.in/yaml.v3"
+)
+
+// This module sends the notices generated by generate-notice through notification channels.
+// The channels and the recipients of each project are configured in a YAML file like:
+//
+//	email:
+//	  smtp:
+//	    host: smtp.example.com
+//	    port: 587
+//	    username: msk@example.com   # The password is read from MSK_SMTP_PASSWORD
+//	  from: "msk <msk@example.com>"
+//	  subject: "TiDB Cloud usage: {{.ClusterCount}} clusters, {{len .Findings}} findings"
+//	  routes:
+//	    - projects: ["1234567890"]
+//	      to: [team-a@example.com]
+//	    - projects: ["*"]           # All projects
+//	      to: [dba@example.com]
+//	webhooks:
+//	  - name: ticketing
+//	    url: https://tickets.example.com/hooks/msk
+//	    secret_env: MSK_WEBHOOK_TICKETING_SECRET  # Environment variable with the HMAC secret
+//	    projects: ["1234567890"]
+//	owners:                     # Send each owner imported by `msk owners import` only the clusters they own
+//	  email: true
+//	  webhooks: [ticketing]     # With the owner in "recipient"
+//	  admin:                    # Where the clusters without owners, or whose owners cannot be reached, are sent
+//	    to: [dba@example.com]
+//	state:                      # Send a finding again only after the interval, or when it gets worse
+//	  renotify_after: 168h
+//	  findings:
+//	    long_running:
+//	      renotify_after: 72h
+//	      escalate_at: [336h, 720h]  # Also sent when the cluster gets older than these
+
+// Config is the configuration of the notification channels.
+type Config struct {
+	Email    *EmailConfig    `yaml:"email,omitempty"`
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	Owners   *OwnersConfig   `yaml:"owners,omitempty"`
+	State    *StateConfig    `yaml:"state,omitempty"`
+}
+
+// EmailConfig is the configuration of the email channel.
+type EmailConfig struct {
+	SMTP    SMTPConfig `yaml:"smtp"`
+	From    string     `yaml:"from"`
+	Subject string     `yaml:"subject,omitempty"` // text/template with the notice as "."
+	// Templates of the text and HTML parts. The built-in Markdown and HTML templates are used by default.
+	TextTemplate []string     `yaml:"text_template,omitempty"`
+	HTMLTemplate []string     `yaml:"html_template,omitempty"`
+	Routes       []EmailRoute `yaml:"routes"` // Optional with owners
+}
+
+// SMTPConfig is the SMTP server to send the emails through.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"` // Authenticates with PLAIN if set
+	// STARTTLS is required unless disabled, and PLAIN authentication is refused without TLS except for localhost.
+	DisableSTARTTLS bool `yaml:"disable_starttls,omitempty"`
+}
+
+// EmailRoute sends the clusters of the projects to the recipients.
+type EmailRoute struct {
+	Projects []string `yaml:"projects"` // Project IDs, or "*" for all projects
+	To       []string `yaml:"to"`
+	Cc       []string `yaml:"cc,omitempty"`
+}
+
+// WebhookConfig is a webhook channel, which receives the JSON notice of its projects.
+type WebhookConfig struct {
+	Name      string `yaml:"name"`
+	URL       string `yaml:"url"`
+	SecretEnv string `yaml:"secret_env"` // Environment variable with the secret of the HMAC-SHA256 signature
+	// Project IDs, or "*" for all projects. Optional if the webhook is used by owners.
+	Projects []string `yaml:"projects"`
+	// Timeout of each attempt.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// MaxAttempts is the number of attempts including the first one.
+	// Network errors, 408, 429 and 5xx responses are retried with an exponential backoff from InitialBackoff.
+	MaxAttempts    int           `yaml:"max_attempts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty"`
+}
+
+// OwnersConfig sends the clusters to their owners through the channels.
+type OwnersConfig struct {
+	Email    bool        `yaml:"email"`    // Email the owners with an email address
+	Webhooks []string    `yaml:"webhooks"` // Names of the webhooks receiving the notice of each owner
+	Admin    AdminConfig `yaml:"admin"`
+}
+
+// AdminConfig is where the clusters without owners, or whose owners have no email address and no webhook, are sent.
+type AdminConfig struct {
+	To       []string `yaml:"to"`
+	Webhooks []string `yaml:"webhooks"`
+}
+
+// StateConfig suppresses the repeated notifications of the same findings by recording when they were notified.
+// With it, only the clusters with findings to notify are sent.
+type StateConfig struct {
+	RenotifyAfter time.Duration                 `yaml:"renotify_after,omitempty"`
+	Findings      map[string]FindingStateConfig `yaml:"findings,omitempty"` // By kind of finding
+}
+
+// FindingStateConfig overrides the re-notify interval of a kind of finding, and escalates it at the thresholds
+// of how long its condition has lasted.
+type FindingStateConfig struct {
+	RenotifyAfter time.Duration   `yaml:"renotify_after,omitempty"`
+	EscalateAt    []time.Duration `yaml:"escalate_at,omitempty"`
+}
+
+// Default values of the channels.
+const (
+	DefaultSMTPPort     = 587
+	DefaultEmailSubject = "TiDB Cloud usage notice: {{.ClusterCount}} clusters, {{len .Findings}} findings ({{date .GeneratedAt}})"
+
+	DefaultWebhookTimeout        = 10 * time.Second
+	DefaultWebhookMaxAttempts    = 4
+	DefaultWebhookInitialBackoff = time.Second
+
+	DefaultRenotifyAfter = 7 * 24 * time.Hour
+)
+
+// LoadConfig reads the configuration file and fills in the defaults.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notify config %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // Unknown fields are rejected to catch typos
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse notify config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notify config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the required fields and fills in the defaults.
+func (c *Config) Validate() error {
+	if c.Email == nil && len(c.Webhooks) == 0 {
+		return errors.New("no channel is configured")
+	}
+
+	if c.Email != nil {
+		e := c.Email
+		if e.SMTP.Host == "" {
+			return errors.New("email.smtp.host is required")
+		}
+		if e.SMTP.Port == 0 {
+			e.SMTP.Port = DefaultSMTPPort
+		}
+		if e.From == "" {
+			return errors.New("email.from is required")
+		}
+		if e.Subject == "" {
+			e.Subject = DefaultEmailSubject
+		}
+		if len(e.Routes) == 0 && c.Owners == nil {
+			return errors.New("email.routes is required without owners")
+		}
+		for i, route := range e.Routes {
+			if len(route.Projects) == 0 || len(route.To) == 0 {
+				return fmt.Errorf("email.routes[%d]: projects and to are required", i)
+			}
+		}
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Webhooks {
+		h := &c.Webhooks[i]
+		if h.Name == "" {
+			return fmt.Errorf("webhooks[%d]: name is required", i)
+		}
+		if names[h.Name] {
+			return fmt.Errorf("webhooks[%d]: duplicate name %s", i, h.Name)
+		}
+		names[h.Name] = true
+
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("webhook %s: url must be an http(s) URL", h.Name)
+		}
+		if h.SecretEnv == "" {
+			return fmt.Errorf("webhook %s: secret_env is required", h.Name)
+		}
+		if len(h.Projects) == 0 && !c.Owners.usesWebhook(h.Name) {
+			return fmt.Errorf("webhook %s: projects is required unless the webhook is used by owners", h.Name)
+		}
+		if h.Timeout == 0 {
+			h.Timeout = DefaultWebhookTimeout
+		}
+		if h.MaxAttempts == 0 {
+			h.MaxAttempts = DefaultWebhookMaxAttempts
+		}
+		if h.InitialBackoff == 0 {
+			h.InitialBackoff = DefaultWebhookInitialBackoff
+		}
+		if h.Timeout < 0 || h.MaxAttempts < 0 || h.InitialBackoff < 0 {
+			return fmt.Errorf("webhook %s: timeout, max_attempts and initial_backoff must not be negative", h.Name)
+		}
+	}
+
+	if o := c.Owners; o != nil {
+		if !o.Email && len(o.Webhooks) == 0 {
+			return errors.New("owners: email or webhooks is required")
+		}
+		if len(o.Admin.To) == 0 && len(o.Admin.Webhooks) == 0 {
+			return errors.New("owners.admin: to or webhooks is required for the clusters without owners")
+		}
+		if (o.Email || len(o.Admin.To) > 0) && c.Email == nil {
+			return errors.New("owners: the email channel is not configured")
+		}
+		for _, name := range append(append([]string{}, o.Webhooks...), o.Admin.Webhooks...) {
+			if !names[name] {
+				return fmt.Errorf("owners: webhook %s is not configured", name)
+			}
+		}
+	}
+
+	if st := c.State; st != nil {
+		if st.RenotifyAfter == 0 {
+			st.RenotifyAfter = DefaultRenotifyAfter
+		}
+		if st.RenotifyAfter < 0 {
+			return errors.New("state.renotify_after must not be negative")
+		}
+		for kind, f := range st.Findings {
+			if kind != notice.FindingLongRunning && kind != notice.FindingStaleBackup {
+				return fmt.Errorf("state.findings: unknown kind %s (kinds: %s, %s)", kind, notice.FindingLongRunning, notice.FindingStaleBackup)
+			}
+			if f.RenotifyAfter < 0 {
+				return fmt.Errorf("state.findings.%s.renotify_after must not be negative", kind)
+			}
+			for i, threshold := range f.EscalateAt {
+				if threshold <= 0 || (i > 0 && threshold <= f.EscalateAt[i-1]) {
+					return fmt.Errorf("state.findings.%s.escalate_at must be positive and in ascending order", kind)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// usesWebhook reports whether the owners or the admins are sent to the webhook.
+func (o *OwnersConfig) usesWebhook(name string) bool {
+	return o != nil && (slices.Contains(o.Webhooks, name) || slices.Contains(o.Admin.Webhooks, name))
+}
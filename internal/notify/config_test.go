@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+email:
+  smtp:
+    host: smtp.example.com
+    username: msk@example.com
+  from: msk@example.com
+  routes:
+    - projects: ["*"]
+      to: [dba@example.com]
+`,
+		},
+		{
+			name: "webhook",
+			yaml: `
+webhooks:
+  - name: tickets
+    url: https://tickets.example.com/hooks/msk
+    secret_env: MSK_TICKETS_SECRET
+    projects: [p1]
+    timeout: 5s
+state:
+  findings:
+    long_running: {renotify_after: 72h, escalate_at: [336h, 720h]}
+`,
+		},
+		{
+			name: "owners",
+			yaml: `
+email:
+  smtp:
+    host: smtp.example.com
+  from: msk@example.com
+webhooks:
+  - name: chatops
+    url: https://chatops.example.com/msk
+    secret_env: MSK_CHATOPS_SECRET
+owners:
+  email: true
+  webhooks: [chatops]
+  admin:
+    to: [dba@example.com]
+`,
+		},
+		{name: "empty", yaml: "", wantErr: "no channel is configured"},
+		{
+			name:    "state kind",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\n    projects: [p1]\nstate:\n  findings:\n    idle: {renotify_after: 24h}\n",
+			wantErr: "state.findings: unknown kind idle",
+		},
+		{
+			name:    "state escalation order",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\n    projects: [p1]\nstate:\n  findings:\n    long_running: {escalate_at: [720h, 336h]}\n",
+			wantErr: "state.findings.long_running.escalate_at must be positive and in ascending order",
+		},
+		{
+			name:    "webhook without projects",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\n",
+			wantErr: "webhook a: projects is required unless the webhook is used by owners",
+		},
+		{
+			name:    "owners without admin",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\nowners:\n  webhooks: [a]\n",
+			wantErr: "owners.admin: to or webhooks is required",
+		},
+		{
+			name:    "owners unknown webhook",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\n    projects: [p1]\nowners:\n  webhooks: [b]\n  admin:\n    webhooks: [a]\n",
+			wantErr: "owners: webhook b is not configured",
+		},
+		{
+			name:    "owners without email",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\nowners:\n  email: true\n  admin:\n    webhooks: [a]\n",
+			wantErr: "owners: the email channel is not configured",
+		},
+		{
+			name:    "webhook url",
+			yaml:    "webhooks:\n  - name: tickets\n    url: tickets.example.com\n    secret_env: S\n    projects: [p1]\n",
+			wantErr: "webhook tickets: url must be an http(s) URL",
+		},
+		{
+			name:    "duplicate webhook",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    secret_env: S\n    projects: [p1]\n  - name: a\n",
+			wantErr: "webhooks[1]: duplicate name a",
+		},
+		{
+			name:    "webhook secret",
+			yaml:    "webhooks:\n  - name: a\n    url: https://a.example.com\n    projects: [p1]\n",
+			wantErr: "webhook a: secret_env is required",
+		},
+		{name: "unknown field", yaml: "email:\n  smtp:\n    hostname: smtp.example.com\n", wantErr: "field hostname not found"},
+		{name: "no host", yaml: "email:\n  from: msk@example.com\n", wantErr: "email.smtp.host is required"},
+		{name: "no from", yaml: "email:\n  smtp:\n    host: smtp.example.com\n", wantErr: "email.from is required"},
+		{name: "no routes", yaml: "email:\n  smtp:\n    host: smtp.example.com\n  from: msk@example.com\n", wantErr: "email.routes is required without owners"},
+		{
+			name:    "no recipients",
+			yaml:    "email:\n  smtp:\n    host: smtp.example.com\n  from: msk@example.com\n  routes:\n    - projects: [p1]\n",
+			wantErr: "email.routes[0]: projects and to are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notify.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.yaml), 0o600))
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if cfg.Email != nil {
+				require.Equal(t, DefaultSMTPPort, cfg.Email.SMTP.Port)
+				require.Equal(t, DefaultEmailSubject, cfg.Email.Subject)
+				require.False(t, cfg.Email.SMTP.DisableSTARTTLS)
+			}
+			if cfg.State != nil {
+				require.Equal(t, DefaultRenotifyAfter, cfg.State.RenotifyAfter)
+				require.Len(t, cfg.State.Findings["long_running"].EscalateAt, 2)
+			}
+			for _, h := range cfg.Webhooks {
+				require.Positive(t, h.Timeout)
+				require.Equal(t, DefaultWebhookMaxAttempts, h.MaxAttempts)
+				require.Equal(t, DefaultWebhookInitialBackoff, h.InitialBackoff)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+)
+
+// EmailNotifier sends the notice as multipart text and HTML emails through an SMTP server.
+type EmailNotifier struct {
+	Config   EmailConfig
+	Password string
+	// TLSConfig is used for STARTTLS. It verifies the certificate of Config.SMTP.Host if nil.
+	TLSConfig *tls.Config
+
+	subject *template.Template
+	text    *notice.Template
+	html    *notice.Template
+}
+
+// NewEmailNotifier returns a new EmailNotifier with the templates of the configuration loaded.
+func NewEmailNotifier(cfg EmailConfig, password string) (*EmailNotifier, error) {
+	subject, err := template.New("subject").Funcs(notice.FuncMap("")).Parse(cfg.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email subject: %w", err)
+	}
+
+	text := notice.DefaultTemplate()
+	if len(cfg.TextTemplate) > 0 {
+		if text, err = notice.LoadTemplate(cfg.TextTemplate); err != nil {
+			return nil, err
+		}
+		if text.IsHTML() {
+			return nil, fmt.Errorf("email.text_template %s must not be an .html or .htm file", cfg.TextTemplate[0])
+		}
+	}
+	html := notice.DefaultHTMLTemplate()
+	if len(cfg.HTMLTemplate) > 0 {
+		if html, err = notice.LoadTemplate(cfg.HTMLTemplate); err != nil {
+			return nil, err
+		}
+		if !html.IsHTML() {
+			return nil, fmt.Errorf("email.html_template %s must be an .html or .htm file", cfg.HTMLTemplate[0])
+		}
+	}
+
+	return &EmailNotifier{Config: cfg, Password: password, subject: subject, text: text, html: html}, nil
+}
+
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends an email per route with the clusters of its projects. Routes without clusters are skipped.
+func (e *EmailNotifier) Notify(ctx context.Context, n *notice.Notice, dryRun bool, w io.Writer) error {
+	var errs []error
+	for _, route := range e.Config.Routes {
+		if err := e.notifyRoute(ctx, n.ForProjects(route.Projects), route, dryRun, w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyRoute emails the notice to the recipients of the route, unless it has no clusters.
+// The projects of the route are not used, the notice is expected to be filtered by the caller.
+func (e *EmailNotifier) notifyRoute(ctx context.Context, n *notice.Notice, route EmailRoute, dryRun bool, w io.Writer) error {
+	recipients := append(append([]string{}, route.To...), route.Cc...)
+	if n.ClusterCount == 0 {
+		fmt.Fprintf(w, "[SKIP] No clusters of projects %v to email to %s\n", route.Projects, strings.Join(recipients, ", "))
+		return nil
+	}
+
+	msg, subject, err := e.buildMessage(n, route)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would email %q (%d clusters) to %s\n", subject, n.ClusterCount, strings.Join(recipients, ", "))
+		return nil
+	}
+
+	if err := e.send(ctx, recipients, msg); err != nil {
+		return fmt.Errorf("failed to email to %s: %w", strings.Join(recipients, ", "), err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Emailed %q (%d clusters) to %s\n", subject, n.ClusterCount, strings.Join(recipients, ", "))
+
+	return nil
+}
+
+// buildMessage returns the multipart/alternative message with the text and HTML parts, and its subject.
+func (e *EmailNotifier) buildMessage(n *notice.Notice, route EmailRoute) ([]byte, string, error) {
+	var subject strings.Builder
+	if err := e.subject.Funcs(notice.FuncMap(n.Currency)).Execute(&subject, n); err != nil {
+		return nil, "", fmt.Errorf("failed to render email subject: %w", err)
+	}
+	var text, html bytes.Buffer
+	if err := e.text.Execute(&text, n); err != nil {
+		return nil, "", err
+	}
+	if err := e.html.Execute(&html, n); err != nil {
+		return nil, "", err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		pw, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, "", err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, "", err
+	}
+
+	var msg bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&msg, "%s: %s\r\n", key, value) }
+	header("From", e.Config.From)
+	header("To", strings.Join(route.To, ", "))
+	if len(route.Cc) > 0 {
+		header("Cc", strings.Join(route.Cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", subject.String()))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(e.Config.From))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), subject.String(), nil
+}
+
+func newMessageID(from string) string {
+	domain := "msk.localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// send delivers the message to the recipients through the SMTP server, upgrading the connection with STARTTLS.
+func (e *EmailNotifier) send(ctx context.Context, recipients []string, msg []byte) error {
+	smtpConfig := e.Config.SMTP
+	addr := net.JoinHostPort(smtpConfig.Host, strconv.Itoa(smtpConfig.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, smtpConfig.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session with %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if !smtpConfig.DisableSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		tlsConfig := e.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: smtpConfig.Host}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to STARTTLS with %s: %w", addr, err)
+		}
+	}
+
+	if smtpConfig.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", smtpConfig.Username, e.Password, smtpConfig.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate to %s: %w", addr, err)
+		}
+	}
+
+	from, err := mail.ParseAddress(e.Config.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %w", e.Config.From, err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		to, err := mail.ParseAddress(rcpt)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", rcpt, err)
+		}
+		if err := client.Rcpt(to.Address); err != nil {
+			return fmt.Errorf("recipient %s is rejected: %w", to.Address, err)
+		}
+	}
+
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(msg); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testNotice returns a notice of two projects with one cluster each.
+func testNotice() *notice.Notice {
+	nodes := clusters.NodeMap{
+		Tidb: clusters.Nodes{{NodeName: "tidb-0", NodeSize: "8C16G", Status: "NORMAL"}},
+		Tikv: clusters.Nodes{{NodeName: "tikv-0", NodeSize: "8C32G", StorageSizeGib: 500, Status: "NORMAL"}},
+	}
+	return notice.New(
+		[]db.Project{{ID: "p1", Name: "payments"}, {ID: "p2", Name: "analytics"}},
+		[]db.Cluster{
+			{ID: "c1", ProjectID: "p1", Name: "pay-main", ClusterStatus: "AVAILABLE", CreateTimestamp: testNow.Add(-30 * 24 * time.Hour).Unix()},
+			{ID: "c2", ProjectID: "p2", Name: "bi", ClusterStatus: "PAUSED", CreateTimestamp: testNow.Add(-time.Hour).Unix()},
+		},
+		map[string]clusters.NodeMap{"c1": nodes, "c2": nodes},
+		nil,
+		nil,
+		notice.Options{Now: testNow, RunningThreshold: 7 * 24 * time.Hour},
+	)
+}
+
+func testEmailConfig(s *fakeSMTPServer) EmailConfig {
+	cfg := &Config{Email: &EmailConfig{
+		SMTP: SMTPConfig{Host: s.host(), Port: s.port(), Username: "msk"},
+		From: "msk <msk@example.com>",
+		Routes: []EmailRoute{
+			{Projects: []string{"p1"}, To: []string{"Payments <pay@example.com>"}, Cc: []string{"lead@example.com"}},
+			{Projects: []string{"*"}, To: []string{"dba@example.com"}},
+			{Projects: []string{"p9"}, To: []string{"nobody@example.com"}},
+		},
+	}}
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+	return *cfg.Email
+}
+
+// parts returns the content of the parts of the multipart message by content type.
+func parts(t *testing.T, msg *mail.Message) map[string]string {
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	contents := make(map[string]string)
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part) // Quoted-printable is decoded by the reader
+		require.NoError(t, err)
+		contents[strings.Split(part.Header.Get("Content-Type"), ";")[0]] = string(content)
+	}
+	return contents
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSMTPServer(t, true)
+	notifier, err := NewEmailNotifier(testEmailConfig(server), "secret")
+	require.NoError(t, err)
+	notifier.TLSConfig = &tls.Config{ServerName: server.host(), RootCAs: server.rootCAs}
+
+	var buf bytes.Buffer
+	require.NoError(t, notifier.Notify(ctx, testNotice(), false, &buf))
+	require.Contains(t, buf.String(), `[SUCCESS] Emailed "TiDB Cloud usage notice: 1 clusters, 1 findings (2025-06-01 12:00 UTC)" (1 clusters) to Payments <pay@example.com>, lead@example.com`)
+	require.Contains(t, buf.String(), "(2 clusters) to dba@example.com")
+	require.Contains(t, buf.String(), "[SKIP] No clusters of projects [p9] to email to nobody@example.com")
+
+	received := server.received()
+	require.Len(t, received, 2)
+	require.True(t, received[0].TLS)
+	require.Equal(t, "msk:secret", received[0].Auth)
+	require.Equal(t, "msk@example.com", received[0].From)
+	require.Equal(t, []string{"pay@example.com", "lead@example.com"}, received[0].To)
+	require.Equal(t, []string{"dba@example.com"}, received[1].To)
+
+	msg, err := mail.ReadMessage(strings.NewReader(received[0].Data))
+	require.NoError(t, err)
+	require.Equal(t, "Payments <pay@example.com>", msg.Header.Get("To"))
+	require.Equal(t, "lead@example.com", msg.Header.Get("Cc"))
+	require.Contains(t, msg.Header.Get("Message-ID"), "@example.com>")
+	contents := parts(t, msg)
+	require.Contains(t, contents["text/plain"], "pay-main")
+	require.NotContains(t, contents["text/plain"], "analytics") // Only the clusters of the route
+	require.Contains(t, contents["text/html"], "<h2>payments (p1)</h2>")
+}
+
+func TestEmailNotifier_DryRun(t *testing.T) {
+	server := newFakeSMTPServer(t, true)
+	notifier, err := NewEmailNotifier(testEmailConfig(server), "secret")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, notifier.Notify(context.Background(), testNotice(), true, &buf))
+	require.Contains(t, buf.String(), "[DRY RUN] Would email \"TiDB Cloud usage notice: 2 clusters, 1 findings (2025-06-01 12:00 UTC)\" (2 clusters) to dba@example.com")
+	require.Empty(t, server.received())
+}
+
+func TestEmailNotifier_STARTTLS(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSMTPServer(t, false)
+	cfg := testEmailConfig(server)
+	cfg.Routes = cfg.Routes[1:2]
+
+	t.Run("required", func(t *testing.T) {
+		notifier, err := NewEmailNotifier(cfg, "secret")
+		require.NoError(t, err)
+		err = notifier.Notify(ctx, testNotice(), false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "failed to email to dba@example.com")
+		require.ErrorContains(t, err, "does not support STARTTLS")
+		require.Empty(t, server.received())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// net/smtp allows PLAIN authentication without TLS only for localhost
+		disabled := cfg
+		disabled.SMTP.DisableSTARTTLS = true
+		disabled.SMTP.Username = ""
+		notifier, err := NewEmailNotifier(disabled, "")
+		require.NoError(t, err)
+		require.NoError(t, notifier.Notify(ctx, testNotice(), false, &bytes.Buffer{}))
+		require.Len(t, server.received(), 1)
+		require.False(t, server.received()[0].TLS)
+	})
+}
+
+func TestNewEmailNotifier(t *testing.T) {
+	cfg := EmailConfig{Subject: "{{.Unknown", From: "msk@example.com"}
+	_, err := NewEmailNotifier(cfg, "")
+	require.ErrorContains(t, err, "failed to parse email subject")
+
+	cfg.Subject = DefaultEmailSubject
+	cfg.HTMLTemplate = []string{"testdata/missing.md.tmpl"}
+	_, err = NewEmailNotifier(cfg, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,16 @@
+package notify
+
+import (
+	"context"
+	"io"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+)
+
+// Notifier sends a notice through a channel.
+type Notifier interface {
+	// Name is the name of the channel, e.g. "email".
+	Name() string
+	// Notify sends the notice. If dryRun is true, only the messages that would be sent are printed.
+	Notify(ctx context.Context, n *notice.Notice, dryRun bool, w io.Writer) error
+}
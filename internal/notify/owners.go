@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/sgykfjsm/msk/internal/owners"
+)
+
+// Recipient is the owner a notice is sent to. The webhooks receive it as "recipient",
+// e.g. for a chatops bot to send the notice to the Slack user.
+type Recipient struct {
+	Name        string `json:"name,omitempty"`
+	Team        string `json:"team,omitempty"`
+	Email       string `json:"email,omitempty"`
+	SlackUserID string `json:"slack_user_id,omitempty"`
+	Admin       bool   `json:"admin,omitempty"` // The notice has the clusters not sent to any owner and is sent to the admin channel
+}
+
+func (r Recipient) String() string {
+	switch {
+	case r.Admin:
+		return "admin"
+	case r.Email != "":
+		return r.Email
+	default:
+		return "slack:" + r.SlackUserID
+	}
+}
+
+// Assignment is the notice of the clusters owned by a recipient.
+type Assignment struct {
+	Recipient Recipient
+	Notice    *notice.Notice
+}
+
+// Assign splits the notice by owner. An owner gets the clusters matched by any of its entries,
+// so a cluster with several owners is in several assignments. The owners without clusters are left out,
+// and the clusters without owners are returned as the unowned notice.
+func Assign(n *notice.Notice, entries []owners.Owner) ([]Assignment, *notice.Notice) {
+	var assignments []Assignment
+	for _, contact := range owners.Contacts(entries) {
+		var recipient Recipient
+		var own []owners.Owner
+		for _, o := range entries {
+			if o.Contact() != contact {
+				continue
+			}
+			own = append(own, o)
+			// The first non-empty value of each field wins
+			recipient.Name = cmp.Or(recipient.Name, o.Name)
+			recipient.Team = cmp.Or(recipient.Team, o.Team)
+			recipient.Email = cmp.Or(recipient.Email, o.Email)
+			recipient.SlackUserID = cmp.Or(recipient.SlackUserID, o.SlackUserID)
+		}
+
+		owned := n.Filter(func(c notice.Cluster) bool { return isOwnedBy(c, own) })
+		if owned.ClusterCount > 0 {
+			assignments = append(assignments, Assignment{Recipient: recipient, Notice: owned})
+		}
+	}
+	unowned := n.Filter(func(c notice.Cluster) bool { return !isOwnedBy(c, entries) })
+
+	return assignments, unowned
+}
+
+func isOwnedBy(c notice.Cluster, entries []owners.Owner) bool {
+	for _, o := range entries {
+		if o.Matches(c.ProjectID, c.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnerNotifier sends each owner the notice of the clusters they own, and the unowned clusters to the admin channel.
+// The clusters of the owners without an email address or a webhook are sent to the admin channel too.
+type OwnerNotifier struct {
+	Owners []owners.Owner
+	Config OwnersConfig
+	// Email sends the emails to the owners and the admins. It is nil if no email is sent.
+	Email *EmailNotifier
+	// Webhooks and AdminWebhooks receive the notices of the owners and of the unowned clusters.
+	Webhooks      []*WebhookNotifier
+	AdminWebhooks []*WebhookNotifier
+}
+
+func (o *OwnerNotifier) Name() string {
+	return "owners"
+}
+
+func (o *OwnerNotifier) Notify(ctx context.Context, n *notice.Notice, dryRun bool, w io.Writer) error {
+	assignments, unowned := Assign(n, o.Owners)
+
+	var errs []error
+	delivered := map[string]bool{} // IDs of the clusters sent to at least one owner
+	for _, a := range assignments {
+		sent := false
+		if o.Config.Email && a.Recipient.Email != "" {
+			to := (&mail.Address{Name: a.Recipient.Name, Address: a.Recipient.Email}).String()
+			if err := o.Email.notifyRoute(ctx, a.Notice, EmailRoute{To: []string{to}}, dryRun, w); err != nil {
+				errs = append(errs, err)
+			}
+			sent = true
+		}
+		for _, h := range o.Webhooks {
+			if err := h.send(ctx, a.Notice, &a.Recipient, dryRun, w); err != nil {
+				errs = append(errs, err)
+			}
+			sent = true
+		}
+		if !sent {
+			fmt.Fprintf(w, "[SKIP] Owner %s of %d clusters has no email address and no webhook is configured for owners\n", a.Recipient, a.Notice.ClusterCount)
+			continue
+		}
+		for _, p := range a.Notice.Projects {
+			for _, c := range p.Clusters {
+				delivered[c.ID] = true
+			}
+		}
+	}
+
+	// The clusters whose owners could not be reached go to the admin channel with the unowned ones,
+	// so that no cluster is left unnotified.
+	admin := n.Filter(func(c notice.Cluster) bool { return !delivered[c.ID] })
+	if admin.ClusterCount == 0 {
+		return errors.Join(errs...)
+	}
+	if unowned.ClusterCount > 0 {
+		fmt.Fprintf(w, "%d clusters have no owner, sending them to the admin channel\n", unowned.ClusterCount)
+	}
+	if undelivered := admin.ClusterCount - unowned.ClusterCount; undelivered > 0 {
+		fmt.Fprintf(w, "%d clusters have no owner to send them to, sending them to the admin channel\n", undelivered)
+	}
+	if len(o.Config.Admin.To) > 0 {
+		if err := o.Email.notifyRoute(ctx, admin, EmailRoute{To: o.Config.Admin.To}, dryRun, w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, h := range o.AdminWebhooks {
+		if err := h.send(ctx, admin, &Recipient{Admin: true}, dryRun, w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/owners"
+	"github.com/stretchr/testify/require"
+)
+
+var testOwners = []owners.Owner{
+	{ProjectID: "p1", ClusterPattern: "pay-*", Name: "Alice", Email: "alice@example.com"},
+	{ProjectID: "p1", ClusterPattern: "*", Name: "Bob", Team: "payments", SlackUserID: "U0B"},
+	{ProjectID: "p1", ClusterPattern: "pay-main", Team: "payments", Email: "alice@example.com", SlackUserID: "U0A"},
+	{ProjectID: "p9", ClusterPattern: "*", Name: "Carol", Email: "carol@example.com"},
+}
+
+func TestAssign(t *testing.T) {
+	assignments, unowned := Assign(testNotice(), testOwners)
+
+	require.Len(t, assignments, 2) // Carol owns no clusters
+	require.Equal(t, Recipient{Name: "Alice", Team: "payments", Email: "alice@example.com", SlackUserID: "U0A"}, assignments[0].Recipient)
+	require.Equal(t, 1, assignments[0].Notice.ClusterCount)
+	require.Equal(t, "pay-main", assignments[0].Notice.Projects[0].Clusters[0].Name)
+	require.Equal(t, Recipient{Name: "Bob", Team: "payments", SlackUserID: "U0B"}, assignments[1].Recipient)
+	require.Equal(t, 1, assignments[1].Notice.ClusterCount)
+
+	require.Equal(t, 1, unowned.ClusterCount)
+	require.Equal(t, "bi", unowned.Projects[0].Clusters[0].Name)
+}
+
+func TestOwnerNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	smtpServer := newFakeSMTPServer(t, true)
+	webhook, requests := newTestWebhook(t)
+
+	cfg := Config{
+		Email:    &EmailConfig{SMTP: SMTPConfig{Host: smtpServer.host(), Port: smtpServer.port()}, From: "msk@example.com"},
+		Webhooks: []WebhookConfig{{Name: "chatops", URL: webhook.URL, SecretEnv: "SECRET"}},
+		Owners:   &OwnersConfig{Email: true, Webhooks: []string{"chatops"}, Admin: AdminConfig{To: []string{"dba@example.com"}, Webhooks: []string{"chatops"}}},
+	}
+	require.NoError(t, cfg.Validate())
+	email, err := NewEmailNotifier(*cfg.Email, "")
+	require.NoError(t, err)
+	email.TLSConfig = &tls.Config{ServerName: smtpServer.host(), RootCAs: smtpServer.rootCAs}
+	h, _ := newTestWebhookNotifier(t, webhook.URL)
+	h.Config.Name = "chatops"
+	notifier := &OwnerNotifier{Owners: testOwners, Config: *cfg.Owners, Email: email, Webhooks: []*WebhookNotifier{h}, AdminWebhooks: []*WebhookNotifier{h}}
+
+	var buf bytes.Buffer
+	require.NoError(t, notifier.Notify(ctx, testNotice(), false, &buf))
+	require.Contains(t, buf.String(), `(1 clusters) to "Alice" <alice@example.com>`)
+	require.Contains(t, buf.String(), "Sent notice (1 clusters) to webhook chatops for slack:U0B")
+	require.Contains(t, buf.String(), "1 clusters have no owner, sending them to the admin channel")
+	require.Contains(t, buf.String(), "Sent notice (1 clusters) to webhook chatops for admin")
+
+	received := smtpServer.received()
+	require.Len(t, received, 2)
+	require.Equal(t, []string{"alice@example.com"}, received[0].To)
+	require.Equal(t, []string{"dba@example.com"}, received[1].To)
+
+	var recipients []Recipient
+	for _, r := range requests() {
+		var body struct {
+			Recipient    Recipient `json:"recipient"`
+			ClusterCount int       `json:"cluster_count"`
+		}
+		require.NoError(t, json.Unmarshal(r.body, &body))
+		require.Equal(t, 1, body.ClusterCount)
+		recipients = append(recipients, body.Recipient)
+	}
+	require.Equal(t, []Recipient{
+		{Name: "Alice", Team: "payments", Email: "alice@example.com", SlackUserID: "U0A"},
+		{Name: "Bob", Team: "payments", SlackUserID: "U0B"},
+		{Admin: true},
+	}, recipients)
+}
+
+func TestOwnerNotifier_NoChannel(t *testing.T) {
+	webhook, requests := newTestWebhook(t)
+	h, _ := newTestWebhookNotifier(t, webhook.URL)
+	notifier := &OwnerNotifier{Owners: testOwners[1:2], Config: OwnersConfig{Email: true}, AdminWebhooks: []*WebhookNotifier{h}}
+
+	var buf bytes.Buffer
+	require.NoError(t, notifier.Notify(context.Background(), testNotice(), false, &buf))
+	require.Contains(t, buf.String(), "[SKIP] Owner slack:U0B of 1 clusters has no email address and no webhook is configured for owners")
+	require.Contains(t, buf.String(), "1 clusters have no owner, sending them to the admin channel")
+	require.Contains(t, buf.String(), "1 clusters have no owner to send them to, sending them to the admin channel")
+
+	// The cluster of the owner is not lost, but sent to the admin channel with the unowned one
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	var body struct {
+		Recipient    Recipient `json:"recipient"`
+		ClusterCount int       `json:"cluster_count"`
+	}
+	require.NoError(t, json.Unmarshal(reqs[0].body, &body))
+	require.Equal(t, Recipient{Admin: true}, body.Recipient)
+	require.Equal(t, 2, body.ClusterCount)
+}
@@ -0,0 +1,270 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/notice"
+)
+
+// State is the notification state of a finding of a cluster.
+type State struct {
+	ClusterID       string
+	Kind            string
+	Level           int // Number of escalation thresholds crossed when it was last notified
+	FirstNotifiedAt time.Time
+	LastNotifiedAt  time.Time
+	Count           int
+}
+
+// StateStore defines an interface for reading and updating the notification state.
+type StateStore interface {
+	ListStates(ctx context.Context) ([]State, error)
+	// UpdateStates records the notified findings and deletes the states of the resolved ones.
+	UpdateStates(ctx context.Context, notified, resolved []State) error
+}
+
+// Reasons why a finding is notified.
+const (
+	ReasonNew      = "new"
+	ReasonWorse    = "worse"
+	ReasonRenotify = "renotify"
+)
+
+// Plan is the result of comparing the findings of a notice with the notification state.
+type Plan struct {
+	Suppressed []notice.Finding
+	Resolved   []State // States whose finding is gone
+
+	due    map[stateKey]string // The reason of each finding to notify
+	levels map[stateKey]int
+}
+
+type stateKey struct {
+	clusterID string
+	kind      string
+}
+
+func keyOf(f notice.Finding) stateKey {
+	return stateKey{f.ClusterID, f.Kind}
+}
+
+// Plan decides which findings of the notice are notified at now: the new ones, the ones that got worse,
+// i.e. crossed another escalation threshold, and the ones last notified at least the re-notify interval ago.
+func (c StateConfig) Plan(n *notice.Notice, states []State, now time.Time) Plan {
+	byKey := make(map[stateKey]State, len(states))
+	for _, s := range states {
+		byKey[stateKey{s.ClusterID, s.Kind}] = s
+	}
+
+	p := Plan{due: make(map[stateKey]string), levels: make(map[stateKey]int)}
+	for _, f := range n.Findings {
+		key := keyOf(f)
+		level := c.level(f)
+		p.levels[key] = level
+
+		s, ok := byKey[key]
+		delete(byKey, key)
+		switch {
+		case !ok:
+			p.due[key] = ReasonNew
+		case level > s.Level:
+			p.due[key] = ReasonWorse
+		case now.Sub(s.LastNotifiedAt) >= c.renotifyAfter(f.Kind):
+			p.due[key] = ReasonRenotify
+		default:
+			p.Suppressed = append(p.Suppressed, f)
+		}
+	}
+	for _, s := range states {
+		if _, ok := byKey[stateKey{s.ClusterID, s.Kind}]; ok {
+			p.Resolved = append(p.Resolved, s)
+		}
+	}
+
+	return p
+}
+
+// level returns the number of escalation thresholds of the kind that the finding has crossed.
+func (c StateConfig) level(f notice.Finding) int {
+	level := 0
+	for _, threshold := range c.Findings[f.Kind].EscalateAt {
+		if time.Duration(f.Seconds)*time.Second >= threshold {
+			level++
+		}
+	}
+	return level
+}
+
+func (c StateConfig) renotifyAfter(kind string) time.Duration {
+	if d := c.Findings[kind].RenotifyAfter; d > 0 {
+		return d
+	}
+	return c.RenotifyAfter
+}
+
+// Reason returns why the finding is notified, or false if it is not.
+func (p Plan) Reason(f notice.Finding) (string, bool) {
+	reason, ok := p.due[keyOf(f)]
+	return reason, ok
+}
+
+// Notice returns the notice with only the findings to notify, and the clusters with them.
+func (p Plan) Notice(n *notice.Notice) *notice.Notice {
+	return n.FilterFindings(func(f notice.Finding) bool {
+		_, ok := p.Reason(f)
+		return ok
+	})
+}
+
+// Notified returns the states of the findings to notify, notified at now.
+func (p Plan) Notified(now time.Time) []State {
+	var states []State
+	for key := range p.due {
+		states = append(states, State{ClusterID: key.clusterID, Kind: key.kind, Level: p.levels[key], FirstNotifiedAt: now, LastNotifiedAt: now})
+	}
+	return states
+}
+
+// Summary returns the number of findings to notify for each reason, e.g. "2 new, 1 worse, 0 renotify".
+func (p Plan) Summary() string {
+	counts := make(map[string]int)
+	for _, reason := range p.due {
+		counts[reason]++
+	}
+	return fmt.Sprintf("%d new, %d worse, %d renotify", counts[ReasonNew], counts[ReasonWorse], counts[ReasonRenotify])
+}
+
+// Run sends the notice through the notifiers. With a state store, only the findings due by the plan are sent,
+// and the state is updated if all notifiers succeeded, so that the failed findings are sent again by the next run.
+func Run(ctx context.Context, n *notice.Notice, notifiers []Notifier, cfg *StateConfig, store StateStore, now time.Time, dryRun bool, w io.Writer) error {
+	var plan Plan
+	if cfg != nil {
+		states, err := store.ListStates(ctx)
+		if err != nil {
+			return err
+		}
+		plan = cfg.Plan(n, states, now)
+		fmt.Fprintf(w, "%d findings to notify (%s), %d suppressed until the re-notify interval or until they get worse, %d resolved\n",
+			len(plan.due), plan.Summary(), len(plan.Suppressed), len(plan.Resolved))
+		n = plan.Notice(n)
+	}
+
+	if n.ClusterCount == 0 {
+		fmt.Fprintln(w, "[SKIP] Nothing to notify")
+	} else {
+		var errs []error
+		for _, notifier := range notifiers {
+			if err := notifier.Notify(ctx, n, dryRun, w); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	if cfg == nil || dryRun {
+		return nil
+	}
+	if err := store.UpdateStates(ctx, plan.Notified(now), plan.Resolved); err != nil {
+		return fmt.Errorf("notified, but failed to update the notification state: %w", err)
+	}
+
+	return nil
+}
+
+// DBStateStore implements StateStore on top of the notification_states table.
+type DBStateStore struct {
+	conn    *sql.DB
+	Queries *db.Queries
+}
+
+// NewDBStateStore initializes a new DBStateStore using the given DSN and optional connection pool settings.
+// Returns an error if the connection fails or cannot be verified.
+func NewDBStateStore(dsn string, poolConfig *db.PoolConfig) (*DBStateStore, error) {
+	conn, err := db.Open(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBStateStore{
+		Queries: db.New(conn),
+		conn:    conn,
+	}, nil
+}
+
+func (s *DBStateStore) ListStates(ctx context.Context) ([]State, error) {
+	rows, err := s.Queries.ListNotificationStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification states: %w", err)
+	}
+
+	states := make([]State, 0, len(rows))
+	for _, row := range rows {
+		states = append(states, State{
+			ClusterID:       row.ClusterID,
+			Kind:            row.FindingKind,
+			Level:           int(row.Level),
+			FirstNotifiedAt: row.FirstNotifiedAt,
+			LastNotifiedAt:  row.LastNotifiedAt,
+			Count:           int(row.NotifyCount),
+		})
+	}
+
+	return states, nil
+}
+
+// UpdateStates records the notified findings and deletes the states of the resolved ones within a transaction.
+func (s *DBStateStore) UpdateStates(ctx context.Context, notified, resolved []State) (err error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to rollback transaction after error: %w", rbErr))
+			}
+		}
+	}()
+
+	qtx := s.Queries.WithTx(tx)
+	for _, state := range notified {
+		if err := qtx.UpsertNotificationState(ctx, db.UpsertNotificationStateParams{
+			ClusterID:       state.ClusterID,
+			FindingKind:     state.Kind,
+			Level:           int32(state.Level),
+			FirstNotifiedAt: state.FirstNotifiedAt.UTC(),
+			LastNotifiedAt:  state.LastNotifiedAt.UTC(),
+		}); err != nil {
+			return fmt.Errorf("failed to record notification of %s of cluster %s: %w", state.Kind, state.ClusterID, err)
+		}
+	}
+	for _, state := range resolved {
+		if err := qtx.DeleteNotificationState(ctx, db.DeleteNotificationStateParams{ClusterID: state.ClusterID, FindingKind: state.Kind}); err != nil {
+			return fmt.Errorf("failed to delete notification state of %s of cluster %s: %w", state.Kind, state.ClusterID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying database connection held by the DBStateStore.
+func (s *DBStateStore) Close() error {
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
+	}
+	return nil
+}
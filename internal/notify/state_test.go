@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStateStore is an in-memory StateStore.
+type fakeStateStore struct {
+	states   []State
+	notified []State
+	resolved []State
+}
+
+func (f *fakeStateStore) ListStates(ctx context.Context) ([]State, error) {
+	return f.states, nil
+}
+
+func (f *fakeStateStore) UpdateStates(ctx context.Context, notified, resolved []State) error {
+	f.notified, f.resolved = notified, resolved
+	return nil
+}
+
+// fakeNotifier records the notices it is given.
+type fakeNotifier struct {
+	notices []*notice.Notice
+	err     error
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(ctx context.Context, n *notice.Notice, dryRun bool, w io.Writer) error {
+	f.notices = append(f.notices, n)
+	return f.err
+}
+
+func TestStateConfig_Plan(t *testing.T) {
+	escalating := StateConfig{
+		RenotifyAfter: DefaultRenotifyAfter,
+		Findings:      map[string]FindingStateConfig{notice.FindingLongRunning: {EscalateAt: []time.Duration{14 * 24 * time.Hour, 60 * 24 * time.Hour}}},
+	}
+	state := func(level int, lastNotified time.Duration) []State {
+		return []State{{ClusterID: "c1", Kind: notice.FindingLongRunning, Level: level, LastNotifiedAt: testNow.Add(-lastNotified)}}
+	}
+
+	tests := []struct {
+		name       string
+		cfg        StateConfig
+		states     []State
+		wantReason string // Empty if suppressed
+	}{
+		{name: "new", cfg: escalating, wantReason: ReasonNew},
+		{name: "worse", cfg: escalating, states: state(0, time.Hour), wantReason: ReasonWorse},
+		{name: "suppressed", cfg: escalating, states: state(1, time.Hour)},
+		{name: "renotify", cfg: escalating, states: state(1, 8*24*time.Hour), wantReason: ReasonRenotify},
+		{
+			name:   "renotify interval of the kind",
+			cfg:    StateConfig{RenotifyAfter: time.Hour, Findings: map[string]FindingStateConfig{notice.FindingLongRunning: {RenotifyAfter: 30 * 24 * time.Hour}}},
+			states: state(0, 8*24*time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := testNotice()
+			plan := tt.cfg.Plan(n, tt.states, testNow)
+
+			reason, ok := plan.Reason(n.Findings[0])
+			require.Equal(t, tt.wantReason, reason)
+			if !ok {
+				require.Len(t, plan.Suppressed, 1)
+				require.Zero(t, plan.Notice(n).ClusterCount)
+				return
+			}
+			require.Empty(t, plan.Suppressed)
+			require.Equal(t, []State{{ClusterID: "c1", Kind: notice.FindingLongRunning, Level: tt.cfg.level(n.Findings[0]), FirstNotifiedAt: testNow, LastNotifiedAt: testNow}}, plan.Notified(testNow))
+			require.Equal(t, 1, plan.Notice(n).ClusterCount) // Only the cluster with the finding
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	cfg := &StateConfig{RenotifyAfter: DefaultRenotifyAfter}
+	resolved := State{ClusterID: "c9", Kind: notice.FindingStaleBackup, LastNotifiedAt: testNow.Add(-time.Hour)}
+
+	t.Run("state updated", func(t *testing.T) {
+		store, notifier := &fakeStateStore{states: []State{resolved}}, &fakeNotifier{}
+		var buf bytes.Buffer
+		require.NoError(t, Run(ctx, testNotice(), []Notifier{notifier}, cfg, store, testNow, false, &buf))
+		require.Contains(t, buf.String(), "1 findings to notify (1 new, 0 worse, 0 renotify), 0 suppressed until the re-notify interval or until they get worse, 1 resolved")
+		require.Len(t, notifier.notices, 1)
+		require.Equal(t, 1, notifier.notices[0].ClusterCount)
+		require.Len(t, store.notified, 1)
+		require.Equal(t, []State{resolved}, store.resolved)
+	})
+
+	t.Run("nothing to notify", func(t *testing.T) {
+		notified := State{ClusterID: "c1", Kind: notice.FindingLongRunning, LastNotifiedAt: testNow.Add(-time.Hour)}
+		store, notifier := &fakeStateStore{states: []State{notified}}, &fakeNotifier{}
+		var buf bytes.Buffer
+		require.NoError(t, Run(ctx, testNotice(), []Notifier{notifier}, cfg, store, testNow, false, &buf))
+		require.Contains(t, buf.String(), "[SKIP] Nothing to notify")
+		require.Empty(t, notifier.notices)
+		require.Empty(t, store.notified)
+	})
+
+	t.Run("not updated on failure or dry run", func(t *testing.T) {
+		store := &fakeStateStore{}
+		err := Run(ctx, testNotice(), []Notifier{&fakeNotifier{err: errors.New("boom")}}, cfg, store, testNow, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "fake: boom")
+		require.Nil(t, store.notified)
+
+		require.NoError(t, Run(ctx, testNotice(), []Notifier{&fakeNotifier{}}, cfg, store, testNow, true, &bytes.Buffer{}))
+		require.Nil(t, store.notified)
+	})
+
+	t.Run("without state", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		require.NoError(t, Run(ctx, testNotice(), []Notifier{notifier}, nil, nil, testNow, false, &bytes.Buffer{}))
+		require.Equal(t, 2, notifier.notices[0].ClusterCount) // Every cluster, with or without findings
+	})
+}
@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+)
+
+// Headers of the webhook requests. The receivers verify the signature by computing
+// HMAC-SHA256 of "<timestamp>.<body>" with the shared secret, and reject old timestamps to prevent replays.
+// The idempotency key is the same for every attempt and run sending the same notice to the same webhook.
+const (
+	HeaderSignature      = "X-MSK-Signature" // "sha256=<hex>"
+	HeaderTimestamp      = "X-MSK-Timestamp" // Unix time in seconds
+	HeaderIdempotencyKey = "X-MSK-Idempotency-Key"
+)
+
+// maxBackoff caps the exponential backoff between the attempts.
+const maxBackoff = time.Minute
+
+// WebhookNotifier POSTs the JSON notice of its projects to a webhook, signed with HMAC-SHA256.
+type WebhookNotifier struct {
+	Config WebhookConfig
+	Secret string
+	Client *http.Client
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewWebhookNotifier returns a new WebhookNotifier of the webhook.
+func NewWebhookNotifier(cfg WebhookConfig, secret string) (*WebhookNotifier, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("webhook %s: secret is empty, set %s", cfg.Name, cfg.SecretEnv)
+	}
+
+	return &WebhookNotifier{
+		Config: cfg,
+		Secret: secret,
+		Client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+		sleep:  sleepContext,
+	}, nil
+}
+
+func (h *WebhookNotifier) Name() string {
+	return "webhook " + h.Config.Name
+}
+
+// Notify sends the notice of the projects of the webhook, retrying on temporary failures.
+// Nothing is sent if the webhook has no projects, i.e. it only receives the notices of owners, or if they have no clusters.
+func (h *WebhookNotifier) Notify(ctx context.Context, n *notice.Notice, dryRun bool, w io.Writer) error {
+	if len(h.Config.Projects) == 0 {
+		return nil
+	}
+	filtered := n.ForProjects(h.Config.Projects)
+	if filtered.ClusterCount == 0 {
+		fmt.Fprintf(w, "[SKIP] No clusters of projects %v to send to webhook %s\n", h.Config.Projects, h.Config.Name)
+		return nil
+	}
+
+	return h.send(ctx, filtered, nil, dryRun, w)
+}
+
+// payload is the body of the webhook requests: the JSON notice, with the recipient if the notice is sent to an owner.
+type payload struct {
+	SchemaVersion int `json:"schema_version"`
+	*notice.Notice
+	Recipient *Recipient `json:"recipient,omitempty"`
+}
+
+// send sends the notice, retrying on temporary failures.
+func (h *WebhookNotifier) send(ctx context.Context, n *notice.Notice, recipient *Recipient, dryRun bool, w io.Writer) error {
+	body, err := json.MarshalIndent(payload{notice.JSONSchemaVersion, n, recipient}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notice to JSON: %w", err)
+	}
+	key := IdempotencyKey(h.Config.Name, body)
+	target := fmt.Sprintf("webhook %s", h.Config.Name)
+	if recipient != nil {
+		target += " for " + recipient.String()
+	}
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would send notice (%d clusters) to %s at %s with idempotency key %s\n", n.ClusterCount, target, h.Config.URL, key)
+		return nil
+	}
+
+	backoff := h.Config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := h.post(ctx, body, key)
+		if err == nil {
+			fmt.Fprintf(w, "[SUCCESS] Sent notice (%d clusters) to %s with idempotency key %s\n", n.ClusterCount, target, key)
+			return nil
+		}
+		if retryAfter < 0 || attempt >= h.Config.MaxAttempts {
+			return fmt.Errorf("failed to send notice to %s after %d attempts: %w", target, attempt, err)
+		}
+
+		wait := max(backoff, retryAfter)
+		fmt.Fprintf(w, "Attempt %d to %s failed: %v, retrying in %s\n", attempt, target, err, wait)
+		if err := h.sleep(ctx, wait); err != nil {
+			return fmt.Errorf("failed to send notice to %s: %w", target, err)
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// post sends the body once. On failure, it returns how long to wait before retrying as requested by Retry-After,
+// or -1 if the failure is permanent.
+func (h *WebhookNotifier) post(ctx context.Context, body []byte, key string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(h.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "msk")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(h.Secret, timestamp, body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, err
+		}
+		return 0, err // Network errors and timeouts are retried
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+	err = fmt.Errorf("unexpected status: %s", resp.Status)
+	if resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return min(time.Duration(seconds)*time.Second, maxBackoff), err
+	}
+
+	return -1, err
+}
+
+// Sign returns the value of the signature header of the body sent at the timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IdempotencyKey returns the key identifying the body sent to the webhook. It does not change between the attempts,
+// and the body includes the generation time of the notice, so the receivers can drop the duplicates.
+func IdempotencyKey(webhook string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(webhook))
+	sum.Write([]byte{0})
+	sum.Write(body)
+
+	return hex.EncodeToString(sum.Sum(nil))[:32]
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/notice"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRequest is a request received by the test webhook.
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestWebhook returns a webhook server responding with the statuses in order, and then 200.
+func newTestWebhook(t *testing.T, statuses ...int) (*httptest.Server, func() []webhookRequest) {
+	var mu sync.Mutex
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		if len(requests) <= len(statuses) {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(statuses[len(requests)-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func newTestWebhookNotifier(t *testing.T, url string, projects ...string) (*WebhookNotifier, *[]time.Duration) {
+	cfg := WebhookConfig{
+		Name:           "tickets",
+		URL:            url,
+		SecretEnv:      "SECRET",
+		Projects:       projects,
+		Timeout:        DefaultWebhookTimeout,
+		MaxAttempts:    DefaultWebhookMaxAttempts,
+		InitialBackoff: DefaultWebhookInitialBackoff,
+	}
+	h, err := NewWebhookNotifier(cfg, "s3cr3t")
+	require.NoError(t, err)
+
+	var waits []time.Duration
+	h.now = func() time.Time { return testNow }
+	h.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return h, &waits
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("signed and retried", func(t *testing.T) {
+		server, requests := newTestWebhook(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+		h, waits := newTestWebhookNotifier(t, server.URL, "p2")
+
+		var buf bytes.Buffer
+		require.NoError(t, h.Notify(ctx, testNotice(), false, &buf))
+		require.Contains(t, buf.String(), "Attempt 1 to webhook tickets failed: unexpected status: 503 Service Unavailable, retrying in 3s")
+		require.Contains(t, buf.String(), "[SUCCESS] Sent notice (1 clusters) to webhook tickets")
+		require.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second}, *waits) // Retry-After is longer than the backoff
+
+		received := requests()
+		require.Len(t, received, 3)
+		for _, r := range received {
+			require.Equal(t, "application/json", r.header.Get("Content-Type"))
+			require.Equal(t, "1748779200", r.header.Get(HeaderTimestamp))
+			require.Equal(t, Sign("s3cr3t", "1748779200", r.body), r.header.Get(HeaderSignature))
+			require.Equal(t, received[0].header.Get(HeaderIdempotencyKey), r.header.Get(HeaderIdempotencyKey))
+		}
+
+		n, err := notice.Decode(received[0].body)
+		require.NoError(t, err)
+		require.Equal(t, 1, n.ClusterCount)
+		require.Equal(t, "p2", n.Projects[0].ID)
+	})
+
+	t.Run("permanent failure", func(t *testing.T) {
+		server, requests := newTestWebhook(t, http.StatusBadRequest)
+		h, _ := newTestWebhookNotifier(t, server.URL, "*")
+
+		err := h.Notify(ctx, testNotice(), false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "failed to send notice to webhook tickets after 1 attempts: unexpected status: 400 Bad Request")
+		require.Len(t, requests(), 1)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		server, requests := newTestWebhook(t, 500, 500, 500, 500, 500)
+		h, waits := newTestWebhookNotifier(t, server.URL, "*")
+		h.Config.InitialBackoff = 4 * time.Second
+
+		err := h.Notify(ctx, testNotice(), false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "after 4 attempts: unexpected status: 500 Internal Server Error")
+		require.Len(t, requests(), DefaultWebhookMaxAttempts)
+		require.Equal(t, []time.Duration{4 * time.Second, 8 * time.Second, 16 * time.Second}, *waits)
+	})
+
+	t.Run("dry run and no clusters", func(t *testing.T) {
+		server, requests := newTestWebhook(t)
+		h, _ := newTestWebhookNotifier(t, server.URL, "p1")
+
+		var buf bytes.Buffer
+		require.NoError(t, h.Notify(ctx, testNotice(), true, &buf))
+		require.Contains(t, buf.String(), "[DRY RUN] Would send notice (1 clusters) to webhook tickets at "+server.URL)
+
+		h.Config.Projects = []string{"p9"}
+		require.NoError(t, h.Notify(ctx, testNotice(), false, &buf))
+		require.Contains(t, buf.String(), "[SKIP] No clusters of projects [p9] to send to webhook tickets")
+		require.Empty(t, requests())
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	body := []byte(`{"generated_at":"2025-06-01T12:00:00Z"}`)
+	require.Equal(t, IdempotencyKey("a", body), IdempotencyKey("a", body))
+	require.NotEqual(t, IdempotencyKey("a", body), IdempotencyKey("b", body))
+	require.Len(t, IdempotencyKey("a", body), 32)
+}
+
+func TestNewWebhookNotifier(t *testing.T) {
+	_, err := NewWebhookNotifier(WebhookConfig{Name: "tickets", SecretEnv: "MSK_TICKETS_SECRET"}, "")
+	require.ErrorContains(t, err, "webhook tickets: secret is empty, set MSK_TICKETS_SECRET")
+}
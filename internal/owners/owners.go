@@ -0,0 +1,318 @@
+package owners
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sgykfjsm/msk/internal/db"
+	"gopkg.in/yaml.v3"
+)
+
+// This module maps clusters to their owners. The mapping is a YAML file like:
+//
+//	owners:
+//	  - name: Alice
+//	    team: payments
+//	    email: alice@example.com
+//	    slack_user_id: U0123456789
+//	    projects: ["1234567890"]   # "*" (all projects) if omitted
+//	    clusters: ["pay-*"]        # Glob of the cluster names, "*" if omitted
+//
+// or a CSV file with the header "project_id,cluster_pattern,name,team,email,slack_user_id" and one row
+// per project and pattern. A cluster is owned by every entry matching it, and an owner is identified by
+// the email address, or the Slack user ID if there is no email address.
+
+// Owner is an owner of the clusters of a project whose names match a pattern.
+type Owner struct {
+	ProjectID      string `json:"project_id"`      // "*" for all projects
+	ClusterPattern string `json:"cluster_pattern"` // Glob of path.Match, e.g. "pay-*"
+	Name           string `json:"name"`
+	Team           string `json:"team"`
+	Email          string `json:"email"`
+	SlackUserID    string `json:"slack_user_id"`
+	Source         string `json:"source"` // The mapping file the owner was read from
+}
+
+// mappingFile is the content of a YAML mapping file.
+type mappingFile struct {
+	Owners []mappingEntry `yaml:"owners"`
+}
+
+type mappingEntry struct {
+	Name        string   `yaml:"name"`
+	Team        string   `yaml:"team"`
+	Email       string   `yaml:"email"`
+	SlackUserID string   `yaml:"slack_user_id"`
+	Projects    []string `yaml:"projects"`
+	Clusters    []string `yaml:"clusters"`
+}
+
+// csvColumns are the columns of a CSV mapping file. Only project_id and cluster_pattern can be empty.
+var csvColumns = []string{"project_id", "cluster_pattern", "name", "team", "email", "slack_user_id"}
+
+// Matches reports whether the owner owns the cluster.
+func (o Owner) Matches(projectID, clusterName string) bool {
+	if o.ProjectID != "*" && o.ProjectID != projectID {
+		return false
+	}
+	ok, _ := path.Match(o.ClusterPattern, clusterName) // The pattern is validated when it is loaded
+	return ok
+}
+
+// Contact returns the identity of the owner: the email address, or the Slack user ID if there is none.
+func (o Owner) Contact() string {
+	if o.Email != "" {
+		return o.Email
+	}
+	return "slack:" + o.SlackUserID
+}
+
+// Load reads the owners from a mapping file, a YAML file with the .yaml or .yml extension or a CSV file with .csv.
+func Load(file string) ([]Owner, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owners mapping %s: %w", file, err)
+	}
+
+	var owners []Owner
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".yaml", ".yml":
+		owners, err = parseYAML(data, file)
+	case ".csv":
+		owners, err = parseCSV(data, file)
+	default:
+		return nil, fmt.Errorf("unsupported owners mapping %s: the extension must be .yaml, .yml or .csv", file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
+
+func parseYAML(data []byte, source string) ([]Owner, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // Unknown fields are rejected to catch typos
+	var file mappingFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse owners mapping %s: %w", source, err)
+	}
+
+	var owners []Owner
+	for i, entry := range file.Owners {
+		projects, clusters := entry.Projects, entry.Clusters
+		if len(projects) == 0 {
+			projects = []string{"*"}
+		}
+		if len(clusters) == 0 {
+			clusters = []string{"*"}
+		}
+		for _, projectID := range projects {
+			for _, pattern := range clusters {
+				owner := Owner{
+					ProjectID:      projectID,
+					ClusterPattern: pattern,
+					Name:           entry.Name,
+					Team:           entry.Team,
+					Email:          entry.Email,
+					SlackUserID:    entry.SlackUserID,
+					Source:         source,
+				}
+				if err := owner.validate(); err != nil {
+					return nil, fmt.Errorf("invalid owner #%d in %s: %w", i+1, source, err)
+				}
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+func parseCSV(data []byte, source string) ([]Owner, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse owners mapping %s: %w", source, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(column))] = i
+	}
+	for _, column := range csvColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("owners mapping %s has no %s column (required columns: %s)", source, column, strings.Join(csvColumns, ", "))
+		}
+	}
+
+	var owners []Owner
+	for i, record := range records[1:] {
+		get := func(column string) string { return strings.TrimSpace(record[columns[column]]) }
+		owner := Owner{
+			ProjectID:      get("project_id"),
+			ClusterPattern: get("cluster_pattern"),
+			Name:           get("name"),
+			Team:           get("team"),
+			Email:          get("email"),
+			SlackUserID:    get("slack_user_id"),
+			Source:         source,
+		}
+		if owner.ProjectID == "" {
+			owner.ProjectID = "*"
+		}
+		if owner.ClusterPattern == "" {
+			owner.ClusterPattern = "*"
+		}
+		if err := owner.validate(); err != nil {
+			return nil, fmt.Errorf("invalid owner in line %d of %s: %w", i+2, source, err)
+		}
+		owners = append(owners, owner)
+	}
+
+	return owners, nil
+}
+
+func (o Owner) validate() error {
+	if o.Email == "" && o.SlackUserID == "" {
+		return fmt.Errorf("owner %q: email or slack_user_id is required", o.Name)
+	}
+	if _, err := path.Match(o.ClusterPattern, ""); err != nil {
+		return fmt.Errorf("owner %q: invalid cluster pattern %q: %w", o.Name, o.ClusterPattern, err)
+	}
+	return nil
+}
+
+// Store defines an interface for storing the owners.
+type Store interface {
+	ReplaceOwners(ctx context.Context, owners []Owner) error
+	ListOwners(ctx context.Context) ([]Owner, error)
+}
+
+// DBOwnerStore implements Store on top of the owners table.
+type DBOwnerStore struct {
+	conn    *sql.DB
+	Queries *db.Queries
+}
+
+// NewDBOwnerStore initializes a new DBOwnerStore using the given DSN and optional connection pool settings.
+// Returns an error if the connection fails or cannot be verified.
+func NewDBOwnerStore(dsn string, poolConfig *db.PoolConfig) (*DBOwnerStore, error) {
+	conn, err := db.Open(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBOwnerStore{
+		Queries: db.New(conn),
+		conn:    conn,
+	}, nil
+}
+
+// ReplaceOwners replaces all stored owners with the given ones within a transaction,
+// so that notify never sees a partially imported mapping.
+func (s *DBOwnerStore) ReplaceOwners(ctx context.Context, owners []Owner) (err error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to rollback transaction after error: %w", rbErr))
+			}
+		}
+	}()
+
+	qtx := s.Queries.WithTx(tx)
+	if err := qtx.DeleteOwners(ctx); err != nil {
+		return fmt.Errorf("failed to delete owners: %w", err)
+	}
+	for _, o := range owners {
+		if err := qtx.InsertOwner(ctx, db.InsertOwnerParams{
+			ProjectID:      o.ProjectID,
+			ClusterPattern: o.ClusterPattern,
+			Name:           o.Name,
+			Team:           o.Team,
+			Email:          o.Email,
+			SlackUserID:    o.SlackUserID,
+			Source:         o.Source,
+		}); err != nil {
+			return fmt.Errorf("failed to insert owner %s: %w", o.Contact(), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListOwners returns the stored owners in the order of the mapping.
+func (s *DBOwnerStore) ListOwners(ctx context.Context) ([]Owner, error) {
+	rows, err := s.Queries.ListOwners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list owners: %w", err)
+	}
+
+	owners := make([]Owner, 0, len(rows))
+	for _, row := range rows {
+		owners = append(owners, Owner{
+			ProjectID:      row.ProjectID,
+			ClusterPattern: row.ClusterPattern,
+			Name:           row.Name,
+			Team:           row.Team,
+			Email:          row.Email,
+			SlackUserID:    row.SlackUserID,
+			Source:         row.Source,
+		})
+	}
+
+	return owners, nil
+}
+
+// Close closes the underlying database connection held by the DBOwnerStore.
+func (s *DBOwnerStore) Close() error {
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// Contacts returns the distinct contacts of the owners in the order of their first entry.
+func Contacts(owners []Owner) []string {
+	var contacts []string
+	for _, o := range owners {
+		if !slices.Contains(contacts, o.Contact()) {
+			contacts = append(contacts, o.Contact())
+		}
+	}
+	return contacts
+}
+
+// PrintOwners writes the owners as a table.
+func PrintOwners(w io.Writer, owners []Owner) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT ID\tCLUSTER PATTERN\tNAME\tTEAM\tEMAIL\tSLACK USER ID")
+	for _, o := range owners {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", o.ProjectID, o.ClusterPattern, o.Name, o.Team, o.Email, o.SlackUserID)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,79 @@
+package owners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeMapping(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeMapping(t, "owners.yaml", `
+owners:
+  - name: Alice
+    team: payments
+    email: alice@example.com
+    projects: [p1, p2]
+    clusters: ["pay-*", "billing"]
+  - name: Bob
+    slack_user_id: U0B
+`)
+	owners, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, owners, 5)
+	require.Equal(t, Owner{ProjectID: "p1", ClusterPattern: "pay-*", Name: "Alice", Team: "payments", Email: "alice@example.com", Source: path}, owners[0])
+	require.Equal(t, Owner{ProjectID: "*", ClusterPattern: "*", Name: "Bob", SlackUserID: "U0B", Source: path}, owners[4])
+	require.Equal(t, []string{"alice@example.com", "slack:U0B"}, Contacts(owners))
+}
+
+func TestLoad_CSV(t *testing.T) {
+	path := writeMapping(t, "owners.csv", "name,email,slack_user_id,team,project_id,cluster_pattern\n"+
+		"Alice,alice@example.com,U0A,payments,p1,pay-*\n"+
+		"Bob,,U0B,,,\n")
+	owners, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []Owner{
+		{ProjectID: "p1", ClusterPattern: "pay-*", Name: "Alice", Team: "payments", Email: "alice@example.com", SlackUserID: "U0A", Source: path},
+		{ProjectID: "*", ClusterPattern: "*", Name: "Bob", SlackUserID: "U0B", Source: path},
+	}, owners)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "no contact", file: "owners.yaml", content: "owners:\n  - name: Alice\n", wantErr: `invalid owner #1 in`},
+		{name: "bad pattern", file: "owners.yaml", content: "owners:\n  - email: a@example.com\n    clusters: [\"pay-[\"]\n", wantErr: `invalid cluster pattern "pay-["`},
+		{name: "unknown field", file: "owners.yml", content: "owners:\n  - mail: a@example.com\n", wantErr: "field mail not found"},
+		{name: "missing column", file: "owners.csv", content: "name,email\nAlice,a@example.com\n", wantErr: "has no project_id column"},
+		{name: "csv no contact", file: "owners.csv", content: "project_id,cluster_pattern,name,team,email,slack_user_id\np1,*,Alice,,,\n", wantErr: "invalid owner in line 2"},
+		{name: "extension", file: "owners.json", content: "{}", wantErr: "the extension must be .yaml, .yml or .csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeMapping(t, tt.file, tt.content))
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestOwner_Matches(t *testing.T) {
+	owner := Owner{ProjectID: "p1", ClusterPattern: "pay-*"}
+	require.True(t, owner.Matches("p1", "pay-main"))
+	require.False(t, owner.Matches("p1", "billing"))
+	require.False(t, owner.Matches("p2", "pay-main"))
+
+	all := Owner{ProjectID: "*", ClusterPattern: "*"}
+	require.True(t, all.Matches("p2", "billing"))
+}
@@ -0,0 +1,167 @@
+package privatelink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// This module sets up AWS PrivateLink connectivity from one of our VPCs to a TiDB Cloud Dedicated cluster:
+// 1. Fetch the endpoint service name of the cluster from the TiDB Cloud API
+// 2. Create an interface VPC endpoint for the service in our VPC, with the given subnets and security groups
+// 3. Register the endpoint ID with TiDB Cloud as a private endpoint connection
+// 4. Wait until both the VPC endpoint and the private endpoint connection are available
+// Teardown does the reverse.
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta1/dedicated/#tag/PrivateEndpointConnectionService
+
+// DefaultAPIEndpointBase is the base URL of the TiDB Cloud Dedicated API.
+const DefaultAPIEndpointBase = "https://dedicated.tidbapi.com/v1beta1"
+
+// PrivateLinkService is the endpoint service exposed by TiDB Cloud for a TiDB node group of a cluster.
+type PrivateLinkService struct {
+	ServiceName    string   `json:"serviceName,omitempty"`
+	ServiceDNSName string   `json:"serviceDnsName,omitempty"`
+	AvailableZones []string `json:"availableZones,omitempty"`
+	State          string   `json:"state,omitempty"` // e.g. ACTIVE, CREATING
+}
+
+// PrivateEndpointConnection is a VPC endpoint registered with TiDB Cloud.
+type PrivateEndpointConnection struct {
+	PrivateEndpointConnectionID string   `json:"privateEndpointConnectionId,omitempty"`
+	ClusterID                   string   `json:"clusterId,omitempty"`
+	TiDBNodeGroupID             string   `json:"tidbNodeGroupId,omitempty"`
+	EndpointID                  string   `json:"endpointId,omitempty"`
+	PrivateIPAddresses          []string `json:"privateIpAddresses,omitempty"`
+	EndpointState               string   `json:"endpointState,omitempty"` // e.g. PENDING, ACTIVE, FAILED
+	Message                     string   `json:"message,omitempty"`
+}
+
+// Endpoint states of a private endpoint connection
+const (
+	EndpointStateActive = "ACTIVE"
+	EndpointStateFailed = "FAILED"
+)
+
+// ListPrivateEndpointConnectionsResponse is the response of the ListPrivateEndpointConnections API.
+type ListPrivateEndpointConnectionsResponse struct {
+	PrivateEndpointConnections []PrivateEndpointConnection `json:"privateEndpointConnections,omitempty"`
+	NextPageToken              string                      `json:"nextPageToken,omitempty"`
+}
+
+// PrivateLinkAPI defines the TiDB Cloud operations needed to set up and tear down a private endpoint.
+type PrivateLinkAPI interface {
+	GetPrivateLinkService(ctx context.Context, clusterID, nodeGroupID string) (*PrivateLinkService, error)
+	CreatePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, endpointID string) (*PrivateEndpointConnection, error)
+	GetPrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) (*PrivateEndpointConnection, error)
+	ListPrivateEndpointConnections(ctx context.Context, clusterID, nodeGroupID string) ([]PrivateEndpointConnection, error)
+	DeletePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) error
+}
+
+// APIPrivateLinkClient implements PrivateLinkAPI using the TiDB Cloud Dedicated API.
+type APIPrivateLinkClient struct {
+	Client       *http.Client
+	EndpointBase string
+}
+
+// NewAPIPrivateLinkClient returns a new APIPrivateLinkClient with the given HTTP client (expected to handle digest auth).
+func NewAPIPrivateLinkClient(client *http.Client, endpointBase string) *APIPrivateLinkClient {
+	if endpointBase == "" {
+		endpointBase = DefaultAPIEndpointBase
+	}
+
+	return &APIPrivateLinkClient{
+		Client:       client,
+		EndpointBase: endpointBase,
+	}
+}
+
+func (c *APIPrivateLinkClient) nodeGroupPath(clusterID, nodeGroupID string, elem ...string) (string, error) {
+	return url.JoinPath(c.EndpointBase, append([]string{"clusters", clusterID, "tidbNodeGroups", nodeGroupID}, elem...)...)
+}
+
+func (c *APIPrivateLinkClient) GetPrivateLinkService(ctx context.Context, clusterID, nodeGroupID string) (*PrivateLinkService, error) {
+	endpoint, err := c.nodeGroupPath(clusterID, nodeGroupID, "privateLinkService")
+	if err != nil {
+		return nil, err
+	}
+
+	var svc PrivateLinkService
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint, nil, &svc); err != nil {
+		return nil, fmt.Errorf("failed to get private link service of cluster %s: %w", clusterID, err)
+	}
+
+	return &svc, nil
+}
+
+func (c *APIPrivateLinkClient) CreatePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, endpointID string) (*PrivateEndpointConnection, error) {
+	endpoint, err := c.nodeGroupPath(clusterID, nodeGroupID, "privateEndpointConnections")
+	if err != nil {
+		return nil, err
+	}
+
+	var conn PrivateEndpointConnection
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodPost, endpoint, &PrivateEndpointConnection{EndpointID: endpointID}, &conn); err != nil {
+		return nil, fmt.Errorf("failed to register endpoint %s with cluster %s: %w", endpointID, clusterID, err)
+	}
+
+	return &conn, nil
+}
+
+func (c *APIPrivateLinkClient) GetPrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) (*PrivateEndpointConnection, error) {
+	endpoint, err := c.nodeGroupPath(clusterID, nodeGroupID, "privateEndpointConnections", connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn PrivateEndpointConnection
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint, nil, &conn); err != nil {
+		return nil, fmt.Errorf("failed to get private endpoint connection %s of cluster %s: %w", connectionID, clusterID, err)
+	}
+
+	return &conn, nil
+}
+
+func (c *APIPrivateLinkClient) ListPrivateEndpointConnections(ctx context.Context, clusterID, nodeGroupID string) ([]PrivateEndpointConnection, error) {
+	endpoint, err := c.nodeGroupPath(clusterID, nodeGroupID, "privateEndpointConnections")
+	if err != nil {
+		return nil, err
+	}
+
+	var conns []PrivateEndpointConnection
+	pageToken := ""
+	for {
+		u := endpoint
+		if pageToken != "" {
+			u += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var page ListPrivateEndpointConnectionsResponse
+		if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, u, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list private endpoint connections of cluster %s: %w", clusterID, err)
+		}
+		conns = append(conns, page.PrivateEndpointConnections...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return conns, nil
+}
+
+func (c *APIPrivateLinkClient) DeletePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) error {
+	endpoint, err := c.nodeGroupPath(clusterID, nodeGroupID, "privateEndpointConnections", connectionID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodDelete, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete private endpoint connection %s of cluster %s: %w", connectionID, clusterID, err)
+	}
+
+	return nil
+}
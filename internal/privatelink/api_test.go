@@ -0,0 +1,79 @@
+package privatelink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIPrivateLinkClient_CreatePrivateEndpointConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/clusters/c1/tidbNodeGroups/g1/privateEndpointConnections", r.URL.Path)
+
+		var in PrivateEndpointConnection
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		require.Equal(t, "vpce-0123", in.EndpointID)
+
+		_ = json.NewEncoder(w).Encode(PrivateEndpointConnection{PrivateEndpointConnectionID: "pec-1", EndpointID: in.EndpointID, EndpointState: "PENDING"})
+	}))
+	defer server.Close()
+
+	conn, err := NewAPIPrivateLinkClient(server.Client(), server.URL).CreatePrivateEndpointConnection(context.Background(), "c1", "g1", "vpce-0123")
+	require.NoError(t, err)
+	require.Equal(t, "pec-1", conn.PrivateEndpointConnectionID)
+	require.Equal(t, "PENDING", conn.EndpointState)
+}
+
+func TestAPIPrivateLinkClient_ListPrivateEndpointConnections_Pagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/clusters/c1/tidbNodeGroups/g1/privateEndpointConnections", r.URL.Path)
+
+		resp := ListPrivateEndpointConnectionsResponse{
+			PrivateEndpointConnections: []PrivateEndpointConnection{{PrivateEndpointConnectionID: "pec-1", EndpointID: "vpce-1"}},
+			NextPageToken:              "next",
+		}
+		if r.URL.Query().Get("pageToken") == "next" {
+			resp = ListPrivateEndpointConnectionsResponse{
+				PrivateEndpointConnections: []PrivateEndpointConnection{{PrivateEndpointConnectionID: "pec-2", EndpointID: "vpce-2"}},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	conns, err := NewAPIPrivateLinkClient(server.Client(), server.URL).ListPrivateEndpointConnections(context.Background(), "c1", "g1")
+	require.NoError(t, err)
+	require.Len(t, conns, 2)
+	require.Equal(t, "vpce-2", conns[1].EndpointID)
+}
+
+func TestAPIPrivateLinkClient_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "unauthorized"},
+		{name: "rate limit", status: http.StatusTooManyRequests, wantErr: "rate limit"},
+		{name: "api error", status: http.StatusNotFound, body: `{"code":5,"message":"cluster not found"}`, wantErr: "cluster not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewAPIPrivateLinkClient(server.Client(), server.URL).GetPrivateLinkService(context.Background(), "c1", "g1")
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
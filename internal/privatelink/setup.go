@@ -0,0 +1,230 @@
+package privatelink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// EC2API is the subset of the EC2 API used to manage VPC endpoints.
+type EC2API interface {
+	DescribeVpcEndpoints(ctx context.Context, params *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error)
+	CreateVpcEndpoint(ctx context.Context, params *ec2.CreateVpcEndpointInput, optFns ...func(*ec2.Options)) (*ec2.CreateVpcEndpointOutput, error)
+	DeleteVpcEndpoints(ctx context.Context, params *ec2.DeleteVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteVpcEndpointsOutput, error)
+}
+
+// Params identifies the cluster and the VPC to connect with a private endpoint.
+type Params struct {
+	ClusterID        string
+	TiDBNodeGroupID  string
+	VPCID            string
+	SubnetIDs        []string
+	SecurityGroupIDs []string
+	PollInterval     time.Duration
+	WaitTimeout      time.Duration
+}
+
+// Setup creates (or reuses) the interface VPC endpoint for the cluster, registers it with TiDB Cloud,
+// and waits until it is available. If dryRun is true, only the planned actions are printed.
+func Setup(ctx context.Context, ec2Client EC2API, api PrivateLinkAPI, p Params, dryRun bool, w io.Writer) error {
+	svc, err := api.GetPrivateLinkService(ctx, p.ClusterID, p.TiDBNodeGroupID)
+	if err != nil {
+		return err
+	}
+	if svc.ServiceName == "" {
+		return fmt.Errorf("private link service of cluster %s is not ready yet (state: %q)", p.ClusterID, svc.State)
+	}
+	fmt.Fprintf(w, "Endpoint service of cluster %s: %s (state: %s, zones: %s)\n", p.ClusterID, svc.ServiceName, svc.State, strings.Join(svc.AvailableZones, ", "))
+
+	// 1. Find or create the interface VPC endpoint
+	endpoint, err := findVPCEndpoint(ctx, ec2Client, p.VPCID, svc.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	var endpointID string
+	switch {
+	case endpoint != nil:
+		endpointID = aws.ToString(endpoint.VpcEndpointId)
+		fmt.Fprintf(w, "[SKIP] VPC endpoint %s for %s already exists in VPC %s (state: %s)\n", endpointID, svc.ServiceName, p.VPCID, endpoint.State)
+	case dryRun:
+		fmt.Fprintf(w, "[DRY RUN] Would create interface VPC endpoint for %s in VPC %s with subnets %v and security groups %v\n",
+			svc.ServiceName, p.VPCID, p.SubnetIDs, p.SecurityGroupIDs)
+		fmt.Fprintf(w, "[DRY RUN] Would register the VPC endpoint with cluster %s and wait until it is available\n", p.ClusterID)
+		return nil
+	default:
+		// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.CreateVpcEndpoint
+		output, err := ec2Client.CreateVpcEndpoint(ctx, &ec2.CreateVpcEndpointInput{
+			VpcId:            aws.String(p.VPCID),
+			ServiceName:      aws.String(svc.ServiceName),
+			VpcEndpointType:  ec2types.VpcEndpointTypeInterface,
+			SubnetIds:        p.SubnetIDs,
+			SecurityGroupIds: p.SecurityGroupIDs,
+			TagSpecifications: []ec2types.TagSpecification{{
+				ResourceType: ec2types.ResourceTypeVpcEndpoint,
+				Tags: []ec2types.Tag{
+					{Key: aws.String("Name"), Value: aws.String("tidb-" + p.ClusterID)},
+					{Key: aws.String("ManagedBy"), Value: aws.String("msk")},
+				},
+			}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create VPC endpoint for %s in VPC %s: %w", svc.ServiceName, p.VPCID, err)
+		}
+		endpointID = aws.ToString(output.VpcEndpoint.VpcEndpointId)
+		fmt.Fprintf(w, "[SUCCESS] VPC endpoint %s created in VPC %s\n", endpointID, p.VPCID)
+	}
+
+	// 2. Register the endpoint with TiDB Cloud unless it is already registered
+	conn, err := findConnection(ctx, api, p.ClusterID, p.TiDBNodeGroupID, endpointID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case conn != nil:
+		fmt.Fprintf(w, "[SKIP] VPC endpoint %s is already registered with cluster %s as %s (state: %s)\n", endpointID, p.ClusterID, conn.PrivateEndpointConnectionID, conn.EndpointState)
+	case dryRun:
+		fmt.Fprintf(w, "[DRY RUN] Would register VPC endpoint %s with cluster %s and wait until it is available\n", endpointID, p.ClusterID)
+		return nil
+	default:
+		conn, err = api.CreatePrivateEndpointConnection(ctx, p.ClusterID, p.TiDBNodeGroupID, endpointID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "[SUCCESS] VPC endpoint %s registered with cluster %s as %s\n", endpointID, p.ClusterID, conn.PrivateEndpointConnectionID)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	// 3. Wait until both sides are available
+	fmt.Fprintf(w, "Waiting for the private endpoint %s to become available...\n", endpointID)
+	err = util.WaitUntil(ctx, p.PollInterval, p.WaitTimeout, func() (bool, error) {
+		current, err := api.GetPrivateEndpointConnection(ctx, p.ClusterID, p.TiDBNodeGroupID, conn.PrivateEndpointConnectionID)
+		if err != nil {
+			return false, err
+		}
+		if current.EndpointState == EndpointStateFailed {
+			return false, fmt.Errorf("private endpoint connection %s failed: %s", current.PrivateEndpointConnectionID, current.Message)
+		}
+		if current.EndpointState != EndpointStateActive {
+			return false, nil
+		}
+
+		endpoint, err := findVPCEndpoint(ctx, ec2Client, p.VPCID, svc.ServiceName)
+		if err != nil {
+			return false, err
+		}
+		return endpoint != nil && endpoint.State == ec2types.StateAvailable, nil
+	})
+	if err != nil {
+		return fmt.Errorf("private endpoint %s did not become available: %w", endpointID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Private endpoint %s is available. Connect to %s\n", endpointID, svc.ServiceDNSName)
+
+	return nil
+}
+
+// Teardown deregisters the VPC endpoint from TiDB Cloud and deletes it.
+// If dryRun is true, only the planned actions are printed.
+func Teardown(ctx context.Context, ec2Client EC2API, api PrivateLinkAPI, p Params, dryRun bool, w io.Writer) error {
+	svc, err := api.GetPrivateLinkService(ctx, p.ClusterID, p.TiDBNodeGroupID)
+	if err != nil {
+		return err
+	}
+	endpoint, err := findVPCEndpoint(ctx, ec2Client, p.VPCID, svc.ServiceName)
+	if err != nil {
+		return err
+	}
+	if endpoint == nil {
+		fmt.Fprintf(w, "[SKIP] No VPC endpoint for %s found in VPC %s\n", svc.ServiceName, p.VPCID)
+		return nil
+	}
+	endpointID := aws.ToString(endpoint.VpcEndpointId)
+
+	conn, err := findConnection(ctx, api, p.ClusterID, p.TiDBNodeGroupID, endpointID)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		if conn != nil {
+			fmt.Fprintf(w, "[DRY RUN] Would deregister private endpoint connection %s from cluster %s\n", conn.PrivateEndpointConnectionID, p.ClusterID)
+		}
+		fmt.Fprintf(w, "[DRY RUN] Would delete VPC endpoint %s in VPC %s\n", endpointID, p.VPCID)
+		return nil
+	}
+
+	if conn != nil {
+		if err := api.DeletePrivateEndpointConnection(ctx, p.ClusterID, p.TiDBNodeGroupID, conn.PrivateEndpointConnectionID); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "[SUCCESS] Private endpoint connection %s deregistered from cluster %s\n", conn.PrivateEndpointConnectionID, p.ClusterID)
+	} else {
+		fmt.Fprintf(w, "[SKIP] VPC endpoint %s is not registered with cluster %s\n", endpointID, p.ClusterID)
+	}
+
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DeleteVpcEndpoints
+	output, err := ec2Client.DeleteVpcEndpoints(ctx, &ec2.DeleteVpcEndpointsInput{VpcEndpointIds: []string{endpointID}})
+	if err != nil {
+		return fmt.Errorf("failed to delete VPC endpoint %s: %w", endpointID, err)
+	}
+	if len(output.Unsuccessful) > 0 {
+		item := output.Unsuccessful[0]
+		if item.Error != nil {
+			return fmt.Errorf("failed to delete VPC endpoint %s: %s", endpointID, aws.ToString(item.Error.Message))
+		}
+	}
+	fmt.Fprintf(w, "[SUCCESS] VPC endpoint %s deleted\n", endpointID)
+
+	return nil
+}
+
+// findVPCEndpoint returns the VPC endpoint for the service in the VPC, or nil if there is none.
+// Endpoints being deleted or already deleted are ignored.
+func findVPCEndpoint(ctx context.Context, ec2Client EC2API, vpcID, serviceName string) (*ec2types.VpcEndpoint, error) {
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeVpcEndpoints
+	output, err := ec2Client.DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("vpc-id"), Values: []string{vpcID}},
+			{Name: aws.String("service-name"), Values: []string{serviceName}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe VPC endpoints for %s in VPC %s: %w", serviceName, vpcID, err)
+	}
+
+	for _, endpoint := range output.VpcEndpoints {
+		switch endpoint.State {
+		case ec2types.StateDeleting, ec2types.StateDeleted, ec2types.StateRejected, ec2types.StateFailed, ec2types.StateExpired:
+			continue
+		}
+		return &endpoint, nil
+	}
+
+	return nil, nil
+}
+
+// findConnection returns the private endpoint connection registered for the endpoint, or nil if there is none.
+func findConnection(ctx context.Context, api PrivateLinkAPI, clusterID, nodeGroupID, endpointID string) (*PrivateEndpointConnection, error) {
+	conns, err := api.ListPrivateEndpointConnections(ctx, clusterID, nodeGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conn := range conns {
+		if conn.EndpointID == endpointID {
+			return &conn, nil
+		}
+	}
+
+	return nil, nil
+}
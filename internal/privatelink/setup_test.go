@@ -0,0 +1,100 @@
+package privatelink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI is an in-memory PrivateLinkAPI whose connections become active right after they are registered.
+type fakeAPI struct {
+	service PrivateLinkService
+	conns   []PrivateEndpointConnection
+}
+
+func (f *fakeAPI) GetPrivateLinkService(ctx context.Context, clusterID, nodeGroupID string) (*PrivateLinkService, error) {
+	return &f.service, nil
+}
+
+func (f *fakeAPI) CreatePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, endpointID string) (*PrivateEndpointConnection, error) {
+	conn := PrivateEndpointConnection{
+		PrivateEndpointConnectionID: fmt.Sprintf("pec-%d", len(f.conns)+1),
+		ClusterID:                   clusterID,
+		TiDBNodeGroupID:             nodeGroupID,
+		EndpointID:                  endpointID,
+		EndpointState:               EndpointStateActive,
+	}
+	f.conns = append(f.conns, conn)
+	return &conn, nil
+}
+
+func (f *fakeAPI) GetPrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) (*PrivateEndpointConnection, error) {
+	for _, conn := range f.conns {
+		if conn.PrivateEndpointConnectionID == connectionID {
+			return &conn, nil
+		}
+	}
+	return nil, fmt.Errorf("private endpoint connection %s not found", connectionID)
+}
+
+func (f *fakeAPI) ListPrivateEndpointConnections(ctx context.Context, clusterID, nodeGroupID string) ([]PrivateEndpointConnection, error) {
+	return f.conns, nil
+}
+
+func (f *fakeAPI) DeletePrivateEndpointConnection(ctx context.Context, clusterID, nodeGroupID, connectionID string) error {
+	for i, conn := range f.conns {
+		if conn.PrivateEndpointConnectionID == connectionID {
+			f.conns = append(f.conns[:i], f.conns[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("private endpoint connection %s not found", connectionID)
+}
+
+func TestSetupAndTeardown(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddVPC(ec2types.Vpc{VpcId: aws.String("vpc-app"), CidrBlock: aws.String("10.0.0.0/16")})
+	api := &fakeAPI{service: PrivateLinkService{ServiceName: "com.amazonaws.vpce.us-west-2.vpce-svc-0123", ServiceDNSName: "tidb.example.com", State: "ACTIVE"}}
+	p := Params{
+		ClusterID:        "c1",
+		TiDBNodeGroupID:  "g1",
+		VPCID:            "vpc-app",
+		SubnetIDs:        []string{"subnet-1"},
+		SecurityGroupIDs: []string{"sg-1"},
+		PollInterval:     time.Millisecond,
+		WaitTimeout:      time.Second,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Setup(ctx, fake, api, p, true, &buf))
+	require.Contains(t, buf.String(), "[DRY RUN] Would create interface VPC endpoint")
+	require.Empty(t, api.conns)
+
+	buf.Reset()
+	require.NoError(t, Setup(ctx, fake, api, p, false, &buf))
+	require.Contains(t, buf.String(), "is available. Connect to tidb.example.com")
+	require.Len(t, api.conns, 1)
+
+	// Running it again reuses the endpoint and the registration
+	buf.Reset()
+	require.NoError(t, Setup(ctx, fake, api, p, false, &buf))
+	require.Contains(t, buf.String(), "already exists")
+	require.Contains(t, buf.String(), "already registered")
+	require.Len(t, api.conns, 1)
+
+	buf.Reset()
+	require.NoError(t, Teardown(ctx, fake, api, p, false, &buf))
+	require.Empty(t, api.conns)
+
+	buf.Reset()
+	require.NoError(t, Teardown(ctx, fake, api, p, false, &buf))
+	require.Contains(t, buf.String(), "[SKIP] No VPC endpoint")
+}
@@ -3,17 +3,14 @@ package project
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
-
-	_ "github.com/go-sql-driver/mysql"
 
 	"github.com/icholy/digest"
 	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
 )
 
 // In this module, I will implement the following:
@@ -53,11 +50,7 @@ type ListProjectResponse struct {
 }
 
 // ListProjectResponseError represents the error structure for the ListProjects API response.
-type ListProjectResponseError struct {
-	Message string   `json:"message,omitempty"`
-	Code    int      `json:"code,omitempty"`
-	Details []string `json:"details,omitempty"`
-}
+type ListProjectResponseError = util.TiDBCloudAPIError
 
 // ProjectFetcher defines the interface for fetching projects from TiDB Cloud.
 // It abstracts the logic of retrieving project data, allowing for easy testing and mocking.
@@ -130,29 +123,12 @@ func (f *APIProjectFetcher) FetchProjects(ctx context.Context, page int, pageSiz
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		var listProjectResponse ListProjectResponse
-		if err := json.NewDecoder(resp.Body).Decode(&listProjectResponse); err != nil {
-			return nil, 0, fmt.Errorf("succeeded to request, but failed to decode response from TiDB Cloud API: %w", err)
-		}
-
-		return listProjectResponse.Items, listProjectResponse.Total, nil
-	}
-
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, 0, errors.New("unauthorized: check your API key")
-	} else if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, 0, errors.New("rate limit: retry after some minutes. See https://docs.pingcap.com/tidbcloud/api/v1beta/#section/Rate-Limiting")
-	}
-
-	// Decode the error response
-	var apiError ListProjectResponseError
-	if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode error response from TiDB Cloud API: %w, status: %s", err, resp.Status)
+	var listProjectResponse ListProjectResponse
+	if err := util.DecodeTiDBCloudResponse(resp, f.Endpoint, &listProjectResponse); err != nil {
+		return nil, 0, err
 	}
 
-	return nil, 0, fmt.Errorf("error from TiDB Cloud API: %s (code: %d, details: %v, endpoint: %s), status: %s", apiError.Message, apiError.Code, apiError.Details, f.Endpoint, resp.Status)
-
+	return listProjectResponse.Items, listProjectResponse.Total, nil
 }
 
 // ProjectStore defines the interface for storing projects in a database.
@@ -175,23 +151,9 @@ type DBProjectStore struct {
 // NewDBProjectStore creates a new instance of DBProjectStore.
 // It takes a DSN (Data Source Name) for the database connection and an optional pool configuration.
 func NewDBProjectStore(dsn string, poolConfig *db.PoolConfig) (*DBProjectStore, error) {
-	conn, err := sql.Open("mysql", dsn)
+	conn, err := db.Open(dsn, poolConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	if poolConfig == nil {
-		poolConfig = db.NewPoolConfig() // Use default pool config if none provided
-	}
-
-	conn.SetMaxOpenConns(poolConfig.MaxOpenConns)
-	conn.SetMaxIdleConns(poolConfig.MaxIdleConns)
-	conn.SetConnMaxLifetime(poolConfig.ConnMaxLifetime)
-
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	if err := conn.PingContext(timeoutCtx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
 	return &DBProjectStore{
@@ -18,26 +18,26 @@ func NewProjectService(fetcher ProjectFetcher, store ProjectStore) *ProjectServi
 }
 
 // FetchAndStoreProjects fetches projects using the ProjectFetcher and processes them.
-// It returns an error if the fetching or processing fails.
-func (s ProjectService) FetchAndStoreProjects(ctx context.Context, page int, pageSize int) error {
-	var processedProjectsNum int
+// It returns the fetched projects, or an error if the fetching or processing fails.
+func (s ProjectService) FetchAndStoreProjects(ctx context.Context, page int, pageSize int) (Projects, error) {
+	var fetched Projects
 
 	for {
 		projects, totalProjectNum, err := s.fetcher.FetchProjects(ctx, page, pageSize)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if err := s.store.StoreProjects(ctx, projects); err != nil {
-			return err
+			return nil, err
 		}
 
-		processedProjectsNum += len(projects)
-		if processedProjectsNum >= totalProjectNum {
+		fetched = append(fetched, projects...)
+		if len(fetched) >= totalProjectNum {
 			break
 		}
 		page++ // Increment the page number to fetch the next set of projects
 	}
 
-	return nil
+	return fetched, nil
 }
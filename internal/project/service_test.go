@@ -52,8 +52,9 @@ func TestProjectService_FetchAndStoreProjects_Success(t *testing.T) {
 		Return(nil).
 		Times(1)
 
-	err := svc.FetchAndStoreProjects(ctx, 1, 2)
+	projects, err := svc.FetchAndStoreProjects(ctx, 1, 2)
 	require.NoError(t, err)
+	require.Equal(t, append(projectPage1, projectPage2...), projects)
 }
 
 func TestProjectService_FetchAndStoreProjects_Fetch_Error(t *testing.T) {
@@ -70,7 +71,7 @@ func TestProjectService_FetchAndStoreProjects_Fetch_Error(t *testing.T) {
 		Return(nil, 0, errors.New("failed to fetch project")).
 		Times(1)
 
-	err := svc.FetchAndStoreProjects(ctx, 1, 2)
+	_, err := svc.FetchAndStoreProjects(ctx, 1, 2)
 	require.Error(t, err)
 }
 
@@ -98,6 +99,6 @@ func TestProjectService_FetchAndStoreProjects_Store_Error(t *testing.T) {
 		Return(errors.New("failed to store project")).
 		Times(1)
 
-	err := svc.FetchAndStoreProjects(ctx, 1, 2)
+	_, err := svc.FetchAndStoreProjects(ctx, 1, 2)
 	require.Error(t, err)
 }
@@ -0,0 +1,151 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// This module restores a deleted (or any) TiDB Cloud Dedicated cluster from one of its backups:
+// 1. Look up the backup and the node map of the original cluster in the database
+// 2. Create a restore with the same node configuration as the original cluster
+// 3. Wait until the restored cluster is AVAILABLE
+// 4. Record the restored cluster in the database like fetch-clusters does
+// Ref: https://docs.pingcap.com/tidbcloud/api/v1beta/#tag/Restore
+
+// Restore is a restore task in the response of the restore API.
+// Note that this instance holds only required attributes for this module, and omit some properties.
+type Restore struct {
+	ID              string          `json:"id,omitempty"`
+	CreateTimestamp string          `json:"create_timestamp,omitempty"` // Unix timestamp in seconds, as a string
+	BackupID        string          `json:"backup_id,omitempty"`
+	ClusterID       string          `json:"cluster_id,omitempty"`
+	Status          string          `json:"status,omitempty"` // PENDING, RUNNING, FAILED or SUCCESS
+	Cluster         RestoredCluster `json:"cluster"`
+	ErrorMessage    string          `json:"error_message,omitempty"`
+}
+
+// RestoredCluster is the cluster created by a restore.
+type RestoredCluster struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// Statuses of a restore
+const (
+	StatusFailed  = "FAILED"
+	StatusSuccess = "SUCCESS"
+)
+
+// CreateRestoreRequest is the request body of the CreateRestoreTask API.
+type CreateRestoreRequest struct {
+	BackupID string        `json:"backup_id"`
+	Name     string        `json:"name"`
+	Config   ClusterConfig `json:"config"`
+}
+
+// ClusterConfig is the configuration of the restored cluster.
+type ClusterConfig struct {
+	RootPassword string              `json:"root_password"`
+	Port         int                 `json:"port,omitempty"`
+	Components   clusters.Components `json:"components"`
+}
+
+// CreateRestoreResponse is the response of the CreateRestoreTask API.
+type CreateRestoreResponse struct {
+	ID        string `json:"id,omitempty"`
+	ClusterID string `json:"cluster_id,omitempty"`
+}
+
+// ListRestoresResponse is the response of the ListRestoreTasks API.
+type ListRestoresResponse struct {
+	Items []Restore `json:"items,omitempty"`
+	Total int       `json:"total,omitempty"`
+}
+
+// RestoreAPI defines the TiDB Cloud operations needed to restore a cluster and follow its progress.
+type RestoreAPI interface {
+	CreateRestore(ctx context.Context, projectID string, req CreateRestoreRequest) (*CreateRestoreResponse, error)
+	GetRestore(ctx context.Context, projectID, restoreID string) (*Restore, error)
+	ListRestores(ctx context.Context, projectID string, page, pageSize int) ([]Restore, int, error)
+	GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error)
+}
+
+// APIRestoreClient implements RestoreAPI using the TiDB Cloud API.
+type APIRestoreClient struct {
+	Client       *http.Client
+	EndpointBase string
+}
+
+// NewAPIRestoreClient returns a new APIRestoreClient with the given HTTP client (expected to handle digest auth).
+func NewAPIRestoreClient(client *http.Client, endpointBase string) *APIRestoreClient {
+	if endpointBase == "" {
+		endpointBase = util.TiDBCloudAPIEndpointBase
+	}
+
+	return &APIRestoreClient{
+		Client:       client,
+		EndpointBase: endpointBase,
+	}
+}
+
+func (c *APIRestoreClient) CreateRestore(ctx context.Context, projectID string, req CreateRestoreRequest) (*CreateRestoreResponse, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "restores")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CreateRestoreResponse
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodPost, endpoint, &req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to restore backup %s: %w", req.BackupID, err)
+	}
+
+	return &resp, nil
+}
+
+func (c *APIRestoreClient) GetRestore(ctx context.Context, projectID, restoreID string) (*Restore, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "restores", restoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	var restore Restore
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint, nil, &restore); err != nil {
+		return nil, fmt.Errorf("failed to get restore %s of project %s: %w", restoreID, projectID, err)
+	}
+
+	return &restore, nil
+}
+
+func (c *APIRestoreClient) ListRestores(ctx context.Context, projectID string, page, pageSize int) ([]Restore, int, error) {
+	endpoint, err := url.JoinPath(c.EndpointBase, "projects", projectID, "restores")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1 // Default to page 1 if not provided or invalid
+	}
+	if pageSize <= 0 {
+		pageSize = 20 // Default to 20 if not provided or invalid
+	}
+	q := url.Values{}
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("page_size", fmt.Sprintf("%d", pageSize))
+
+	var resp ListRestoresResponse
+	if err := util.DoTiDBCloudRequest(ctx, c.Client, http.MethodGet, endpoint+"?"+q.Encode(), nil, &resp); err != nil {
+		return nil, 0, fmt.Errorf("failed to list restores of project %s: %w", projectID, err)
+	}
+
+	return resp.Items, resp.Total, nil
+}
+
+func (c *APIRestoreClient) GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error) {
+	return clusters.NewAPIClusterFetcher(c.Client, c.EndpointBase).FetchCluster(ctx, projectID, clusterID)
+}
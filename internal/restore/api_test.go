@@ -0,0 +1,90 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIRestoreClient_CreateRestore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/projects/p1/restores", r.URL.Path)
+
+		var in map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		require.Equal(t, "b1", in["backup_id"])
+		config := in["config"].(map[string]any)
+		require.Equal(t, "secret", config["root_password"])
+		components := config["components"].(map[string]any)
+		require.Equal(t, map[string]any{"node_size": "8C16G", "node_quantity": float64(2)}, components["tidb"])
+		require.Equal(t, map[string]any{"node_size": "8C32G", "storage_size_gib": float64(500), "node_quantity": float64(3)}, components["tikv"])
+		require.NotContains(t, components, "tiflash")
+
+		_ = json.NewEncoder(w).Encode(CreateRestoreResponse{ID: "r1", ClusterID: "c2"})
+	}))
+	defer server.Close()
+
+	resp, err := NewAPIRestoreClient(server.Client(), server.URL).CreateRestore(context.Background(), "p1", CreateRestoreRequest{
+		BackupID: "b1",
+		Name:     "restored",
+		Config: ClusterConfig{
+			RootPassword: "secret",
+			Port:         4000,
+			Components: clusters.Components{
+				TiDB: &clusters.ComponentConfig{NodeSize: "8C16G", NodeQuantity: 2},
+				TiKV: &clusters.ComponentConfig{NodeSize: "8C32G", StorageSizeGib: 500, NodeQuantity: 3},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &CreateRestoreResponse{ID: "r1", ClusterID: "c2"}, resp)
+}
+
+func TestAPIRestoreClient_ListRestores(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/projects/p1/restores", r.URL.Path)
+		require.Equal(t, "2", r.URL.Query().Get("page"))
+		require.Equal(t, "10", r.URL.Query().Get("page_size"))
+
+		_ = json.NewEncoder(w).Encode(ListRestoresResponse{Items: []Restore{{ID: "r11", Status: StatusSuccess}}, Total: 11})
+	}))
+	defer server.Close()
+
+	restores, total, err := NewAPIRestoreClient(server.Client(), server.URL).ListRestores(context.Background(), "p1", 2, 10)
+	require.NoError(t, err)
+	require.Equal(t, 11, total)
+	require.Equal(t, []Restore{{ID: "r11", Status: StatusSuccess}}, restores)
+}
+
+func TestAPIRestoreClient_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "unauthorized"},
+		{name: "rate limit", status: http.StatusTooManyRequests, wantErr: "rate limit"},
+		{name: "api error", status: http.StatusNotFound, body: `{"code":5,"message":"restore not found"}`, wantErr: "restore not found"},
+		{name: "invalid error response", status: http.StatusBadRequest, body: "invalid json", wantErr: "failed to decode error response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewAPIRestoreClient(server.Client(), server.URL).GetRestore(context.Background(), "p1", "r1")
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
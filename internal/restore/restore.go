@@ -0,0 +1,221 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/backups"
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/sgykfjsm/msk/internal/db"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// Source is a backup to restore from, with the node map of the cluster it was taken from.
+type Source struct {
+	BackupID     string
+	BackupStatus string
+	ProjectID    string
+	ClusterID    string
+	NodeMap      clusters.NodeMap
+}
+
+// RestoreStore defines an interface for reading the source of a restore and recording the restored cluster.
+type RestoreStore interface {
+	GetSource(ctx context.Context, backupID string) (*Source, error)
+	StoreCluster(ctx context.Context, cluster clusters.Cluster) error
+}
+
+// DBRestoreStore implements RestoreStore on top of the tables filled by fetch-clusters.
+type DBRestoreStore struct {
+	clusters *clusters.DBClusterStore
+}
+
+// NewDBRestoreStore initializes a new DBRestoreStore using the given DSN and optional connection pool settings.
+func NewDBRestoreStore(dsn string, poolConfig *db.PoolConfig) (*DBRestoreStore, error) {
+	store, err := clusters.NewDBClusterStore(dsn, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBRestoreStore{clusters: store}, nil
+}
+
+// GetSource returns the backup and the stored node map of its cluster.
+// The backup must have been fetched by fetch-clusters, i.e. its project must have backup tracking enabled.
+func (s *DBRestoreStore) GetSource(ctx context.Context, backupID string) (*Source, error) {
+	backup, err := s.clusters.Queries.GetClusterBackup(ctx, backupID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("backup %s not found, enable backup tracking of its project with track-backups and run fetch-clusters first", backupID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get backup %s: %w", backupID, err)
+	}
+
+	nodeMap, err := s.clusters.GetNodeMap(ctx, backup.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		BackupID:     backup.ID,
+		BackupStatus: backup.BackupStatus,
+		ProjectID:    backup.ProjectID,
+		ClusterID:    backup.ClusterID,
+		NodeMap:      nodeMap,
+	}, nil
+}
+
+// StoreCluster records the cluster, with its node map, in the inventory.
+func (s *DBRestoreStore) StoreCluster(ctx context.Context, cluster clusters.Cluster) error {
+	return s.clusters.StoreClusters(ctx, clusters.Clusters{cluster})
+}
+
+// Close closes the underlying database connection held by the DBRestoreStore.
+func (s *DBRestoreStore) Close() error {
+	return s.clusters.Close()
+}
+
+// Params holds the backup to restore and the settings of the restored cluster.
+type Params struct {
+	BackupID     string
+	Name         string
+	RootPassword string
+	Port         int
+	PollInterval time.Duration
+	WaitTimeout  time.Duration
+}
+
+// NewComponents returns the node configuration of the restored cluster from the node map of the original cluster.
+// Each component gets as many nodes as the original one, with the node size (and storage size) of its first node.
+func NewComponents(nodeMap clusters.NodeMap) (clusters.Components, error) {
+	if len(nodeMap.Tidb) == 0 || len(nodeMap.Tikv) == 0 {
+		return clusters.Components{}, errors.New("node map has no TiDB or TiKV nodes")
+	}
+
+	return clusters.ComponentsFromNodeMap(nodeMap), nil
+}
+
+// Create restores the backup as a new cluster with the node configuration of the original cluster,
+// waits until the cluster is AVAILABLE and records it in the inventory.
+// If dryRun is true, only the planned restore is printed.
+func Create(ctx context.Context, api RestoreAPI, store RestoreStore, p Params, dryRun bool, w io.Writer) error {
+	src, err := store.GetSource(ctx, p.BackupID)
+	if err != nil {
+		return err
+	}
+	if src.BackupStatus != backups.BackupStatusSuccess {
+		return fmt.Errorf("backup %s is in status %q, only %s backups can be restored", p.BackupID, src.BackupStatus, backups.BackupStatusSuccess)
+	}
+
+	components, err := NewComponents(src.NodeMap)
+	if err != nil {
+		return fmt.Errorf("cannot restore backup %s of cluster %s: %w, run fetch-clusters while the cluster exists", p.BackupID, src.ClusterID, err)
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "[DRY RUN] Would restore backup %s of cluster %s in project %s as %q with %s\n", p.BackupID, src.ClusterID, src.ProjectID, p.Name, components)
+		return nil
+	}
+
+	// 1. Create the restore
+	resp, err := api.CreateRestore(ctx, src.ProjectID, CreateRestoreRequest{
+		BackupID: p.BackupID,
+		Name:     p.Name,
+		Config: ClusterConfig{
+			RootPassword: p.RootPassword,
+			Port:         p.Port,
+			Components:   components,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "[SUCCESS] Restore %s started from backup %s as cluster %s (%s) with %s\n", resp.ID, p.BackupID, resp.ClusterID, p.Name, components)
+
+	// 2. Wait until the restored cluster is available
+	fmt.Fprintf(w, "Waiting for cluster %s to become %s...\n", resp.ClusterID, clusters.ClusterStatusAvailable)
+	var cluster *clusters.Cluster
+	err = util.WaitUntil(ctx, p.PollInterval, p.WaitTimeout, func() (bool, error) {
+		restore, err := api.GetRestore(ctx, src.ProjectID, resp.ID)
+		if err != nil {
+			return false, err
+		}
+		if restore.Status == StatusFailed {
+			return false, fmt.Errorf("restore %s failed: %s", resp.ID, restore.ErrorMessage)
+		}
+
+		cluster, err = api.GetCluster(ctx, src.ProjectID, resp.ClusterID)
+		if err != nil {
+			return false, err
+		}
+		return cluster.Status.ClusterStatus == clusters.ClusterStatusAvailable, nil
+	})
+	if err != nil {
+		return fmt.Errorf("cluster %s restored from backup %s did not become available: %w", resp.ClusterID, p.BackupID, err)
+	}
+
+	// 3. Record the restored cluster in the inventory
+	if err := store.StoreCluster(ctx, *cluster); err != nil {
+		return fmt.Errorf("cluster %s is available, but failed to record it: %w", cluster.ID, err)
+	}
+	fmt.Fprintf(w, "[SUCCESS] Cluster %s (%s) is %s and recorded in the inventory\n", cluster.ID, cluster.Name, cluster.Status.ClusterStatus)
+
+	return nil
+}
+
+// ListAll returns all restores of the project.
+func ListAll(ctx context.Context, api RestoreAPI, projectID string, pageSize int) ([]Restore, error) {
+	var restores []Restore
+	page := 1
+	for {
+		items, total, err := api.ListRestores(ctx, projectID, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		restores = append(restores, items...)
+
+		if len(restores) >= total || len(items) == 0 {
+			break
+		}
+		page++
+	}
+
+	return restores, nil
+}
+
+// PrintRestores writes the restores as a table.
+func PrintRestores(w io.Writer, restores []Restore) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tBACKUP ID\tCLUSTER ID\tCLUSTER NAME\tSTATUS\tCLUSTER STATUS\tCREATED")
+	for _, r := range restores {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.BackupID, r.ClusterID, r.Cluster.Name, r.Status, r.Cluster.Status, formatTimestamp(r.CreateTimestamp))
+	}
+
+	return tw.Flush()
+}
+
+// PrintRestore writes the details of a restore.
+func PrintRestore(w io.Writer, r Restore) {
+	fmt.Fprintf(w, "Restore %s: %s\n", r.ID, r.Status)
+	fmt.Fprintf(w, "    Backup : %s\n", r.BackupID)
+	fmt.Fprintf(w, "    Cluster: %s (%s) %s\n", r.ClusterID, r.Cluster.Name, r.Cluster.Status)
+	fmt.Fprintf(w, "    Created: %s\n", formatTimestamp(r.CreateTimestamp))
+	if r.ErrorMessage != "" {
+		fmt.Fprintf(w, "    Error  : %s\n", r.ErrorMessage)
+	}
+}
+
+// formatTimestamp formats a Unix timestamp in seconds given as a string, or returns it as is if it is not a number.
+func formatTimestamp(ts string) string {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ts
+	}
+
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
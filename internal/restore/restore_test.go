@@ -0,0 +1,165 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/clusters"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI is an in-memory RestoreAPI whose restored cluster becomes available after the given number of polls.
+type fakeAPI struct {
+	created       []CreateRestoreRequest
+	restoreStatus string
+	pollsLeft     int
+}
+
+func (f *fakeAPI) CreateRestore(ctx context.Context, projectID string, req CreateRestoreRequest) (*CreateRestoreResponse, error) {
+	f.created = append(f.created, req)
+	return &CreateRestoreResponse{ID: "r1", ClusterID: "c2"}, nil
+}
+
+func (f *fakeAPI) GetRestore(ctx context.Context, projectID, restoreID string) (*Restore, error) {
+	return &Restore{ID: restoreID, Status: f.restoreStatus, ErrorMessage: "no capacity"}, nil
+}
+
+func (f *fakeAPI) ListRestores(ctx context.Context, projectID string, page, pageSize int) ([]Restore, int, error) {
+	all := []Restore{{ID: "r1"}, {ID: "r2"}, {ID: "r3"}}
+	start := (page - 1) * pageSize
+	end := min(start+pageSize, len(all))
+	return all[start:end], len(all), nil
+}
+
+func (f *fakeAPI) GetCluster(ctx context.Context, projectID, clusterID string) (*clusters.Cluster, error) {
+	status := "RESTORING"
+	if f.pollsLeft--; f.pollsLeft <= 0 {
+		status = clusters.ClusterStatusAvailable
+	}
+	return &clusters.Cluster{ID: clusterID, ProjectID: projectID, Name: f.created[0].Name, Status: clusters.ClusterStatus{ClusterStatus: status}}, nil
+}
+
+// fakeStore is an in-memory RestoreStore.
+type fakeStore struct {
+	sources map[string]*Source
+	stored  []clusters.Cluster
+}
+
+func (f *fakeStore) GetSource(ctx context.Context, backupID string) (*Source, error) {
+	src, ok := f.sources[backupID]
+	if !ok {
+		return nil, fmt.Errorf("backup %s not found", backupID)
+	}
+	return src, nil
+}
+
+func (f *fakeStore) StoreCluster(ctx context.Context, cluster clusters.Cluster) error {
+	f.stored = append(f.stored, cluster)
+	return nil
+}
+
+func newFakeStore() *fakeStore {
+	nodeMap := clusters.NodeMap{
+		Tidb: clusters.Nodes{{NodeName: "tidb-0", NodeSize: "8C16G"}, {NodeName: "tidb-1", NodeSize: "8C16G"}},
+		Tikv: clusters.Nodes{
+			{NodeName: "tikv-0", NodeSize: "8C32G", StorageSizeGib: 500},
+			{NodeName: "tikv-1", NodeSize: "8C32G", StorageSizeGib: 500},
+			{NodeName: "tikv-2", NodeSize: "8C32G", StorageSizeGib: 500},
+		},
+	}
+	return &fakeStore{sources: map[string]*Source{
+		"b1":      {BackupID: "b1", BackupStatus: "SUCCESS", ProjectID: "p1", ClusterID: "c1", NodeMap: nodeMap},
+		"running": {BackupID: "running", BackupStatus: "RUNNING", ProjectID: "p1", ClusterID: "c1", NodeMap: nodeMap},
+		"nonodes": {BackupID: "nonodes", BackupStatus: "SUCCESS", ProjectID: "p1", ClusterID: "c0"},
+	}}
+}
+
+func TestNewComponents(t *testing.T) {
+	nodeMap := clusters.NodeMap{
+		Tidb:    clusters.Nodes{{NodeSize: "4C16G"}},
+		Tikv:    clusters.Nodes{{NodeSize: "4C16G", StorageSizeGib: 200}, {NodeSize: "4C16G", StorageSizeGib: 200}, {NodeSize: "4C16G", StorageSizeGib: 200}},
+		Tiflash: clusters.Nodes{{NodeSize: "8C64G", StorageSizeGib: 1024}},
+	}
+
+	components, err := NewComponents(nodeMap)
+	require.NoError(t, err)
+	require.Equal(t, &clusters.ComponentConfig{NodeSize: "4C16G", NodeQuantity: 1}, components.TiDB)
+	require.Equal(t, &clusters.ComponentConfig{NodeSize: "4C16G", StorageSizeGib: 200, NodeQuantity: 3}, components.TiKV)
+	require.Equal(t, &clusters.ComponentConfig{NodeSize: "8C64G", StorageSizeGib: 1024, NodeQuantity: 1}, components.TiFlash)
+	require.Equal(t, "TiDB 1 x 4C16G, TiKV 3 x 4C16G (200 GiB), TiFlash 1 x 8C64G (1024 GiB)", components.String())
+
+	_, err = NewComponents(clusters.NodeMap{Tidb: nodeMap.Tidb})
+	require.ErrorContains(t, err, "no TiDB or TiKV nodes")
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	p := Params{BackupID: "b1", Name: "restored", RootPassword: "secret", Port: 4000, PollInterval: time.Millisecond, WaitTimeout: time.Second}
+
+	t.Run("dry run", func(t *testing.T) {
+		api, store := &fakeAPI{}, newFakeStore()
+		var buf bytes.Buffer
+		require.NoError(t, Create(ctx, api, store, p, true, &buf))
+		require.Contains(t, buf.String(), `[DRY RUN] Would restore backup b1 of cluster c1 in project p1 as "restored" with TiDB 2 x 8C16G, TiKV 3 x 8C32G (500 GiB)`)
+		require.Empty(t, api.created)
+		require.Empty(t, store.stored)
+	})
+
+	t.Run("restored and recorded", func(t *testing.T) {
+		api, store := &fakeAPI{restoreStatus: "RUNNING", pollsLeft: 3}, newFakeStore()
+		var buf bytes.Buffer
+		require.NoError(t, Create(ctx, api, store, p, false, &buf))
+		require.Len(t, api.created, 1)
+		require.Equal(t, 3, api.created[0].Config.Components.TiKV.NodeQuantity)
+		require.Equal(t, "secret", api.created[0].Config.RootPassword)
+		require.Len(t, store.stored, 1)
+		require.Equal(t, "c2", store.stored[0].ID)
+		require.Equal(t, "p1", store.stored[0].ProjectID)
+		require.Contains(t, buf.String(), "[SUCCESS] Cluster c2 (restored) is AVAILABLE and recorded in the inventory")
+	})
+
+	t.Run("restore failed", func(t *testing.T) {
+		api, store := &fakeAPI{restoreStatus: StatusFailed, pollsLeft: 3}, newFakeStore()
+		err := Create(ctx, api, store, p, false, &bytes.Buffer{})
+		require.ErrorContains(t, err, "restore r1 failed: no capacity")
+		require.Empty(t, store.stored)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		api, store := &fakeAPI{restoreStatus: "RUNNING", pollsLeft: 1 << 30}, newFakeStore()
+		p := p
+		p.WaitTimeout = 10 * time.Millisecond
+		err := Create(ctx, api, store, p, false, &bytes.Buffer{})
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("backup not successful", func(t *testing.T) {
+		p := p
+		p.BackupID = "running"
+		require.ErrorContains(t, Create(ctx, &fakeAPI{}, newFakeStore(), p, false, &bytes.Buffer{}), `backup running is in status "RUNNING"`)
+	})
+
+	t.Run("no stored node map", func(t *testing.T) {
+		p := p
+		p.BackupID = "nonodes"
+		require.ErrorContains(t, Create(ctx, &fakeAPI{}, newFakeStore(), p, false, &bytes.Buffer{}), "run fetch-clusters while the cluster exists")
+	})
+}
+
+func TestListAll(t *testing.T) {
+	restores, err := ListAll(context.Background(), &fakeAPI{}, "p1", 2)
+	require.NoError(t, err)
+	require.Len(t, restores, 3)
+	require.Equal(t, "r3", restores[2].ID)
+}
+
+func TestPrintRestores(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintRestores(&buf, []Restore{{ID: "r1", BackupID: "b1", ClusterID: "c2", Status: StatusSuccess, CreateTimestamp: "1700000000", Cluster: RestoredCluster{Name: "restored", Status: "AVAILABLE"}}}))
+	require.Contains(t, buf.String(), "CLUSTER NAME")
+	require.Contains(t, buf.String(), "2023-11-14T22:13:20Z")
+}
@@ -8,20 +8,69 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
-// PrintAWSVariables prints AWS related variables to the provided writer.
-func PrintAWSVariables(ctx context.Context, w io.Writer) error {
-	cfg, err := config.LoadDefaultConfig(ctx)
+// AWSOptions holds the options used to build the AWS configuration shared by every AWS client of msk.
+// Empty fields fall back to the SDK defaults (environment variables, shared config files, etc.).
+type AWSOptions struct {
+	Profile       string // Shared config profile
+	Region        string
+	AssumeRoleARN string // Role to assume on top of the base credentials, e.g. to work in another account
+	ExternalID    string // External ID required by the trust policy of the role, if any
+	SessionName   string // Session name of the assumed role
+	EndpointURL   string // Base endpoint of all AWS services, e.g. LocalStack or Moto running locally
+}
+
+// STSAPI is the subset of the STS API used by msk.
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// LoadAWSConfig loads the AWS configuration according to the given options.
+// If AssumeRoleARN is set, the returned configuration uses the credentials of the assumed role.
+func LoadAWSConfig(ctx context.Context, opts AWSOptions) (aws.Config, error) {
+	var loadOpts []func(*config.LoadOptions) error
+	if opts.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.Region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(opts.Region))
+	}
+	if opts.EndpointURL != "" {
+		// Ref: https://docs.aws.amazon.com/sdkref/latest/guide/feature-ss-endpoints.html
+		loadOpts = append(loadOpts, config.WithBaseEndpoint(opts.EndpointURL))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
 	if err != nil {
-		return fmt.Errorf("unable to load SDK config: %w", err)
+		return aws.Config{}, fmt.Errorf("unable to load SDK config: %w", err)
 	}
 
-	accountID, err := GetCallerAccountID(ctx, cfg)
+	if opts.AssumeRoleARN != "" {
+		// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/credentials/stscreds#NewAssumeRoleProvider
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), opts.AssumeRoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if opts.ExternalID != "" {
+				o.ExternalID = aws.String(opts.ExternalID)
+			}
+			if opts.SessionName != "" {
+				o.RoleSessionName = opts.SessionName
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, nil
+}
+
+// PrintAWSVariables prints AWS related variables to the provided writer.
+// The identity is the effective one, i.e. the assumed role if a role is assumed.
+func PrintAWSVariables(ctx context.Context, cfg aws.Config, stsClient STSAPI, opts AWSOptions, w io.Writer) error {
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
-		return fmt.Errorf("failed to get AWS account ID: %w", err)
+		return fmt.Errorf("failed to get AWS caller identity: %w", err)
 	}
 
 	credentials, err := cfg.Credentials.Retrieve(ctx)
@@ -30,16 +79,25 @@ func PrintAWSVariables(ctx context.Context, w io.Writer) error {
 	}
 
 	fmt.Fprintln(w, "AWS Context variables")
-	fmt.Fprintf(w, "    Account ID: %s\n", accountID)
+	fmt.Fprintf(w, "    Account ID: %s\n", aws.ToString(identity.Account))
+	fmt.Fprintf(w, "    Identity  : %s\n", aws.ToString(identity.Arn))
 	fmt.Fprintf(w, "    Region    : %s\n", cfg.Region)
 	fmt.Fprintf(w, "    Credential: %s\n", credentials.Source)
+	if opts.Profile != "" {
+		fmt.Fprintf(w, "    Profile   : %s\n", opts.Profile)
+	}
+	if opts.AssumeRoleARN != "" {
+		fmt.Fprintf(w, "    Role      : %s\n", opts.AssumeRoleARN)
+	}
+	if opts.EndpointURL != "" {
+		fmt.Fprintf(w, "    Endpoint  : %s\n", opts.EndpointURL)
+	}
 
 	return nil
 }
 
 // GetCallerAccountID retrieves the AWS account ID of the caller.
-func GetCallerAccountID(ctx context.Context, cfg aws.Config) (string, error) {
-	stsClient := sts.NewFromConfig(cfg)
+func GetCallerAccountID(ctx context.Context, stsClient STSAPI) (string, error) {
 	output, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
 		return "", fmt.Errorf("unable to get caller identity: %w", err)
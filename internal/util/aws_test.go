@@ -0,0 +1,48 @@
+package util
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAWSConfig(t *testing.T) {
+	// Isolate from the shared config of the machine running the test
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_PROFILE", "")
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := LoadAWSConfig(context.Background(), AWSOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "us-east-1", cfg.Region)
+	})
+
+	t.Run("regionOverride", func(t *testing.T) {
+		cfg, err := LoadAWSConfig(context.Background(), AWSOptions{Region: "ap-northeast-1"})
+		require.NoError(t, err)
+		require.Equal(t, "ap-northeast-1", cfg.Region)
+	})
+
+	t.Run("assumeRole", func(t *testing.T) {
+		cfg, err := LoadAWSConfig(context.Background(), AWSOptions{AssumeRoleARN: "arn:aws:iam::123456789012:role/msk", ExternalID: "ext", SessionName: "test"})
+		require.NoError(t, err)
+		require.IsType(t, &aws.CredentialsCache{}, cfg.Credentials)
+	})
+
+	t.Run("endpointURL", func(t *testing.T) {
+		cfg, err := LoadAWSConfig(context.Background(), AWSOptions{EndpointURL: "http://localhost:4566"})
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:4566", aws.ToString(cfg.BaseEndpoint))
+	})
+
+	t.Run("unknownProfile", func(t *testing.T) {
+		_, err := LoadAWSConfig(context.Background(), AWSOptions{Profile: "does-not-exist"})
+		require.Error(t, err)
+	})
+}
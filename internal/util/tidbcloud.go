@@ -0,0 +1,77 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TiDBCloudAPIEndpointBase is the base URL of the TiDB Cloud API.
+const TiDBCloudAPIEndpointBase = "https://api.tidbcloud.com/api/v1beta"
+
+// TiDBCloudAPIError is the error response from the TiDB Cloud API.
+type TiDBCloudAPIError struct {
+	Message string   `json:"message,omitempty"`
+	Code    int      `json:"code,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+// DecodeTiDBCloudResponse decodes the JSON body of a successful response from the TiDB Cloud API into out, if given.
+// Any other response is turned into an error with the error returned by the API and the endpoint called.
+func DecodeTiDBCloudResponse(resp *http.Response, endpoint string, out any) error {
+	if resp.StatusCode == http.StatusOK {
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("succeeded to request, but failed to decode response from TiDB Cloud API: %w", err)
+		}
+		return nil
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("unauthorized: check your API key")
+	} else if resp.StatusCode == http.StatusTooManyRequests {
+		return errors.New("rate limit: retry after some minutes. See https://docs.pingcap.com/tidbcloud/api/v1beta/#section/Rate-Limiting")
+	}
+
+	var apiError TiDBCloudAPIError
+	if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
+		return fmt.Errorf("failed to decode error response from TiDB Cloud API: %w, status: %s", err, resp.Status)
+	}
+
+	return fmt.Errorf("error from TiDB Cloud API: %s (code: %d, details: %v, endpoint: %s), status: %s", apiError.Message, apiError.Code, apiError.Details, endpoint, resp.Status)
+}
+
+// DoTiDBCloudRequest sends a request with an optional JSON body to the TiDB Cloud API with the client
+// (expected to handle digest auth), and decodes the JSON response into out, if given.
+func DoTiDBCloudRequest(ctx context.Context, client *http.Client, method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return DecodeTiDBCloudResponse(resp, endpoint, out)
+}
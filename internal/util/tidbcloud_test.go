@@ -0,0 +1,69 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTiDBCloudResponse(t *testing.T) {
+	response := func(status int, body string) *http.Response {
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, DecodeTiDBCloudResponse(response(http.StatusOK, `{"id":"1"}`), "https://example.com", &out))
+	require.Equal(t, "1", out.ID)
+	require.NoError(t, DecodeTiDBCloudResponse(response(http.StatusOK, ""), "https://example.com", nil))
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "invalid response", status: http.StatusOK, body: "oops", wantErr: "failed to decode response from TiDB Cloud API"},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: "unauthorized: check your API key"},
+		{name: "rate limit", status: http.StatusTooManyRequests, wantErr: "rate limit"},
+		{name: "api error", status: http.StatusBadRequest, body: `{"message":"bad","code":400,"details":["x"]}`, wantErr: "error from TiDB Cloud API: bad (code: 400, details: [x], endpoint: https://example.com)"},
+		{name: "undecodable error", status: http.StatusInternalServerError, body: "oops", wantErr: "failed to decode error response from TiDB Cloud API"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeTiDBCloudResponse(response(tt.status, tt.body), "https://example.com", &out)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDoTiDBCloudRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.JSONEq(t, `{"name":"c1"}`, string(body))
+		} else {
+			require.Empty(t, body)
+		}
+		_, _ = io.WriteString(w, `{"id":"1"}`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	in := struct {
+		Name string `json:"name"`
+	}{Name: "c1"}
+	var out struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, DoTiDBCloudRequest(ctx, server.Client(), http.MethodPost, server.URL, &in, &out))
+	require.Equal(t, "1", out.ID)
+	require.NoError(t, DoTiDBCloudRequest(ctx, server.Client(), http.MethodGet, server.URL, nil, nil))
+}
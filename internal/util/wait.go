@@ -0,0 +1,32 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitUntil calls check every interval until it returns true or an error, or the timeout expires.
+func WaitUntil(ctx context.Context, interval, timeout time.Duration, check func() (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
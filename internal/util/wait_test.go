@@ -0,0 +1,24 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitUntil(t *testing.T) {
+	calls := 0
+	err := WaitUntil(context.Background(), time.Millisecond, time.Second, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	err = WaitUntil(context.Background(), time.Millisecond, 10*time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	require.ErrorContains(t, err, "timed out")
+}
@@ -0,0 +1,164 @@
+package versions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is the policy of the TiDB versions the clusters may run. It is read from a YAML file like:
+//
+//	minimum_version: v7.1.0
+//	blocked_versions:         # Full versions, or partial ones matching all their patch versions
+//	  - version: v7.5.0
+//	    reason: known data corruption bug, upgrade to v7.5.1 or later
+//	  - version: v6.6
+//	end_of_support:           # The most specific version matching a cluster wins
+//	  - version: v6.5
+//	    date: 2025-12-31
+//	  - version: v7.1
+//	    date: 2026-06-30
+//	eos_warning: 2160h        # Warn of the versions reaching the end of support within 90 days
+type Policy struct {
+	MinimumVersion  string           `yaml:"minimum_version,omitempty"`
+	BlockedVersions []BlockedVersion `yaml:"blocked_versions,omitempty"`
+	EndOfSupport    []EndOfSupport   `yaml:"end_of_support,omitempty"`
+	EOSWarning      time.Duration    `yaml:"eos_warning,omitempty"`
+
+	minimum *Version
+}
+
+// BlockedVersion is a version the clusters must not run.
+type BlockedVersion struct {
+	Version string `yaml:"version"`
+	Reason  string `yaml:"reason,omitempty"`
+
+	pattern Pattern
+}
+
+// EndOfSupport is the date from which a version is no longer supported.
+type EndOfSupport struct {
+	Version string `yaml:"version"`
+	Date    string `yaml:"date"` // 2006-01-02. The version is out of support from the beginning of the day in UTC
+
+	pattern Pattern
+	date    time.Time
+}
+
+// LoadPolicy reads and validates the policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version policy %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // Unknown fields are rejected to catch typos
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse version policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid version policy %s: %w", path, err)
+	}
+
+	return &policy, nil
+}
+
+// Validate parses the versions and the dates of the policy.
+func (p *Policy) Validate() error {
+	p.minimum = nil
+	if p.MinimumVersion != "" {
+		minimum, err := ParseVersion(p.MinimumVersion)
+		if err != nil {
+			return fmt.Errorf("minimum_version: %w", err)
+		}
+		p.minimum = &minimum
+	}
+
+	for i := range p.BlockedVersions {
+		b := &p.BlockedVersions[i]
+		pattern, err := ParsePattern(b.Version)
+		if err != nil {
+			return fmt.Errorf("blocked_versions[%d]: %w", i, err)
+		}
+		b.pattern = pattern
+	}
+
+	for i := range p.EndOfSupport {
+		e := &p.EndOfSupport[i]
+		pattern, err := ParsePattern(e.Version)
+		if err != nil {
+			return fmt.Errorf("end_of_support[%d]: %w", i, err)
+		}
+		date, err := time.Parse(time.DateOnly, e.Date)
+		if err != nil {
+			return fmt.Errorf("end_of_support[%d]: invalid date %q, expected 2006-01-02", i, e.Date)
+		}
+		e.pattern, e.date = pattern, date
+	}
+
+	if p.EOSWarning < 0 {
+		return errors.New("eos_warning must not be negative")
+	}
+
+	return nil
+}
+
+// Check returns the issues of the version under the policy, violations first. A version that cannot be parsed is
+// reported as a warning, since it cannot be checked.
+func (p *Policy) Check(version string, now time.Time) []Issue {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return []Issue{{Kind: IssueInvalidVersion, Severity: StatusWarning, Message: err.Error()}}
+	}
+
+	var violations, warnings []Issue
+	if p.minimum != nil && v.Compare(*p.minimum) < 0 {
+		violations = append(violations, Issue{Kind: IssueBelowMinimum, Severity: StatusViolation,
+			Message: fmt.Sprintf("older than the minimum version %s", p.minimum)})
+	}
+	for _, b := range p.BlockedVersions {
+		if !b.pattern.Matches(v) {
+			continue
+		}
+		message := fmt.Sprintf("blocked by %s", b.pattern)
+		if b.Reason != "" {
+			message += ": " + b.Reason
+		}
+		violations = append(violations, Issue{Kind: IssueBlocked, Severity: StatusViolation, Message: message})
+	}
+
+	if eos := p.endOfSupport(v); eos != nil {
+		date := eos.date.Format(time.DateOnly)
+		if !now.Before(eos.date) {
+			violations = append(violations, Issue{Kind: IssueEndOfSupport, Severity: StatusViolation,
+				Message: fmt.Sprintf("out of support since %s (%s)", date, eos.pattern)})
+		} else if p.EOSWarning > 0 && eos.date.Sub(now) <= p.EOSWarning {
+			days := int(math.Ceil(eos.date.Sub(now).Hours() / 24))
+			warnings = append(warnings, Issue{Kind: IssueEndOfSupportSoon, Severity: StatusWarning,
+				Message: fmt.Sprintf("out of support on %s (%s), in %d days", date, eos.pattern, days)})
+		}
+	}
+
+	return append(violations, warnings...)
+}
+
+// endOfSupport returns the most specific end of support matching the version, or the first one among equally specific ones.
+func (p *Policy) endOfSupport(v Version) *EndOfSupport {
+	var found *EndOfSupport
+	for i := range p.EndOfSupport {
+		e := &p.EndOfSupport[i]
+		if e.pattern.Matches(v) && (found == nil || e.pattern.specificity() > found.pattern.specificity()) {
+			found = e
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,97 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const testPolicy = `
+minimum_version: v7.1.0
+blocked_versions:
+  - version: v7.5.0
+    reason: known data corruption bug
+  - version: v6.6
+end_of_support:
+  - version: v7.1
+    date: 2025-06-01
+  - version: v7.1.8
+    date: "2026-01-31"
+  - version: v7.5
+    date: 2025-08-30
+eos_warning: 2160h
+`
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "versions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+	require.Equal(t, "v7.1.0", policy.MinimumVersion)
+	require.Equal(t, 90*24*time.Hour, policy.EOSWarning)
+	require.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), policy.EndOfSupport[0].date)
+
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{content: "minimum_version: v7", wantErr: "minimum_version: invalid version \"v7\": expected major.minor.patch"},
+		{content: "blocked_versions: [{version: latest}]", wantErr: "blocked_versions[0]: invalid version \"latest\""},
+		{content: "end_of_support: [{version: v6.5, date: 31/12/2025}]", wantErr: "end_of_support[0]: invalid date \"31/12/2025\""},
+		{content: "eos_warning: -1h", wantErr: "eos_warning must not be negative"},
+		{content: "minimum: v7.1.0", wantErr: "field minimum not found"},
+	}
+	for _, tt := range tests {
+		_, err := LoadPolicy(writePolicy(t, tt.content))
+		require.ErrorContains(t, err, tt.wantErr, tt.content)
+	}
+
+	policy, err = LoadPolicy(writePolicy(t, ""))
+	require.NoError(t, err)
+	require.Empty(t, policy.Check("v5.0.0", testNow))
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+
+	tests := []struct {
+		version string
+		want    []Issue
+	}{
+		{version: "v8.1.0"},
+		{version: "v6.5.12", want: []Issue{
+			{Kind: IssueBelowMinimum, Severity: StatusViolation, Message: "older than the minimum version v7.1.0"},
+		}},
+		{version: "v6.6.0", want: []Issue{
+			{Kind: IssueBelowMinimum, Severity: StatusViolation, Message: "older than the minimum version v7.1.0"},
+			{Kind: IssueBlocked, Severity: StatusViolation, Message: "blocked by v6.6"},
+		}},
+		{version: "v7.1.5", want: []Issue{
+			{Kind: IssueEndOfSupport, Severity: StatusViolation, Message: "out of support since 2025-06-01 (v7.1)"},
+		}},
+		// The end of support of v7.1.8 overrides the one of v7.1, and is beyond the warning
+		{version: "v7.1.8"},
+		{version: "v7.5.0", want: []Issue{
+			{Kind: IssueBlocked, Severity: StatusViolation, Message: "blocked by v7.5.0: known data corruption bug"},
+			{Kind: IssueEndOfSupportSoon, Severity: StatusWarning, Message: "out of support on 2025-08-30 (v7.5), in 90 days"},
+		}},
+		{version: "nightly", want: []Issue{
+			{Kind: IssueInvalidVersion, Severity: StatusWarning, Message: `invalid version "nightly": "nightly" is not a number`},
+		}},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, policy.Check(tt.version, testNow), tt.version)
+	}
+}
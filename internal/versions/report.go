@@ -0,0 +1,196 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sgykfjsm/msk/internal/db"
+)
+
+// Statuses of the versions, also used as the severities of the issues.
+const (
+	StatusOK        = "ok"
+	StatusWarning   = "warning"
+	StatusViolation = "violation"
+)
+
+// Kinds of the issues.
+const (
+	IssueBelowMinimum     = "below_minimum"
+	IssueBlocked          = "blocked"
+	IssueEndOfSupport     = "end_of_support"
+	IssueEndOfSupportSoon = "end_of_support_soon"
+	IssueInvalidVersion   = "invalid_version"
+)
+
+// Issue is a breach of the policy by a version.
+type Issue struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"` // warning or violation
+	Message  string `json:"message"`
+}
+
+// Cluster is a cluster stored by fetch-clusters.
+type Cluster struct {
+	ID          string `json:"id"`
+	ProjectID   string `json:"project_id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	TiDBVersion string `json:"tidb_version"`
+}
+
+// Group holds the clusters running a version.
+type Group struct {
+	Version  string    `json:"version"`
+	Status   string    `json:"status"`
+	Issues   []Issue   `json:"issues"`
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Report holds the clusters grouped by version, newest first.
+type Report struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	ClusterCount int       `json:"cluster_count"`
+	Violations   int       `json:"violations"` // Number of the clusters violating the policy
+	Warnings     int       `json:"warnings"`   // Number of the clusters with warnings only
+	Versions     []Group   `json:"versions"`
+}
+
+// Check groups the clusters by version and checks the versions against the policy.
+func Check(policy *Policy, clusters []Cluster, now time.Time) *Report {
+	byVersion := make(map[string][]Cluster)
+	for _, c := range clusters {
+		byVersion[c.TiDBVersion] = append(byVersion[c.TiDBVersion], c)
+	}
+
+	report := &Report{GeneratedAt: now, ClusterCount: len(clusters), Versions: []Group{}}
+	for version, members := range byVersion {
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].ProjectID != members[j].ProjectID {
+				return members[i].ProjectID < members[j].ProjectID
+			}
+			return members[i].Name < members[j].Name
+		})
+
+		group := Group{Version: version, Status: StatusOK, Issues: policy.Check(version, now), Clusters: members}
+		if len(group.Issues) > 0 {
+			group.Status = group.Issues[0].Severity
+		}
+		switch group.Status {
+		case StatusViolation:
+			report.Violations += len(members)
+		case StatusWarning:
+			report.Warnings += len(members)
+		}
+		report.Versions = append(report.Versions, group)
+	}
+	sort.Slice(report.Versions, func(i, j int) bool {
+		return newer(report.Versions[i].Version, report.Versions[j].Version)
+	})
+
+	return report
+}
+
+// newer reports whether a sorts before b: newer versions first, followed by the ones that cannot be parsed.
+func newer(a, b string) bool {
+	va, errA := ParseVersion(a)
+	vb, errB := ParseVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		if c := va.Compare(vb); c != 0 {
+			return c > 0
+		}
+		return a < b
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
+
+// PrintReport writes the versions as a table, followed by the clusters breaching the policy.
+func PrintReport(w io.Writer, r *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tSTATUS\tCLUSTERS\tISSUES")
+	for _, g := range r.Versions {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", g.Version, g.Status, len(g.Clusters), issueMessages(g.Issues))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.Violations+r.Warnings > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PROJECT ID\tCLUSTER ID\tCLUSTER NAME\tCLUSTER STATUS\tVERSION\tSTATUS")
+		for _, g := range r.Versions {
+			if g.Status == StatusOK {
+				continue
+			}
+			for _, c := range g.Clusters {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c.ProjectID, c.ID, c.Name, c.Status, g.Version, g.Status)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d clusters on %d versions: %d violate the policy, %d have warnings\n", r.ClusterCount, len(r.Versions), r.Violations, r.Warnings)
+
+	return nil
+}
+
+func issueMessages(issues []Issue) string {
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Inventory defines an interface for reading the stored clusters.
+type Inventory interface {
+	ListClusters(ctx context.Context) ([]Cluster, error)
+}
+
+// DBInventory implements Inventory on top of the tables filled by fetch-clusters.
+type DBInventory struct {
+	queries *db.Queries
+}
+
+// NewDBInventory returns a DBInventory reading with the given queries. The caller owns the connection under them.
+func NewDBInventory(queries *db.Queries) *DBInventory {
+	return &DBInventory{queries: queries}
+}
+
+// ListClusters returns the stored clusters that are not marked as deleted.
+func (s *DBInventory) ListClusters(ctx context.Context) ([]Cluster, error) {
+	rows, err := s.queries.ListClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	var active []Cluster
+	for _, row := range rows {
+		if row.IsDeleted {
+			continue
+		}
+		active = append(active, Cluster{
+			ID:          row.ID,
+			ProjectID:   row.ProjectID,
+			Name:        row.Name,
+			Status:      row.ClusterStatus,
+			TiDBVersion: row.TidbVersion,
+		})
+	}
+
+	return active, nil
+}
@@ -0,0 +1,55 @@
+package versions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testClusters() []Cluster {
+	return []Cluster{
+		{ID: "c1", ProjectID: "p1", Name: "pay-main", Status: "AVAILABLE", TiDBVersion: "v8.1.0"},
+		{ID: "c2", ProjectID: "p1", Name: "pay-dev", Status: "AVAILABLE", TiDBVersion: "v7.5.0"},
+		{ID: "c3", ProjectID: "p2", Name: "bi", Status: "PAUSED", TiDBVersion: "v6.5.12"},
+		{ID: "c4", ProjectID: "p1", Name: "pay-batch", Status: "AVAILABLE", TiDBVersion: "v8.1.0"},
+		{ID: "c5", ProjectID: "p2", Name: "bi-next", Status: "AVAILABLE", TiDBVersion: "v7.5.2"},
+		{ID: "c6", ProjectID: "p3", Name: "lab", Status: "AVAILABLE", TiDBVersion: "nightly"},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+
+	r := Check(policy, testClusters(), testNow)
+	require.Equal(t, 6, r.ClusterCount)
+	require.Equal(t, 2, r.Violations)
+	require.Equal(t, 2, r.Warnings)
+
+	var versions, statuses []string
+	for _, g := range r.Versions {
+		versions = append(versions, g.Version)
+		statuses = append(statuses, g.Status)
+	}
+	require.Equal(t, []string{"v8.1.0", "v7.5.2", "v7.5.0", "v6.5.12", "nightly"}, versions)
+	require.Equal(t, []string{StatusOK, StatusWarning, StatusViolation, StatusViolation, StatusWarning}, statuses)
+	require.Equal(t, []Cluster{testClusters()[3], testClusters()[0]}, r.Versions[0].Clusters)
+}
+
+func TestPrintReport(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintReport(&buf, Check(policy, testClusters(), testNow)))
+	require.Contains(t, buf.String(), "v8.1.0   ok         2         \n")
+	require.Contains(t, buf.String(), "v7.5.0   violation  1         blocked by v7.5.0: known data corruption bug; out of support on 2025-08-30 (v7.5), in 90 days\n")
+	require.Contains(t, buf.String(), "p2          c3          bi            PAUSED          v6.5.12  violation\n")
+	require.NotContains(t, buf.String(), "pay-main")
+	require.Contains(t, buf.String(), "\n6 clusters on 5 versions: 2 violate the policy, 2 have warnings\n")
+
+	buf.Reset()
+	require.NoError(t, PrintReport(&buf, Check(&Policy{}, testClusters()[:1], testNow)))
+	require.Equal(t, "VERSION  STATUS  CLUSTERS  ISSUES\nv8.1.0   ok      1         \n\n1 clusters on 1 versions: 0 violate the policy, 0 have warnings\n", buf.String())
+}
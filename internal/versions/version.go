@@ -0,0 +1,155 @@
+package versions
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version of TiDB, e.g. v7.5.1 or v8.0.0-alpha.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string // e.g. "alpha" of v8.0.0-alpha, empty for releases
+}
+
+// ParseVersion parses a version like "v7.5.1". The "v" prefix is optional and the build metadata, e.g. "+build.1", is ignored.
+func ParseVersion(s string) (Version, error) {
+	numbers, pre, err := parse(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if len(numbers) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], PreRelease: pre}, nil
+}
+
+// parse returns the numbers and the pre-release of a full or partial version.
+func parse(s string) ([]int, string, error) {
+	value := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	value, _, _ = strings.Cut(value, "+")
+	value, pre, hasPre := strings.Cut(value, "-")
+	if hasPre && pre == "" {
+		return nil, "", fmt.Errorf("invalid version %q: empty pre-release", s)
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) > 3 {
+		return nil, "", fmt.Errorf("invalid version %q: too many numbers", s)
+	}
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid version %q: %q is not a number", s, part)
+		}
+		numbers = append(numbers, n)
+	}
+
+	return numbers, pre, nil
+}
+
+// String returns the version with the "v" prefix, e.g. "v7.5.1".
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older than, the same as or newer than o
+// in the precedence of semantic versioning, i.e. a pre-release is older than its release.
+func (v Version) Compare(o Version) int {
+	for _, d := range [][2]int{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if d[0] != d[1] {
+			return cmp.Compare(d[0], d[1])
+		}
+	}
+
+	switch {
+	case v.PreRelease == o.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case o.PreRelease == "":
+		return -1
+	}
+	return comparePreReleases(v.PreRelease, o.PreRelease)
+}
+
+// comparePreReleases compares the dot separated identifiers. Numeric ones are compared numerically
+// and are older than alphanumeric ones, and a shorter list of equal identifiers is older.
+func comparePreReleases(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return cmp.Compare(len(as), len(bs))
+}
+
+// Pattern matches versions by prefix, e.g. "v6.5" matches all the patch versions of v6.5 and "v7.5.0" only v7.5.0.
+type Pattern struct {
+	raw     string
+	numbers []int
+	pre     string
+}
+
+// ParsePattern parses a full or partial version, e.g. "v6", "v6.5" or "v6.5.3". Only full versions can have a pre-release.
+func ParsePattern(s string) (Pattern, error) {
+	numbers, pre, err := parse(s)
+	if err != nil {
+		return Pattern{}, err
+	}
+	if pre != "" && len(numbers) != 3 {
+		return Pattern{}, fmt.Errorf("invalid version %q: a pre-release requires major.minor.patch", s)
+	}
+
+	return Pattern{raw: s, numbers: numbers, pre: pre}, nil
+}
+
+// Matches reports whether the version starts with the numbers of the pattern. A full pattern matches its pre-release
+// only if it has the same one.
+func (p Pattern) Matches(v Version) bool {
+	actual := []int{v.Major, v.Minor, v.Patch}
+	for i, n := range p.numbers {
+		if n != actual[i] {
+			return false
+		}
+	}
+	if len(p.numbers) == 3 {
+		return p.pre == v.PreRelease
+	}
+
+	return true
+}
+
+// specificity returns the number of the version numbers of the pattern.
+func (p Pattern) specificity() int {
+	return len(p.numbers)
+}
+
+// String returns the pattern as written.
+func (p Pattern) String() string {
+	return p.raw
+}
@@ -0,0 +1,99 @@
+package versions
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Version
+		wantErr string
+	}{
+		{value: "v7.5.1", want: Version{Major: 7, Minor: 5, Patch: 1}},
+		{value: "8.1.0", want: Version{Major: 8, Minor: 1, Patch: 0}},
+		{value: "v8.0.0-alpha.1+build.5", want: Version{Major: 8, PreRelease: "alpha.1"}},
+		{value: "v7.5", wantErr: "expected major.minor.patch"},
+		{value: "v7.5.x", wantErr: `"x" is not a number`},
+		{value: "v7.5.1-", wantErr: "empty pre-release"},
+		{value: "v1.2.3.4", wantErr: "too many numbers"},
+		{value: "", wantErr: `"" is not a number`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseVersion(tt.value)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	// In ascending order
+	ordered := []string{
+		"v6.5.12",
+		"v7.1.0",
+		"v7.5.0-alpha",
+		"v7.5.0-alpha.1",
+		"v7.5.0-alpha.beta",
+		"v7.5.0-beta.2",
+		"v7.5.0-beta.11",
+		"v7.5.0-rc.1",
+		"v7.5.0",
+		"v7.5.1",
+		"v7.10.0",
+		"v8.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, err := ParseVersion(ordered[i])
+			require.NoError(t, err)
+			b, err := ParseVersion(ordered[j])
+			require.NoError(t, err)
+			require.Equal(t, cmp.Compare(i, j), a.Compare(b), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+}
+
+func TestPattern_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		version string
+		want    bool
+	}{
+		{"v7", "v7.5.1", true},
+		{"v7", "v8.0.0", false},
+		{"v6.5", "v6.5.12", true},
+		{"v6.5", "v6.50.0", false},
+		{"v7.5.0", "v7.5.0", true},
+		{"v7.5.0", "v7.5.1", false},
+		{"v7.5.0", "v7.5.0-rc.1", false},
+		{"v8.0.0-alpha", "v8.0.0-alpha", true},
+		{"v7.5", "v7.5.0-rc.1", true},
+	}
+
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.pattern)
+		require.NoError(t, err)
+		v, err := ParseVersion(tt.version)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, p.Matches(v), "%s matches %s", tt.pattern, tt.version)
+	}
+
+	_, err := ParsePattern("v8-alpha")
+	require.ErrorContains(t, err, "a pre-release requires major.minor.patch")
+}
+
+func TestVersion_String(t *testing.T) {
+	require.Equal(t, "v7.5.1", Version{Major: 7, Minor: 5, Patch: 1}.String())
+	require.Equal(t, "v8.0.0-alpha", Version{Major: 8, PreRelease: "alpha"}.String())
+}
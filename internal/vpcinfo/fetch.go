@@ -7,8 +7,9 @@ import (
 	"io"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/sgykfjsm/msk/internal/util"
 	"gopkg.in/yaml.v3"
 )
@@ -16,8 +17,11 @@ import (
 // This module provides information about the VPC (Virtual Private Cloud) in which the application is running.
 // As of now, following information will be provided using the AWS SDK for Go v2:
 // - VPC ID (expected to be passed from the caller)
-// - CIDR block
-// - Caller's AWS account ID
+// - CIDR block, and secondary IPv4 / IPv6 CIDR blocks associated with the VPC
+// - Caller's AWS account ID and the region
+// - Subnets with their availability zone IDs
+// - Route tables with their associations and routes
+// - VPC peering connections in which the VPC is the requester or the accepter
 
 // Output format is available in:
 // - JSON
@@ -25,15 +29,144 @@ import (
 // - Text as log message
 
 type VPCInfo struct {
-	VPCID     string `json:"vpc_id" yaml:"vpc_id"`                 // The ID of the VPC
-	CIDRBlock string `json:"cidr_block" yaml:"cidr_block"`         // The CIDR block of the VPC
-	AccountID string `json:"aws_account_id" yaml:"aws_account_id"` // The AWS account
+	VPCID               string                 `json:"vpc_id" yaml:"vpc_id"`                 // The ID of the VPC
+	CIDRBlock           string                 `json:"cidr_block" yaml:"cidr_block"`         // The CIDR block of the VPC
+	AccountID           string                 `json:"aws_account_id" yaml:"aws_account_id"` // The AWS account
+	Region              string                 `json:"region,omitempty" yaml:"region,omitempty"`
+	SecondaryCIDRBlocks []CIDRBlockAssociation `json:"secondary_cidr_blocks,omitempty" yaml:"secondary_cidr_blocks,omitempty"`
+	IPv6CIDRBlocks      []CIDRBlockAssociation `json:"ipv6_cidr_blocks,omitempty" yaml:"ipv6_cidr_blocks,omitempty"`
+	Subnets             []Subnet               `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+	RouteTables         []RouteTable           `json:"route_tables,omitempty" yaml:"route_tables,omitempty"`
+	PeeringConnections  []PeeringConnection    `json:"peering_connections,omitempty" yaml:"peering_connections,omitempty"`
+}
+
+// CIDRBlockAssociation is an IPv4 or IPv6 CIDR block associated with the VPC.
+type CIDRBlockAssociation struct {
+	CIDRBlock     string `json:"cidr_block" yaml:"cidr_block"`
+	AssociationID string `json:"association_id" yaml:"association_id"`
+	State         string `json:"state" yaml:"state"` // e.g. associated, disassociated
+}
+
+// Subnet is a subnet in the VPC. The AZ ID (e.g. apne1-az1) is consistent across accounts unlike the AZ name.
+type Subnet struct {
+	SubnetID           string `json:"subnet_id" yaml:"subnet_id"`
+	Name               string `json:"name,omitempty" yaml:"name,omitempty"`
+	CIDRBlock          string `json:"cidr_block" yaml:"cidr_block"`
+	AvailabilityZone   string `json:"availability_zone" yaml:"availability_zone"`
+	AvailabilityZoneID string `json:"availability_zone_id" yaml:"availability_zone_id"`
+}
+
+// RouteTable is a route table in the VPC with the subnets (or gateways) it is associated with.
+type RouteTable struct {
+	RouteTableID string                  `json:"route_table_id" yaml:"route_table_id"`
+	Name         string                  `json:"name,omitempty" yaml:"name,omitempty"`
+	Main         bool                    `json:"main" yaml:"main"`
+	Associations []RouteTableAssociation `json:"associations,omitempty" yaml:"associations,omitempty"`
+	Routes       []Route                 `json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+// RouteTableAssociation is an association between a route table and a subnet or a gateway.
+type RouteTableAssociation struct {
+	AssociationID string `json:"association_id" yaml:"association_id"`
+	SubnetID      string `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+	GatewayID     string `json:"gateway_id,omitempty" yaml:"gateway_id,omitempty"`
+	Main          bool   `json:"main" yaml:"main"`
+}
+
+// Route is a route in a route table.
+type Route struct {
+	Destination string `json:"destination" yaml:"destination"` // IPv4/IPv6 CIDR block or prefix list ID
+	Target      string `json:"target" yaml:"target"`           // e.g. local, igw-..., pcx-...
+	State       string `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// PeeringConnection is a VPC peering connection in which the VPC is the requester or the accepter.
+type PeeringConnection struct {
+	PeeringConnectionID string   `json:"peering_connection_id" yaml:"peering_connection_id"`
+	Status              string   `json:"status" yaml:"status"`
+	RequesterVPCID      string   `json:"requester_vpc_id" yaml:"requester_vpc_id"`
+	RequesterOwnerID    string   `json:"requester_owner_id" yaml:"requester_owner_id"`
+	RequesterRegion     string   `json:"requester_region,omitempty" yaml:"requester_region,omitempty"`
+	RequesterCIDRBlock  string   `json:"requester_cidr_block,omitempty" yaml:"requester_cidr_block,omitempty"`
+	RequesterCIDRBlocks []string `json:"requester_cidr_blocks,omitempty" yaml:"requester_cidr_blocks,omitempty"` // Including the secondary CIDR blocks
+	AccepterVPCID       string   `json:"accepter_vpc_id" yaml:"accepter_vpc_id"`
+	AccepterOwnerID     string   `json:"accepter_owner_id" yaml:"accepter_owner_id"`
+	AccepterRegion      string   `json:"accepter_region,omitempty" yaml:"accepter_region,omitempty"`
+	AccepterCIDRBlock   string   `json:"accepter_cidr_block,omitempty" yaml:"accepter_cidr_block,omitempty"`
+	AccepterCIDRBlocks  []string `json:"accepter_cidr_blocks,omitempty" yaml:"accepter_cidr_blocks,omitempty"` // Including the secondary CIDR blocks
 }
 
 func (v *VPCInfo) String() string {
 	return "VPC ID: " + v.VPCID + ", CIDR Block: " + v.CIDRBlock + ", AWS Account ID: " + v.AccountID
 }
 
+// WriteText writes the summary line followed by the details of the VPC in a human-readable form.
+func (v *VPCInfo) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, v.String())
+	if v.Region != "" {
+		fmt.Fprintf(&b, "Region: %s\n", v.Region)
+	}
+
+	if len(v.SecondaryCIDRBlocks) > 0 {
+		fmt.Fprintln(&b, "Secondary CIDR Blocks:")
+		for _, c := range v.SecondaryCIDRBlocks {
+			fmt.Fprintf(&b, "  %s (%s, %s)\n", c.CIDRBlock, c.AssociationID, c.State)
+		}
+	}
+
+	if len(v.IPv6CIDRBlocks) > 0 {
+		fmt.Fprintln(&b, "IPv6 CIDR Blocks:")
+		for _, c := range v.IPv6CIDRBlocks {
+			fmt.Fprintf(&b, "  %s (%s, %s)\n", c.CIDRBlock, c.AssociationID, c.State)
+		}
+	}
+
+	if len(v.Subnets) > 0 {
+		fmt.Fprintln(&b, "Subnets:")
+		for _, s := range v.Subnets {
+			fmt.Fprintf(&b, "  %s %q %s AZ: %s (%s)\n", s.SubnetID, s.Name, s.CIDRBlock, s.AvailabilityZone, s.AvailabilityZoneID)
+		}
+	}
+
+	if len(v.RouteTables) > 0 {
+		fmt.Fprintln(&b, "Route Tables:")
+		for _, rtb := range v.RouteTables {
+			main := ""
+			if rtb.Main {
+				main = " (main)"
+			}
+			fmt.Fprintf(&b, "  %s %q%s\n", rtb.RouteTableID, rtb.Name, main)
+			for _, a := range rtb.Associations {
+				switch {
+				case a.SubnetID != "":
+					fmt.Fprintf(&b, "    associated with %s\n", a.SubnetID)
+				case a.GatewayID != "":
+					fmt.Fprintf(&b, "    associated with %s\n", a.GatewayID)
+				}
+			}
+			for _, rt := range rtb.Routes {
+				fmt.Fprintf(&b, "    route %s -> %s (%s)\n", rt.Destination, rt.Target, rt.State)
+			}
+		}
+	}
+
+	if len(v.PeeringConnections) > 0 {
+		fmt.Fprintln(&b, "VPC Peering Connections:")
+		for _, p := range v.PeeringConnections {
+			fmt.Fprintf(&b, "  %s [%s] requester: %s/%s %s (%s), accepter: %s/%s %s (%s)\n", p.PeeringConnectionID, p.Status,
+				p.RequesterOwnerID, p.RequesterVPCID, p.RequesterCIDRBlock, p.RequesterRegion,
+				p.AccepterOwnerID, p.AccepterVPCID, p.AccepterCIDRBlock, p.AccepterRegion)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (v *VPCInfo) ToJSON() ([]byte, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -52,20 +185,24 @@ func (v *VPCInfo) ToYAML() ([]byte, error) {
 	return data, nil
 }
 
-func FetchVPCInfo(ctx context.Context, vpcID string) (*VPCInfo, error) {
+// EC2API is the subset of the EC2 API used to describe a VPC.
+type EC2API interface {
+	DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+	DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeVpcPeeringConnections(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error)
+}
+
+// FetchVPCInfo describes the VPC and its related resources with the given clients.
+// region is the region the EC2 client works in, and is recorded in the result as is.
+func FetchVPCInfo(ctx context.Context, ec2Client EC2API, stsClient util.STSAPI, region, vpcID string) (*VPCInfo, error) {
 	vpcInfo := &VPCInfo{
 		VPCID:     vpcID,
 		CIDRBlock: "",
 		AccountID: "", // This would be fetched from the AWS SDK
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load SDK config: %w", err)
-	}
-
 	// Fetch the VPC details using the EC2 client
-	ec2Client := ec2.NewFromConfig(cfg)
 	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeVpcs
 	vpcs, err := ec2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
 		VpcIds: []string{vpcID},
@@ -79,10 +216,93 @@ func FetchVPCInfo(ctx context.Context, vpcID string) (*VPCInfo, error) {
 		return nil, fmt.Errorf("no VPC found with ID: %s", vpcID)
 	}
 	targetVPC := vpcs.Vpcs[0]
-	vpcInfo.CIDRBlock = *targetVPC.CidrBlock // // We need the primary IPv4 CIDR block for the VPC.
+	vpcInfo.CIDRBlock = aws.ToString(targetVPC.CidrBlock) // We need the primary IPv4 CIDR block for the VPC.
+	vpcInfo.Region = region
+	for _, assoc := range targetVPC.CidrBlockAssociationSet {
+		cidr := aws.ToString(assoc.CidrBlock)
+		if cidr == vpcInfo.CIDRBlock {
+			continue // The primary CIDR block is also listed in the association set
+		}
+		state := ""
+		if assoc.CidrBlockState != nil {
+			state = string(assoc.CidrBlockState.State)
+		}
+		vpcInfo.SecondaryCIDRBlocks = append(vpcInfo.SecondaryCIDRBlocks, CIDRBlockAssociation{
+			CIDRBlock:     cidr,
+			AssociationID: aws.ToString(assoc.AssociationId),
+			State:         state,
+		})
+	}
+	for _, assoc := range targetVPC.Ipv6CidrBlockAssociationSet {
+		state := ""
+		if assoc.Ipv6CidrBlockState != nil {
+			state = string(assoc.Ipv6CidrBlockState.State)
+		}
+		vpcInfo.IPv6CIDRBlocks = append(vpcInfo.IPv6CIDRBlocks, CIDRBlockAssociation{
+			CIDRBlock:     aws.ToString(assoc.Ipv6CidrBlock),
+			AssociationID: aws.ToString(assoc.AssociationId),
+			State:         state,
+		})
+	}
+
+	vpcFilter := []ec2types.Filter{{Name: aws.String("vpc-id"), Values: []string{vpcID}}}
+
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeSubnets
+	subnets := ec2.NewDescribeSubnetsPaginator(ec2Client, &ec2.DescribeSubnetsInput{Filters: vpcFilter})
+	for subnets.HasMorePages() {
+		page, err := subnets.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to describe subnets of VPC %s: %w", vpcID, err)
+		}
+		for _, subnet := range page.Subnets {
+			vpcInfo.Subnets = append(vpcInfo.Subnets, Subnet{
+				SubnetID:           aws.ToString(subnet.SubnetId),
+				Name:               util.GetNameFromTags(subnet.Tags),
+				CIDRBlock:          aws.ToString(subnet.CidrBlock),
+				AvailabilityZone:   aws.ToString(subnet.AvailabilityZone),
+				AvailabilityZoneID: aws.ToString(subnet.AvailabilityZoneId),
+			})
+		}
+	}
+
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeRouteTables
+	routeTables := ec2.NewDescribeRouteTablesPaginator(ec2Client, &ec2.DescribeRouteTablesInput{Filters: vpcFilter})
+	for routeTables.HasMorePages() {
+		page, err := routeTables.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to describe route tables of VPC %s: %w", vpcID, err)
+		}
+		for _, rtb := range page.RouteTables {
+			vpcInfo.RouteTables = append(vpcInfo.RouteTables, newRouteTable(rtb))
+		}
+	}
+
+	// A VPC can be either side of a peering connection, and filters are ANDed, so query both sides.
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeVpcPeeringConnections
+	seen := make(map[string]bool)
+	for _, filterName := range []string{"requester-vpc-info.vpc-id", "accepter-vpc-info.vpc-id"} {
+		param := &ec2.DescribeVpcPeeringConnectionsInput{
+			Filters: []ec2types.Filter{{Name: aws.String(filterName), Values: []string{vpcID}}},
+		}
+		peerings := ec2.NewDescribeVpcPeeringConnectionsPaginator(ec2Client, param)
+		for peerings.HasMorePages() {
+			page, err := peerings.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to describe VPC peering connections of VPC %s: %w", vpcID, err)
+			}
+			for _, pcx := range page.VpcPeeringConnections {
+				id := aws.ToString(pcx.VpcPeeringConnectionId)
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				vpcInfo.PeeringConnections = append(vpcInfo.PeeringConnections, newPeeringConnection(pcx))
+			}
+		}
+	}
 
 	// Fetch the AWS account ID
-	accountID, err := util.GetCallerAccountID(ctx, cfg)
+	accountID, err := util.GetCallerAccountID(ctx, stsClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get AWS account ID: %w", err)
 	}
@@ -91,6 +311,80 @@ func FetchVPCInfo(ctx context.Context, vpcID string) (*VPCInfo, error) {
 	return vpcInfo, nil
 }
 
+func newRouteTable(rtb ec2types.RouteTable) RouteTable {
+	routeTable := RouteTable{
+		RouteTableID: aws.ToString(rtb.RouteTableId),
+		Name:         util.GetNameFromTags(rtb.Tags),
+	}
+
+	for _, assoc := range rtb.Associations {
+		if aws.ToBool(assoc.Main) {
+			routeTable.Main = true
+		}
+		routeTable.Associations = append(routeTable.Associations, RouteTableAssociation{
+			AssociationID: aws.ToString(assoc.RouteTableAssociationId),
+			SubnetID:      aws.ToString(assoc.SubnetId),
+			GatewayID:     aws.ToString(assoc.GatewayId),
+			Main:          aws.ToBool(assoc.Main),
+		})
+	}
+
+	for _, rt := range rtb.Routes {
+		routeTable.Routes = append(routeTable.Routes, Route{
+			Destination: firstNonEmpty(aws.ToString(rt.DestinationCidrBlock), aws.ToString(rt.DestinationIpv6CidrBlock), aws.ToString(rt.DestinationPrefixListId)),
+			Target: firstNonEmpty(aws.ToString(rt.VpcPeeringConnectionId), aws.ToString(rt.GatewayId), aws.ToString(rt.NatGatewayId),
+				aws.ToString(rt.TransitGatewayId), aws.ToString(rt.NetworkInterfaceId), aws.ToString(rt.InstanceId),
+				aws.ToString(rt.EgressOnlyInternetGatewayId), aws.ToString(rt.LocalGatewayId), aws.ToString(rt.CarrierGatewayId),
+				aws.ToString(rt.CoreNetworkArn)),
+			State: string(rt.State),
+		})
+	}
+
+	return routeTable
+}
+
+func newPeeringConnection(pcx ec2types.VpcPeeringConnection) PeeringConnection {
+	peering := PeeringConnection{PeeringConnectionID: aws.ToString(pcx.VpcPeeringConnectionId)}
+	if pcx.Status != nil {
+		peering.Status = string(pcx.Status.Code)
+	}
+	if r := pcx.RequesterVpcInfo; r != nil {
+		peering.RequesterVPCID = aws.ToString(r.VpcId)
+		peering.RequesterOwnerID = aws.ToString(r.OwnerId)
+		peering.RequesterRegion = aws.ToString(r.Region)
+		peering.RequesterCIDRBlock = aws.ToString(r.CidrBlock)
+		peering.RequesterCIDRBlocks = cidrBlocks(r.CidrBlockSet)
+	}
+	if a := pcx.AccepterVpcInfo; a != nil {
+		peering.AccepterVPCID = aws.ToString(a.VpcId)
+		peering.AccepterOwnerID = aws.ToString(a.OwnerId)
+		peering.AccepterRegion = aws.ToString(a.Region)
+		peering.AccepterCIDRBlock = aws.ToString(a.CidrBlock)
+		peering.AccepterCIDRBlocks = cidrBlocks(a.CidrBlockSet)
+	}
+
+	return peering
+}
+
+func cidrBlocks(set []ec2types.CidrBlock) []string {
+	var blocks []string
+	for _, b := range set {
+		if c := aws.ToString(b.CidrBlock); c != "" {
+			blocks = append(blocks, c)
+		}
+	}
+	return blocks
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 func (v *VPCInfo) PrintAs(format string, w io.Writer) error {
 	format = strings.ToLower(format)
 	if format != "json" && format != "yaml" && format != "text" {
@@ -123,7 +417,7 @@ func (v *VPCInfo) PrintAs(format string, w io.Writer) error {
 		}
 	default:
 		// Default to text output
-		if _, err := fmt.Fprintln(w, v.String()); err != nil {
+		if err := v.WriteText(w); err != nil {
 			return fmt.Errorf("error writing text output: %w", err)
 		}
 	}
@@ -1,6 +1,17 @@
 package vpcinfo
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"gopkg.in/yaml.v3"
+)
 
 func TestFetch_VPCInfo_ToString(t *testing.T) {
 	vpcInfo := &VPCInfo{
@@ -48,3 +59,151 @@ aws_account_id: "123456789012"
 		t.Errorf("Expected %q, got %q", expected, string(data))
 	}
 }
+
+func testDetailedVPCInfo() *VPCInfo {
+	return &VPCInfo{
+		VPCID:               "vpc-12345678",
+		CIDRBlock:           "10.0.0.0/16",
+		AccountID:           "123456789012",
+		Region:              "ap-northeast-1",
+		SecondaryCIDRBlocks: []CIDRBlockAssociation{{CIDRBlock: "10.1.0.0/16", AssociationID: "vpc-cidr-assoc-1", State: "associated"}},
+		IPv6CIDRBlocks:      []CIDRBlockAssociation{{CIDRBlock: "2600:1f18::/56", AssociationID: "vpc-cidr-assoc-2", State: "associated"}},
+		Subnets:             []Subnet{{SubnetID: "subnet-1", Name: "private-a", CIDRBlock: "10.0.1.0/24", AvailabilityZone: "ap-northeast-1a", AvailabilityZoneID: "apne1-az4"}},
+		RouteTables: []RouteTable{{
+			RouteTableID: "rtb-1",
+			Name:         "private",
+			Main:         true,
+			Associations: []RouteTableAssociation{{AssociationID: "rtbassoc-1", SubnetID: "subnet-1"}},
+			Routes:       []Route{{Destination: "10.0.0.0/16", Target: "local", State: "active"}},
+		}},
+		PeeringConnections: []PeeringConnection{{
+			PeeringConnectionID: "pcx-1", Status: "active",
+			RequesterVPCID: "vpc-tidb", RequesterOwnerID: "380838443567", RequesterRegion: "ap-northeast-1", RequesterCIDRBlock: "172.16.0.0/21",
+			AccepterVPCID: "vpc-12345678", AccepterOwnerID: "123456789012", AccepterRegion: "ap-northeast-1", AccepterCIDRBlock: "10.0.0.0/16",
+		}},
+	}
+}
+
+func TestFetch_VPCInfo_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testDetailedVPCInfo().PrintAs("text", &buf); err != nil {
+		t.Fatalf("Failed to print as text: %v", err)
+	}
+
+	expected := `VPC ID: vpc-12345678, CIDR Block: 10.0.0.0/16, AWS Account ID: 123456789012
+Region: ap-northeast-1
+Secondary CIDR Blocks:
+  10.1.0.0/16 (vpc-cidr-assoc-1, associated)
+IPv6 CIDR Blocks:
+  2600:1f18::/56 (vpc-cidr-assoc-2, associated)
+Subnets:
+  subnet-1 "private-a" 10.0.1.0/24 AZ: ap-northeast-1a (apne1-az4)
+Route Tables:
+  rtb-1 "private" (main)
+    associated with subnet-1
+    route 10.0.0.0/16 -> local (active)
+VPC Peering Connections:
+  pcx-1 [active] requester: 380838443567/vpc-tidb 172.16.0.0/21 (ap-northeast-1), accepter: 123456789012/vpc-12345678 10.0.0.0/16 (ap-northeast-1)
+`
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestFetch_VPCInfo_JSONRoundTrip(t *testing.T) {
+	vpcInfo := testDetailedVPCInfo()
+	data, err := vpcInfo.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to convert to JSON: %v", err)
+	}
+
+	var decoded VPCInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if !reflect.DeepEqual(vpcInfo, &decoded) {
+		t.Errorf("Expected %+v, got %+v", vpcInfo, decoded)
+	}
+}
+
+func TestFetch_VPCInfo_YAMLRoundTrip(t *testing.T) {
+	vpcInfo := testDetailedVPCInfo()
+	data, err := vpcInfo.ToYAML()
+	if err != nil {
+		t.Fatalf("Failed to convert to YAML: %v", err)
+	}
+
+	var decoded VPCInfo
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode YAML: %v", err)
+	}
+	if !reflect.DeepEqual(vpcInfo, &decoded) {
+		t.Errorf("Expected %+v, got %+v", vpcInfo, decoded)
+	}
+}
+
+func TestFetch_newRouteTable(t *testing.T) {
+	rtb := ec2types.RouteTable{
+		RouteTableId: aws.String("rtb-1"),
+		Tags:         []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("main")}},
+		Associations: []ec2types.RouteTableAssociation{{RouteTableAssociationId: aws.String("rtbassoc-1"), Main: aws.Bool(true)}},
+		Routes: []ec2types.Route{
+			{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), State: ec2types.RouteStateActive},
+			{DestinationPrefixListId: aws.String("pl-1"), VpcPeeringConnectionId: aws.String("pcx-1"), State: ec2types.RouteStateBlackhole},
+		},
+	}
+
+	expected := RouteTable{
+		RouteTableID: "rtb-1",
+		Name:         "main",
+		Main:         true,
+		Associations: []RouteTableAssociation{{AssociationID: "rtbassoc-1", Main: true}},
+		Routes: []Route{
+			{Destination: "10.0.0.0/16", Target: "local", State: "active"},
+			{Destination: "pl-1", Target: "pcx-1", State: "blackhole"},
+		},
+	}
+	if got := newRouteTable(rtb); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestFetchVPCInfo(t *testing.T) {
+	fake := awsfake.NewEC2()
+	fake.AddVPC(ec2types.Vpc{
+		VpcId:     aws.String("vpc-app"),
+		CidrBlock: aws.String("10.0.0.0/16"),
+		CidrBlockAssociationSet: []ec2types.VpcCidrBlockAssociation{
+			{CidrBlock: aws.String("10.0.0.0/16"), AssociationId: aws.String("vpc-cidr-assoc-1")},
+			{CidrBlock: aws.String("10.1.0.0/16"), AssociationId: aws.String("vpc-cidr-assoc-2")},
+		},
+	})
+	fake.AddVPC(ec2types.Vpc{VpcId: aws.String("vpc-other"), CidrBlock: aws.String("10.9.0.0/16")})
+	fake.AddSubnet(ec2types.Subnet{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-app"), CidrBlock: aws.String("10.0.1.0/24")})
+	fake.AddSubnet(ec2types.Subnet{SubnetId: aws.String("subnet-9"), VpcId: aws.String("vpc-other"), CidrBlock: aws.String("10.9.1.0/24")})
+	fake.AddRouteTable(ec2types.RouteTable{RouteTableId: aws.String("rtb-1"), VpcId: aws.String("vpc-app")})
+	fake.AddPeeringConnection(ec2types.VpcPeeringConnection{
+		VpcPeeringConnectionId: aws.String("pcx-1"),
+		RequesterVpcInfo:       &ec2types.VpcPeeringConnectionVpcInfo{VpcId: aws.String("vpc-tidb")},
+		AccepterVpcInfo:        &ec2types.VpcPeeringConnectionVpcInfo{VpcId: aws.String("vpc-app")},
+	})
+
+	info, err := FetchVPCInfo(context.Background(), fake, awsfake.NewSTS("111111111111"), "us-west-2", "vpc-app")
+	if err != nil {
+		t.Fatalf("FetchVPCInfo() error = %v", err)
+	}
+
+	if info.AccountID != "111111111111" || info.Region != "us-west-2" || info.CIDRBlock != "10.0.0.0/16" {
+		t.Errorf("unexpected VPC info: %+v", info)
+	}
+	if len(info.SecondaryCIDRBlocks) != 1 || info.SecondaryCIDRBlocks[0].CIDRBlock != "10.1.0.0/16" {
+		t.Errorf("SecondaryCIDRBlocks = %+v, want only 10.1.0.0/16", info.SecondaryCIDRBlocks)
+	}
+	if len(info.Subnets) != 1 || len(info.RouteTables) != 1 || len(info.PeeringConnections) != 1 {
+		t.Errorf("got %d subnets, %d route tables, %d peerings, want 1 each", len(info.Subnets), len(info.RouteTables), len(info.PeeringConnections))
+	}
+
+	if _, err := FetchVPCInfo(context.Background(), fake, awsfake.NewSTS("111111111111"), "us-west-2", "vpc-missing"); err == nil {
+		t.Error("FetchVPCInfo() for a missing VPC should fail")
+	}
+}
@@ -6,21 +6,21 @@ import (
 	"io"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
+// EC2API is the subset of the EC2 API used to list and accept VPC peering connections.
+type EC2API interface {
+	DescribeVpcPeeringConnections(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error)
+	AcceptVpcPeeringConnection(ctx context.Context, params *ec2.AcceptVpcPeeringConnectionInput, optFns ...func(*ec2.Options)) (*ec2.AcceptVpcPeeringConnectionOutput, error)
+}
+
 // AcceptVPCPeeringConnection accepts a VPC peering connection request.
-// It takes a context, the ID of the peering connection, an io.Writer for output,
+// It takes a context, the EC2 client, the ID of the peering connection, an io.Writer for output,
 // and a boolean to indicate if the operation is a dry run (check only).
 // Returns an error if the operation fails.
-func AcceptVPCPeeringConnection(ctx context.Context, peeringID string, w io.Writer, checkOnly bool) error {
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to load AWS configuration: %w", err)
-	}
-	ec2Client := ec2.NewFromConfig(cfg)
-
+func AcceptVPCPeeringConnection(ctx context.Context, ec2Client EC2API, peeringID string, w io.Writer, checkOnly bool) error {
 	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeVpcPeeringConnections
 	param := &ec2.DescribeVpcPeeringConnectionsInput{VpcPeeringConnectionIds: []string{peeringID}}
 	output, err := ec2Client.DescribeVpcPeeringConnections(ctx, param)
@@ -60,3 +60,29 @@ func AcceptVPCPeeringConnection(ctx context.Context, peeringID string, w io.Writ
 
 	return nil
 }
+
+// AcceptPendingFrom accepts every pending connection requested by the given account that can be accepted in the account
+// and region of the EC2 client, and returns the number of the connections accepted (or checked in a dry run).
+// The candidates are looked up on their own, so that they do not depend on the filters of a listing.
+func AcceptPendingFrom(ctx context.Context, ec2Client EC2API, account, region, requesterAccountID string, dryRun bool, w io.Writer) (int, error) {
+	pending, err := ListVPCPeeringConnections(ctx, ec2Client, account, region, ListFilter{
+		States:             []string{string(ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance)},
+		RequesterAccountID: requesterAccountID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	accepted := 0
+	for _, p := range pending {
+		if !p.AcceptableFrom(requesterAccountID) {
+			continue
+		}
+		if err := AcceptVPCPeeringConnection(ctx, ec2Client, p.ID, w, dryRun); err != nil {
+			return accepted, err
+		}
+		accepted++
+	}
+
+	return accepted, nil
+}
@@ -0,0 +1,96 @@
+package vpcpeering
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"github.com/stretchr/testify/require"
+)
+
+func testPeering(id, requesterAccount string, code ec2types.VpcPeeringConnectionStateReasonCode) ec2types.VpcPeeringConnection {
+	return ec2types.VpcPeeringConnection{
+		VpcPeeringConnectionId: aws.String(id),
+		Status:                 &ec2types.VpcPeeringConnectionStateReason{Code: code},
+		RequesterVpcInfo:       &ec2types.VpcPeeringConnectionVpcInfo{VpcId: aws.String("vpc-tidb"), OwnerId: aws.String(requesterAccount), Region: aws.String("us-west-2")},
+		AccepterVpcInfo:        &ec2types.VpcPeeringConnectionVpcInfo{VpcId: aws.String("vpc-app"), OwnerId: aws.String("111111111111"), Region: aws.String("us-west-2")},
+	}
+}
+
+func TestAcceptVPCPeeringConnection(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddPeeringConnection(testPeering("pcx-pending", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance))
+	fake.AddPeeringConnection(testPeering("pcx-active", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodeActive))
+
+	var buf bytes.Buffer
+	require.NoError(t, AcceptVPCPeeringConnection(ctx, fake, "pcx-pending", &buf, true))
+	require.Contains(t, buf.String(), "[CHECK]")
+	require.Equal(t, ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance, fake.PeeringConnection("pcx-pending").Status.Code)
+
+	buf.Reset()
+	require.NoError(t, AcceptVPCPeeringConnection(ctx, fake, "pcx-pending", &buf, false))
+	require.Contains(t, buf.String(), "[SUCCESS]")
+	require.Equal(t, ec2types.VpcPeeringConnectionStateReasonCodeActive, fake.PeeringConnection("pcx-pending").Status.Code)
+
+	buf.Reset()
+	require.NoError(t, AcceptVPCPeeringConnection(ctx, fake, "pcx-active", &buf, false))
+	require.Contains(t, buf.String(), "[SKIP]")
+
+	require.Error(t, AcceptVPCPeeringConnection(ctx, fake, "pcx-unknown", &buf, false))
+}
+
+func TestAcceptPendingFrom(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddPeeringConnection(testPeering("pcx-1", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance))
+	fake.AddPeeringConnection(testPeering("pcx-2", "333333333333", ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance))
+	fake.AddPeeringConnection(testPeering("pcx-3", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodeActive))
+
+	var buf bytes.Buffer
+	n, err := AcceptPendingFrom(ctx, fake, "111111111111", "us-west-2", "222222222222", true, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Contains(t, buf.String(), "[CHECK] VPC peering connection pcx-1")
+	require.Equal(t, ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance, fake.PeeringConnection("pcx-1").Status.Code)
+
+	buf.Reset()
+	n, err = AcceptPendingFrom(ctx, fake, "111111111111", "us-west-2", "222222222222", false, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Contains(t, buf.String(), "[SUCCESS] VPC peering connection pcx-1 accepted.")
+	require.Equal(t, ec2types.VpcPeeringConnectionStateReasonCodeActive, fake.PeeringConnection("pcx-1").Status.Code)
+	require.Equal(t, ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance, fake.PeeringConnection("pcx-2").Status.Code)
+
+	// Nothing is left to accept, and a listing made afterwards shows the new status
+	n, err = AcceptPendingFrom(ctx, fake, "111111111111", "us-west-2", "222222222222", false, &buf)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	conns, err := ListVPCPeeringConnections(ctx, fake, "111111111111", "us-west-2", ListFilter{States: []string{"active"}})
+	require.NoError(t, err)
+	require.Len(t, conns, 2)
+}
+
+func TestListVPCPeeringConnections(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddPeeringConnection(testPeering("pcx-1", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance))
+	fake.AddPeeringConnection(testPeering("pcx-2", "333333333333", ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance))
+	fake.AddPeeringConnection(testPeering("pcx-3", "222222222222", ec2types.VpcPeeringConnectionStateReasonCodeActive))
+
+	conns, err := ListVPCPeeringConnections(ctx, fake, "111111111111", "us-west-2", ListFilter{
+		States:             []string{"pending-acceptance"},
+		RequesterAccountID: "222222222222",
+	})
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+	require.Equal(t, "pcx-1", conns[0].ID)
+	require.True(t, conns[0].AcceptableFrom("222222222222"))
+
+	conns, err = ListVPCPeeringConnections(ctx, fake, "111111111111", "us-west-2", ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, conns, 3)
+}
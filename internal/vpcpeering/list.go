@@ -0,0 +1,121 @@
+package vpcpeering
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// PeeringConnection is a VPC peering connection found while scanning a region of an account.
+type PeeringConnection struct {
+	ID                 string     `json:"id"`
+	Status             string     `json:"status"`
+	Account            string     `json:"account"` // The account in which the connection was found
+	Region             string     `json:"region"`  // The region in which the connection was found
+	RequesterOwnerID   string     `json:"requester_owner_id"`
+	RequesterVPCID     string     `json:"requester_vpc_id"`
+	RequesterCIDRBlock string     `json:"requester_cidr_block,omitempty"`
+	RequesterRegion    string     `json:"requester_region,omitempty"`
+	AccepterOwnerID    string     `json:"accepter_owner_id"`
+	AccepterVPCID      string     `json:"accepter_vpc_id"`
+	AccepterCIDRBlock  string     `json:"accepter_cidr_block,omitempty"`
+	AccepterRegion     string     `json:"accepter_region,omitempty"`
+	ExpirationTime     *time.Time `json:"expiration_time,omitempty"` // Set while the request is pending
+}
+
+// ValidStates are the states of a VPC peering connection that can be used as a filter.
+var ValidStates = func() map[string]bool {
+	states := make(map[string]bool)
+	for _, code := range ec2types.VpcPeeringConnectionStateReasonCode("").Values() {
+		states[string(code)] = true
+	}
+	return states
+}()
+
+// ListFilter narrows down the peering connections returned by ListVPCPeeringConnections.
+// Empty fields match everything.
+type ListFilter struct {
+	States             []string // e.g. pending-acceptance, active, failed, expired
+	RequesterAccountID string
+}
+
+// ListVPCPeeringConnections returns the VPC peering connections visible with the given EC2 client,
+// i.e. in a single account and region. account and region are recorded in the results as is.
+func ListVPCPeeringConnections(ctx context.Context, ec2Client EC2API, account, region string, filter ListFilter) ([]PeeringConnection, error) {
+	var filters []ec2types.Filter
+	if len(filter.States) > 0 {
+		filters = append(filters, ec2types.Filter{Name: aws.String("status-code"), Values: filter.States})
+	}
+	if filter.RequesterAccountID != "" {
+		filters = append(filters, ec2types.Filter{Name: aws.String("requester-vpc-info.owner-id"), Values: []string{filter.RequesterAccountID}})
+	}
+
+	var connections []PeeringConnection
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeVpcPeeringConnections
+	paginator := ec2.NewDescribeVpcPeeringConnectionsPaginator(ec2Client, &ec2.DescribeVpcPeeringConnectionsInput{Filters: filters})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe VPC peering connections in %s (account %s): %w", region, account, err)
+		}
+
+		for _, pcx := range page.VpcPeeringConnections {
+			connections = append(connections, newPeeringConnection(pcx, account, region))
+		}
+	}
+
+	return connections, nil
+}
+
+func newPeeringConnection(pcx ec2types.VpcPeeringConnection, account, region string) PeeringConnection {
+	conn := PeeringConnection{
+		ID:             aws.ToString(pcx.VpcPeeringConnectionId),
+		Account:        account,
+		Region:         region,
+		ExpirationTime: pcx.ExpirationTime,
+	}
+	if pcx.Status != nil {
+		conn.Status = string(pcx.Status.Code)
+	}
+	if r := pcx.RequesterVpcInfo; r != nil {
+		conn.RequesterOwnerID = aws.ToString(r.OwnerId)
+		conn.RequesterVPCID = aws.ToString(r.VpcId)
+		conn.RequesterCIDRBlock = aws.ToString(r.CidrBlock)
+		conn.RequesterRegion = aws.ToString(r.Region)
+	}
+	if a := pcx.AccepterVpcInfo; a != nil {
+		conn.AccepterOwnerID = aws.ToString(a.OwnerId)
+		conn.AccepterVPCID = aws.ToString(a.VpcId)
+		conn.AccepterCIDRBlock = aws.ToString(a.CidrBlock)
+		conn.AccepterRegion = aws.ToString(a.Region)
+	}
+
+	return conn
+}
+
+// AcceptableFrom reports whether the connection is a pending request from the given requester account
+// that can be accepted in the account it was found in.
+func (p PeeringConnection) AcceptableFrom(requesterAccountID string) bool {
+	return p.Status == string(ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance) &&
+		p.RequesterOwnerID == requesterAccountID &&
+		p.AccepterOwnerID == p.Account
+}
+
+// PrintPeeringConnections writes the connections as a table.
+func PrintPeeringConnections(w io.Writer, connections []PeeringConnection) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tACCOUNT\tREGION\tREQUESTER\tREQUESTER CIDR\tACCEPTER\tACCEPTER CIDR")
+	for _, p := range connections {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s/%s\t%s\t%s/%s\t%s\n", p.ID, p.Status, p.Account, p.Region,
+			p.RequesterOwnerID, p.RequesterVPCID, p.RequesterCIDRBlock,
+			p.AccepterOwnerID, p.AccepterVPCID, p.AccepterCIDRBlock)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+package vpcpeering
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPeeringConnection(t *testing.T) {
+	pcx := ec2types.VpcPeeringConnection{
+		VpcPeeringConnectionId: aws.String("pcx-1"),
+		Status:                 &ec2types.VpcPeeringConnectionStateReason{Code: ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance},
+		RequesterVpcInfo:       &ec2types.VpcPeeringConnectionVpcInfo{OwnerId: aws.String("380838443567"), VpcId: aws.String("vpc-tidb"), CidrBlock: aws.String("172.16.0.0/21"), Region: aws.String("us-west-2")},
+		AccepterVpcInfo:        &ec2types.VpcPeeringConnectionVpcInfo{OwnerId: aws.String("123456789012"), VpcId: aws.String("vpc-app"), Region: aws.String("us-west-2")},
+	}
+
+	got := newPeeringConnection(pcx, "123456789012", "us-west-2")
+	require.Equal(t, PeeringConnection{
+		ID: "pcx-1", Status: "pending-acceptance", Account: "123456789012", Region: "us-west-2",
+		RequesterOwnerID: "380838443567", RequesterVPCID: "vpc-tidb", RequesterCIDRBlock: "172.16.0.0/21", RequesterRegion: "us-west-2",
+		AccepterOwnerID: "123456789012", AccepterVPCID: "vpc-app", AccepterRegion: "us-west-2",
+	}, got)
+}
+
+func TestPeeringConnection_AcceptableFrom(t *testing.T) {
+	pending := PeeringConnection{Status: "pending-acceptance", Account: "111", RequesterOwnerID: "999", AccepterOwnerID: "111"}
+
+	require.True(t, pending.AcceptableFrom("999"))
+	require.False(t, pending.AcceptableFrom("888"), "requested by another account")
+
+	active := pending
+	active.Status = "active"
+	require.False(t, active.AcceptableFrom("999"), "already active")
+
+	requesterSide := pending
+	requesterSide.Account = "999"
+	require.False(t, requesterSide.AcceptableFrom("999"), "found in the requester account")
+}
+
+func TestValidStates(t *testing.T) {
+	for _, state := range []string{"pending-acceptance", "active", "failed", "expired"} {
+		require.True(t, ValidStates[state], state)
+	}
+	require.False(t, ValidStates["pending"])
+}
+
+func TestPrintPeeringConnections(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintPeeringConnections(&buf, []PeeringConnection{
+		{ID: "pcx-1", Status: "active", Account: "111", Region: "us-west-2", RequesterOwnerID: "999", RequesterVPCID: "vpc-a", AccepterOwnerID: "111", AccepterVPCID: "vpc-b"},
+	}))
+	require.Contains(t, buf.String(), "ID     STATUS  ACCOUNT")
+	require.Contains(t, buf.String(), "pcx-1  active  111")
+}
@@ -0,0 +1,204 @@
+package vpcrtb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// A journal is a snapshot of the route tables of a VPC taken right before msk changes any of them.
+// It is kept in a blob store (local directory or S3) so that `msk routes restore` can put the routes back.
+
+const journalKeyPrefix = "route-journals/"
+
+// Journal holds the full state of the route tables of a VPC at a point in time.
+type Journal struct {
+	ID          string               `json:"id"`
+	VPCID       string               `json:"vpc_id"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Reason      string               `json:"reason,omitempty"` // What msk was about to do when the snapshot was taken
+	RouteTables []RouteTableSnapshot `json:"route_tables"`
+}
+
+// RouteTableSnapshot holds the routes of a single route table.
+type RouteTableSnapshot struct {
+	RouteTableID string  `json:"route_table_id"`
+	Name         string  `json:"name,omitempty"`
+	Routes       []Route `json:"routes"`
+}
+
+// Route is a serializable copy of ec2types.Route.
+// Only one of the destination fields and (usually) one of the target fields are set.
+type Route struct {
+	DestinationCidrBlock        string `json:"destination_cidr_block,omitempty"`
+	DestinationIpv6CidrBlock    string `json:"destination_ipv6_cidr_block,omitempty"`
+	DestinationPrefixListID     string `json:"destination_prefix_list_id,omitempty"`
+	GatewayID                   string `json:"gateway_id,omitempty"`
+	NatGatewayID                string `json:"nat_gateway_id,omitempty"`
+	TransitGatewayID            string `json:"transit_gateway_id,omitempty"`
+	VpcPeeringConnectionID      string `json:"vpc_peering_connection_id,omitempty"`
+	NetworkInterfaceID          string `json:"network_interface_id,omitempty"`
+	InstanceID                  string `json:"instance_id,omitempty"`
+	EgressOnlyInternetGatewayID string `json:"egress_only_internet_gateway_id,omitempty"`
+	LocalGatewayID              string `json:"local_gateway_id,omitempty"`
+	CarrierGatewayID            string `json:"carrier_gateway_id,omitempty"`
+	CoreNetworkArn              string `json:"core_network_arn,omitempty"`
+	Origin                      string `json:"origin,omitempty"`
+	State                       string `json:"state,omitempty"`
+}
+
+// Destination returns the destination of the route, whichever kind it is.
+func (r Route) Destination() string {
+	switch {
+	case r.DestinationCidrBlock != "":
+		return r.DestinationCidrBlock
+	case r.DestinationIpv6CidrBlock != "":
+		return r.DestinationIpv6CidrBlock
+	default:
+		return r.DestinationPrefixListID
+	}
+}
+
+// Target returns the target of the route, whichever kind it is.
+func (r Route) Target() string {
+	for _, t := range []string{
+		r.VpcPeeringConnectionID, r.GatewayID, r.NatGatewayID, r.TransitGatewayID, r.NetworkInterfaceID, r.InstanceID,
+		r.EgressOnlyInternetGatewayID, r.LocalGatewayID, r.CarrierGatewayID, r.CoreNetworkArn,
+	} {
+		if t != "" {
+			return t
+		}
+	}
+	return "unset"
+}
+
+// managed reports whether the route can be changed by CreateRoute/ReplaceRoute/DeleteRoute.
+// The local route and the routes propagated from a virtual private gateway are owned by AWS.
+func (r Route) managed() bool {
+	return r.Origin != string(ec2types.RouteOriginCreateRouteTable) &&
+		r.Origin != string(ec2types.RouteOriginEnableVgwRoutePropagation)
+}
+
+func newRoute(rt ec2types.Route) Route {
+	return Route{
+		DestinationCidrBlock:        aws.ToString(rt.DestinationCidrBlock),
+		DestinationIpv6CidrBlock:    aws.ToString(rt.DestinationIpv6CidrBlock),
+		DestinationPrefixListID:     aws.ToString(rt.DestinationPrefixListId),
+		GatewayID:                   aws.ToString(rt.GatewayId),
+		NatGatewayID:                aws.ToString(rt.NatGatewayId),
+		TransitGatewayID:            aws.ToString(rt.TransitGatewayId),
+		VpcPeeringConnectionID:      aws.ToString(rt.VpcPeeringConnectionId),
+		NetworkInterfaceID:          aws.ToString(rt.NetworkInterfaceId),
+		InstanceID:                  aws.ToString(rt.InstanceId),
+		EgressOnlyInternetGatewayID: aws.ToString(rt.EgressOnlyInternetGatewayId),
+		LocalGatewayID:              aws.ToString(rt.LocalGatewayId),
+		CarrierGatewayID:            aws.ToString(rt.CarrierGatewayId),
+		CoreNetworkArn:              aws.ToString(rt.CoreNetworkArn),
+		Origin:                      string(rt.Origin),
+		State:                       string(rt.State),
+	}
+}
+
+func newRouteTableSnapshot(rtb ec2types.RouteTable) RouteTableSnapshot {
+	snapshot := RouteTableSnapshot{
+		RouteTableID: aws.ToString(rtb.RouteTableId),
+		Name:         util.GetNameFromTags(rtb.Tags),
+		Routes:       make([]Route, 0, len(rtb.Routes)),
+	}
+	for _, rt := range rtb.Routes {
+		snapshot.Routes = append(snapshot.Routes, newRoute(rt))
+	}
+
+	return snapshot
+}
+
+// journalIDLayout has a fixed width nanosecond fraction, so that the IDs of the journals taken within the same second
+// stay distinct and keep sorting in the order they were taken.
+const journalIDLayout = "20060102T150405.000000000Z"
+
+// NewJournal builds a Journal from the route tables described from EC2.
+func NewJournal(vpcID, reason string, routeTables []ec2types.RouteTable, now time.Time) *Journal {
+	j := &Journal{
+		ID:          fmt.Sprintf("%s-%s", now.UTC().Format(journalIDLayout), vpcID),
+		VPCID:       vpcID,
+		CreatedAt:   now.UTC(),
+		Reason:      reason,
+		RouteTables: make([]RouteTableSnapshot, 0, len(routeTables)),
+	}
+	for _, rtb := range routeTables {
+		j.RouteTables = append(j.RouteTables, newRouteTableSnapshot(rtb))
+	}
+
+	return j
+}
+
+// JournalStore saves and loads journals using a blob store.
+type JournalStore struct {
+	store blob.Store
+}
+
+// NewJournalStore returns a new JournalStore backed by the given blob store.
+func NewJournalStore(store blob.Store) *JournalStore {
+	return &JournalStore{store: store}
+}
+
+// Save writes the journal as JSON. An existing journal is never overwritten, since it may be the only record
+// that can undo an earlier change.
+func (s *JournalStore) Save(ctx context.Context, j *Journal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal %s: %w", j.ID, err)
+	}
+
+	key := journalKeyPrefix + j.ID + ".json"
+	if _, err := s.store.Get(ctx, key); err == nil {
+		return fmt.Errorf("failed to save journal %s: journal already exists", j.ID)
+	} else if !errors.Is(err, blob.ErrNotFound) {
+		return fmt.Errorf("failed to check journal %s: %w", j.ID, err)
+	}
+
+	if err := s.store.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("failed to save journal %s: %w", j.ID, err)
+	}
+
+	return nil
+}
+
+// Load reads the journal with the given ID.
+func (s *JournalStore) Load(ctx context.Context, id string) (*Journal, error) {
+	data, err := s.store.Get(ctx, journalKeyPrefix+id+".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal %s: %w", id, err)
+	}
+
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to decode journal %s: %w", id, err)
+	}
+
+	return &j, nil
+}
+
+// List returns the IDs of all saved journals, oldest first.
+func (s *JournalStore) List(ctx context.Context) ([]string, error) {
+	keys, err := s.store.List(ctx, journalKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journals: %w", err)
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, strings.TrimSuffix(path.Base(key), ".json"))
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,302 @@
+package vpcrtb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// A plan is the exact list of route operations update-routes would perform, together with the route table state
+// it was computed from. It can be saved to a file, reviewed, and applied later by ApplyPlan, which refuses to run
+// if the route tables have changed in the meantime.
+
+// PlanVersion is the version of the plan file format.
+const PlanVersion = 1
+
+// RouteOperationAction is the kind of operation planned for a route table.
+type RouteOperationAction string
+
+const (
+	RouteOperationCreate  RouteOperationAction = "create"
+	RouteOperationReplace RouteOperationAction = "replace"
+	RouteOperationSkip    RouteOperationAction = "skip"
+)
+
+// RouteOperation is a single planned operation on a route table.
+type RouteOperation struct {
+	Action               RouteOperationAction `json:"action"`
+	RouteTableID         string               `json:"route_table_id"`
+	RouteTableName       string               `json:"route_table_name,omitempty"`
+	DestinationCidrBlock string               `json:"destination_cidr_block"`
+	CurrentPeerID        string               `json:"current_peer_id,omitempty"` // "unset" if the route exists but has no peering target
+	PeerID               string               `json:"peer_id"`
+}
+
+// Plan holds the planned route operations for a VPC and the observed route tables.
+type Plan struct {
+	Version    int                  `json:"version"`
+	VPCID      string               `json:"vpc_id"`
+	CIDR       string               `json:"cidr"`
+	PeerID     string               `json:"peer_id"`
+	CreatedAt  time.Time            `json:"created_at"`
+	Operations []RouteOperation     `json:"operations"`
+	Observed   []RouteTableSnapshot `json:"observed_route_tables"`
+}
+
+// BuildPlan computes the route operations needed to route the CIDR to the peering connection
+// in each of the given route tables.
+// - If the route table already has a route for the CIDR
+//   - If the peer ID is same, skip it
+//   - If the peer ID is different, replace it
+//
+// - If the route table does not have a route for the CIDR, create a new route with the peer ID
+func BuildPlan(vpcID, cidr, peerID string, routeTables []ec2types.RouteTable, now time.Time) *Plan {
+	plan := &Plan{
+		Version:    PlanVersion,
+		VPCID:      vpcID,
+		CIDR:       cidr,
+		PeerID:     peerID,
+		CreatedAt:  now.UTC(),
+		Operations: make([]RouteOperation, 0, len(routeTables)),
+		Observed:   make([]RouteTableSnapshot, 0, len(routeTables)),
+	}
+
+	for _, rtb := range routeTables {
+		op := RouteOperation{
+			Action:               RouteOperationCreate,
+			RouteTableID:         aws.ToString(rtb.RouteTableId),
+			RouteTableName:       util.GetNameFromTags(rtb.Tags),
+			DestinationCidrBlock: cidr,
+			PeerID:               peerID,
+		}
+		for _, rt := range rtb.Routes {
+			if aws.ToString(rt.DestinationCidrBlock) == cidr {
+				if rt.VpcPeeringConnectionId == nil {
+					op.Action = RouteOperationReplace
+					op.CurrentPeerID = "unset"
+				} else if aws.ToString(rt.VpcPeeringConnectionId) != peerID {
+					op.Action = RouteOperationReplace
+					op.CurrentPeerID = aws.ToString(rt.VpcPeeringConnectionId)
+				} else {
+					op.Action = RouteOperationSkip
+					op.CurrentPeerID = peerID
+				}
+				break
+			}
+		}
+
+		plan.Operations = append(plan.Operations, op)
+		plan.Observed = append(plan.Observed, newRouteTableSnapshot(rtb))
+	}
+
+	return plan
+}
+
+// HasChanges reports whether the plan contains any create or replace operation.
+func (p *Plan) HasChanges() bool {
+	for _, op := range p.Operations {
+		if op.Action != RouteOperationSkip {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the planned operations in the same wording as `update-routes --dry-run`.
+func (p *Plan) Print(w io.Writer) {
+	for _, op := range p.Operations {
+		switch op.Action {
+		case RouteOperationCreate:
+			fmt.Fprintf(w, "[DRY RUN] Would add route for CIDR %s to route table %q (ID: %s) with peer ID %s\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.PeerID)
+		case RouteOperationReplace:
+			fmt.Fprintf(w, "[DRY RUN] Would update route for CIDR %s in route table %q (ID: %s) from peer ID %s to peer ID %s\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.CurrentPeerID, op.PeerID)
+		default:
+			fmt.Fprintf(w, "Route for CIDR %s already exists in route table %q (ID: %s) with peer ID %s, skipping\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.PeerID)
+		}
+	}
+}
+
+// WritePlanFile saves the plan as JSON to the given path.
+func WritePlanFile(path string, p *Plan) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plan file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadPlanFile loads a plan saved by WritePlanFile.
+func ReadPlanFile(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode plan file %s: %w", path, err)
+	}
+
+	if p.Version != PlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d in %s, expected %d", p.Version, path, PlanVersion)
+	}
+
+	return &p, nil
+}
+
+// VerifyPlan checks that the route tables observed when the plan was made are identical to the given current ones.
+// Only destinations, targets and origins of routes are compared; the route state (active/blackhole) is ignored.
+func VerifyPlan(p *Plan, current []RouteTableSnapshot) error {
+	currentByID := make(map[string]RouteTableSnapshot, len(current))
+	for _, rtb := range current {
+		currentByID[rtb.RouteTableID] = rtb
+	}
+
+	if len(current) != len(p.Observed) {
+		return fmt.Errorf("VPC %s has %d route tables, but the plan was made with %d", p.VPCID, len(current), len(p.Observed))
+	}
+
+	for _, observed := range p.Observed {
+		rtb, ok := currentByID[observed.RouteTableID]
+		if !ok {
+			return fmt.Errorf("route table %q (ID: %s) no longer exists in VPC %s", observed.Name, observed.RouteTableID, p.VPCID)
+		}
+
+		want, got := routeKeys(observed.Routes), routeKeys(rtb.Routes)
+		if len(want) != len(got) {
+			return fmt.Errorf("route table %q (ID: %s) has %d routes, but the plan was made with %d", observed.Name, observed.RouteTableID, len(got), len(want))
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				return fmt.Errorf("route table %q (ID: %s) has changed since the plan was made: observed %q, now %q", observed.Name, observed.RouteTableID, want[i], got[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func routeKeys(routes []Route) []string {
+	keys := make([]string, 0, len(routes))
+	for _, rt := range routes {
+		keys = append(keys, fmt.Sprintf("%s -> %s (%s)", rt.Destination(), rt.Target(), rt.Origin))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// PlanRoutes describes the route tables of the VPC and builds the plan to route the CIDR to the peering connection.
+func PlanRoutes(ctx context.Context, ec2Client EC2API, vpcID, cidr, peerID string) (*Plan, error) {
+	routeTables, err := describeVPCRouteTables(ctx, ec2Client, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildPlan(vpcID, cidr, peerID, routeTables, time.Now()), nil
+}
+
+// ApplyPlan performs the operations of the plan.
+// It refuses to run if the route tables of the VPC no longer match the state observed when the plan was made.
+// The route tables are journaled to the given store before the first change.
+func ApplyPlan(ctx context.Context, ec2Client EC2API, p *Plan, journals *JournalStore, w io.Writer) error {
+	routeTables, err := describeVPCRouteTables(ctx, ec2Client, p.VPCID)
+	if err != nil {
+		return err
+	}
+
+	current := make([]RouteTableSnapshot, 0, len(routeTables))
+	for _, rtb := range routeTables {
+		current = append(current, newRouteTableSnapshot(rtb))
+	}
+	if err := VerifyPlan(p, current); err != nil {
+		return fmt.Errorf("refusing to apply the plan made at %s: %w", p.CreatedAt.Format(time.RFC3339), err)
+	}
+
+	return executePlan(ctx, ec2Client, p, routeTables, journals, w)
+}
+
+func describeVPCRouteTables(ctx context.Context, ec2Client EC2API, vpcID string) ([]ec2types.RouteTable, error) {
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeRouteTables
+	param := &ec2.DescribeRouteTablesInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("vpc-id"),
+				Values: []string{vpcID}},
+		},
+	}
+	rtOutput, err := ec2Client.DescribeRouteTables(ctx, param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe route tables for VPC %s: %w", vpcID, err)
+	}
+
+	return rtOutput.RouteTables, nil
+}
+
+// executePlan performs the create and replace operations of the plan.
+// The given route tables are journaled right before the first change.
+func executePlan(ctx context.Context, ec2Client EC2API, p *Plan, routeTables []ec2types.RouteTable, journals *JournalStore, w io.Writer) error {
+	if p.HasChanges() {
+		reason := fmt.Sprintf("update-routes cidr=%s peer-id=%s", p.CIDR, p.PeerID)
+		j := NewJournal(p.VPCID, reason, routeTables, time.Now())
+		if err := journals.Save(ctx, j); err != nil {
+			return fmt.Errorf("failed to journal route tables of VPC %s before updating routes: %w", p.VPCID, err)
+		}
+		fmt.Fprintf(w, "Saved route tables of VPC %s to journal %s\n", p.VPCID, j.ID)
+	}
+
+	for _, op := range p.Operations {
+		switch op.Action {
+		case RouteOperationCreate:
+			params := &ec2.CreateRouteInput{
+				RouteTableId:           aws.String(op.RouteTableID),
+				DestinationCidrBlock:   aws.String(op.DestinationCidrBlock),
+				VpcPeeringConnectionId: aws.String(op.PeerID),
+			}
+
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.CreateRoute
+			if _, err := ec2Client.CreateRoute(ctx, params); err != nil {
+				return fmt.Errorf("failed to create route for CIDR %s in route table %q (ID: %s): %w",
+					op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, err)
+			}
+			fmt.Fprintf(w, "[SUCCESS] Route for CIDR %s added to route table %q (ID: %s) with peer ID %s\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.PeerID)
+
+		case RouteOperationReplace:
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.ReplaceRoute
+			param := &ec2.ReplaceRouteInput{
+				RouteTableId:           aws.String(op.RouteTableID),
+				DestinationCidrBlock:   aws.String(op.DestinationCidrBlock),
+				VpcPeeringConnectionId: aws.String(op.PeerID),
+			}
+			if _, err := ec2Client.ReplaceRoute(ctx, param); err != nil {
+				return fmt.Errorf("failed to replace route for CIDR %s in route table %q (ID: %s): %w",
+					op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, err)
+			}
+			fmt.Fprintf(w, "[SUCCESS] Route for CIDR %s updated in route table %q (ID: %s) from peer ID %s to peer ID %s\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.CurrentPeerID, op.PeerID)
+
+		default:
+			fmt.Fprintf(w, "Route for CIDR %s already exists in route table %q (ID: %s) with peer ID %s, skipping\n",
+				op.DestinationCidrBlock, op.RouteTableName, op.RouteTableID, op.PeerID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package vpcrtb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouteTables() []ec2types.RouteTable {
+	return []ec2types.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-missing"),
+			Routes: []ec2types.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), Origin: ec2types.RouteOriginCreateRouteTable},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-other-peer"),
+			Routes: []ec2types.Route{
+				{DestinationCidrBlock: aws.String("192.168.1.0/24"), VpcPeeringConnectionId: aws.String("pcx-old"), Origin: ec2types.RouteOriginCreateRoute},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-no-peer"),
+			Routes: []ec2types.Route{
+				{DestinationCidrBlock: aws.String("192.168.1.0/24"), GatewayId: aws.String("igw-1"), Origin: ec2types.RouteOriginCreateRoute},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-done"),
+			Tags:         []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("done")}},
+			Routes: []ec2types.Route{
+				{DestinationCidrBlock: aws.String("192.168.1.0/24"), VpcPeeringConnectionId: aws.String("pcx-new"), Origin: ec2types.RouteOriginCreateRoute},
+			},
+		},
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	plan := BuildPlan("vpc-1", "192.168.1.0/24", "pcx-new", testRouteTables(), time.Now())
+
+	require.Equal(t, PlanVersion, plan.Version)
+	require.Len(t, plan.Observed, 4)
+	require.Equal(t, []RouteOperation{
+		{Action: RouteOperationCreate, RouteTableID: "rtb-missing", DestinationCidrBlock: "192.168.1.0/24", PeerID: "pcx-new"},
+		{Action: RouteOperationReplace, RouteTableID: "rtb-other-peer", DestinationCidrBlock: "192.168.1.0/24", CurrentPeerID: "pcx-old", PeerID: "pcx-new"},
+		{Action: RouteOperationReplace, RouteTableID: "rtb-no-peer", DestinationCidrBlock: "192.168.1.0/24", CurrentPeerID: "unset", PeerID: "pcx-new"},
+		{Action: RouteOperationSkip, RouteTableID: "rtb-done", RouteTableName: "done", DestinationCidrBlock: "192.168.1.0/24", CurrentPeerID: "pcx-new", PeerID: "pcx-new"},
+	}, plan.Operations)
+	require.True(t, plan.HasChanges())
+
+	var buf bytes.Buffer
+	plan.Print(&buf)
+	require.Contains(t, buf.String(), `[DRY RUN] Would add route for CIDR 192.168.1.0/24 to route table "" (ID: rtb-missing) with peer ID pcx-new`)
+	require.Contains(t, buf.String(), `[DRY RUN] Would update route for CIDR 192.168.1.0/24 in route table "" (ID: rtb-other-peer) from peer ID pcx-old to peer ID pcx-new`)
+	require.Contains(t, buf.String(), `already exists in route table "done" (ID: rtb-done) with peer ID pcx-new, skipping`)
+}
+
+func TestVerifyPlan(t *testing.T) {
+	plan := BuildPlan("vpc-1", "192.168.1.0/24", "pcx-new", testRouteTables(), time.Now())
+
+	snapshots := func(rtbs []ec2types.RouteTable) []RouteTableSnapshot {
+		var s []RouteTableSnapshot
+		for _, rtb := range rtbs {
+			s = append(s, newRouteTableSnapshot(rtb))
+		}
+		return s
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		require.NoError(t, VerifyPlan(plan, snapshots(testRouteTables())))
+	})
+
+	t.Run("routeStateIgnored", func(t *testing.T) {
+		rtbs := testRouteTables()
+		rtbs[1].Routes[0].State = ec2types.RouteStateBlackhole
+		require.NoError(t, VerifyPlan(plan, snapshots(rtbs)))
+	})
+
+	t.Run("targetChanged", func(t *testing.T) {
+		rtbs := testRouteTables()
+		rtbs[1].Routes[0].VpcPeeringConnectionId = aws.String("pcx-other")
+		require.ErrorContains(t, VerifyPlan(plan, snapshots(rtbs)), "rtb-other-peer")
+	})
+
+	t.Run("routeAdded", func(t *testing.T) {
+		rtbs := testRouteTables()
+		rtbs[0].Routes = append(rtbs[0].Routes, ec2types.Route{DestinationCidrBlock: aws.String("192.168.1.0/24"), VpcPeeringConnectionId: aws.String("pcx-new")})
+		require.ErrorContains(t, VerifyPlan(plan, snapshots(rtbs)), "rtb-missing")
+	})
+
+	t.Run("routeTableRemoved", func(t *testing.T) {
+		require.Error(t, VerifyPlan(plan, snapshots(testRouteTables()[1:])))
+	})
+}
+
+func TestPlanFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := BuildPlan("vpc-1", "192.168.1.0/24", "pcx-new", testRouteTables(), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, WritePlanFile(path, plan))
+	loaded, err := ReadPlanFile(path)
+	require.NoError(t, err)
+	require.Equal(t, plan, loaded)
+}
+
+func TestReadPlanFile_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := BuildPlan("vpc-1", "192.168.1.0/24", "pcx-new", nil, time.Now())
+	plan.Version = PlanVersion + 1
+
+	require.NoError(t, WritePlanFile(path, plan))
+	_, err := ReadPlanFile(path)
+	require.ErrorContains(t, err, "unsupported plan version")
+}
@@ -0,0 +1,214 @@
+package vpcrtb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// RouteChangeAction is the kind of change needed to bring a route back to its journaled state.
+type RouteChangeAction string
+
+const (
+	RouteChangeCreate  RouteChangeAction = "create"
+	RouteChangeReplace RouteChangeAction = "replace"
+	RouteChangeDelete  RouteChangeAction = "delete"
+)
+
+// RouteChange describes a single difference between the journaled and the current routes of a route table.
+type RouteChange struct {
+	Action       RouteChangeAction
+	RouteTableID string
+	Saved        *Route // nil for RouteChangeDelete
+	Current      *Route // nil for RouteChangeCreate
+}
+
+// String returns the change in a diff-like notation.
+func (c RouteChange) String() string {
+	switch c.Action {
+	case RouteChangeCreate:
+		return fmt.Sprintf("+ %s %s -> %s", c.RouteTableID, c.Saved.Destination(), c.Saved.Target())
+	case RouteChangeReplace:
+		return fmt.Sprintf("~ %s %s -> %s (currently %s)", c.RouteTableID, c.Saved.Destination(), c.Saved.Target(), c.Current.Target())
+	default:
+		return fmt.Sprintf("- %s %s -> %s", c.RouteTableID, c.Current.Destination(), c.Current.Target())
+	}
+}
+
+// DiffRoutes compares the journaled routes of a route table with its current routes and
+// returns the changes needed to restore the journaled state. Routes owned by AWS are ignored.
+func DiffRoutes(routeTableID string, saved, current []Route) []RouteChange {
+	currentByDest := make(map[string]Route, len(current))
+	for _, rt := range current {
+		if rt.managed() {
+			currentByDest[rt.Destination()] = rt
+		}
+	}
+
+	var changes []RouteChange
+	savedDests := make(map[string]bool, len(saved))
+	for _, rt := range saved {
+		if !rt.managed() {
+			continue
+		}
+		savedDests[rt.Destination()] = true
+
+		cur, ok := currentByDest[rt.Destination()]
+		switch {
+		case !ok:
+			changes = append(changes, RouteChange{Action: RouteChangeCreate, RouteTableID: routeTableID, Saved: &rt})
+		case cur.Target() != rt.Target():
+			changes = append(changes, RouteChange{Action: RouteChangeReplace, RouteTableID: routeTableID, Saved: &rt, Current: &cur})
+		}
+	}
+
+	for _, rt := range current {
+		if rt.managed() && !savedDests[rt.Destination()] {
+			changes = append(changes, RouteChange{Action: RouteChangeDelete, RouteTableID: routeTableID, Current: &rt})
+		}
+	}
+
+	return changes
+}
+
+// RestoreRoutes puts the routes recorded in the journal back to the route tables.
+// The current state is journaled to the given store before any change, so a restore can be undone as well.
+// If dryRun is true, only the diff is printed.
+func RestoreRoutes(ctx context.Context, ec2Client EC2API, j *Journal, journals *JournalStore, dryRun bool, w io.Writer) error {
+	rtbIDs := make([]string, 0, len(j.RouteTables))
+	for _, rtb := range j.RouteTables {
+		rtbIDs = append(rtbIDs, rtb.RouteTableID)
+	}
+
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeRouteTables
+	rtOutput, err := ec2Client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{RouteTableIds: rtbIDs})
+	if err != nil {
+		return fmt.Errorf("failed to describe route tables %v: %w", rtbIDs, err)
+	}
+
+	currentByID := make(map[string]RouteTableSnapshot, len(rtOutput.RouteTables))
+	for _, rtb := range rtOutput.RouteTables {
+		snapshot := newRouteTableSnapshot(rtb)
+		currentByID[snapshot.RouteTableID] = snapshot
+	}
+
+	var changes []RouteChange
+	for _, saved := range j.RouteTables {
+		current, ok := currentByID[saved.RouteTableID]
+		if !ok {
+			return fmt.Errorf("route table %q (ID: %s) recorded in journal %s no longer exists", saved.Name, saved.RouteTableID, j.ID)
+		}
+		changes = append(changes, DiffRoutes(saved.RouteTableID, saved.Routes, current.Routes)...)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "Routes of VPC %s already match journal %s, nothing to restore\n", j.VPCID, j.ID)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%d route changes to restore journal %s (VPC %s, taken at %s):\n", len(changes), j.ID, j.VPCID, j.CreatedAt.Format(time.RFC3339))
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+
+	if dryRun {
+		fmt.Fprintln(w, "[DRY RUN] No changes were made")
+		return nil
+	}
+
+	backup := NewJournal(j.VPCID, "restore journal "+j.ID, rtOutput.RouteTables, time.Now())
+	if err := journals.Save(ctx, backup); err != nil {
+		return fmt.Errorf("failed to journal route tables before restoring: %w", err)
+	}
+	fmt.Fprintf(w, "Saved current route tables to journal %s\n", backup.ID)
+
+	for _, change := range changes {
+		if err := applyRouteChange(ctx, ec2Client, change); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "[SUCCESS] %s\n", change)
+	}
+
+	return nil
+}
+
+func applyRouteChange(ctx context.Context, ec2Client EC2API, change RouteChange) error {
+	switch change.Action {
+	case RouteChangeCreate:
+		rt := change.Saved
+		// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.CreateRoute
+		param := &ec2.CreateRouteInput{
+			RouteTableId:                aws.String(change.RouteTableID),
+			DestinationCidrBlock:        optional(rt.DestinationCidrBlock),
+			DestinationIpv6CidrBlock:    optional(rt.DestinationIpv6CidrBlock),
+			DestinationPrefixListId:     optional(rt.DestinationPrefixListID),
+			GatewayId:                   optional(rt.GatewayID),
+			NatGatewayId:                optional(rt.NatGatewayID),
+			TransitGatewayId:            optional(rt.TransitGatewayID),
+			VpcPeeringConnectionId:      optional(rt.VpcPeeringConnectionID),
+			NetworkInterfaceId:          optional(rt.NetworkInterfaceID),
+			EgressOnlyInternetGatewayId: optional(rt.EgressOnlyInternetGatewayID),
+			LocalGatewayId:              optional(rt.LocalGatewayID),
+			CarrierGatewayId:            optional(rt.CarrierGatewayID),
+			CoreNetworkArn:              optional(rt.CoreNetworkArn),
+		}
+		if rt.NetworkInterfaceID == "" {
+			param.InstanceId = optional(rt.InstanceID)
+		}
+		if _, err := ec2Client.CreateRoute(ctx, param); err != nil {
+			return fmt.Errorf("failed to create route for %s in route table %s: %w", rt.Destination(), change.RouteTableID, err)
+		}
+
+	case RouteChangeReplace:
+		rt := change.Saved
+		// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.ReplaceRoute
+		param := &ec2.ReplaceRouteInput{
+			RouteTableId:                aws.String(change.RouteTableID),
+			DestinationCidrBlock:        optional(rt.DestinationCidrBlock),
+			DestinationIpv6CidrBlock:    optional(rt.DestinationIpv6CidrBlock),
+			DestinationPrefixListId:     optional(rt.DestinationPrefixListID),
+			GatewayId:                   optional(rt.GatewayID),
+			NatGatewayId:                optional(rt.NatGatewayID),
+			TransitGatewayId:            optional(rt.TransitGatewayID),
+			VpcPeeringConnectionId:      optional(rt.VpcPeeringConnectionID),
+			NetworkInterfaceId:          optional(rt.NetworkInterfaceID),
+			EgressOnlyInternetGatewayId: optional(rt.EgressOnlyInternetGatewayID),
+			LocalGatewayId:              optional(rt.LocalGatewayID),
+			CarrierGatewayId:            optional(rt.CarrierGatewayID),
+			CoreNetworkArn:              optional(rt.CoreNetworkArn),
+		}
+		if rt.NetworkInterfaceID == "" {
+			param.InstanceId = optional(rt.InstanceID)
+		}
+		if _, err := ec2Client.ReplaceRoute(ctx, param); err != nil {
+			return fmt.Errorf("failed to replace route for %s in route table %s: %w", rt.Destination(), change.RouteTableID, err)
+		}
+
+	case RouteChangeDelete:
+		rt := change.Current
+		// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DeleteRoute
+		param := &ec2.DeleteRouteInput{
+			RouteTableId:             aws.String(change.RouteTableID),
+			DestinationCidrBlock:     optional(rt.DestinationCidrBlock),
+			DestinationIpv6CidrBlock: optional(rt.DestinationIpv6CidrBlock),
+			DestinationPrefixListId:  optional(rt.DestinationPrefixListID),
+		}
+		if _, err := ec2Client.DeleteRoute(ctx, param); err != nil {
+			return fmt.Errorf("failed to delete route for %s in route table %s: %w", rt.Destination(), change.RouteTableID, err)
+		}
+	}
+
+	return nil
+}
+
+// optional returns nil for an empty string so that unset fields are omitted from the request.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
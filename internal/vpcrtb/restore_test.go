@@ -0,0 +1,103 @@
+package vpcrtb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRoutes(t *testing.T) {
+	local := Route{DestinationCidrBlock: "10.0.0.0/16", GatewayID: "local", Origin: "CreateRouteTable"}
+	igw := Route{DestinationCidrBlock: "0.0.0.0/0", GatewayID: "igw-1", Origin: "CreateRoute"}
+	peer1 := Route{DestinationCidrBlock: "172.16.0.0/16", VpcPeeringConnectionID: "pcx-1", Origin: "CreateRoute"}
+	peer2 := Route{DestinationCidrBlock: "172.16.0.0/16", VpcPeeringConnectionID: "pcx-2", Origin: "CreateRoute"}
+	added := Route{DestinationCidrBlock: "192.168.0.0/24", VpcPeeringConnectionID: "pcx-3", Origin: "CreateRoute"}
+
+	tests := []struct {
+		name     string
+		saved    []Route
+		current  []Route
+		expected []RouteChangeAction
+	}{
+		{"unchanged", []Route{local, igw, peer1}, []Route{local, igw, peer1}, nil},
+		{"replaced", []Route{local, peer1}, []Route{local, peer2}, []RouteChangeAction{RouteChangeReplace}},
+		{"removed", []Route{local, igw, peer1}, []Route{local, igw}, []RouteChangeAction{RouteChangeCreate}},
+		{"added", []Route{local}, []Route{local, added}, []RouteChangeAction{RouteChangeDelete}},
+		{"localRouteIgnored", []Route{igw}, []Route{local, igw}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffRoutes("rtb-1", tt.saved, tt.current)
+			var actions []RouteChangeAction
+			for _, c := range changes {
+				actions = append(actions, c.Action)
+			}
+			require.Equal(t, tt.expected, actions)
+		})
+	}
+}
+
+func TestRouteChange_String(t *testing.T) {
+	saved := Route{DestinationCidrBlock: "172.16.0.0/16", VpcPeeringConnectionID: "pcx-1"}
+	current := Route{DestinationCidrBlock: "172.16.0.0/16", VpcPeeringConnectionID: "pcx-2"}
+
+	require.Equal(t, "+ rtb-1 172.16.0.0/16 -> pcx-1", RouteChange{Action: RouteChangeCreate, RouteTableID: "rtb-1", Saved: &saved}.String())
+	require.Equal(t, "~ rtb-1 172.16.0.0/16 -> pcx-1 (currently pcx-2)", RouteChange{Action: RouteChangeReplace, RouteTableID: "rtb-1", Saved: &saved, Current: &current}.String())
+	require.Equal(t, "- rtb-1 172.16.0.0/16 -> pcx-2", RouteChange{Action: RouteChangeDelete, RouteTableID: "rtb-1", Current: &current}.String())
+}
+
+func TestJournalStore_SaveLoadList(t *testing.T) {
+	ctx := context.Background()
+	journals := NewJournalStore(blob.NewLocalStore(t.TempDir()))
+
+	routeTables := []ec2types.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-1"),
+			Tags:         []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("main")}},
+			Routes: []ec2types.Route{
+				{DestinationCidrBlock: aws.String("172.16.0.0/16"), VpcPeeringConnectionId: aws.String("pcx-1"), Origin: ec2types.RouteOriginCreateRoute},
+			},
+		},
+	}
+	j := NewJournal("vpc-1", "test", routeTables, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.Equal(t, "20250102T030405.000000000Z-vpc-1", j.ID)
+	require.NoError(t, journals.Save(ctx, j))
+
+	loaded, err := journals.Load(ctx, j.ID)
+	require.NoError(t, err)
+	require.Equal(t, j, loaded)
+	require.Equal(t, "main", loaded.RouteTables[0].Name)
+	require.Equal(t, "pcx-1", loaded.RouteTables[0].Routes[0].Target())
+
+	ids, err := journals.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{j.ID}, ids)
+}
+
+func TestJournalStore_SaveWithinSameSecond(t *testing.T) {
+	ctx := context.Background()
+	journals := NewJournalStore(blob.NewLocalStore(t.TempDir()))
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := NewJournal("vpc-1", "first", nil, now)
+	second := NewJournal("vpc-1", "second", nil, now.Add(300*time.Millisecond))
+	require.NotEqual(t, first.ID, second.ID)
+	require.NoError(t, journals.Save(ctx, first))
+	require.NoError(t, journals.Save(ctx, second))
+
+	// The same ID is refused rather than overwriting the first journal
+	err := journals.Save(ctx, NewJournal("vpc-1", "again", nil, now))
+	require.ErrorContains(t, err, "already exists")
+	loaded, err := journals.Load(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "first", loaded.Reason)
+
+	ids, err := journals.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{first.ID, second.ID}, ids)
+}
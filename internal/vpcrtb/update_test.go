@@ -0,0 +1,97 @@
+package vpcrtb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"github.com/sgykfjsm/msk/internal/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeEC2() *awsfake.EC2 {
+	fake := awsfake.NewEC2()
+	for _, rtb := range testRouteTables() {
+		rtb.VpcId = aws.String("vpc-1")
+		fake.AddRouteTable(rtb)
+	}
+	return fake
+}
+
+func peerOf(t *testing.T, fake *awsfake.EC2, rtbID, cidr string) string {
+	t.Helper()
+	rtb := fake.RouteTable(rtbID)
+	require.NotNil(t, rtb)
+	for _, rt := range rtb.Routes {
+		if aws.ToString(rt.DestinationCidrBlock) == cidr {
+			return aws.ToString(rt.VpcPeeringConnectionId)
+		}
+	}
+	return ""
+}
+
+func TestUpdateRoutes_AndRestore(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeEC2()
+	journals := NewJournalStore(blob.NewLocalStore(t.TempDir()))
+
+	var buf bytes.Buffer
+	require.NoError(t, UpdateRoutes(ctx, fake, "vpc-1", "192.168.1.0/24", "pcx-new", true, journals, &buf))
+	require.Contains(t, buf.String(), "[DRY RUN] Would add route")
+	require.Equal(t, "", peerOf(t, fake, "rtb-missing", "192.168.1.0/24"), "dry run must not change routes")
+
+	buf.Reset()
+	require.NoError(t, UpdateRoutes(ctx, fake, "vpc-1", "192.168.1.0/24", "pcx-new", false, journals, &buf))
+	for _, id := range []string{"rtb-missing", "rtb-other-peer", "rtb-no-peer", "rtb-done"} {
+		require.Equal(t, "pcx-new", peerOf(t, fake, id, "192.168.1.0/24"), id)
+	}
+
+	ids, err := journals.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	j, err := journals.Load(ctx, ids[0])
+	require.NoError(t, err)
+
+	buf.Reset()
+	require.NoError(t, RestoreRoutes(ctx, fake, j, journals, false, &buf))
+	require.Equal(t, "", peerOf(t, fake, "rtb-missing", "192.168.1.0/24"))
+	require.Equal(t, "pcx-old", peerOf(t, fake, "rtb-other-peer", "192.168.1.0/24"))
+	require.Equal(t, "", peerOf(t, fake, "rtb-no-peer", "192.168.1.0/24"))
+	require.Equal(t, "igw-1", aws.ToString(fake.RouteTable("rtb-no-peer").Routes[0].GatewayId))
+
+	// The restore itself is journaled
+	require.Contains(t, buf.String(), "Saved current route tables to journal")
+}
+
+func TestApplyPlan_RefusesChangedRouteTables(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeEC2()
+	journals := NewJournalStore(blob.NewLocalStore(t.TempDir()))
+
+	plan, err := PlanRoutes(ctx, fake, "vpc-1", "192.168.1.0/24", "pcx-new")
+	require.NoError(t, err)
+	require.True(t, plan.HasChanges())
+
+	_, err = fake.CreateRoute(ctx, &ec2.CreateRouteInput{
+		RouteTableId:         aws.String("rtb-done"),
+		DestinationCidrBlock: aws.String("172.16.0.0/21"),
+		GatewayId:            aws.String("igw-1"),
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = ApplyPlan(ctx, fake, plan, journals, &buf)
+	require.ErrorContains(t, err, "refusing to apply")
+	require.Equal(t, "", peerOf(t, fake, "rtb-missing", "192.168.1.0/24"))
+
+	// A fresh plan applies cleanly
+	plan, err = PlanRoutes(ctx, fake, "vpc-1", "192.168.1.0/24", "pcx-new")
+	require.NoError(t, err)
+	require.NoError(t, ApplyPlan(ctx, fake, plan, journals, &buf))
+	require.Equal(t, "pcx-new", peerOf(t, fake, "rtb-missing", "192.168.1.0/24"))
+	require.Equal(t, ec2types.RouteStateActive, fake.RouteTable("rtb-missing").Routes[1].State)
+}
@@ -0,0 +1,234 @@
+package vpcsg
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/util"
+)
+
+// This module ensures that security groups allow the TiDB traffic to and from a TiDB Cloud CIDR or prefix list,
+// in the same way as vpcrtb.UpdateRoutes reconciles routes:
+// - If the security group already has the rule, skip it
+// - If the security group does not have the rule, authorize it
+// With revoke, existing rules are revoked and missing ones are skipped.
+
+// Direction is the direction of a security group rule.
+type Direction string
+
+const (
+	Ingress Direction = "ingress"
+	Egress  Direction = "egress"
+)
+
+// Rule is a TCP rule for a port range from or to a CIDR or a prefix list.
+// Exactly one of CIDR and PrefixListID is set.
+type Rule struct {
+	Direction    Direction
+	FromPort     int32
+	ToPort       int32
+	CIDR         string
+	PrefixListID string
+	Description  string
+}
+
+// Peer returns the CIDR or the prefix list of the rule.
+func (r Rule) Peer() string {
+	if r.PrefixListID != "" {
+		return r.PrefixListID
+	}
+	return r.CIDR
+}
+
+func (r Rule) String() string {
+	ports := fmt.Sprintf("%d", r.FromPort)
+	if r.FromPort != r.ToPort {
+		ports = fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+	}
+	if r.Direction == Ingress {
+		return fmt.Sprintf("ingress tcp/%s from %s", ports, r.Peer())
+	}
+	return fmt.Sprintf("egress tcp/%s to %s", ports, r.Peer())
+}
+
+// matches reports whether the permission contains the rule.
+// The protocol and the port range must be identical; wider permissions such as "all traffic" are not taken into account,
+// because only identical permissions can be revoked.
+func (r Rule) matches(perm ec2types.IpPermission) bool {
+	if aws.ToString(perm.IpProtocol) != "tcp" || aws.ToInt32(perm.FromPort) != r.FromPort || aws.ToInt32(perm.ToPort) != r.ToPort {
+		return false
+	}
+	if r.PrefixListID != "" {
+		for _, pl := range perm.PrefixListIds {
+			if aws.ToString(pl.PrefixListId) == r.PrefixListID {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ipRange := range perm.IpRanges {
+		if aws.ToString(ipRange.CidrIp) == r.CIDR {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) ipPermission() ec2types.IpPermission {
+	perm := ec2types.IpPermission{
+		IpProtocol: aws.String("tcp"),
+		FromPort:   aws.Int32(r.FromPort),
+		ToPort:     aws.Int32(r.ToPort),
+	}
+	var description *string
+	if r.Description != "" {
+		description = aws.String(r.Description)
+	}
+	if r.PrefixListID != "" {
+		perm.PrefixListIds = []ec2types.PrefixListId{{PrefixListId: aws.String(r.PrefixListID), Description: description}}
+	} else {
+		perm.IpRanges = []ec2types.IpRange{{CidrIp: aws.String(r.CIDR), Description: description}}
+	}
+	return perm
+}
+
+// EC2API is the subset of the EC2 API used to read and change security group rules.
+type EC2API interface {
+	DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+	AuthorizeSecurityGroupIngress(ctx context.Context, params *ec2.AuthorizeSecurityGroupIngressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
+	AuthorizeSecurityGroupEgress(ctx context.Context, params *ec2.AuthorizeSecurityGroupEgressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupEgressOutput, error)
+	RevokeSecurityGroupIngress(ctx context.Context, params *ec2.RevokeSecurityGroupIngressInput, optFns ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupIngressOutput, error)
+	RevokeSecurityGroupEgress(ctx context.Context, params *ec2.RevokeSecurityGroupEgressInput, optFns ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupEgressOutput, error)
+}
+
+// OperationAction is the kind of operation for a security group rule.
+type OperationAction string
+
+const (
+	OperationAuthorize OperationAction = "authorize"
+	OperationRevoke    OperationAction = "revoke"
+	OperationSkip      OperationAction = "skip"
+)
+
+// Operation is a single operation on a security group.
+type Operation struct {
+	Action    OperationAction
+	GroupID   string
+	GroupName string
+	Rule      Rule
+	Exists    bool // whether the security group had the rule when the operation was built
+}
+
+// BuildOperations computes the operations needed to make the rules exist in (or, with revoke, be absent from)
+// each of the given security groups.
+func BuildOperations(groups []ec2types.SecurityGroup, rules []Rule, revoke bool) []Operation {
+	ops := make([]Operation, 0, len(groups)*len(rules))
+	for _, sg := range groups {
+		name := util.GetNameFromTags(sg.Tags)
+		if name == "" {
+			name = aws.ToString(sg.GroupName)
+		}
+
+		for _, rule := range rules {
+			perms := sg.IpPermissionsEgress
+			if rule.Direction == Ingress {
+				perms = sg.IpPermissions
+			}
+
+			found := false
+			for _, perm := range perms {
+				if rule.matches(perm) {
+					found = true
+					break
+				}
+			}
+
+			op := Operation{Action: OperationSkip, GroupID: aws.ToString(sg.GroupId), GroupName: name, Rule: rule, Exists: found}
+			if found && revoke {
+				op.Action = OperationRevoke
+			} else if !found && !revoke {
+				op.Action = OperationAuthorize
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// PrintOperations writes the operations as `--dry-run` output.
+func PrintOperations(w io.Writer, ops []Operation) {
+	for _, op := range ops {
+		switch op.Action {
+		case OperationAuthorize:
+			fmt.Fprintf(w, "[DRY RUN] Would authorize %s in security group %q (ID: %s)\n", op.Rule, op.GroupName, op.GroupID)
+		case OperationRevoke:
+			fmt.Fprintf(w, "[DRY RUN] Would revoke %s in security group %q (ID: %s)\n", op.Rule, op.GroupName, op.GroupID)
+		default:
+			printSkip(w, op)
+		}
+	}
+}
+
+func printSkip(w io.Writer, op Operation) {
+	if op.Exists {
+		fmt.Fprintf(w, "Rule %s already exists in security group %q (ID: %s), skipping\n", op.Rule, op.GroupName, op.GroupID)
+	} else {
+		fmt.Fprintf(w, "Rule %s does not exist in security group %q (ID: %s), skipping\n", op.Rule, op.GroupName, op.GroupID)
+	}
+}
+
+// EnsureRules authorizes the rules in the given security groups, or revokes them if revoke is true.
+// If dryRun is true, only the planned operations are printed.
+func EnsureRules(ctx context.Context, ec2Client EC2API, groupIDs []string, rules []Rule, revoke, dryRun bool, w io.Writer) error {
+	// 1. Find the security groups
+	// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.DescribeSecurityGroups
+	output, err := ec2Client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: groupIDs})
+	if err != nil {
+		return fmt.Errorf("failed to describe security groups %v: %w", groupIDs, err)
+	}
+	fmt.Fprintf(w, "Found %d security groups\n", len(output.SecurityGroups))
+
+	// 2. Check the rules one by one. If dryRun is true, only simulate the update without making changes.
+	ops := BuildOperations(output.SecurityGroups, rules, revoke)
+	if dryRun {
+		PrintOperations(w, ops)
+		return nil
+	}
+
+	for _, op := range ops {
+		perms := []ec2types.IpPermission{op.Rule.ipPermission()}
+		switch {
+		case op.Action == OperationAuthorize && op.Rule.Direction == Ingress:
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.AuthorizeSecurityGroupIngress
+			_, err = ec2Client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{GroupId: aws.String(op.GroupID), IpPermissions: perms})
+		case op.Action == OperationAuthorize:
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.AuthorizeSecurityGroupEgress
+			_, err = ec2Client.AuthorizeSecurityGroupEgress(ctx, &ec2.AuthorizeSecurityGroupEgressInput{GroupId: aws.String(op.GroupID), IpPermissions: perms})
+		case op.Action == OperationRevoke && op.Rule.Direction == Ingress:
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.RevokeSecurityGroupIngress
+			_, err = ec2Client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{GroupId: aws.String(op.GroupID), IpPermissions: perms})
+		case op.Action == OperationRevoke:
+			// Ref: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/ec2#Client.RevokeSecurityGroupEgress
+			_, err = ec2Client.RevokeSecurityGroupEgress(ctx, &ec2.RevokeSecurityGroupEgressInput{GroupId: aws.String(op.GroupID), IpPermissions: perms})
+		default:
+			printSkip(w, op)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s in security group %q (ID: %s): %w", op.Action, op.Rule, op.GroupName, op.GroupID, err)
+		}
+
+		verb := "authorized"
+		if op.Action == OperationRevoke {
+			verb = "revoked"
+		}
+		fmt.Fprintf(w, "[SUCCESS] Rule %s %s in security group %q (ID: %s)\n", op.Rule, verb, op.GroupName, op.GroupID)
+	}
+
+	return nil
+}
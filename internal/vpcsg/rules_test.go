@@ -0,0 +1,135 @@
+package vpcsg
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sgykfjsm/msk/internal/awsfake"
+	"github.com/stretchr/testify/require"
+)
+
+func tcpPermission(from, to int32, cidrs []string, prefixLists []string) ec2types.IpPermission {
+	perm := ec2types.IpPermission{IpProtocol: aws.String("tcp"), FromPort: aws.Int32(from), ToPort: aws.Int32(to)}
+	for _, cidr := range cidrs {
+		perm.IpRanges = append(perm.IpRanges, ec2types.IpRange{CidrIp: aws.String(cidr)})
+	}
+	for _, pl := range prefixLists {
+		perm.PrefixListIds = append(perm.PrefixListIds, ec2types.PrefixListId{PrefixListId: aws.String(pl)})
+	}
+	return perm
+}
+
+func TestBuildOperations(t *testing.T) {
+	groups := []ec2types.SecurityGroup{
+		{
+			GroupId:             aws.String("sg-app"),
+			GroupName:           aws.String("app"),
+			IpPermissionsEgress: []ec2types.IpPermission{tcpPermission(4000, 4000, []string{"10.0.0.0/16", "172.16.0.0/21"}, nil)},
+		},
+		{
+			GroupId:   aws.String("sg-batch"),
+			GroupName: aws.String("batch-default"),
+			Tags:      []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("batch")}},
+			IpPermissions: []ec2types.IpPermission{
+				tcpPermission(4000, 4000, nil, []string{"pl-0123"}),
+				// all traffic does not count as the rule
+				{IpProtocol: aws.String("-1"), IpRanges: []ec2types.IpRange{{CidrIp: aws.String("172.16.0.0/21")}}},
+			},
+			IpPermissionsEgress: []ec2types.IpPermission{tcpPermission(3000, 5000, []string{"172.16.0.0/21"}, nil)},
+		},
+	}
+
+	egress := Rule{Direction: Egress, FromPort: 4000, ToPort: 4000, CIDR: "172.16.0.0/21"}
+	ingress := Rule{Direction: Ingress, FromPort: 4000, ToPort: 4000, CIDR: "172.16.0.0/21"}
+	ingressPL := Rule{Direction: Ingress, FromPort: 4000, ToPort: 4000, PrefixListID: "pl-0123"}
+
+	tests := []struct {
+		name   string
+		rules  []Rule
+		revoke bool
+		want   map[string]OperationAction // "group rule" -> action
+	}{
+		{
+			name:  "ensure",
+			rules: []Rule{egress, ingress, ingressPL},
+			want: map[string]OperationAction{
+				"sg-app " + egress.String():      OperationSkip,
+				"sg-app " + ingress.String():     OperationAuthorize,
+				"sg-app " + ingressPL.String():   OperationAuthorize,
+				"sg-batch " + egress.String():    OperationAuthorize,
+				"sg-batch " + ingress.String():   OperationAuthorize,
+				"sg-batch " + ingressPL.String(): OperationSkip,
+			},
+		},
+		{
+			name:   "revoke",
+			rules:  []Rule{egress, ingressPL},
+			revoke: true,
+			want: map[string]OperationAction{
+				"sg-app " + egress.String():      OperationRevoke,
+				"sg-app " + ingressPL.String():   OperationSkip,
+				"sg-batch " + egress.String():    OperationSkip,
+				"sg-batch " + ingressPL.String(): OperationRevoke,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := BuildOperations(groups, tt.rules, tt.revoke)
+			got := make(map[string]OperationAction, len(ops))
+			for _, op := range ops {
+				got[op.GroupID+" "+op.Rule.String()] = op.Action
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, "batch", ops[len(ops)-1].GroupName)
+		})
+	}
+}
+
+func TestPrintOperations(t *testing.T) {
+	rule := Rule{Direction: Egress, FromPort: 4000, ToPort: 4000, CIDR: "172.16.0.0/21"}
+	ops := []Operation{
+		{Action: OperationAuthorize, GroupID: "sg-1", GroupName: "app", Rule: rule},
+		{Action: OperationSkip, GroupID: "sg-2", GroupName: "batch", Rule: rule, Exists: true},
+	}
+
+	var buf bytes.Buffer
+	PrintOperations(&buf, ops)
+	require.Equal(t,
+		"[DRY RUN] Would authorize egress tcp/4000 to 172.16.0.0/21 in security group \"app\" (ID: sg-1)\n"+
+			"Rule egress tcp/4000 to 172.16.0.0/21 already exists in security group \"batch\" (ID: sg-2), skipping\n",
+		buf.String())
+}
+
+func TestEnsureRules(t *testing.T) {
+	ctx := context.Background()
+	fake := awsfake.NewEC2()
+	fake.AddSecurityGroup(ec2types.SecurityGroup{
+		GroupId:             aws.String("sg-app"),
+		GroupName:           aws.String("app"),
+		IpPermissionsEgress: []ec2types.IpPermission{tcpPermission(4000, 4000, []string{"172.16.0.0/21"}, nil)},
+	})
+
+	rules := []Rule{
+		{Direction: Ingress, FromPort: 4000, ToPort: 4000, CIDR: "172.16.0.0/21"},
+		{Direction: Egress, FromPort: 4000, ToPort: 4000, CIDR: "172.16.0.0/21"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, EnsureRules(ctx, fake, []string{"sg-app"}, rules, false, false, &buf))
+	sg := fake.SecurityGroup("sg-app")
+	require.Len(t, sg.IpPermissions, 1)
+	require.Len(t, sg.IpPermissionsEgress, 1)
+
+	// Running it again changes nothing
+	require.NoError(t, EnsureRules(ctx, fake, []string{"sg-app"}, rules, false, false, &buf))
+
+	require.NoError(t, EnsureRules(ctx, fake, []string{"sg-app"}, rules, true, false, &buf))
+	sg = fake.SecurityGroup("sg-app")
+	require.Empty(t, sg.IpPermissions)
+	require.Empty(t, sg.IpPermissionsEgress)
+}
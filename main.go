@@ -16,29 +16,28 @@ func main() {
 		Name:    "msk",
 		Usage:   "Manage TiDB Cloud clusters, Support daily operations, and Keep your workloads efficient",
 		Version: Version,
+		Flags:   mskcmd.AWSFlags,
 
 		Commands: []*cli.Command{
 			mskcmd.FetchProjectsCmd,
 			mskcmd.FetchClustersCmd,
-			{
-				Name:  "generate-notice",
-				Usage: "Generate a notice from the information collected by fetch-clusters and save it to S3",
-				Action: func(ctx context.Context, cmd *cli.Command) error {
-					slog.Info("Generating notice from collected information...")
-					return nil
-				},
-			},
-			{
-				Name:  "notify",
-				Usage: "Notify via messaging service using the generated notice",
-				Action: func(ctx context.Context, cmd *cli.Command) error {
-					slog.Info("Sending notification with the generated notice...")
-					return nil
-				},
-			},
+			mskcmd.TrackBackupsCmd,
+			mskcmd.AnalyzeCmd,
+			mskcmd.RestoreCmd,
+			mskcmd.DriftCmd,
+			mskcmd.ClusterCmd,
+			mskcmd.GenerateNoticeCmd,
+			mskcmd.NotifyCmd,
+			mskcmd.OwnersCmd,
+			mskcmd.ExemptCmd,
+			mskcmd.ReportCmd,
 			mskcmd.ShowVPCInfoCmd,
 			mskcmd.AcceptPeeringCmd,
+			mskcmd.PeeringCmd,
 			mskcmd.UpdateRoutesCmd,
+			mskcmd.RoutesCmd,
+			mskcmd.NetworkCmd,
+			mskcmd.PrivateEndpointCmd,
 		},
 	}
 